@@ -0,0 +1,188 @@
+package containermanger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// buildkitTraceID : id of the json message, which holds the BuildKit progress in the aux field
+const buildkitTraceID = "moby.buildkit.trace"
+
+// buildVertex : step of the BuildKit build
+type buildVertex struct {
+	index      int
+	name       string
+	isStarted  bool
+	isCached   bool
+	isComplete bool
+	isErrored  bool
+}
+
+// buildProgressPrinter : converts the BuildKit progress to plain text logs, same as `docker build --progress=plain`
+type buildProgressPrinter struct {
+	vertexes map[string]*buildVertex
+}
+
+// newBuildLogStream : converts the BuildKit build response to the json stream of the classic builder
+// so that the build logs can be read from the `stream` and `error` fields of the messages
+func newBuildLogStream(body io.ReadCloser, onClose func()) io.Reader {
+	reader, writer := io.Pipe()
+	go func() {
+		defer func() {
+			_ = body.Close()
+			onClose()
+		}()
+		printer := &buildProgressPrinter{vertexes: make(map[string]*buildVertex)}
+		decoder := json.NewDecoder(body)
+		encoder := json.NewEncoder(writer)
+		for {
+			var message jsonmessage.JSONMessage
+			if err := decoder.Decode(&message); err != nil {
+				if err == io.EOF {
+					_ = writer.Close()
+				} else {
+					_ = writer.CloseWithError(err)
+				}
+				return
+			}
+			if message.ID == buildkitTraceID && message.Aux != nil {
+				var trace []byte
+				if err := json.Unmarshal(*message.Aux, &trace); err != nil {
+					continue
+				}
+				logs := printer.print(trace)
+				if logs == "" {
+					continue
+				}
+				message = jsonmessage.JSONMessage{Stream: logs}
+			}
+			if err := encoder.Encode(message); err != nil {
+				return
+			}
+		}
+	}()
+	return reader
+}
+
+// print : logs for the StatusResponse of the BuildKit control api
+func (p *buildProgressPrinter) print(trace []byte) string {
+	var logs strings.Builder
+	_ = consumeFields(trace, func(number protowire.Number, value []byte) {
+		switch number {
+		case 1: // vertexes
+			p.printVertex(&logs, value)
+		case 3: // logs
+			p.printVertexLog(&logs, value)
+		case 4: // warnings
+			p.printVertexWarning(&logs, value)
+		}
+	})
+	return logs.String()
+}
+
+func (p *buildProgressPrinter) vertex(digest string) *buildVertex {
+	vertex, ok := p.vertexes[digest]
+	if !ok {
+		vertex = &buildVertex{index: len(p.vertexes) + 1}
+		p.vertexes[digest] = vertex
+	}
+	return vertex
+}
+
+func (p *buildProgressPrinter) printVertex(logs *strings.Builder, data []byte) {
+	var digest, name, errorMessage string
+	isCached, isStarted, isCompleted := false, false, false
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return
+		}
+		data = data[n:]
+		if number == 4 && wireType == protowire.VarintType {
+			value, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return
+			}
+			isCached = value == 1
+			data = data[n:]
+			continue
+		}
+		if wireType == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return
+			}
+			switch number {
+			case 1:
+				digest = string(value)
+			case 3:
+				name = string(value)
+			case 5:
+				isStarted = true
+			case 6:
+				isCompleted = true
+			case 7:
+				errorMessage = string(value)
+			}
+			data = data[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(number, wireType, data)
+		if n < 0 {
+			return
+		}
+		data = data[n:]
+	}
+	vertex := p.vertex(digest)
+	if name != "" {
+		vertex.name = name
+	}
+	if (isStarted || isCached || isCompleted) && !vertex.isStarted {
+		vertex.isStarted = true
+		logs.WriteString(fmt.Sprintf("#%d %s\n", vertex.index, vertex.name))
+	}
+	if isCached && !vertex.isCached {
+		vertex.isCached = true
+		logs.WriteString(fmt.Sprintf("#%d CACHED\n", vertex.index))
+	}
+	if errorMessage != "" && !vertex.isErrored {
+		vertex.isErrored = true
+		logs.WriteString(fmt.Sprintf("#%d ERROR: %s\n", vertex.index, errorMessage))
+	} else if isCompleted && !vertex.isCached && !vertex.isComplete {
+		vertex.isComplete = true
+		logs.WriteString(fmt.Sprintf("#%d DONE\n", vertex.index))
+	}
+}
+
+func (p *buildProgressPrinter) printVertexLog(logs *strings.Builder, data []byte) {
+	var digest string
+	var message []byte
+	_ = consumeFields(data, func(number protowire.Number, value []byte) {
+		switch number {
+		case 1:
+			digest = string(value)
+		case 4:
+			message = value
+		}
+	})
+	if len(message) == 0 {
+		return
+	}
+	vertex := p.vertex(digest)
+	for _, line := range strings.Split(strings.TrimSuffix(string(message), "\n"), "\n") {
+		logs.WriteString(fmt.Sprintf("#%d %s\n", vertex.index, line))
+	}
+}
+
+func (p *buildProgressPrinter) printVertexWarning(logs *strings.Builder, data []byte) {
+	_ = consumeFields(data, func(number protowire.Number, value []byte) {
+		if number == 3 {
+			logs.WriteString(fmt.Sprintf("WARNING: %s\n", value))
+		}
+	})
+}
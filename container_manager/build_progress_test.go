@@ -0,0 +1,127 @@
+package containermanger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type testVertex struct {
+	digest    string
+	name      string
+	cached    bool
+	started   bool
+	completed bool
+	err       string
+}
+
+func appendBytesField(b []byte, number protowire.Number, value []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(b, number, protowire.BytesType), value)
+}
+
+// buildkitTrace : StatusResponse of the BuildKit control api with the vertexes, logs and warnings
+func buildkitTrace(vertexes []testVertex, logs map[string]string, warnings []string) []byte {
+	var trace []byte
+	for _, vertex := range vertexes {
+		var b []byte
+		b = appendBytesField(b, 1, []byte(vertex.digest))
+		b = appendBytesField(b, 3, []byte(vertex.name))
+		if vertex.cached {
+			b = protowire.AppendVarint(protowire.AppendTag(b, 4, protowire.VarintType), 1)
+		}
+		if vertex.started {
+			b = appendBytesField(b, 5, []byte{})
+		}
+		if vertex.completed {
+			b = appendBytesField(b, 6, []byte{})
+		}
+		if vertex.err != "" {
+			b = appendBytesField(b, 7, []byte(vertex.err))
+		}
+		trace = appendBytesField(trace, 1, b)
+	}
+	for digest, message := range logs {
+		var b []byte
+		b = appendBytesField(b, 1, []byte(digest))
+		b = appendBytesField(b, 4, []byte(message))
+		trace = appendBytesField(trace, 3, b)
+	}
+	for _, warning := range warnings {
+		trace = appendBytesField(trace, 4, appendBytesField(nil, 3, []byte(warning)))
+	}
+	return trace
+}
+
+func buildkitTraceMessage(trace []byte) string {
+	aux, _ := json.Marshal(base64.StdEncoding.EncodeToString(trace))
+	message, _ := json.Marshal(map[string]interface{}{"id": buildkitTraceID, "aux": json.RawMessage(aux)})
+	return string(message) + "\n"
+}
+
+func TestBuildLogStream(t *testing.T) {
+	body := strings.Join([]string{
+		buildkitTraceMessage(buildkitTrace([]testVertex{
+			{digest: "sha256:a", name: "[1/2] FROM docker.io/library/node:20", started: true},
+			{digest: "sha256:b", name: "[2/2] RUN npm ci", cached: true, completed: true},
+		}, nil, nil)),
+		buildkitTraceMessage(buildkitTrace([]testVertex{
+			{digest: "sha256:a", name: "[1/2] FROM docker.io/library/node:20", started: true, completed: true},
+			{digest: "sha256:c", name: "[3/3] RUN npm test", started: true},
+		}, map[string]string{"sha256:c": "added 10 packages\nok\n"}, nil)),
+		// repeated status shouldn't print the vertex again
+		buildkitTraceMessage(buildkitTrace([]testVertex{
+			{digest: "sha256:a", name: "[1/2] FROM docker.io/library/node:20", started: true, completed: true},
+		}, nil, nil)),
+		buildkitTraceMessage(buildkitTrace([]testVertex{
+			{digest: "sha256:c", name: "[3/3] RUN npm test", started: true, completed: true, err: "exit code: 1"},
+		}, nil, []string{"FromAsCasing: 'as' and 'FROM' keywords' casing do not match"})),
+		`{"stream":"classic builder log\n"}` + "\n",
+		`{"errorDetail":{"message":"build failed"},"error":"build failed"}` + "\n",
+	}, "")
+	closed := make(chan struct{})
+	reader := newBuildLogStream(io.NopCloser(strings.NewReader(body)), func() {
+		close(closed)
+	})
+	decoder := json.NewDecoder(reader)
+	var logs strings.Builder
+	var errorMessage string
+	for {
+		var message jsonmessage.JSONMessage
+		err := decoder.Decode(&message)
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		logs.WriteString(message.Stream)
+		if message.Error != nil {
+			errorMessage = message.Error.Message
+		}
+	}
+	assert.Equal(t, strings.Join([]string{
+		"#1 [1/2] FROM docker.io/library/node:20",
+		"#2 [2/2] RUN npm ci",
+		"#2 CACHED",
+		"#1 DONE",
+		"#3 [3/3] RUN npm test",
+		"#3 added 10 packages",
+		"#3 ok",
+		"#3 ERROR: exit code: 1",
+		"WARNING: FromAsCasing: 'as' and 'FROM' keywords' casing do not match",
+		"classic builder log",
+	}, "\n")+"\n", logs.String())
+	assert.Equal(t, "build failed", errorMessage)
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Error("build response body should be closed after the stream ends")
+	}
+}
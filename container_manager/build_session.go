@@ -0,0 +1,372 @@
+package containermanger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// BuildKit pulls the secrets and the registry credentials from the client over a session.
+// The docker daemon forwards the session to the client through the hijacked `/session` endpoint,
+// and the client serves the gRPC services of the session on that connection.
+// Only the services required by swiftwave are implemented, the messages are encoded by hand to avoid depending on buildkit.
+
+const (
+	buildSessionName         = "swiftwave"
+	secretsServiceName       = "moby.buildkit.secrets.v1.Secrets"
+	authServiceName          = "moby.filesync.v1.Auth"
+	healthCheckMethod        = "/grpc.health.v1.Health/Check"
+	buildSessionTokenTimeout = 30 * time.Second
+)
+
+// buildSession : session of a single build
+type buildSession struct {
+	id                  string
+	secrets             map[string]string
+	registryCredentials map[string]RegistryCredential
+	server              *grpc.Server
+	conn                net.Conn
+}
+
+// startBuildSession : start serving the session for the build, session should be closed after the build
+func (m Manager) startBuildSession(ctx context.Context, config ImageBuildConfig) (*buildSession, error) {
+	session := &buildSession{
+		id:                  uuid.NewString(),
+		secrets:             config.Secrets,
+		registryCredentials: make(map[string]RegistryCredential),
+	}
+	if session.secrets == nil {
+		session.secrets = make(map[string]string)
+	}
+	for _, credential := range config.RegistryCredentials {
+		session.registryCredentials[normalizeRegistryHost(credential.Host)] = credential
+	}
+	sharedKey := make([]byte, 16)
+	if _, err := rand.Read(sharedKey); err != nil {
+		return nil, err
+	}
+	session.server = grpc.NewServer(grpc.ForceServerCodec(sessionCodec{}))
+	healthpb.RegisterHealthServer(session.server, health.NewServer())
+	session.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: secretsServiceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "GetSecret", Handler: session.getSecret},
+		},
+	}, session)
+	session.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: authServiceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "Credentials", Handler: session.credentials},
+			{MethodName: "FetchToken", Handler: session.fetchToken},
+		},
+	}, session)
+	conn, err := m.client.DialHijack(ctx, "/session", "h2c", map[string][]string{
+		"X-Docker-Expose-Session-Uuid":      {session.id},
+		"X-Docker-Expose-Session-Name":      {buildSessionName},
+		"X-Docker-Expose-Session-Sharedkey": {hex.EncodeToString(sharedKey)},
+		"X-Docker-Expose-Session-Grpc-Method": {
+			healthCheckMethod,
+			"/" + secretsServiceName + "/GetSecret",
+			"/" + authServiceName + "/Credentials",
+			"/" + authServiceName + "/FetchToken",
+		},
+	})
+	if err != nil {
+		session.server.Stop()
+		return nil, errors.New("failed to start build session")
+	}
+	session.conn = conn
+	go (&http2.Server{}).ServeConn(conn, &http2.ServeConnOpts{Handler: session.server})
+	return session, nil
+}
+
+// Close : stop serving the session
+func (s *buildSession) Close() {
+	s.server.Stop()
+	_ = s.conn.Close()
+}
+
+func (s *buildSession) getSecret(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &getSecretRequest{}
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	value, ok := s.secrets[request.id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "secret %s not found", request.id)
+	}
+	return &getSecretResponse{data: []byte(value)}, nil
+}
+
+func (s *buildSession) credentials(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &credentialsRequest{}
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	// empty credentials for unknown registries, to pull anonymously
+	credential := s.registryCredentials[normalizeRegistryHost(request.host)]
+	return &credentialsResponse{username: credential.Username, secret: credential.Password}, nil
+}
+
+// fetchToken : fetch the bearer token for the registry, with the credentials of the registry if available
+func (s *buildSession) fetchToken(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &fetchTokenRequest{}
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	realm, err := url.Parse(request.realm)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid realm %s", request.realm)
+	}
+	query := realm.Query()
+	if request.service != "" {
+		query.Set("service", request.service)
+	}
+	for _, scope := range request.scopes {
+		query.Add("scope", scope)
+	}
+	realm.RawQuery = query.Encode()
+	ctx, cancel := context.WithTimeout(ctx, buildSessionTokenTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if credential, ok := s.registryCredentials[normalizeRegistryHost(request.host)]; ok && (credential.Username != "" || credential.Password != "") {
+		req.SetBasicAuth(credential.Username, credential.Password)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to fetch token from %s", realm.Host)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return nil, status.Errorf(codes.PermissionDenied, "failed to fetch token from %s, status code %d", realm.Host, res.StatusCode)
+	}
+	var token struct {
+		Token       string    `json:"token"`
+		AccessToken string    `json:"access_token"`
+		ExpiresIn   int64     `json:"expires_in"`
+		IssuedAt    time.Time `json:"issued_at"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid token response from %s", realm.Host)
+	}
+	response := &fetchTokenResponse{token: token.Token, expiresIn: token.ExpiresIn}
+	if response.token == "" {
+		response.token = token.AccessToken
+	}
+	if !token.IssuedAt.IsZero() {
+		response.issuedAt = token.IssuedAt.Unix()
+	}
+	return response, nil
+}
+
+// normalizeRegistryHost : host of the registry without scheme and path, docker hub is referred with multiple names
+func normalizeRegistryHost(host string) string {
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	if host == "docker.io" || host == "index.docker.io" {
+		return "registry-1.docker.io"
+	}
+	return host
+}
+
+// sessionMessage : protobuf message of the session services
+type sessionMessage interface {
+	marshal() []byte
+	unmarshal(data []byte) error
+}
+
+// sessionCodec : encodes the session messages by hand, and the rest (health check) with the default proto codec
+type sessionCodec struct{}
+
+func (sessionCodec) Marshal(v interface{}) ([]byte, error) {
+	if message, ok := v.(sessionMessage); ok {
+		return message.marshal(), nil
+	}
+	return encoding.GetCodec("proto").Marshal(v)
+}
+
+func (sessionCodec) Unmarshal(data []byte, v interface{}) error {
+	if message, ok := v.(sessionMessage); ok {
+		return message.unmarshal(data)
+	}
+	return encoding.GetCodec("proto").Unmarshal(data, v)
+}
+
+func (sessionCodec) Name() string {
+	return "proto"
+}
+
+type getSecretRequest struct {
+	id string
+}
+
+func (r *getSecretRequest) marshal() []byte {
+	return protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), r.id)
+}
+
+func (r *getSecretRequest) unmarshal(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, value []byte) {
+		if number == 1 {
+			r.id = string(value)
+		}
+	})
+}
+
+type getSecretResponse struct {
+	data []byte
+}
+
+func (r *getSecretResponse) marshal() []byte {
+	return protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), r.data)
+}
+
+func (r *getSecretResponse) unmarshal(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, value []byte) {
+		if number == 1 {
+			r.data = append([]byte{}, value...)
+		}
+	})
+}
+
+type credentialsRequest struct {
+	host string
+}
+
+func (r *credentialsRequest) marshal() []byte {
+	return protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), r.host)
+}
+
+func (r *credentialsRequest) unmarshal(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, value []byte) {
+		if number == 1 {
+			r.host = string(value)
+		}
+	})
+}
+
+type credentialsResponse struct {
+	username string
+	secret   string
+}
+
+func (r *credentialsResponse) marshal() []byte {
+	b := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), r.username)
+	return protowire.AppendString(protowire.AppendTag(b, 2, protowire.BytesType), r.secret)
+}
+
+func (r *credentialsResponse) unmarshal(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, value []byte) {
+		switch number {
+		case 1:
+			r.username = string(value)
+		case 2:
+			r.secret = string(value)
+		}
+	})
+}
+
+type fetchTokenRequest struct {
+	clientID string
+	host     string
+	realm    string
+	service  string
+	scopes   []string
+}
+
+func (r *fetchTokenRequest) marshal() []byte {
+	var b []byte
+	for number, value := range []string{r.clientID, r.host, r.realm, r.service} {
+		b = protowire.AppendString(protowire.AppendTag(b, protowire.Number(number+1), protowire.BytesType), value)
+	}
+	for _, scope := range r.scopes {
+		b = protowire.AppendString(protowire.AppendTag(b, 5, protowire.BytesType), scope)
+	}
+	return b
+}
+
+func (r *fetchTokenRequest) unmarshal(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, value []byte) {
+		switch number {
+		case 1:
+			r.clientID = string(value)
+		case 2:
+			r.host = string(value)
+		case 3:
+			r.realm = string(value)
+		case 4:
+			r.service = string(value)
+		case 5:
+			r.scopes = append(r.scopes, string(value))
+		}
+	})
+}
+
+type fetchTokenResponse struct {
+	token     string
+	expiresIn int64
+	issuedAt  int64
+}
+
+func (r *fetchTokenResponse) marshal() []byte {
+	b := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), r.token)
+	b = protowire.AppendVarint(protowire.AppendTag(b, 2, protowire.VarintType), uint64(r.expiresIn))
+	return protowire.AppendVarint(protowire.AppendTag(b, 3, protowire.VarintType), uint64(r.issuedAt))
+}
+
+func (r *fetchTokenResponse) unmarshal(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, value []byte) {
+		if number == 1 {
+			r.token = string(value)
+		}
+	})
+}
+
+// consumeFields : call fn with the length delimited fields of the message, other fields are skipped
+func consumeFields(data []byte, fn func(number protowire.Number, value []byte)) error {
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if wireType == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			fn(number, value)
+			data = data[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(number, wireType, data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package containermanger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sessionRequestDecoder : decode the request as the grpc server does
+func sessionRequestDecoder(request sessionMessage) func(interface{}) error {
+	data, _ := sessionCodec{}.Marshal(request)
+	return func(v interface{}) error {
+		return sessionCodec{}.Unmarshal(data, v)
+	}
+}
+
+func TestBuildSessionGetSecret(t *testing.T) {
+	session := &buildSession{secrets: map[string]string{"NPM_TOKEN": "secret-token"}}
+
+	response, err := session.getSecret(nil, context.Background(), sessionRequestDecoder(&getSecretRequest{id: "NPM_TOKEN"}), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte("secret-token"), response.(*getSecretResponse).data)
+	}
+
+	_, err = session.getSecret(nil, context.Background(), sessionRequestDecoder(&getSecretRequest{id: "UNKNOWN"}), nil)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestBuildSessionCredentials(t *testing.T) {
+	session := &buildSession{registryCredentials: map[string]RegistryCredential{
+		"registry-1.docker.io": {Username: "hub-user", Password: "hub-password"},
+		"ghcr.io":              {Username: "gh-user", Password: "gh-password"},
+	}}
+	tests := []struct {
+		host     string
+		username string
+		secret   string
+	}{
+		{"registry-1.docker.io", "hub-user", "hub-password"},
+		{"ghcr.io", "gh-user", "gh-password"},
+		{"quay.io", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			response, err := session.credentials(nil, context.Background(), sessionRequestDecoder(&credentialsRequest{host: test.host}), nil)
+			if assert.NoError(t, err) {
+				credentials := response.(*credentialsResponse)
+				assert.Equal(t, test.username, credentials.username)
+				assert.Equal(t, test.secret, credentials.secret)
+			}
+		})
+	}
+}
+
+func TestNormalizeRegistryHost(t *testing.T) {
+	tests := map[string]string{
+		"docker.io":                     "registry-1.docker.io",
+		"index.docker.io":               "registry-1.docker.io",
+		"https://index.docker.io/v1/":   "registry-1.docker.io",
+		"registry-1.docker.io":          "registry-1.docker.io",
+		"ghcr.io":                       "ghcr.io",
+		"http://registry.local:5000/v2": "registry.local:5000",
+	}
+	for host, expected := range tests {
+		assert.Equal(t, expected, normalizeRegistryHost(host), host)
+	}
+}
+
+func TestSessionMessagesRoundTrip(t *testing.T) {
+	request := &fetchTokenRequest{
+		clientID: "buildkit-client",
+		host:     "ghcr.io",
+		realm:    "https://ghcr.io/token",
+		service:  "ghcr.io",
+		scopes:   []string{"repository:owner/app:pull", "repository:owner/base:pull"},
+	}
+	decodedRequest := &fetchTokenRequest{}
+	if assert.NoError(t, decodedRequest.unmarshal(request.marshal())) {
+		assert.Equal(t, request, decodedRequest)
+	}
+	response := &credentialsResponse{username: "user", secret: "password"}
+	decodedResponse := &credentialsResponse{}
+	if assert.NoError(t, decodedResponse.unmarshal(response.marshal())) {
+		assert.Equal(t, response, decodedResponse)
+	}
+}
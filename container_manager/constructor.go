@@ -28,6 +28,28 @@ func New(ctx context.Context, conn net.Conn) (*Manager, error) {
 	return manager, nil
 }
 
+// NewWithDialer creates a new container manager which opens a new connection with the dialer for every request
+// It is required for the operations which use multiple connections at once, like BuildKit builds with session
+func NewWithDialer(ctx context.Context, dialer func(ctx context.Context) (net.Conn, error)) (*Manager, error) {
+	manager := &Manager{}
+	c, err := client.NewClientWithOpts(
+		client.WithAPIVersionNegotiation(),
+		client.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer(ctx)
+				},
+			},
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	manager.ctx = ctx
+	manager.client = c
+	return manager, nil
+}
+
 // NewLocalClient creates a new container manager with a local client
 func NewLocalClient(ctx context.Context) (*Manager, error) {
 	manager := &Manager{}
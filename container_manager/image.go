@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -15,12 +17,14 @@ import (
 )
 
 /*
-CreateImageWithContext builds a Docker image from a Dockerfile and returns a scanner to read the build logs.
-It takes the Dockerfile content as a string, a map of build arguments, the path to the code directory, and the name of the image to be built.
-It returns a scanner to read the build logs and an error if any.
+CreateImageWithContext builds a Docker image from a Dockerfile with BuildKit and returns a scanner to read the build logs.
+It takes the Dockerfile content as a string, the build config, the path to the code directory, and the name of the image to be built.
+The layers are cached by the builder, and the image is built with inline cache so that it can be used as cache for the next builds.
+Secrets and registry credentials are served to the builder over a session, so they don't end up in the image history.
+It returns a scanner to read the build logs in the format of the classic builder and an error if any.
 It takes a context.Context as an additional argument.
 */
-func (m Manager) CreateImageWithContext(ctx context.Context, dockerfile string, buildargs map[string]string, sourceCodeDirectory string, codePath string, imagename string) (*bufio.Scanner, error) {
+func (m Manager) CreateImageWithContext(ctx context.Context, dockerfile string, config ImageBuildConfig, sourceCodeDirectory string, codePath string, imagename string) (*bufio.Scanner, error) {
 	// add path
 	codePath = strings.TrimSpace(codePath)
 	if codePath != "" && codePath != "/" {
@@ -38,30 +42,42 @@ func (m Manager) CreateImageWithContext(ctx context.Context, dockerfile string,
 	// Buildargs map
 	final_buildargs := map[string]*string{}
 	// convert buildargs map to final_buildargs map
-	for key, value := range buildargs {
+	for key, value := range config.BuildArgs {
 		valueBytes := []byte(value)
 		ptrValue := new(string)
 		*ptrValue = string(valueBytes)
 		final_buildargs[key] = ptrValue
 	}
+	// embed the cache metadata in the image, so that it can be used as cache source
+	inlineCache := "1"
+	final_buildargs["BUILDKIT_INLINE_CACHE"] = &inlineCache
 	// tar the sourceCodeDirectory
 	tar, err := archive.TarWithOptions(sourceCodeDirectory, &archive.TarOptions{})
 	if err != nil {
 		return nil, errors.New("failed to tar the sourceCodeDirectory")
 	}
+	// start the session for secrets and registry credentials
+	session, err := m.startBuildSession(ctx, config)
+	if err != nil {
+		return nil, err
+	}
 	// Build the image
 	response, err := m.client.ImageBuild(ctx, tar, types.ImageBuildOptions{
+		Version:    types.BuilderBuildKit,
+		SessionID:  session.id,
 		Dockerfile: "Dockerfile",
+		Target:     config.Target,
 		Remove:     true,
-		NoCache:    true,
 		Tags:       []string{imagename},
 		BuildArgs:  final_buildargs,
+		CacheFrom:  config.CacheFrom,
 	})
 	if err != nil {
+		session.Close()
 		return nil, errors.New("failed to build the image")
 	}
 	// Return scanner to read the build logs
-	scanner := bufio.NewScanner(response.Body)
+	scanner := bufio.NewScanner(newBuildLogStream(response.Body, session.Close))
 	return scanner, nil
 }
 
@@ -104,6 +120,78 @@ func (m Manager) ExistsImage(image string) bool {
 	return err == nil
 }
 
+// ExistsImageInRegistry checks if a Docker image is available in the remote registry, without pulling it.
+func (m Manager) ExistsImageInRegistry(ctx context.Context, imageTag string, username string, password string) bool {
+	authHeader, err := generateAuthHeader(username, password)
+	if err != nil {
+		return false
+	}
+	_, err = m.client.DistributionInspect(ctx, imageTag, authHeader)
+	return err == nil
+}
+
+// ImageDigest resolves the digest of the Docker image in the remote registry, without pulling it.
+func (m Manager) ImageDigest(ctx context.Context, imageTag string, username string, password string) (string, error) {
+	authHeader, err := generateAuthHeader(username, password)
+	if err != nil {
+		return "", errors.New("failed to generate auth header")
+	}
+	inspect, err := m.client.DistributionInspect(ctx, imageTag, authHeader)
+	if err != nil {
+		return "", errors.New("failed to inspect the image in registry")
+	}
+	return inspect.Descriptor.Digest.String(), nil
+}
+
+// RemoveImageFromRegistry deletes the image tag from the remote registry, the registry should allow deletion.
+// The digest is resolved by the docker daemon, and the manifest is deleted through the registry HTTP API.
+func (m Manager) RemoveImageFromRegistry(ctx context.Context, imageTag string, username string, password string, useTLS bool) error {
+	authHeader, err := generateAuthHeader(username, password)
+	if err != nil {
+		return err
+	}
+	inspect, err := m.client.DistributionInspect(ctx, imageTag, authHeader)
+	if err != nil {
+		return errors.New("failed to inspect the image in registry")
+	}
+	// image tag format - <registry>/<repository>:<tag>
+	registryHost, repositoryWithTag, found := strings.Cut(imageTag, "/")
+	if !found {
+		return errors.New("image tag doesn't have the registry")
+	}
+	repository := repositoryWithTag
+	if index := strings.LastIndex(repositoryWithTag, ":"); index != -1 {
+		repository = repositoryWithTag[:index]
+	}
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, registryHost, repository, inspect.Descriptor.Digest.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	if strings.Compare(username, "") != 0 || strings.Compare(password, "") != 0 {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.New("failed to connect to the registry")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	case http.StatusMethodNotAllowed:
+		return errors.New("deletion of images is not enabled in the registry")
+	default:
+		return fmt.Errorf("failed to delete the image from registry, status code %d", resp.StatusCode)
+	}
+}
+
 // RemoveImage removes a Docker image from the local registry.
 func (m Manager) RemoveImage(imageTag string) error {
 	// Remove the image
@@ -0,0 +1,113 @@
+package containermanger
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// RunJob runs the service as a one-off job and waits for the task to exit
+// Each line of the output is passed to logHandler while the job is running
+// The job service is removed afterwards, exit code of the task is returned
+func (m Manager) RunJob(service Service, username string, password string, queryRegistry bool, timeout time.Duration, logHandler func(line string)) (int, error) {
+	service.DeploymentMode = DeploymentModeReplicatedJob
+	service.Replicas = 1
+	// remove the leftover of an earlier run
+	if _, err := m.GetService(service.Name); err == nil {
+		err = m.RemoveService(service.Name)
+		if err != nil {
+			return -1, err
+		}
+	}
+	err := m.CreateService(service, username, password, queryRegistry)
+	if err != nil {
+		return -1, err
+	}
+	defer func() {
+		err := m.RemoveService(service.Name)
+		if err != nil {
+			log.Println("failed to remove job service > "+service.Name, err)
+		}
+	}()
+	// stream the logs till the job exits
+	logCtx, cancelLogStream := context.WithCancel(m.ctx)
+	logStreamDone := make(chan struct{})
+	go func() {
+		defer close(logStreamDone)
+		m.streamJobLogs(logCtx, service.Name, logHandler)
+	}()
+	exitCode, err := m.waitForJob(service.Name, timeout)
+	// logs are shipped a bit later than the exit of the task
+	<-time.After(2 * time.Second)
+	cancelLogStream()
+	<-logStreamDone
+	return exitCode, err
+}
+
+// waitForJob polls the task of the job till it exits or the timeout is reached
+func (m Manager) waitForJob(serviceName string, timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		tasks, err := m.client.TaskList(m.ctx, types.TaskListOptions{
+			Filters: filters.NewArgs(
+				filters.Arg("service", serviceName),
+			),
+		})
+		if err != nil {
+			return -1, errors.New("error getting task list")
+		}
+		for _, task := range tasks {
+			switch task.Status.State {
+			case swarm.TaskStateComplete, swarm.TaskStateFailed:
+				if task.Status.ContainerStatus != nil {
+					return task.Status.ContainerStatus.ExitCode, nil
+				}
+				if task.Status.State == swarm.TaskStateComplete {
+					return 0, nil
+				}
+				return -1, errors.New("job failed > " + task.Status.Err)
+			case swarm.TaskStateRejected:
+				return -1, errors.New("job rejected > " + task.Status.Err)
+			}
+		}
+		<-time.After(2 * time.Second)
+	}
+	return -1, errors.New("job did not exit within " + timeout.String())
+}
+
+// streamJobLogs passes each line of the job output to logHandler till the context is cancelled
+func (m Manager) streamJobLogs(ctx context.Context, serviceName string, logHandler func(line string)) {
+	logs, err := m.client.ServiceLogs(ctx, serviceName, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		log.Println("failed to stream logs of job service > "+serviceName, err)
+		return
+	}
+	defer func(logs io.ReadCloser) {
+		_ = logs.Close()
+	}(logs)
+	// logs are multiplexed as the job doesn't have a tty
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(writer, writer, logs)
+		_ = writer.CloseWithError(err)
+	}()
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		logHandler(scanner.Text())
+	}
+	// unblock the copy, if scanning stopped early
+	_ = reader.Close()
+}
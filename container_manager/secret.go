@@ -0,0 +1,59 @@
+package containermanger
+
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/oklog/ulid"
+	"math/rand"
+	"time"
+)
+
+// secretId is the actual id of the secret in swiftwave system
+// it's different from the docker secret id, it's the same as docker secret name
+
+// FetchDockerSecretId fetches the docker secret id of a secret
+func (m Manager) FetchDockerSecretId(secretId string) (string, error) {
+	secret, _, err := m.client.SecretInspectWithRaw(m.ctx, secretId)
+	if err != nil {
+		return "", err
+	}
+	return secret.ID, nil
+}
+
+// CreateSecret creates a new secret and returns the secret id
+// Docker secrets are immutable, so create a new secret to rotate the value
+func (m Manager) CreateSecret(content string, applicationId string) (string, error) {
+	// generate a random secret id
+	secretId := ulid.MustNew(ulid.Timestamp(time.Now()), ulid.Monotonic(rand.New(rand.NewSource(time.Now().UnixNano())), 0)).String()
+	_, err := m.client.SecretCreate(m.ctx, swarm.SecretSpec{
+		Annotations: swarm.Annotations{
+			Name: secretId,
+			Labels: map[string]string{
+				"applicationId": applicationId,
+			},
+		},
+		Data: []byte(content),
+	})
+	if err != nil {
+		return "", err
+	}
+	return secretId, nil
+}
+
+// PruneSecret removes all the secrets with the given applicationId.
+// Secrets in use by the service can't be removed, so only the unused secrets will be removed
+// It will not raise any error if failed to remove a secret
+func (m Manager) PruneSecret(applicationId string) {
+	res, err := m.client.SecretList(m.ctx, types.SecretListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("label", "applicationId="+applicationId),
+		),
+	})
+	if err != nil {
+		return
+	}
+	for _, s := range res {
+		_ = m.client.SecretRemove(m.ctx, s.ID)
+	}
+}
@@ -73,6 +73,11 @@ func (m Manager) GetService(serviceName string) (Service, error) {
 	// Set deployment mode
 	if serviceData.Spec.Mode.Replicated != nil {
 		service.DeploymentMode = DeploymentModeReplicated
+	} else if serviceData.Spec.Mode.ReplicatedJob != nil {
+		service.DeploymentMode = DeploymentModeReplicatedJob
+		if serviceData.Spec.Mode.ReplicatedJob.TotalCompletions != nil {
+			service.Replicas = *serviceData.Spec.Mode.ReplicatedJob.TotalCompletions
+		}
 	} else {
 		service.DeploymentMode = DeploymentModeGlobal
 	}
@@ -276,6 +281,70 @@ func (m Manager) NoOfRunningTasks(serviceName string) (int, error) {
 	return runningCount, nil
 }
 
+// ServiceHealth returns the state of the tasks created for the current version of the service
+func (m Manager) ServiceHealth(serviceName string) (ServiceHealth, error) {
+	health := ServiceHealth{}
+	serviceData, _, err := m.client.ServiceInspectWithRaw(m.ctx, serviceName, types.ServiceInspectOptions{})
+	if err != nil {
+		return health, errors.New("error getting service details")
+	}
+	// tasks created before the last update belong to the previous version
+	var since time.Time
+	if serviceData.UpdateStatus != nil {
+		if serviceData.UpdateStatus.StartedAt != nil {
+			since = *serviceData.UpdateStatus.StartedAt
+		}
+		switch serviceData.UpdateStatus.State {
+		case swarm.UpdateStateCompleted:
+			health.UpdateCompleted = true
+		case swarm.UpdateStatePaused:
+			health.UpdatePaused = true
+			health.Message = serviceData.UpdateStatus.Message
+		case swarm.UpdateStateRollbackStarted, swarm.UpdateStateRollbackPaused, swarm.UpdateStateRollbackCompleted:
+			health.RolledBack = true
+			health.Message = serviceData.UpdateStatus.Message
+		}
+	}
+	tasks, err := m.client.TaskList(m.ctx, types.TaskListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("service", serviceName),
+		),
+	})
+	if err != nil {
+		return health, errors.New("error getting task list")
+	}
+	var latestFailedAt time.Time
+	desiredTasks := 0
+	for _, task := range tasks {
+		if task.CreatedAt.Before(since) {
+			continue
+		}
+		if task.DesiredState == swarm.TaskStateRunning {
+			desiredTasks++
+			if task.Status.State == swarm.TaskStateRunning {
+				health.RunningTasks++
+			}
+		}
+		if task.Status.State == swarm.TaskStateFailed || task.Status.State == swarm.TaskStateRejected {
+			health.FailedTasks++
+			if task.Status.Timestamp.After(latestFailedAt) {
+				latestFailedAt = task.Status.Timestamp
+				if task.Status.Err != "" {
+					health.Message = task.Status.Err
+				}
+			}
+		}
+	}
+	if serviceData.Spec.Mode.Replicated != nil && serviceData.Spec.Mode.Replicated.Replicas != nil {
+		health.DesiredTasks = int(*serviceData.Spec.Mode.Replicated.Replicas)
+		health.ScaledToZero = health.DesiredTasks == 0
+	} else {
+		// for global service, one task is scheduled on each eligible node
+		health.DesiredTasks = desiredTasks
+	}
+	return health, nil
+}
+
 // ServiceRunningServers Fetch the servers where a service is running
 func (m Manager) ServiceRunningServers(serviceName string) ([]string, error) {
 	// fetch all nodes and store in map > nodeID:nodeDetails
@@ -400,6 +469,15 @@ func (m Manager) serviceToServiceSpec(service Service) (swarm.ServiceSpec, error
 		serviceMode = swarm.ServiceMode{
 			Global: &swarm.GlobalService{},
 		}
+	} else if service.DeploymentMode == DeploymentModeReplicatedJob {
+		// allow job, tasks are run one at a time
+		maxConcurrent := uint64(1)
+		serviceMode = swarm.ServiceMode{
+			ReplicatedJob: &swarm.ReplicatedJob{
+				MaxConcurrent:    &maxConcurrent,
+				TotalCompletions: &service.Replicas,
+			},
+		}
 	} else {
 		print(service.DeploymentMode)
 		panic("invalid deployment mode > ")
@@ -424,6 +502,25 @@ func (m Manager) serviceToServiceSpec(service Service) (swarm.ServiceSpec, error
 		})
 	}
 
+	// secret references
+	var secrets = make([]*swarm.SecretReference, 0)
+	for _, secret := range service.SecretMounts {
+		secretId, err := m.FetchDockerSecretId(secret.SecretID)
+		if err != nil {
+			return swarm.ServiceSpec{}, err
+		}
+		secrets = append(secrets, &swarm.SecretReference{
+			SecretName: secret.SecretID,
+			SecretID:   secretId,
+			File: &swarm.SecretReferenceFileTarget{
+				Name: secret.FileName,
+				UID:  strconv.Itoa(int(secret.Uid)),
+				GID:  strconv.Itoa(int(secret.Gid)),
+				Mode: os.FileMode(uint32(secret.FileMode)),
+			},
+		})
+	}
+
 	// memory bytes
 	var reservedMemoryBytes int64 = 0
 	if service.ReservedResource.MemoryMB >= 6 {
@@ -447,6 +544,34 @@ func (m Manager) serviceToServiceSpec(service Service) (swarm.ServiceSpec, error
 		}
 	}
 
+	// update and rollback strategy, docker's default will be used if not provided
+	var updateConfig *swarm.UpdateConfig
+	var rollbackConfig *swarm.UpdateConfig
+	if service.UpdateConfig != (UpdateConfig{}) && service.DeploymentMode != DeploymentModeReplicatedJob {
+		updateConfig = &swarm.UpdateConfig{
+			Parallelism:     service.UpdateConfig.Parallelism,
+			Delay:           time.Duration(service.UpdateConfig.DelaySeconds) * time.Second,
+			FailureAction:   service.UpdateConfig.FailureAction,
+			Monitor:         time.Duration(service.UpdateConfig.MonitorSeconds) * time.Second,
+			MaxFailureRatio: service.UpdateConfig.MaxFailureRatio,
+			Order:           service.UpdateConfig.Order,
+		}
+		rollbackConfig = &swarm.UpdateConfig{}
+		*rollbackConfig = *updateConfig
+		if rollbackConfig.FailureAction == swarm.UpdateFailureActionRollback {
+			// rollback can't be rolled back
+			rollbackConfig.FailureAction = swarm.UpdateFailureActionPause
+		}
+	}
+
+	// job tasks should not be restarted on exit
+	var restartPolicy *swarm.RestartPolicy
+	if service.DeploymentMode == DeploymentModeReplicatedJob {
+		restartPolicy = &swarm.RestartPolicy{
+			Condition: swarm.RestartPolicyConditionNone,
+		}
+	}
+
 	// Build service spec
 	serviceSpec := swarm.ServiceSpec{
 		// Set name of the service
@@ -463,6 +588,7 @@ func (m Manager) serviceToServiceSpec(service Service) (swarm.ServiceSpec, error
 				Env:      env,
 				Mounts:   volumeMounts,
 				Configs:  configs,
+				Secrets:  secrets,
 				Privileges: &swarm.Privileges{
 					NoNewPrivileges: true,
 					AppArmor: &swarm.AppArmorOpts{
@@ -487,11 +613,14 @@ func (m Manager) serviceToServiceSpec(service Service) (swarm.ServiceSpec, error
 					MemoryBytes: limitMemoryBytes,
 				},
 			},
+			RestartPolicy: restartPolicy,
 			// Set network name
 			Networks: networkAttachmentConfigs,
 		},
 		// allow replicated service
-		Mode: serviceMode,
+		Mode:           serviceMode,
+		UpdateConfig:   updateConfig,
+		RollbackConfig: rollbackConfig,
 		// constant endpoint
 		EndpointSpec: &swarm.EndpointSpec{
 			Mode: swarm.ResolutionModeDNSRR,
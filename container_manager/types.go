@@ -16,6 +16,8 @@ type DeploymentMode string
 const (
 	DeploymentModeReplicated DeploymentMode = "replicated"
 	DeploymentModeGlobal     DeploymentMode = "global"
+	// DeploymentModeReplicatedJob : tasks run to completion and are not restarted, Replicas is the number of completions
+	DeploymentModeReplicatedJob DeploymentMode = "replicated-job"
 )
 
 type Service struct {
@@ -27,6 +29,7 @@ type Service struct {
 	Capabilities         []string          `json:"capabilities,omitempty"`
 	Sysctls              map[string]string `json:"sysctl,omitempty"`
 	ConfigMounts         []ConfigMount     `json:"configmounts,omitempty"`
+	SecretMounts         []SecretMount     `json:"secretmounts,omitempty"`
 	VolumeMounts         []VolumeMount     `json:"volumemounts,omitempty"`
 	VolumeBinds          []VolumeBind      `json:"volumebinds,omitempty"`
 	Networks             []string          `json:"networks,omitempty"`
@@ -36,6 +39,17 @@ type Service struct {
 	ReservedResource     Resource          `json:"reserved_resource,omitempty"`
 	ResourceLimit        Resource          `json:"resource_limit,omitempty"`
 	CustomHealthCheck    CustomHealthCheck `json:"custom_health_check,omitempty"`
+	UpdateConfig         UpdateConfig      `json:"update_config,omitempty"`
+}
+
+// UpdateConfig : strategy to replace the tasks on update, same settings are used for rollback
+type UpdateConfig struct {
+	Parallelism     uint64  `json:"parallelism"`       // Number of tasks updated simultaneously, 0 to update all at once
+	DelaySeconds    uint64  `json:"delay_seconds"`     // Delay between updating each batch of tasks
+	Order           string  `json:"order"`             // "stop-first" or "start-first"
+	FailureAction   string  `json:"failure_action"`    // "pause", "continue" or "rollback"
+	MonitorSeconds  uint64  `json:"monitor_seconds"`   // Duration to monitor each updated task for failure
+	MaxFailureRatio float32 `json:"max_failure_ratio"` // Failure rate to tolerate during an update
 }
 
 type CustomHealthCheck struct {
@@ -83,6 +97,15 @@ type ConfigMount struct {
 	MountingPath string `json:"mounting_path"`
 }
 
+// SecretMount : secret will be available at /run/secrets/<FileName> in the container
+type SecretMount struct {
+	SecretID string `json:"secret_id"`
+	FileName string `json:"file_name"`
+	Uid      uint   `json:"uid"`
+	Gid      uint   `json:"gid"`
+	FileMode uint   `json:"file_mode"`
+}
+
 type Resource struct {
 	MemoryMB int `json:"memory_mb,omitempty"`
 }
@@ -127,3 +150,46 @@ type DockerProxyPermission struct {
 	Tasks        DockerProxyPermissionType `json:"tasks" gorm:"default:none"`
 	Volumes      DockerProxyPermissionType `json:"volumes" gorm:"default:none"`
 }
+
+// ServiceHealth hold the state of the tasks of the current version of a service
+type ServiceHealth struct {
+	DesiredTasks    int
+	RunningTasks    int
+	FailedTasks     int
+	UpdatePaused    bool   // swarm paused the update due to task failures
+	UpdateCompleted bool   // swarm replaced all the tasks of the previous version
+	RolledBack      bool   // swarm rolled back the update due to task failures
+	ScaledToZero    bool   // service is configured with 0 replicas, so no task is expected
+	Message         string // latest error reported by swarm for the tasks
+}
+
+// IsHealthy : all the desired tasks of the current version are running
+// Swarm reports a task as running only after the health check has passed, if the image or service has one
+// No desired task is healthy only for a service scaled to zero, global service has no task scheduled right after the update
+func (h ServiceHealth) IsHealthy() bool {
+	if h.UpdatePaused || h.RolledBack {
+		return false
+	}
+	if h.DesiredTasks == 0 {
+		return h.ScaledToZero
+	}
+	return h.RunningTasks >= h.DesiredTasks
+}
+
+// ImageBuildConfig : options for the BuildKit build of the image
+type ImageBuildConfig struct {
+	BuildArgs map[string]string
+	Target    string            // stage of the multi-stage Dockerfile to build, empty to build the last stage
+	Secrets   map[string]string // secret id -> value, available to `RUN --mount=type=secret,id=<id>` only
+	// CacheFrom : images built with inline cache, the layers of them are reused if the steps are same
+	CacheFrom []string
+	// RegistryCredentials : credentials for the registries, used to pull the cache and base images
+	RegistryCredentials []RegistryCredential
+}
+
+// RegistryCredential : credentials of a docker registry
+type RegistryCredential struct {
+	Host     string // e.g. registry.example.com:5000
+	Username string
+	Password string
+}
@@ -0,0 +1,29 @@
+package containermanger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceHealthIsHealthy(t *testing.T) {
+	tests := []struct {
+		name    string
+		health  ServiceHealth
+		healthy bool
+	}{
+		{"all tasks running", ServiceHealth{DesiredTasks: 3, RunningTasks: 3}, true},
+		{"some tasks starting", ServiceHealth{DesiredTasks: 3, RunningTasks: 2}, false},
+		{"no task running", ServiceHealth{DesiredTasks: 1}, false},
+		{"failed tasks got replaced", ServiceHealth{DesiredTasks: 2, RunningTasks: 2, FailedTasks: 4}, true},
+		{"update paused", ServiceHealth{DesiredTasks: 2, RunningTasks: 2, UpdatePaused: true}, false},
+		{"scaled to zero", ServiceHealth{ScaledToZero: true}, true},
+		{"no task scheduled yet", ServiceHealth{}, false},
+		{"scaled to zero but update paused", ServiceHealth{ScaledToZero: true, UpdatePaused: true}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.healthy, test.health.IsHealthy())
+		})
+	}
+}
@@ -0,0 +1,443 @@
+package gitmanager
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	cryptoSSH "golang.org/x/crypto/ssh"
+)
+
+// lfsPointerVersion : first line of the pointer file, committed in place of the file tracked by git lfs
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// lfsPointerMaxSize : pointer files are always smaller than this
+const lfsPointerMaxSize = 1024
+
+// lfsMediaType : content type of the git lfs batch api
+const lfsMediaType = "application/vnd.git-lfs+json"
+
+// lfsHttpClient : files can be large, so the timeout is generous
+var lfsHttpClient = &http.Client{Timeout: 30 * time.Minute}
+
+// lfsPointer : file tracked by git lfs, which needs to be downloaded
+type lfsPointer struct {
+	Oid  string
+	Size int64
+	Path string // absolute path of the pointer file in the worktree
+}
+
+// lfsEndpoint : git lfs api of the remote, with the headers to authenticate
+type lfsEndpoint struct {
+	URL    string
+	Header map[string]string
+	Auth   *githttp.BasicAuth
+}
+
+// fetchLFSFiles : replace the git lfs pointer files in the worktree with the actual files
+// Submodules are processed recursively, the credentials are sent only if the remote is on a trusted host
+func fetchLFSFiles(repo *git.Repository, gitUrl string, credentials *credentialScope, worktreePath string) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return errors.New("failed to open worktree of repository")
+	}
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return errors.New("failed to fetch submodules of repository")
+	}
+	// submodules have their own lfs storage
+	submodulePaths := make(map[string]bool)
+	for _, submodule := range submodules {
+		submodulePaths[filepath.Join(worktreePath, submodule.Config().Path)] = true
+	}
+	pointers, err := findLFSPointers(worktreePath, submodulePaths)
+	if err != nil {
+		return err
+	}
+	if len(pointers) > 0 {
+		err = downloadLFSObjects(gitUrl, credentials.authFor(gitUrl), pointers)
+		if err != nil {
+			return err
+		}
+	}
+	for _, submodule := range submodules {
+		submoduleRepo, err := submodule.Repository()
+		if err != nil {
+			// submodule is not checked out
+			if errors.Is(err, git.ErrSubmoduleNotInitialized) {
+				continue
+			}
+			return errors.New("failed to open submodule " + submodule.Config().Name)
+		}
+		remote, err := submoduleRepo.Remote(git.DefaultRemoteName)
+		if err != nil || len(remote.Config().URLs) == 0 {
+			return errors.New("failed to find remote of submodule " + submodule.Config().Name)
+		}
+		err = fetchLFSFiles(submoduleRepo, remote.Config().URLs[0], credentials, filepath.Join(worktreePath, submodule.Config().Path))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findLFSPointers : find the pointer files in the worktree, skipping the submodules
+func findLFSPointers(worktreePath string, skipPaths map[string]bool) ([]lfsPointer, error) {
+	pointers := make([]lfsPointer, 0)
+	err := filepath.WalkDir(worktreePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == git.GitDirName || skipPaths[path] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > lfsPointerMaxSize {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pointer, ok := parseLFSPointer(content)
+		if ok {
+			pointer.Path = path
+			pointers = append(pointers, pointer)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("failed to search git lfs files in repository")
+	}
+	return pointers, nil
+}
+
+// parseLFSPointer : parse the oid and size from the content of the pointer file
+func parseLFSPointer(content []byte) (lfsPointer, bool) {
+	pointer := lfsPointer{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	if !scanner.Scan() || scanner.Text() != lfsPointerVersion {
+		return pointer, false
+	}
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), " ")
+		if !found {
+			return pointer, false
+		}
+		switch key {
+		case "oid":
+			pointer.Oid = strings.TrimPrefix(value, "sha256:")
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return pointer, false
+			}
+			pointer.Size = size
+		}
+	}
+	if len(pointer.Oid) != sha256.Size*2 {
+		return pointer, false
+	}
+	return pointer, true
+}
+
+// downloadLFSObjects : download the objects from the lfs storage of the remote
+// Local repositories are read directly, others are fetched with the git lfs batch api
+func downloadLFSObjects(gitUrl string, auth transport.AuthMethod, pointers []lfsPointer) error {
+	endpoint, err := transport.NewEndpoint(gitUrl)
+	if err != nil {
+		return errors.New("invalid git url for git lfs")
+	}
+	if endpoint.Protocol == "file" {
+		for _, pointer := range pointers {
+			err = replaceLFSPointer(pointer, func() (io.ReadCloser, error) {
+				return openLocalLFSObject(endpoint.Path, pointer.Oid)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	lfsApi, err := lfsApiEndpoint(endpoint, auth)
+	if err != nil {
+		return err
+	}
+	downloads, err := lfsApi.batchDownload(pointers)
+	if err != nil {
+		return err
+	}
+	for _, pointer := range pointers {
+		download, ok := downloads[pointer.Oid]
+		if !ok {
+			return errors.New("git lfs object " + pointer.Oid + " not found on server")
+		}
+		err = replaceLFSPointer(pointer, func() (io.ReadCloser, error) {
+			return download.open()
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openLocalLFSObject : lfs objects are stored in lfs/objects/<oid[0:2]>/<oid[2:4]>/<oid> of the git directory
+func openLocalLFSObject(repositoryPath string, oid string) (io.ReadCloser, error) {
+	for _, gitDirectory := range []string{repositoryPath, filepath.Join(repositoryPath, git.GitDirName)} {
+		file, err := os.Open(filepath.Join(gitDirectory, "lfs", "objects", oid[0:2], oid[2:4], oid))
+		if err == nil {
+			return file, nil
+		}
+	}
+	return nil, errors.New("git lfs object " + oid + " not found in " + repositoryPath)
+}
+
+// replaceLFSPointer : download the object to a temporary file, verify it and replace the pointer file
+func replaceLFSPointer(pointer lfsPointer, open func() (io.ReadCloser, error)) error {
+	reader, err := open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	info, err := os.Stat(pointer.Path)
+	if err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(pointer.Path), ".lfs-*")
+	if err != nil {
+		return errors.New("failed to create temporary file for git lfs object")
+	}
+	defer func() {
+		_ = os.Remove(tempFile.Name())
+	}()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tempFile, hash), reader)
+	closeErr := tempFile.Close()
+	if err != nil || closeErr != nil {
+		return errors.New("failed to download git lfs object " + pointer.Oid)
+	}
+	if size != pointer.Size || hex.EncodeToString(hash.Sum(nil)) != pointer.Oid {
+		return errors.New("git lfs object " + pointer.Oid + " is corrupted")
+	}
+	err = os.Chmod(tempFile.Name(), info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), pointer.Path)
+}
+
+// lfsApiEndpoint : api endpoint of the remote
+// For ssh remotes, the endpoint and the credentials are requested with git-lfs-authenticate command
+func lfsApiEndpoint(endpoint *transport.Endpoint, auth transport.AuthMethod) (*lfsEndpoint, error) {
+	if endpoint.Protocol == "ssh" {
+		return sshLFSApiEndpoint(endpoint, auth)
+	}
+	repositoryUrl := strings.TrimSuffix(endpoint.String(), "/")
+	if !strings.HasSuffix(repositoryUrl, ".git") {
+		repositoryUrl += ".git"
+	}
+	lfsApi := &lfsEndpoint{
+		URL:    repositoryUrl + "/info/lfs",
+		Header: make(map[string]string),
+	}
+	if basicAuth, ok := auth.(*githttp.BasicAuth); ok {
+		lfsApi.Auth = basicAuth
+	}
+	return lfsApi, nil
+}
+
+func sshLFSApiEndpoint(endpoint *transport.Endpoint, auth transport.AuthMethod) (*lfsEndpoint, error) {
+	sshAuth, ok := auth.(gitssh.AuthMethod)
+	if !ok {
+		return nil, errors.New("ssh credential is required for git lfs")
+	}
+	config, err := sshAuth.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config.HostKeyCallback == nil {
+		config.HostKeyCallback = cryptoSSH.InsecureIgnoreHostKey()
+	}
+	port := endpoint.Port
+	if port == 0 {
+		port = 22
+	}
+	client, err := cryptoSSH.Dial("tcp", net.JoinHostPort(endpoint.Host, strconv.Itoa(port)), config)
+	if err != nil {
+		return nil, errors.New("failed to connect to git server for git lfs")
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, errors.New("failed to connect to git server for git lfs")
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+	output, err := session.Output(fmt.Sprintf("git-lfs-authenticate '%s' download", strings.TrimPrefix(endpoint.Path, "/")))
+	if err != nil {
+		return nil, errors.New("git lfs is not supported by git server")
+	}
+	var response struct {
+		Href   string            `json:"href"`
+		Header map[string]string `json:"header"`
+	}
+	if err := json.Unmarshal(output, &response); err != nil || response.Href == "" {
+		return nil, errors.New("invalid response of git-lfs-authenticate")
+	}
+	if response.Header == nil {
+		response.Header = make(map[string]string)
+	}
+	return &lfsEndpoint{
+		URL:    strings.TrimSuffix(response.Href, "/"),
+		Header: response.Header,
+	}, nil
+}
+
+// lfsDownload : download action of an object, returned by the batch api
+type lfsDownload struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header"`
+	// auth is used, if the object is served by the same host and no header is provided
+	auth *githttp.BasicAuth
+}
+
+// batchDownload : request the download actions of the objects
+func (e *lfsEndpoint) batchDownload(pointers []lfsPointer) (map[string]*lfsDownload, error) {
+	type lfsObject struct {
+		Oid  string `json:"oid"`
+		Size int64  `json:"size"`
+	}
+	request := struct {
+		Operation string      `json:"operation"`
+		Transfers []string    `json:"transfers"`
+		Objects   []lfsObject `json:"objects"`
+	}{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   make([]lfsObject, 0, len(pointers)),
+	}
+	isRequested := make(map[string]bool)
+	for _, pointer := range pointers {
+		if isRequested[pointer.Oid] {
+			continue
+		}
+		isRequested[pointer.Oid] = true
+		request.Objects = append(request.Objects, lfsObject{Oid: pointer.Oid, Size: pointer.Size})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := http.NewRequest(http.MethodPost, e.URL+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Accept", lfsMediaType)
+	httpRequest.Header.Set("Content-Type", lfsMediaType)
+	for key, value := range e.Header {
+		httpRequest.Header.Set(key, value)
+	}
+	if e.Auth != nil {
+		e.Auth.SetAuth(httpRequest)
+	}
+	httpResponse, err := lfsHttpClient.Do(httpRequest)
+	if err != nil {
+		return nil, errors.New("failed to connect to git lfs server")
+	}
+	defer func() {
+		_ = httpResponse.Body.Close()
+	}()
+	if httpResponse.StatusCode == http.StatusUnauthorized || httpResponse.StatusCode == http.StatusForbidden {
+		return nil, transport.ErrAuthorizationFailed
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("git lfs server responded with status %d", httpResponse.StatusCode)
+	}
+	var response struct {
+		Objects []struct {
+			Oid     string `json:"oid"`
+			Actions struct {
+				Download *lfsDownload `json:"download"`
+			} `json:"actions"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"objects"`
+	}
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return nil, errors.New("invalid response of git lfs server")
+	}
+	batchUrl, _ := url.Parse(e.URL)
+	downloads := make(map[string]*lfsDownload)
+	for _, object := range response.Objects {
+		if object.Error != nil {
+			return nil, errors.New("failed to fetch git lfs object " + object.Oid + " > " + object.Error.Message)
+		}
+		download := object.Actions.Download
+		if download == nil {
+			continue
+		}
+		downloadUrl, err := url.Parse(download.Href)
+		if err == nil && batchUrl != nil && downloadUrl.Host == batchUrl.Host && len(download.Header) == 0 {
+			download.auth = e.Auth
+		}
+		downloads[object.Oid] = download
+	}
+	return downloads, nil
+}
+
+// open : start the download of the object
+func (d *lfsDownload) open() (io.ReadCloser, error) {
+	httpRequest, err := http.NewRequest(http.MethodGet, d.Href, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range d.Header {
+		httpRequest.Header.Set(key, value)
+	}
+	if d.auth != nil {
+		d.auth.SetAuth(httpRequest)
+	}
+	httpResponse, err := lfsHttpClient.Do(httpRequest)
+	if err != nil {
+		return nil, errors.New("failed to download git lfs object")
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		_ = httpResponse.Body.Close()
+		return nil, fmt.Errorf("git lfs server responded with status %d", httpResponse.StatusCode)
+	}
+	return httpResponse.Body, nil
+}
@@ -3,6 +3,7 @@ package gitmanager
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -16,6 +17,9 @@ import (
 	cryptoSSH "golang.org/x/crypto/ssh"
 )
 
+// peeledTagSuffix : suffix of the peeled refs of annotated tags in ls-remote
+const peeledTagSuffix = "^{}"
+
 func FetchLatestCommitHash(gitUrl string, branch string, username string, password string, privateKey string) (string, error) {
 	// Parse the URL
 	repoInfo, err := ParseGitRepoInfo(gitUrl)
@@ -87,7 +91,88 @@ func FetchBranches(gitUrl string, username string, password string, privateKey s
 	return branches, nil
 }
 
-func CloneRepository(gitUrl string, branch string, username string, password string, privateKey string, destFolder string) (commitHash string, commitMessage string, err error) {
+// FetchTags returns the tags of the repository, sorted by name
+func FetchTags(gitUrl string, username string, password string, privateKey string) ([]string, error) {
+	tagCommitHashes, err := FetchTagCommitHashes(gitUrl, username, password, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0, len(tagCommitHashes))
+	for tag := range tagCommitHashes {
+		tags = append(tags, tag)
+	}
+	// sort the tags
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// FetchTagCommitHashes returns the commit hash of each tag of the repository
+// Annotated tags are resolved to the commit they point to
+func FetchTagCommitHashes(gitUrl string, username string, password string, privateKey string) (map[string]string, error) {
+	// Parse the URL
+	repoInfo, err := ParseGitRepoInfo(gitUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the auth method
+	auth, err := getAuthMethod(repoInfo, username, password, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// ls-remote the repo
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{gitUrl},
+	})
+	refs, err := remote.List(&git.ListOptions{
+		Auth:            auth,
+		InsecureSkipTLS: true,
+		PeelingOption:   git.AppendPeeled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tagCommitHashes := make(map[string]string)
+	for _, ref := range refs {
+		if !ref.Name().IsTag() {
+			continue
+		}
+		tag := ref.Name().Short()
+		if strings.HasSuffix(tag, peeledTagSuffix) {
+			// peeled ref of annotated tag points to the commit
+			tagCommitHashes[strings.TrimSuffix(tag, peeledTagSuffix)] = ref.Hash().String()
+		} else if _, ok := tagCommitHashes[tag]; !ok {
+			tagCommitHashes[tag] = ref.Hash().String()
+		}
+	}
+	return tagCommitHashes, nil
+}
+
+// CloneOptions : optional features of the clone
+// Credentials are sent to the submodules and git lfs servers only if those are on the host of the repository or on CredentialHosts
+// Others are accessed anonymously, so that a change in .gitmodules can't redirect the credentials to another server
+type CloneOptions struct {
+	Submodules      bool     // checkout the submodules recursively
+	LFS             bool     // replace the git lfs pointer files with the actual files
+	Shallow         bool     // clone only the checked out commit of the branch or tag, instead of the whole history
+	CredentialHosts []string // other hosts, where the credentials are allowed to be used
+}
+
+// DefaultCloneOptions : submodules are checked out from shallow clone, git lfs files are not fetched
+func DefaultCloneOptions() CloneOptions {
+	return CloneOptions{
+		Submodules: true,
+		LFS:        false,
+		Shallow:    true,
+	}
+}
+
+// CloneRepository clones the branch or the tag of the repository, and checks out the commit if commitHash is provided
+// Otherwise, the latest commit of the branch or the commit of the tag is checked out
+// If both branch and tag are empty, the commit is searched in the whole repository
+func CloneRepository(gitUrl string, branch string, tag string, commitHash string, username string, password string, privateKey string, options CloneOptions, destFolder string) (clonedCommitHash string, commitMessage string, err error) {
 	// Parse the URL
 	repoInfo, err := ParseGitRepoInfo(gitUrl)
 	if err != nil {
@@ -99,37 +184,236 @@ func CloneRepository(gitUrl string, branch string, username string, password str
 	if err != nil {
 		return "", "", err
 	}
+	credentials := newCredentialScope(gitUrl, auth, options.CredentialHosts)
 
 	// check if folder exists
 	if _, err := os.Stat(destFolder); os.IsNotExist(err) {
 		return "", "", errors.New("destination folder does not exist")
 	}
 
-	// clone the repo
-	repo, err := git.PlainClone(destFolder, false, &git.CloneOptions{
-		URL:               gitUrl,
-		Progress:          nil,
-		ReferenceName:     plumbing.NewBranchReferenceName(branch),
-		Auth:              auth,
-		Depth:             1,
-		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
-		ShallowSubmodules: true,
-	})
-	if err != nil {
-		return "", "", errors.New("failed to clone repository")
+	var repo *git.Repository
+	if strings.Compare(branch, "") == 0 && strings.Compare(tag, "") == 0 {
+		// commit can be in any branch, so the whole repository is cloned
+		if strings.Compare(commitHash, "") == 0 {
+			return "", "", errors.New("branch, tag or commit hash is required to clone repository")
+		}
+		repo, err = cloneRepositoryAtCommit(gitUrl, "", commitHash, auth, options, destFolder)
+		if err != nil {
+			return "", "", err
+		}
+	} else {
+		referenceName := plumbing.NewBranchReferenceName(branch)
+		if strings.Compare(tag, "") != 0 {
+			referenceName = plumbing.NewTagReferenceName(tag)
+		}
+		// submodules are checked out afterwards, to restrict the credentials
+		cloneOptions := &git.CloneOptions{
+			URL:               gitUrl,
+			Progress:          nil,
+			ReferenceName:     referenceName,
+			SingleBranch:      true,
+			Auth:              auth,
+			RecurseSubmodules: git.NoRecurseSubmodules,
+		}
+		if options.Shallow {
+			cloneOptions.Depth = 1
+		}
+		// clone the repo
+		repo, err = git.PlainClone(destFolder, false, cloneOptions)
+		if err != nil {
+			return "", "", errors.New("failed to clone repository")
+		}
+		head, err := repo.Head()
+		if err != nil {
+			return "", "", errors.New("failed to get commit history of repository")
+		}
+		if strings.Compare(commitHash, "") != 0 && strings.Compare(head.Hash().String(), commitHash) != 0 {
+			// the commit is not the tip of the branch anymore, clone the history of the branch to find it
+			// tag could have been moved to another commit, so the commit is searched in the whole repository
+			searchBranch := branch
+			if strings.Compare(tag, "") != 0 {
+				searchBranch = ""
+			}
+			repo, err = cloneRepositoryAtCommit(gitUrl, searchBranch, commitHash, auth, options, destFolder)
+			if err != nil {
+				return "", "", err
+			}
+		}
 	}
-	commitObject, err := repo.CommitObjects()
+	if options.Submodules {
+		err = updateSubmodules(repo, credentials, options, git.DefaultSubmoduleRecursionDepth)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	head, err := repo.Head()
 	if err != nil {
 		return "", "", errors.New("failed to get commit history of repository")
 	}
-	commit, err := commitObject.Next()
+	commit, err := repo.CommitObject(head.Hash())
 	if err != nil {
 		return "", "", errors.New("failed to get commit history of repository")
 	}
+	if options.LFS {
+		err = fetchLFSFiles(repo, gitUrl, credentials, destFolder)
+		if err != nil {
+			return "", "", err
+		}
+	}
 	return commit.Hash.String(), commit.Message, nil
 }
 
+// IsAuthError checks if the git server has rejected the credentials
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+		return true
+	}
+	// ssh handshake doesn't return typed error
+	return strings.Contains(err.Error(), "unable to authenticate")
+}
+
 // private function
+// cloneRepositoryAtCommit clones the history of the branch and checks out the commit, all branches are cloned if branch is empty
+func cloneRepositoryAtCommit(gitUrl string, branch string, commitHash string, auth transport.AuthMethod, options CloneOptions, destFolder string) (*git.Repository, error) {
+	// clean up the shallow clone
+	entries, err := os.ReadDir(destFolder)
+	if err != nil {
+		return nil, errors.New("failed to clean destination folder")
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(destFolder, entry.Name())); err != nil {
+			return nil, errors.New("failed to clean destination folder")
+		}
+	}
+	cloneOptions := &git.CloneOptions{
+		URL:        gitUrl,
+		Progress:   nil,
+		Auth:       auth,
+		NoCheckout: true,
+	}
+	if strings.Compare(branch, "") != 0 {
+		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(branch)
+		cloneOptions.SingleBranch = true
+	} else {
+		cloneOptions.Tags = git.AllTags
+	}
+	repo, err := git.PlainClone(destFolder, false, cloneOptions)
+	if err != nil {
+		return nil, errors.New("failed to clone repository")
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, errors.New("failed to checkout commit " + commitHash)
+	}
+	err = worktree.Checkout(&git.CheckoutOptions{
+		Hash:  plumbing.NewHash(commitHash),
+		Force: true,
+	})
+	if err != nil {
+		if strings.Compare(branch, "") == 0 {
+			return nil, errors.New("commit " + commitHash + " not found in repository")
+		}
+		return nil, errors.New("commit " + commitHash + " not found in branch " + branch)
+	}
+	return repo, nil
+}
+
+// updateSubmodules checks out the submodules recursively, the credentials are sent only to the trusted hosts
+func updateSubmodules(repo *git.Repository, credentials *credentialScope, options CloneOptions, depth git.SubmoduleRescursivity) error {
+	if depth == 0 {
+		return nil
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return errors.New("failed to open worktree of repository")
+	}
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return errors.New("failed to fetch submodules of repository")
+	}
+	for _, submodule := range submodules {
+		err = submodule.Init()
+		if err != nil && !errors.Is(err, git.ErrSubmoduleAlreadyInitialized) {
+			return errors.New("failed to init submodule " + submodule.Config().Name)
+		}
+		// relative url of the submodule is resolved, when the repository of the submodule is created
+		submoduleRepo, err := submodule.Repository()
+		if err != nil {
+			return errors.New("failed to open submodule " + submodule.Config().Name)
+		}
+		remote, err := submoduleRepo.Remote(git.DefaultRemoteName)
+		if err != nil || len(remote.Config().URLs) == 0 {
+			return errors.New("failed to find remote of submodule " + submodule.Config().Name)
+		}
+		submoduleUpdateOptions := &git.SubmoduleUpdateOptions{
+			RecurseSubmodules: git.NoRecurseSubmodules,
+			Auth:              credentials.authFor(remote.Config().URLs[0]),
+		}
+		if options.Shallow {
+			submoduleUpdateOptions.Depth = 1
+		}
+		err = submodule.Update(submoduleUpdateOptions)
+		if err != nil {
+			return errors.New("failed to clone submodule " + submodule.Config().Name)
+		}
+		submoduleRepo, err = submodule.Repository()
+		if err != nil {
+			return errors.New("failed to open submodule " + submodule.Config().Name)
+		}
+		err = updateSubmodules(submoduleRepo, credentials, options, depth-1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// credentialScope : credentials of the repository, with the hosts where those can be sent
+type credentialScope struct {
+	auth  transport.AuthMethod
+	hosts map[string]bool
+}
+
+func newCredentialScope(gitUrl string, auth transport.AuthMethod, allowedHosts []string) *credentialScope {
+	scope := &credentialScope{
+		auth:  auth,
+		hosts: make(map[string]bool),
+	}
+	if endpoint, err := transport.NewEndpoint(gitUrl); err == nil {
+		scope.hosts[strings.ToLower(endpoint.Host)] = true
+	}
+	for _, host := range allowedHosts {
+		scope.hosts[strings.ToLower(strings.TrimSpace(host))] = true
+	}
+	return scope
+}
+
+// authFor : credentials for the remote, nil if the remote is not on a trusted host
+func (s *credentialScope) authFor(remoteUrl string) transport.AuthMethod {
+	if s.auth == nil {
+		return nil
+	}
+	endpoint, err := transport.NewEndpoint(remoteUrl)
+	if err != nil || !s.hosts[strings.ToLower(endpoint.Host)] {
+		return nil
+	}
+	// http credentials are not sent to ssh remote and vice versa
+	switch s.auth.(type) {
+	case *http.BasicAuth:
+		if endpoint.Protocol != "http" && endpoint.Protocol != "https" {
+			return nil
+		}
+	case ssh.AuthMethod:
+		if endpoint.Protocol != "ssh" {
+			return nil
+		}
+	}
+	return s.auth
+}
+
 func getAuthMethod(repoInfo *GitRepoInfo, username string, password string, privateKey string) (transport.AuthMethod, error) {
 	if repoInfo == nil {
 		return nil, errors.New("invalid repository info")
@@ -0,0 +1,172 @@
+package gitmanager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+const testLFSContent = "large file tracked by git lfs\n"
+
+// testRepositories : bare repositories served from the local filesystem
+type testRepositories struct {
+	appUrl        string
+	firstCommit   string
+	latestCommit  string
+	lfsPointerRaw string
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=swiftwave", "-c", "user.email=test@swiftwave.xyz", "-c", "protocol.file.allow=always"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %s", args, output)
+	}
+	return string(output)
+}
+
+// setupTestRepositories : app repository with a submodule, a git lfs file and two commits
+func setupTestRepositories(t *testing.T) *testRepositories {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	libBare := filepath.Join(root, "lib.git")
+	appBare := filepath.Join(root, "app.git")
+	runGit(t, root, "init", "--bare", "-b", "main", libBare)
+	runGit(t, root, "init", "--bare", "-b", "main", appBare)
+
+	// submodule
+	libWork := filepath.Join(root, "lib")
+	runGit(t, root, "init", "-b", "main", libWork)
+	assert.NoError(t, os.WriteFile(filepath.Join(libWork, "lib.txt"), []byte("shared code\n"), 0644))
+	runGit(t, libWork, "add", ".")
+	runGit(t, libWork, "commit", "-m", "add shared code")
+	runGit(t, libWork, "push", libBare, "main")
+
+	// app with git lfs pointer, the object is stored in the lfs storage of the bare repository
+	appWork := filepath.Join(root, "app")
+	runGit(t, root, "init", "-b", "main", appWork)
+	hash := sha256.Sum256([]byte(testLFSContent))
+	oid := hex.EncodeToString(hash[:])
+	pointer := fmt.Sprintf("%s\noid sha256:%s\nsize %d\n", lfsPointerVersion, oid, len(testLFSContent))
+	objectDir := filepath.Join(appBare, "lfs", "objects", oid[0:2], oid[2:4])
+	assert.NoError(t, os.MkdirAll(objectDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(objectDir, oid), []byte(testLFSContent), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(appWork, "asset.bin"), []byte(pointer), 0644))
+	runGit(t, appWork, "submodule", "add", libBare, "lib")
+	runGit(t, appWork, "add", ".")
+	runGit(t, appWork, "commit", "-m", "first commit")
+	firstCommit := runGit(t, appWork, "rev-parse", "HEAD")
+	assert.NoError(t, os.WriteFile(filepath.Join(appWork, "README.md"), []byte("app\n"), 0644))
+	runGit(t, appWork, "add", ".")
+	runGit(t, appWork, "commit", "-m", "second commit")
+	latestCommit := runGit(t, appWork, "rev-parse", "HEAD")
+	runGit(t, appWork, "push", appBare, "main")
+
+	return &testRepositories{
+		appUrl:        appBare,
+		firstCommit:   firstCommit[:40],
+		latestCommit:  latestCommit[:40],
+		lfsPointerRaw: pointer,
+	}
+}
+
+func commitCount(t *testing.T, dir string) int {
+	repo, err := git.PlainOpen(dir)
+	assert.NoError(t, err)
+	commits, err := repo.Log(&git.LogOptions{})
+	assert.NoError(t, err)
+	count := 0
+	_ = commits.ForEach(func(_ *object.Commit) error {
+		count++
+		return nil
+	})
+	return count
+}
+
+func TestCloneRepository(t *testing.T) {
+	repositories := setupTestRepositories(t)
+
+	t.Run("default options checkout submodules from shallow clone without lfs files", func(t *testing.T) {
+		dest := t.TempDir()
+		commitHash, commitMessage, err := CloneRepository(repositories.appUrl, "main", "", "", "", "", "", DefaultCloneOptions(), dest)
+		assert.NoError(t, err)
+		assert.Equal(t, repositories.latestCommit, commitHash)
+		assert.Equal(t, "second commit\n", commitMessage)
+		assert.FileExists(t, filepath.Join(dest, "lib", "lib.txt"))
+		content, err := os.ReadFile(filepath.Join(dest, "asset.bin"))
+		assert.NoError(t, err)
+		assert.Equal(t, repositories.lfsPointerRaw, string(content))
+		assert.Equal(t, 1, commitCount(t, dest))
+	})
+
+	t.Run("lfs files are fetched from local repository", func(t *testing.T) {
+		dest := t.TempDir()
+		_, _, err := CloneRepository(repositories.appUrl, "main", "", "", "", "", "", CloneOptions{Submodules: true, LFS: true, Shallow: true}, dest)
+		assert.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(dest, "asset.bin"))
+		assert.NoError(t, err)
+		assert.Equal(t, testLFSContent, string(content))
+	})
+
+	t.Run("submodules are skipped if disabled", func(t *testing.T) {
+		dest := t.TempDir()
+		_, _, err := CloneRepository(repositories.appUrl, "main", "", "", "", "", "", CloneOptions{Submodules: false, LFS: false, Shallow: true}, dest)
+		assert.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(dest, "lib", "lib.txt"))
+	})
+
+	t.Run("whole history is cloned if shallow clone is disabled", func(t *testing.T) {
+		dest := t.TempDir()
+		_, _, err := CloneRepository(repositories.appUrl, "main", "", "", "", "", "", CloneOptions{Submodules: true, LFS: false, Shallow: false}, dest)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, commitCount(t, dest))
+	})
+
+	t.Run("older commit is checked out with submodules and lfs files", func(t *testing.T) {
+		dest := t.TempDir()
+		commitHash, _, err := CloneRepository(repositories.appUrl, "main", "", repositories.firstCommit, "", "", "", CloneOptions{Submodules: true, LFS: true, Shallow: true}, dest)
+		assert.NoError(t, err)
+		assert.Equal(t, repositories.firstCommit, commitHash)
+		assert.NoFileExists(t, filepath.Join(dest, "README.md"))
+		assert.FileExists(t, filepath.Join(dest, "lib", "lib.txt"))
+		content, err := os.ReadFile(filepath.Join(dest, "asset.bin"))
+		assert.NoError(t, err)
+		assert.Equal(t, testLFSContent, string(content))
+	})
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	hash := sha256.Sum256([]byte(testLFSContent))
+	oid := hex.EncodeToString(hash[:])
+	pointer, ok := parseLFSPointer([]byte(fmt.Sprintf("%s\noid sha256:%s\nsize 30\n", lfsPointerVersion, oid)))
+	assert.True(t, ok)
+	assert.Equal(t, oid, pointer.Oid)
+	assert.Equal(t, int64(30), pointer.Size)
+
+	_, ok = parseLFSPointer([]byte("regular file\n"))
+	assert.False(t, ok)
+	_, ok = parseLFSPointer([]byte(fmt.Sprintf("%s\noid sha256:abc\nsize 30\n", lfsPointerVersion)))
+	assert.False(t, ok)
+}
+
+func TestIsAuthError(t *testing.T) {
+	assert.False(t, IsAuthError(nil))
+	assert.False(t, IsAuthError(errors.New("repository not found")))
+	assert.True(t, IsAuthError(transport.ErrAuthenticationRequired))
+	assert.True(t, IsAuthError(fmt.Errorf("failed to fetch > %w", transport.ErrAuthorizationFailed)))
+	assert.True(t, IsAuthError(errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey]")))
+}
@@ -137,43 +137,16 @@ func (s Manager) GetReplicaCount(transactionId string, backendProtocol BackendPr
 		return 0, errors.New("backend does not exist")
 	}
 
-	// Fetch server template request query parameters
-	fetchServerTemplateRequestQueryParams := QueryParameters{}
-	fetchServerTemplateRequestQueryParams.add("backend", backendName)
-	fetchServerTemplateRequestQueryParams.add("transaction_id", transactionId)
-	// Send GET request to fetch server template
-	serverTemplateRes, serverTemplateErr := s.getRequest("/services/haproxy/configuration/server_templates", fetchServerTemplateRequestQueryParams)
-	if serverTemplateErr != nil {
-		return 0, errors.New("failed to fetch server template")
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Println("[haproxy_manager] FetchBackendReplicas:", err)
-		}
-	}(serverTemplateRes.Body)
-	if !isValidStatusCode(serverTemplateRes.StatusCode) {
-		return 0, errors.New("failed to fetch server template")
-	}
-	// Read response body
-	serverTemplateResBody, err := io.ReadAll(serverTemplateRes.Body)
+	// Fetch server template of the service, backend can also have the servers of a candidate service
+	serverTemplate, err := s.fetchServerTemplate(transactionId, backendName, serviceName+"_container-")
 	if err != nil {
-		return 0, errors.New("failed to read server template response body")
-	}
-	// Unmarshal response body
-	serverTemplateResBodyMap := map[string]interface{}{}
-	err = json.Unmarshal(serverTemplateResBody, &serverTemplateResBodyMap)
-	if err != nil {
-		return 0, errors.New("failed to unmarshal server template response body")
+		return 0, err
 	}
-	serverTemplates := serverTemplateResBodyMap["data"].([]interface{})
-	if len(serverTemplates) == 0 {
+	if serverTemplate == nil {
 		return 0, nil
 	}
-	// Get server template
-	serverTemplate := serverTemplates[0].(map[string]interface{})
 	// Get server template replicas
-	return strconv.Atoi(serverTemplate["num_or_range"].(string))
+	return serverTemplateReplicas(serverTemplate)
 }
 
 // UpdateBackendReplicas : Update Backend Replicas
@@ -197,6 +170,11 @@ func (s Manager) UpdateBackendReplicas(transactionId string, backendProtocol Bac
 	replicasStr := strconv.Itoa(replicas)
 	// Server template prefix
 	serverTemplatePrefix := serviceName + "_container-"
+	// Preserve the weight of the servers, set during traffic split with candidate service
+	existingServerTemplate, err := s.fetchServerTemplate(transactionId, backendName, serverTemplatePrefix)
+	if err != nil {
+		return err
+	}
 	// Update template query parameters
 	updateServerTemplateRequestQueryParams := QueryParameters{}
 	updateServerTemplateRequestQueryParams.add("transaction_id", transactionId)
@@ -211,6 +189,11 @@ func (s Manager) UpdateBackendReplicas(transactionId string, backendProtocol Bac
 		"init-addr":    "none",
 		"num_or_range": replicasStr,
 	}
+	if existingServerTemplate != nil {
+		if weight, ok := existingServerTemplate["weight"]; ok {
+			updateServerTemplateRequestBody["weight"] = weight
+		}
+	}
 	updateServerTemplateRequestBodyBytes, err := json.Marshal(updateServerTemplateRequestBody)
 	if err != nil {
 		return errors.New("failed to marshal add_server_template_request_body")
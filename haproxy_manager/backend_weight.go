@@ -0,0 +1,263 @@
+package haproxymanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// maxServerWeight : haproxy accepts server weight in range 0-256
+const maxServerWeight = 256
+
+// defaultServerWeight : weight of the servers if not set explicitly
+const defaultServerWeight = 1
+
+// fetchServerTemplate : Fetch the server template of the backend by prefix
+// Returns nil if server template doesn't exist
+func (s Manager) fetchServerTemplate(transactionId string, backendName string, prefix string) (map[string]interface{}, error) {
+	params := QueryParameters{}
+	params.add("transaction_id", transactionId)
+	params.add("backend", backendName)
+	// Send request
+	serverTemplateRes, serverTemplateErr := s.getRequest("/services/haproxy/configuration/server_templates/"+prefix, params)
+	if serverTemplateErr != nil {
+		return nil, errors.New("failed to fetch server template")
+	}
+	defer func(body io.ReadCloser) {
+		_ = body.Close()
+	}(serverTemplateRes.Body)
+	if serverTemplateRes.StatusCode == 404 {
+		return nil, nil
+	}
+	if !isValidStatusCode(serverTemplateRes.StatusCode) {
+		return nil, errors.New("failed to fetch server template")
+	}
+	// Parse response
+	var serverTemplateData map[string]interface{}
+	err := json.NewDecoder(serverTemplateRes.Body).Decode(&serverTemplateData)
+	if err != nil {
+		return nil, errors.New("failed to unmarshal server template response body")
+	}
+	serverTemplate, ok := serverTemplateData["data"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid server template response body")
+	}
+	return serverTemplate, nil
+}
+
+// serverTemplateReplicas : number of servers in the server template
+func serverTemplateReplicas(serverTemplate map[string]interface{}) (int, error) {
+	replicas, ok := serverTemplate["num_or_range"].(string)
+	if !ok {
+		return 0, errors.New("invalid replicas in server template")
+	}
+	return strconv.Atoi(replicas)
+}
+
+// IsServerTemplateExist : Check servers of the target service are added in the backend of the service
+func (s Manager) IsServerTemplateExist(transactionId string, backendProtocol BackendProtocol, serviceName string, port int, targetServiceName string) (bool, error) {
+	backendName := s.GenerateBackendName(backendProtocol, serviceName, port)
+	serverTemplate, err := s.fetchServerTemplate(transactionId, backendName, targetServiceName+"_container-")
+	if err != nil {
+		return false, err
+	}
+	return serverTemplate != nil, nil
+}
+
+// AddOrUpdateServerTemplate : Add servers of the target service in the backend of the service
+// -- Used to run a candidate version next to the current version of the service
+// -- New servers get no traffic till weight is set by SetTrafficSplit, weight of existing servers is preserved
+func (s Manager) AddOrUpdateServerTemplate(transactionId string, backendProtocol BackendProtocol, serviceName string, port int, targetServiceName string, replicas int) error {
+	backendName := s.GenerateBackendName(backendProtocol, serviceName, port)
+	// Check if backend exist
+	isBackendExist, err := s.IsBackendExist(transactionId, backendName)
+	if err != nil {
+		return err
+	}
+	if !isBackendExist {
+		return errors.New("backend does not exist")
+	}
+	if replicas <= 0 {
+		replicas = 1
+	}
+	serverTemplatePrefix := targetServiceName + "_container-"
+	existingServerTemplate, err := s.fetchServerTemplate(transactionId, backendName, serverTemplatePrefix)
+	if err != nil {
+		return err
+	}
+	weight := 0
+	if existingServerTemplate != nil {
+		if existingWeight, ok := existingServerTemplate["weight"].(float64); ok {
+			weight = int(existingWeight)
+		}
+	}
+	params := QueryParameters{}
+	params.add("transaction_id", transactionId)
+	params.add("backend", backendName)
+	reqBody := map[string]interface{}{
+		"prefix":       serverTemplatePrefix,
+		"fqdn":         targetServiceName,
+		"port":         port,
+		"check":        "disabled",
+		"resolvers":    "docker",
+		"init-addr":    "none",
+		"num_or_range": strconv.Itoa(replicas),
+		"weight":       weight,
+	}
+	reqBodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return errors.New("failed to marshal server_template_request_body")
+	}
+	if existingServerTemplate == nil {
+		serverTemplateRes, serverTemplateErr := s.postRequest("/services/haproxy/configuration/server_templates", params, bytes.NewReader(reqBodyBytes))
+		if serverTemplateErr != nil || !isValidStatusCode(serverTemplateRes.StatusCode) {
+			return errors.New("failed to add server template")
+		}
+		defer func(body io.ReadCloser) {
+			_ = body.Close()
+		}(serverTemplateRes.Body)
+	} else {
+		serverTemplateRes, serverTemplateErr := s.putRequest("/services/haproxy/configuration/server_templates/"+serverTemplatePrefix, params, bytes.NewReader(reqBodyBytes))
+		if serverTemplateErr != nil || !isValidStatusCode(serverTemplateRes.StatusCode) {
+			return errors.New("failed to update server template")
+		}
+		defer func(body io.ReadCloser) {
+			_ = body.Close()
+		}(serverTemplateRes.Body)
+	}
+	return nil
+}
+
+// DeleteServerTemplate : Remove servers of the target service from the backend of the service
+func (s Manager) DeleteServerTemplate(transactionId string, backendProtocol BackendProtocol, serviceName string, port int, targetServiceName string) error {
+	backendName := s.GenerateBackendName(backendProtocol, serviceName, port)
+	params := QueryParameters{}
+	params.add("transaction_id", transactionId)
+	params.add("backend", backendName)
+	serverTemplateRes, serverTemplateErr := s.deleteRequest("/services/haproxy/configuration/server_templates/"+targetServiceName+"_container-", params)
+	if serverTemplateErr != nil {
+		return errors.New("failed to delete server template")
+	}
+	defer func(body io.ReadCloser) {
+		_ = body.Close()
+	}(serverTemplateRes.Body)
+	if serverTemplateRes.StatusCode == 404 {
+		return nil
+	} else if !isValidStatusCode(serverTemplateRes.StatusCode) {
+		return errors.New("failed to delete server template")
+	}
+	return nil
+}
+
+// updateServerTemplateWeight : Update weight of each server in the server template
+func (s Manager) updateServerTemplateWeight(transactionId string, backendName string, serverTemplate map[string]interface{}, weight int) error {
+	prefix, ok := serverTemplate["prefix"].(string)
+	if !ok {
+		return errors.New("invalid prefix in server template")
+	}
+	serverTemplate["weight"] = weight
+	params := QueryParameters{}
+	params.add("transaction_id", transactionId)
+	params.add("backend", backendName)
+	reqBodyBytes, err := json.Marshal(serverTemplate)
+	if err != nil {
+		return errors.New("failed to marshal server_template_request_body")
+	}
+	serverTemplateRes, serverTemplateErr := s.putRequest("/services/haproxy/configuration/server_templates/"+prefix, params, bytes.NewReader(reqBodyBytes))
+	if serverTemplateErr != nil || !isValidStatusCode(serverTemplateRes.StatusCode) {
+		return errors.New("failed to update weight of server template")
+	}
+	defer func(body io.ReadCloser) {
+		_ = body.Close()
+	}(serverTemplateRes.Body)
+	return nil
+}
+
+// SetTrafficSplit : Update the weights of the servers, so that candidate service receives candidatePercent of the traffic
+// -- Servers of the candidate service should be added using AddOrUpdateServerTemplate before
+func (s Manager) SetTrafficSplit(transactionId string, backendProtocol BackendProtocol, serviceName string, port int, candidateServiceName string, candidatePercent int) error {
+	backendName := s.GenerateBackendName(backendProtocol, serviceName, port)
+	serverTemplate, err := s.fetchServerTemplate(transactionId, backendName, serviceName+"_container-")
+	if err != nil {
+		return err
+	}
+	if serverTemplate == nil {
+		return errors.New("servers of " + serviceName + " not found in backend")
+	}
+	candidateServerTemplate, err := s.fetchServerTemplate(transactionId, backendName, candidateServiceName+"_container-")
+	if err != nil {
+		return err
+	}
+	if candidateServerTemplate == nil {
+		return errors.New("servers of " + candidateServiceName + " not found in backend")
+	}
+	replicas, err := serverTemplateReplicas(serverTemplate)
+	if err != nil {
+		return err
+	}
+	candidateReplicas, err := serverTemplateReplicas(candidateServerTemplate)
+	if err != nil {
+		return err
+	}
+	weight, candidateWeight := trafficSplitWeights(replicas, candidateReplicas, candidatePercent)
+	err = s.updateServerTemplateWeight(transactionId, backendName, serverTemplate, weight)
+	if err != nil {
+		return err
+	}
+	return s.updateServerTemplateWeight(transactionId, backendName, candidateServerTemplate, candidateWeight)
+}
+
+// ResetServerWeight : Restore the default weight of the servers of the service
+// -- Should be called after removing the servers of the candidate service
+func (s Manager) ResetServerWeight(transactionId string, backendProtocol BackendProtocol, serviceName string, port int) error {
+	backendName := s.GenerateBackendName(backendProtocol, serviceName, port)
+	serverTemplate, err := s.fetchServerTemplate(transactionId, backendName, serviceName+"_container-")
+	if err != nil {
+		return err
+	}
+	if serverTemplate == nil {
+		return nil
+	}
+	return s.updateServerTemplateWeight(transactionId, backendName, serverTemplate, defaultServerWeight)
+}
+
+// trafficSplitWeights : weight of each server of the service and the candidate service
+// Weight is per server, so the number of servers on each side is taken into account
+func trafficSplitWeights(replicas int, candidateReplicas int, candidatePercent int) (int, int) {
+	if candidatePercent <= 0 {
+		return defaultServerWeight, 0
+	}
+	if candidatePercent >= 100 {
+		return 0, defaultServerWeight
+	}
+	if replicas <= 0 {
+		replicas = 1
+	}
+	if candidateReplicas <= 0 {
+		candidateReplicas = 1
+	}
+	weight := (100 - candidatePercent) * candidateReplicas
+	candidateWeight := candidatePercent * replicas
+	divisor := gcd(weight, candidateWeight)
+	weight /= divisor
+	candidateWeight /= divisor
+	// scale down to the range accepted by haproxy
+	if weight > maxServerWeight || candidateWeight > maxServerWeight {
+		largest := weight
+		if candidateWeight > largest {
+			largest = candidateWeight
+		}
+		weight = max(1, weight*maxServerWeight/largest)
+		candidateWeight = max(1, candidateWeight*maxServerWeight/largest)
+	}
+	return weight, candidateWeight
+}
+
+func gcd(a int, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,122 @@
+package haproxymanager
+
+import (
+	"fmt"
+	"gotest.tools/v3/assert"
+	"strings"
+	"testing"
+)
+
+func TestTrafficSplitWeights(t *testing.T) {
+	t.Run("no traffic to candidate", func(t *testing.T) {
+		weight, candidateWeight := trafficSplitWeights(3, 1, 0)
+		assert.Check(t, weight == 1 && candidateWeight == 0, "candidate should get no traffic")
+	})
+
+	t.Run("all traffic to candidate", func(t *testing.T) {
+		weight, candidateWeight := trafficSplitWeights(3, 1, 100)
+		assert.Check(t, weight == 0 && candidateWeight == 1, "service should get no traffic")
+	})
+
+	t.Run("same replicas", func(t *testing.T) {
+		weight, candidateWeight := trafficSplitWeights(2, 2, 25)
+		assert.Check(t, weight == 3 && candidateWeight == 1, "weights should be 3:1")
+	})
+
+	t.Run("different replicas", func(t *testing.T) {
+		// 4 servers of weight 1 and 1 server of weight 1 => 20% to candidate
+		weight, candidateWeight := trafficSplitWeights(4, 1, 20)
+		assert.Check(t, weight == 1 && candidateWeight == 1, "weights should be 1:1")
+	})
+
+	t.Run("weights in haproxy range", func(t *testing.T) {
+		weight, candidateWeight := trafficSplitWeights(7, 3, 1)
+		assert.Check(t, weight <= maxServerWeight && candidateWeight <= maxServerWeight, "weights should not exceed max weight")
+		assert.Check(t, weight >= 1 && candidateWeight >= 1, "weights should not be zero")
+	})
+}
+
+func TestCandidateServerTemplate(t *testing.T) {
+	serviceName := "test-service"
+	candidateServiceName := "test-service-candidate"
+	servicePort := 8080
+	backendProtocol := HTTPBackend
+
+	t.Run("add candidate servers", func(t *testing.T) {
+		transactionId := newTransaction()
+		defer deleteTransaction(transactionId)
+		_, err := haproxyTestManager.AddBackend(transactionId, backendProtocol, serviceName, servicePort, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = haproxyTestManager.AddOrUpdateServerTemplate(transactionId, backendProtocol, serviceName, servicePort, candidateServiceName, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isExists, err := haproxyTestManager.IsServerTemplateExist(transactionId, backendProtocol, serviceName, servicePort, candidateServiceName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Check(t, isExists, "candidate server template should exist")
+		config := fetchConfig(transactionId)
+		assert.Check(t, strings.Contains(config, fmt.Sprintf("server-template %s_container- 2 %s:%d", candidateServiceName, candidateServiceName, servicePort)), "candidate server template should be in config")
+		replicas, err := haproxyTestManager.GetReplicaCount(transactionId, backendProtocol, serviceName, servicePort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Check(t, replicas == 2, "replicas count of service should not be affected by candidate")
+	})
+
+	t.Run("set traffic split", func(t *testing.T) {
+		transactionId := newTransaction()
+		defer deleteTransaction(transactionId)
+		_, err := haproxyTestManager.AddBackend(transactionId, backendProtocol, serviceName, servicePort, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = haproxyTestManager.AddOrUpdateServerTemplate(transactionId, backendProtocol, serviceName, servicePort, candidateServiceName, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = haproxyTestManager.SetTrafficSplit(transactionId, backendProtocol, serviceName, servicePort, candidateServiceName, 25)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config := fetchConfig(transactionId)
+		assert.Check(t, strings.Contains(config, "weight 3"), "service servers should have weight 3")
+		assert.Check(t, strings.Contains(config, "weight 1"), "candidate servers should have weight 1")
+		// weight should be preserved on replica update
+		err = haproxyTestManager.UpdateBackendReplicas(transactionId, backendProtocol, serviceName, servicePort, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Check(t, strings.Contains(fetchConfig(transactionId), "weight 3"), "weight should be preserved after replica update")
+	})
+
+	t.Run("delete candidate servers", func(t *testing.T) {
+		transactionId := newTransaction()
+		defer deleteTransaction(transactionId)
+		_, err := haproxyTestManager.AddBackend(transactionId, backendProtocol, serviceName, servicePort, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = haproxyTestManager.AddOrUpdateServerTemplate(transactionId, backendProtocol, serviceName, servicePort, candidateServiceName, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = haproxyTestManager.DeleteServerTemplate(transactionId, backendProtocol, serviceName, servicePort, candidateServiceName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = haproxyTestManager.ResetServerWeight(transactionId, backendProtocol, serviceName, servicePort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isExists, err := haproxyTestManager.IsServerTemplateExist(transactionId, backendProtocol, serviceName, servicePort, candidateServiceName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Check(t, !isExists, "candidate server template should not exist after deletion")
+		assert.Check(t, !strings.Contains(fetchConfig(transactionId), candidateServiceName), "candidate should not be in config")
+	})
+}
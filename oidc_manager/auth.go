@@ -0,0 +1,94 @@
+package oidcmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewAuthRequest : generate state, nonce and pkce code verifier for a new login
+func NewAuthRequest() (*AuthRequest, error) {
+	state, err := randomString(24)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString(24)
+	if err != nil {
+		return nil, err
+	}
+	// 32 bytes result in 43 characters, the minimum length allowed by RFC 7636
+	codeVerifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthRequest{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	}, nil
+}
+
+// AuthCodeURL : url of the authorization endpoint to redirect the user for login
+func (p *Provider) AuthCodeURL(request *AuthRequest) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.options.ClientID)
+	params.Set("redirect_uri", p.options.RedirectURL)
+	params.Set("scope", strings.Join(p.options.Scopes, " "))
+	params.Set("state", request.State)
+	params.Set("nonce", request.Nonce)
+	params.Set("code_challenge", codeChallenge(request.CodeVerifier))
+	params.Set("code_challenge_method", "S256")
+	separator := "?"
+	if strings.Contains(p.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.discovery.AuthorizationEndpoint + separator + params.Encode()
+}
+
+// Exchange : exchange the authorization code for the tokens and return the raw id token
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	if code == "" {
+		return "", errors.New("authorization code is missing")
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.options.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.options.ClientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.options.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.options.ClientID), url.QueryEscape(p.options.ClientSecret))
+	}
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call token endpoint > %w", err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	var response tokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode token response > %w", err)
+	}
+	if res.StatusCode != http.StatusOK || response.Error != "" {
+		if response.Error != "" {
+			return "", fmt.Errorf("token endpoint returned error > %s %s", response.Error, response.ErrorDescription)
+		}
+		return "", fmt.Errorf("token endpoint returned status code %d", res.StatusCode)
+	}
+	if response.IDToken == "" {
+		return "", errors.New("token response doesn't contain id token")
+	}
+	return response.IDToken, nil
+}
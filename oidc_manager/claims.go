@@ -0,0 +1,35 @@
+package oidcmanager
+
+import (
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+)
+
+// ClaimString : value of a string claim, empty if not found
+func ClaimString(claims jwt.MapClaims, name string) string {
+	value, ok := claims[name].(string)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// ClaimValues : values of a claim which can be a string, a space/comma separated string or an array
+func ClaimValues(claims jwt.MapClaims, name string) []string {
+	values := make([]string, 0)
+	switch v := claims[name].(type) {
+	case string:
+		values = append(values, strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })...)
+	case []interface{}:
+		for _, item := range v {
+			if item == nil {
+				continue
+			}
+			values = append(values, fmt.Sprintf("%v", item))
+		}
+	case bool, float64:
+		values = append(values, fmt.Sprintf("%v", v))
+	}
+	return values
+}
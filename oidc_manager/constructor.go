@@ -0,0 +1,73 @@
+package oidcmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NewProvider : fetch the discovery document of the issuer and create a new provider
+func NewProvider(ctx context.Context, options Options) (*Provider, error) {
+	if options.IssuerURL == "" || options.ClientID == "" || options.RedirectURL == "" {
+		return nil, errors.New("issuer url, client id and redirect url are required")
+	}
+	if len(options.Scopes) == 0 {
+		options.Scopes = []string{"openid"}
+	}
+	if !isExistsInList(options.Scopes, "openid") {
+		options.Scopes = append([]string{"openid"}, options.Scopes...)
+	}
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	provider := &Provider{
+		options:    options,
+		httpClient: httpClient,
+	}
+	discoveryURL := strings.TrimSuffix(options.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := provider.getJSON(ctx, discoveryURL, &provider.discovery); err != nil {
+		return nil, fmt.Errorf("failed to fetch discovery document > %w", err)
+	}
+	// issuer in the discovery document must be the configured issuer
+	if strings.TrimSuffix(provider.discovery.Issuer, "/") != strings.TrimSuffix(options.IssuerURL, "/") {
+		return nil, fmt.Errorf("issuer mismatch, expected %s but got %s", options.IssuerURL, provider.discovery.Issuer)
+	}
+	if provider.discovery.AuthorizationEndpoint == "" || provider.discovery.TokenEndpoint == "" || provider.discovery.JWKSURI == "" {
+		return nil, errors.New("discovery document is missing required endpoints")
+	}
+	// pkce with S256 is mandatory, if the provider publishes the supported methods
+	if len(provider.discovery.CodeChallengeMethodsSupported) > 0 &&
+		!isExistsInList(provider.discovery.CodeChallengeMethodsSupported, "S256") {
+		return nil, errors.New("provider doesn't support S256 code challenge method")
+	}
+	return provider, nil
+}
+
+// Discovery : return the discovery document of the provider
+func (p *Provider) Discovery() DiscoveryDocument {
+	return p.discovery
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", res.StatusCode, url)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
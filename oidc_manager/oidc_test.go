@@ -0,0 +1,243 @@
+package oidcmanager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID     = "swiftwave"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://localhost:3333/auth/oidc/callback"
+	testKeyID        = "test-key"
+)
+
+// mockIssuer : minimal OpenID Connect provider for testing
+type mockIssuer struct {
+	server     *httptest.Server
+	privateKey *rsa.PrivateKey
+	mutex      sync.Mutex
+	// code -> pending authorization
+	codes map[string]mockAuthorization
+	// claims to be added to the next issued id token
+	claims jwt.MapClaims
+}
+
+type mockAuthorization struct {
+	codeChallenge string
+	nonce         string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &mockIssuer{
+		privateKey: privateKey,
+		codes:      make(map[string]mockAuthorization),
+		claims:     jwt.MapClaims{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(DiscoveryDocument{
+			Issuer:                        issuer.server.URL,
+			AuthorizationEndpoint:         issuer.server.URL + "/authorize",
+			TokenEndpoint:                 issuer.server.URL + "/token",
+			JWKSURI:                       issuer.server.URL + "/jwks",
+			CodeChallengeMethodsSupported: []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jsonWebKeySet{
+			Keys: []jsonWebKey{{
+				Kty: "RSA",
+				Kid: testKeyID,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(privateKey.PublicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != testClientID || clientSecret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_client"})
+			return
+		}
+		_ = r.ParseForm()
+		issuer.mutex.Lock()
+		authorization, found := issuer.codes[r.Form.Get("code")]
+		delete(issuer.codes, r.Form.Get("code"))
+		issuer.mutex.Unlock()
+		if !found || codeChallenge(r.Form.Get("code_verifier")) != authorization.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   issuer.server.URL,
+			"aud":   testClientID,
+			"sub":   "1234",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": authorization.nonce,
+		}
+		for key, value := range issuer.claims {
+			claims[key] = value
+		}
+		_ = json.NewEncoder(w).Encode(tokenResponse{
+			AccessToken: "access-token",
+			TokenType:   "Bearer",
+			IDToken:     issuer.sign(t, claims),
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// authorize : simulate the user login at the authorization endpoint and return the code
+func (issuer *mockIssuer) authorize(t *testing.T, authCodeURL string) string {
+	parsedURL, err := url.Parse(authCodeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsedURL.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, testClientID, query.Get("client_id"))
+	assert.Equal(t, testRedirectURL, query.Get("redirect_uri"))
+	code := "code-" + query.Get("state")
+	issuer.mutex.Lock()
+	issuer.codes[code] = mockAuthorization{
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+	}
+	issuer.mutex.Unlock()
+	return code
+}
+
+func (issuer *mockIssuer) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(issuer.privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func (issuer *mockIssuer) provider(t *testing.T) *Provider {
+	provider, err := NewProvider(context.Background(), Options{
+		IssuerURL:    issuer.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"profile", "email"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestDiscovery(t *testing.T) {
+	issuer := newMockIssuer(t)
+
+	t.Run("fetch discovery document", func(t *testing.T) {
+		provider := issuer.provider(t)
+		assert.Equal(t, issuer.server.URL+"/token", provider.Discovery().TokenEndpoint)
+		assert.Equal(t, []string{"openid", "profile", "email"}, provider.options.Scopes)
+	})
+
+	t.Run("reject issuer mismatch", func(t *testing.T) {
+		_, err := NewProvider(context.Background(), Options{
+			IssuerURL:   issuer.server.URL + "/other",
+			ClientID:    testClientID,
+			RedirectURL: testRedirectURL,
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := issuer.provider(t)
+
+	t.Run("login with pkce", func(t *testing.T) {
+		issuer.claims = jwt.MapClaims{"preferred_username": "john", "groups": []interface{}{"developers", "ops"}}
+		request, err := NewAuthRequest()
+		assert.NoError(t, err)
+		code := issuer.authorize(t, provider.AuthCodeURL(request))
+		rawIDToken, err := provider.Exchange(context.Background(), code, request.CodeVerifier)
+		assert.NoError(t, err)
+		claims, err := provider.VerifyIDToken(context.Background(), rawIDToken, request.Nonce)
+		assert.NoError(t, err)
+		assert.Equal(t, "john", ClaimString(claims, "preferred_username"))
+		assert.Equal(t, []string{"developers", "ops"}, ClaimValues(claims, "groups"))
+	})
+
+	t.Run("reject wrong code verifier", func(t *testing.T) {
+		request, err := NewAuthRequest()
+		assert.NoError(t, err)
+		code := issuer.authorize(t, provider.AuthCodeURL(request))
+		_, err = provider.Exchange(context.Background(), code, "wrong-code-verifier")
+		assert.Error(t, err)
+	})
+
+	t.Run("reject nonce mismatch", func(t *testing.T) {
+		request, err := NewAuthRequest()
+		assert.NoError(t, err)
+		code := issuer.authorize(t, provider.AuthCodeURL(request))
+		rawIDToken, err := provider.Exchange(context.Background(), code, request.CodeVerifier)
+		assert.NoError(t, err)
+		_, err = provider.VerifyIDToken(context.Background(), rawIDToken, "other-nonce")
+		assert.Error(t, err)
+	})
+
+	t.Run("reject expired id token", func(t *testing.T) {
+		rawIDToken := issuer.sign(t, jwt.MapClaims{
+			"iss":   issuer.server.URL,
+			"aud":   testClientID,
+			"exp":   time.Now().Add(-time.Minute).Unix(),
+			"nonce": "nonce",
+		})
+		_, err := provider.VerifyIDToken(context.Background(), rawIDToken, "nonce")
+		assert.Error(t, err)
+	})
+
+	t.Run("reject id token of other audience", func(t *testing.T) {
+		rawIDToken := issuer.sign(t, jwt.MapClaims{
+			"iss":   issuer.server.URL,
+			"aud":   "other-client",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": "nonce",
+		})
+		_, err := provider.VerifyIDToken(context.Background(), rawIDToken, "nonce")
+		assert.Error(t, err)
+	})
+}
+
+func TestClaimValues(t *testing.T) {
+	claims := jwt.MapClaims{
+		"groups": "admins, developers",
+		"roles":  []interface{}{"viewer"},
+	}
+	assert.Equal(t, []string{"admins", "developers"}, ClaimValues(claims, "groups"))
+	assert.Equal(t, []string{"viewer"}, ClaimValues(claims, "roles"))
+	assert.Equal(t, []string{}, ClaimValues(claims, "missing"))
+}
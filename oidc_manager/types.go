@@ -0,0 +1,65 @@
+package oidcmanager
+
+import (
+	"net/http"
+)
+
+// Provider : OpenID Connect relying party for a single issuer
+type Provider struct {
+	options    Options
+	discovery  DiscoveryDocument
+	httpClient *http.Client
+}
+
+// Options : configuration of the relying party
+type Options struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// HTTPClient is optional, http.DefaultClient with timeout is used if nil
+	HTTPClient *http.Client
+}
+
+// DiscoveryDocument : subset of the provider metadata published at /.well-known/openid-configuration
+type DiscoveryDocument struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// AuthRequest : values which need to be preserved between the login redirect and the callback
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
@@ -0,0 +1,107 @@
+package oidcmanager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+)
+
+var supportedSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// VerifyIDToken : verify signature, issuer, audience, expiry and nonce of the id token and return the claims
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (jwt.MapClaims, error) {
+	var keySet jsonWebKeySet
+	if err := p.getJSON(ctx, p.discovery.JWKSURI, &keySet); err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks > %w", err)
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keySet.publicKey(kid)
+	},
+		jwt.WithValidMethods(supportedSigningMethods),
+		jwt.WithIssuer(p.discovery.Issuer),
+		jwt.WithAudience(p.options.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token > %w", err)
+	}
+	tokenNonce, _ := claims["nonce"].(string)
+	if tokenNonce != nonce {
+		return nil, errors.New("invalid id token > nonce mismatch")
+	}
+	return claims, nil
+}
+
+// publicKey : find the public key by key id, if key id is empty and only one signing key exists, that key is used
+func (keySet jsonWebKeySet) publicKey(kid string) (interface{}, error) {
+	var candidates []jsonWebKey
+	for _, key := range keySet.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if kid == "" || key.Kid == kid {
+			candidates = append(candidates, key)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no signing key found for kid %s", kid)
+	}
+	if len(candidates) > 1 {
+		return nil, errors.New("multiple signing keys found, id token must have kid in header")
+	}
+	return candidates[0].publicKey()
+}
+
+func (key jsonWebKey) publicKey() (interface{}, error) {
+	switch key.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, errors.New("invalid rsa modulus in jwk")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, errors.New("invalid rsa exponent in jwk")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s in jwk", key.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil {
+			return nil, errors.New("invalid x coordinate in jwk")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(key.Y)
+		if err != nil {
+			return nil, errors.New("invalid y coordinate in jwk")
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s in jwk", key.Kty)
+	}
+}
@@ -1,6 +1,7 @@
 package ssh_toolkit
 
 import (
+	"context"
 	"fmt"
 	"golang.org/x/crypto/ssh"
 	"net"
@@ -11,6 +12,18 @@ func NetConnOverSSH(
 	network, address string, netTimeoutSeconds int, // for target task
 	host string, port int, user string, privateKey string, // for ssh client
 ) (net.Conn, error) {
+	return NetConnOverSSHWithContext(context.Background(), network, address, netTimeoutSeconds, host, port, user, privateKey)
+}
+
+// NetConnOverSSHWithContext : same as NetConnOverSSH, but dialing is aborted once the context is done
+func NetConnOverSSHWithContext(
+	ctx context.Context,
+	network, address string, netTimeoutSeconds int, // for target task
+	host string, port int, user string, privateKey string, // for ssh client
+) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	// fetch ssh client
 	sshRecord, err := getSSHClientWithOptions(host, port, user, privateKey, true)
 	if err != nil {
@@ -20,7 +33,7 @@ func NetConnOverSSH(
 		return nil, err
 	}
 	// create net connection
-	conn, err := dialWithTimeout(sshRecord, network, address, time.Duration(netTimeoutSeconds)*time.Second)
+	conn, err := dialWithTimeout(ctx, sshRecord, network, address, time.Duration(netTimeoutSeconds)*time.Second)
 	if err != nil && isErrorWhenSSHClientNeedToBeRecreated(err) {
 		DeleteSSHClient(host)
 	}
@@ -28,7 +41,7 @@ func NetConnOverSSH(
 }
 
 // private functions
-func dialWithTimeout(client *ssh.Client, network, address string, timeout time.Duration) (net.Conn, error) {
+func dialWithTimeout(ctx context.Context, client *ssh.Client, network, address string, timeout time.Duration) (net.Conn, error) {
 	type dialResult struct {
 		conn net.Conn
 		err  error
@@ -38,10 +51,23 @@ func dialWithTimeout(client *ssh.Client, network, address string, timeout time.D
 		conn, err := client.Dial(network, address)
 		resultCh <- dialResult{conn, err}
 	}()
+	// close the connection, if it's established after giving up
+	closeLateConn := func() {
+		go func() {
+			result := <-resultCh
+			if result.conn != nil {
+				_ = result.conn.Close()
+			}
+		}()
+	}
 	select {
 	case result := <-resultCh:
 		return result.conn, result.err
+	case <-ctx.Done():
+		closeLateConn()
+		return nil, ctx.Err()
 	case <-time.After(timeout):
+		closeLateConn()
 		return nil, fmt.Errorf("dial timeout after %s", timeout)
 	}
 }
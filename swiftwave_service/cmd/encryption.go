@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/config/local_config"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/config/system_config"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/db"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/encryption"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+// encryptedModels : models having fields encrypted at rest
+var encryptedModels = []interface{}{
+	&core.Application{},
+	&core.GitCredential{},
+	&core.ImageRegistryCredential{},
+	&core.PersistentVolume{},
+	&core.Domain{},
+	&core.EnvironmentVariable{},
+	&core.PreviewEnvironmentVariable{},
+	&core.Deployment{},
+	&system_config.SystemConfig{},
+}
+
+func init() {
+	encryptionCmd.AddCommand(rotateMasterKeyCmd)
+	encryptionCmd.AddCommand(encryptSecretsCmd)
+	rotateMasterKeyCmd.Flags().String("new-key", "", "New master key (base64 encoded 32 bytes) [Optional, generated if not provided]")
+}
+
+var encryptionCmd = &cobra.Command{
+	Use:   "encryption",
+	Short: "Manage encryption of secrets stored in database",
+	Long:  "Manage encryption of secrets stored in database. Secrets are encrypted by the master key stored in config file or SWIFTWAVE_MASTER_KEY environment variable",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Help()
+		if err != nil {
+			return
+		}
+	},
+}
+
+var encryptSecretsCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the existing plaintext secrets in database",
+	Long:  "Encrypt the existing plaintext secrets in database with the current master key. Secrets encrypted by the previous master key are re-encrypted as well",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !encryption.IsEnabled() {
+			printError("Master key is not configured")
+			return
+		}
+		// Initiating database client
+		dbClient, err := db.GetClient(config.LocalConfig, 10)
+		if err != nil {
+			printError("Failed to connect to database")
+			return
+		}
+		count, err := reEncryptSecrets(dbClient)
+		if err != nil {
+			printError("Failed to encrypt secrets")
+			printError("Reason: " + err.Error())
+			return
+		}
+		printSuccess("Encrypted secrets of " + strconv.FormatInt(count, 10) + " records")
+		// all the secrets are encrypted by the active key now, previous key is of no use after rotation
+		localConfig := config.LocalConfig
+		if localConfig.EncryptionConfig.PreviousMasterKey != "" && !localConfig.IsMasterKeyFromEnvironment() {
+			localConfig.EncryptionConfig.PreviousMasterKey = ""
+			err = local_config.Update(localConfig)
+			if err != nil {
+				printError("Failed to remove previous master key from config file > " + err.Error())
+				printInfo("Remove encryption.previous_master_key manually from " + local_config.LocalConfigPath)
+				return
+			}
+			printSuccess("Removed previous master key from config file")
+		}
+	},
+}
+
+var rotateMasterKeyCmd = &cobra.Command{
+	Use:     "rotate-key",
+	Short:   "Rotate the master key and re-encrypt the secrets",
+	Long:    "Rotate the master key and re-encrypt all the secrets in database with the new key. Restart the service after rotation",
+	Example: "swiftwave encryption rotate-key\nSWIFTWAVE_MASTER_KEY=<current key> swiftwave encryption rotate-key --new-key <new key>",
+	Run: func(cmd *cobra.Command, args []string) {
+		localConfig := config.LocalConfig
+		currentMasterKey := localConfig.ActiveMasterKey()
+		newMasterKey := cmd.Flag("new-key").Value.String()
+		isMasterKeyFromEnvironment := localConfig.IsMasterKeyFromEnvironment()
+		if isMasterKeyFromEnvironment && newMasterKey == "" {
+			printError("Master key is provided by " + encryption.MasterKeyEnvironmentVariable + " environment variable")
+			printInfo("Provide the new key with --new-key flag, generate one with `openssl rand -base64 32`")
+			return
+		}
+		if newMasterKey == "" {
+			var err error
+			newMasterKey, err = encryption.GenerateMasterKey()
+			if err != nil {
+				printError("Failed to generate master key")
+				return
+			}
+		}
+		if _, err := encryption.ParseMasterKey(newMasterKey); err != nil {
+			printError("Invalid master key > " + err.Error())
+			return
+		}
+		if newMasterKey == currentMasterKey {
+			printError("New master key is same as the current master key")
+			return
+		}
+		// Initiating database client
+		dbClient, err := db.GetClient(localConfig, 10)
+		if err != nil {
+			printError("Failed to connect to database")
+			return
+		}
+		// re-encrypt with the new key first, the config is switched only after the secrets are committed
+		// so a failed rotation leaves both database and config untouched
+		err = encryption.SetMasterKeys(newMasterKey, currentMasterKey)
+		if err != nil {
+			printError("Failed to load master key > " + err.Error())
+			return
+		}
+		count, err := reEncryptSecrets(dbClient)
+		if err != nil {
+			printError("Failed to re-encrypt secrets, no changes made in database and config")
+			printError("Reason: " + err.Error())
+			return
+		}
+		printSuccess("Re-encrypted secrets of " + strconv.FormatInt(count, 10) + " records")
+		if isMasterKeyFromEnvironment {
+			printWarning("Update " + encryption.MasterKeyEnvironmentVariable + " environment variable with the new key and restart the service")
+			printInfo("Secrets written by the service before restart are encrypted by the current key, set it as encryption.previous_master_key in " + local_config.LocalConfigPath + " and run `swiftwave encryption encrypt` after restart")
+			return
+		}
+		// the running service keeps encrypting with the current key till restart, so keep it to decrypt those secrets
+		localConfig.EncryptionConfig.MasterKey = newMasterKey
+		localConfig.EncryptionConfig.PreviousMasterKey = currentMasterKey
+		err = local_config.Update(localConfig)
+		if err != nil {
+			printError("Secrets are encrypted with the new master key, but failed to update config file > " + err.Error())
+			printInfo("Set encryption.master_key to " + newMasterKey + " and encryption.previous_master_key to the current key manually in " + local_config.LocalConfigPath)
+			return
+		}
+		printSuccess("Master key rotated successfully")
+		printInfo("Restart the service and run `swiftwave encryption encrypt` to re-encrypt the secrets written before restart and remove the previous master key")
+	},
+}
+
+// reEncryptSecrets : re-encrypt all the secrets with the active master key in a single transaction
+func reEncryptSecrets(dbClient *gorm.DB) (int64, error) {
+	var count int64
+	err := dbClient.Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = encryption.ReEncrypt(tx, encryptedModels...)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -95,6 +95,8 @@ var initCmd = &cobra.Command{
 		currentLocalImageRegistryUser := ""
 		currentLocalImageRegistryPassword := ""
 
+		currentEncryptionConfig := local_config.EncryptionConfig{}
+
 		if config != nil && config.LocalConfig != nil {
 			currentPostgresHost = config.LocalConfig.PostgresqlConfig.Host
 			currentPostgresPort = config.LocalConfig.PostgresqlConfig.Port
@@ -107,6 +109,8 @@ var initCmd = &cobra.Command{
 			currentLocalImageRegistryPort = config.LocalConfig.LocalImageRegistryConfig.Port
 			currentLocalImageRegistryUser = config.LocalConfig.LocalImageRegistryConfig.Username
 			currentLocalImageRegistryPassword = config.LocalConfig.LocalImageRegistryConfig.Password
+			// keep the master key, otherwise the encrypted secrets in database can't be decrypted
+			currentEncryptionConfig = config.LocalConfig.EncryptionConfig
 		}
 
 		// Create config
@@ -133,12 +137,18 @@ var initCmd = &cobra.Command{
 				Username: defaultString(currentLocalImageRegistryUser, "user_"+generateRandomString(8)),
 				Password: defaultString(currentLocalImageRegistryPassword, generateRandomString(20)),
 			},
+			EncryptionConfig: currentEncryptionConfig,
 		}
 		err = local_config.FillDefaults(newConfig)
 		if err != nil {
 			printError(err.Error())
 			os.Exit(1)
 		}
+		err = newConfig.SetupEncryption()
+		if err != nil {
+			printError("Failed to load master key > " + err.Error())
+			os.Exit(1)
+		}
 		// generate list of folders to create
 		requiredFolders := []string{
 			newConfig.ServiceConfig.DataDirectory,
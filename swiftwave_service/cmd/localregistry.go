@@ -141,6 +141,7 @@ func startLocalRegistry(ctx context.Context) error {
 				"-e", "REGISTRY_AUTH=htpasswd",
 				"-e", "REGISTRY_AUTH_HTPASSWD_PATH=/auth/htpasswd",
 				"-e", "REGISTRY_AUTH_HTPASSWD_REALM=Registry Realm",
+				"-e", "REGISTRY_STORAGE_DELETE_ENABLED=true",
 				"-e", "REGISTRY_HTTP_TLS_CERTIFICATE=/cert/certificate.crt",
 				"-e", "REGISTRY_HTTP_TLS_KEY=/cert/private.key",
 				"-v", fmt.Sprintf("%s:/cert", config.LocalConfig.LocalImageRegistryConfig.CertPath),
@@ -155,6 +156,7 @@ func startLocalRegistry(ctx context.Context) error {
 				"-e", "REGISTRY_AUTH=htpasswd",
 				"-e", "REGISTRY_AUTH_HTPASSWD_PATH=/auth/htpasswd",
 				"-e", "REGISTRY_AUTH_HTPASSWD_REALM=Registry Realm",
+				"-e", "REGISTRY_STORAGE_DELETE_ENABLED=true",
 				"-v", fmt.Sprintf("%s:/auth", config.LocalConfig.LocalImageRegistryConfig.AuthPath),
 				"-v", fmt.Sprintf("%s:/var/lib/registry", config.LocalConfig.LocalImageRegistryConfig.DataPath),
 				"--name", localRegistryContainerName, config.LocalConfig.LocalImageRegistryConfig.Image)
@@ -28,6 +28,7 @@ func init() {
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(autoUpdateCmd)
 	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(encryptionCmd)
 }
 
 var rootCmd = &cobra.Command{
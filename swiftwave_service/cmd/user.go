@@ -15,10 +15,15 @@ func init() {
 	userManagementCmd.AddCommand(createUserCmd)
 	userManagementCmd.AddCommand(deleteUserCmd)
 	userManagementCmd.AddCommand(disableTotpCmd)
+	userManagementCmd.AddCommand(unlockUserCmd)
+	userManagementCmd.AddCommand(removeWebAuthnCmd)
 	createUserCmd.Flags().StringP("username", "u", "", "Username")
 	createUserCmd.Flags().StringP("password", "p", "", "Password [Optional]")
+	createUserCmd.Flags().StringP("role", "r", string(core.AdministratorRole), "Role of the user [admin, manager, viewer]")
 	deleteUserCmd.Flags().StringP("username", "u", "", "Username")
 	disableTotpCmd.Flags().StringP("username", "u", "", "Username")
+	unlockUserCmd.Flags().StringP("username", "u", "", "Username")
+	removeWebAuthnCmd.Flags().StringP("username", "u", "", "Username")
 }
 
 var userManagementCmd = &cobra.Command{
@@ -40,6 +45,7 @@ var createUserCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		username := cmd.Flag("username").Value.String()
 		password := cmd.Flag("password").Value.String()
+		role := core.UserRole(cmd.Flag("role").Value.String())
 		if username == "" {
 			printError("Username is required")
 			err := cmd.Help()
@@ -48,6 +54,10 @@ var createUserCmd = &cobra.Command{
 			}
 			return
 		}
+		if !role.IsValid() {
+			printError("Invalid role > " + string(role))
+			return
+		}
 		if password == "" {
 			// Ask for password
 			fmt.Print("Enter password: ")
@@ -86,6 +96,7 @@ var createUserCmd = &cobra.Command{
 		// Create user
 		user := core.User{
 			Username: username,
+			Role:     role,
 		}
 		err = user.SetPassword(password)
 		if err != nil {
@@ -97,7 +108,7 @@ var createUserCmd = &cobra.Command{
 			printError("Failed to create user")
 			return
 		}
-		printSuccess("Created user > " + createUser.Username)
+		printSuccess("Created user > " + createUser.Username + " [" + string(createUser.Role) + "]")
 	},
 }
 
@@ -169,3 +180,70 @@ var disableTotpCmd = &cobra.Command{
 		printSuccess("Disabled Totp for user > " + username)
 	},
 }
+
+var unlockUserCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock a user locked due to failed login attempts",
+	Long:  "Unlock a user locked due to failed login attempts and reset the failed attempts count",
+	Run: func(cmd *cobra.Command, args []string) {
+		username := cmd.Flag("username").Value.String()
+		if username == "" {
+			printError("Username is required")
+			err := cmd.Help()
+			if err != nil {
+				return
+			}
+			return
+		}
+		// Initiating database client
+		dbClient, err := db.GetClient(config.LocalConfig, 10)
+		if err != nil {
+			printError("Failed to connect to database")
+			return
+		}
+		// Unlock user
+		err = core.UnlockUser(context.Background(), *dbClient, username)
+		if err != nil {
+			printError("Failed to unlock user")
+			printError("Reason: " + err.Error())
+			return
+		}
+		printSuccess("Unlocked user > " + username)
+	},
+}
+
+var removeWebAuthnCmd = &cobra.Command{
+	Use:   "remove-webauthn",
+	Short: "Remove all security keys of a user",
+	Long:  "Remove all registered WebAuthn security keys of a user, in case the user lost access to them",
+	Run: func(cmd *cobra.Command, args []string) {
+		username := cmd.Flag("username").Value.String()
+		if username == "" {
+			printError("Username is required")
+			err := cmd.Help()
+			if err != nil {
+				return
+			}
+			return
+		}
+		// Initiating database client
+		dbClient, err := db.GetClient(config.LocalConfig, 10)
+		if err != nil {
+			printError("Failed to connect to database")
+			return
+		}
+		user, err := core.FindUserByUsername(context.Background(), *dbClient, username)
+		if err != nil {
+			printError("User not found")
+			return
+		}
+		// Remove security keys
+		err = core.DeleteWebAuthnCredentialsByUserID(context.Background(), *dbClient, user.ID)
+		if err != nil {
+			printError("Failed to remove security keys")
+			printError("Reason: " + err.Error())
+			return
+		}
+		printSuccess("Removed security keys of user > " + username)
+	},
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/db"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func init() {
+	userManagementCmd.AddCommand(userTokenCmd)
+	userTokenCmd.AddCommand(createUserTokenCmd)
+	userTokenCmd.AddCommand(listUserTokensCmd)
+	userTokenCmd.AddCommand(revokeUserTokenCmd)
+	createUserTokenCmd.Flags().StringP("username", "u", "", "Username")
+	createUserTokenCmd.Flags().StringP("name", "n", "", "Name of the token")
+	createUserTokenCmd.Flags().StringSliceP("scopes", "s", []string{string(core.PersonalAccessTokenScopeRead), string(core.PersonalAccessTokenScopeWrite)}, "Scopes of the token [read, write]")
+	createUserTokenCmd.Flags().DurationP("expires-in", "e", 0, "Expire the token after the duration (e.g. 720h) [Optional]")
+	listUserTokensCmd.Flags().StringP("username", "u", "", "Username")
+	revokeUserTokenCmd.Flags().StringP("username", "u", "", "Username")
+	revokeUserTokenCmd.Flags().String("id", "", "ID of the token")
+}
+
+var userTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage personal access tokens of user",
+	Long:  "Manage personal access tokens of user, which can be used to access the api from scripts and CI pipelines",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Help()
+		if err != nil {
+			return
+		}
+	},
+}
+
+var createUserTokenCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Create a new personal access token",
+	Long:    "Create a new personal access token. The token will be shown only once",
+	Example: "swiftwave user token create -u admin -n github-actions -s read,write -e 720h",
+	Run: func(cmd *cobra.Command, args []string) {
+		username := cmd.Flag("username").Value.String()
+		name := cmd.Flag("name").Value.String()
+		if username == "" || name == "" {
+			printError("Username and name of token are required")
+			printInfo(cmd.Example)
+			return
+		}
+		scopes, err := cmd.Flags().GetStringSlice("scopes")
+		if err != nil {
+			printError("Failed to read scopes")
+			return
+		}
+		expiresIn, err := cmd.Flags().GetDuration("expires-in")
+		if err != nil {
+			printError("Failed to read expiry duration")
+			return
+		}
+		// Initiating database client
+		dbClient, err := db.GetClient(config.LocalConfig, 10)
+		if err != nil {
+			printError("Failed to connect to database")
+			return
+		}
+		user, err := core.FindUserByUsername(context.Background(), *dbClient, username)
+		if err != nil {
+			printError("User not found")
+			return
+		}
+		record := &core.PersonalAccessToken{
+			UserID: user.ID,
+			Name:   name,
+			Scopes: scopes,
+		}
+		if expiresIn > 0 {
+			expiresAt := time.Now().Add(expiresIn)
+			record.ExpiresAt = &expiresAt
+		}
+		token, err := record.Create(context.Background(), *dbClient)
+		if err != nil {
+			printError("Failed to create token")
+			printError("Reason: " + err.Error())
+			return
+		}
+		printSuccess("Created personal access token > " + record.Name + " [" + record.ID + "]")
+		printInfo("Copy the token now, it will not be shown again")
+		fmt.Println(token)
+	},
+}
+
+var listUserTokensCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List personal access tokens of user",
+	Long:  "List personal access tokens of user",
+	Run: func(cmd *cobra.Command, args []string) {
+		username := cmd.Flag("username").Value.String()
+		if username == "" {
+			printError("Username is required")
+			err := cmd.Help()
+			if err != nil {
+				return
+			}
+			return
+		}
+		// Initiating database client
+		dbClient, err := db.GetClient(config.LocalConfig, 10)
+		if err != nil {
+			printError("Failed to connect to database")
+			return
+		}
+		user, err := core.FindUserByUsername(context.Background(), *dbClient, username)
+		if err != nil {
+			printError("User not found")
+			return
+		}
+		tokens, err := core.FindPersonalAccessTokensByUserID(context.Background(), *dbClient, user.ID)
+		if err != nil {
+			printError("Failed to fetch tokens")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 4, 1, 2, ' ', 0)
+		fs := "%s\t%s\t%s\t%s\t%s\n"
+		_, _ = fmt.Fprintf(w, fs, "ID", "NAME", "SCOPES", "EXPIRES AT", "LAST USED AT")
+		_, _ = fmt.Fprintf(w, fs, "--", "----", "------", "----------", "------------")
+		for _, token := range tokens {
+			expiresAt := "never"
+			if token.ExpiresAt != nil {
+				expiresAt = token.ExpiresAt.Format(time.RFC3339)
+				if token.IsExpired() {
+					expiresAt += " (expired)"
+				}
+			}
+			lastUsedAt := "never"
+			if token.LastUsedAt != nil {
+				lastUsedAt = token.LastUsedAt.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, fs, token.ID, token.Name, strings.Join(token.Scopes, ","), expiresAt, lastUsedAt)
+		}
+		_ = w.Flush()
+	},
+}
+
+var revokeUserTokenCmd = &cobra.Command{
+	Use:     "revoke",
+	Short:   "Revoke a personal access token",
+	Long:    "Revoke a personal access token",
+	Example: "swiftwave user token revoke -u admin --id <token_id>",
+	Run: func(cmd *cobra.Command, args []string) {
+		username := cmd.Flag("username").Value.String()
+		id := cmd.Flag("id").Value.String()
+		if username == "" || id == "" {
+			printError("Username and id of token are required")
+			printInfo(cmd.Example)
+			return
+		}
+		// Initiating database client
+		dbClient, err := db.GetClient(config.LocalConfig, 10)
+		if err != nil {
+			printError("Failed to connect to database")
+			return
+		}
+		user, err := core.FindUserByUsername(context.Background(), *dbClient, username)
+		if err != nil {
+			printError("User not found")
+			return
+		}
+		record := &core.PersonalAccessToken{}
+		err = record.FindById(context.Background(), *dbClient, id)
+		if err != nil || record.UserID != user.ID {
+			printError("Token not found")
+			return
+		}
+		err = record.Delete(context.Background(), *dbClient)
+		if err != nil {
+			printError("Failed to revoke token")
+			return
+		}
+		printSuccess("Revoked personal access token > " + record.Name)
+	},
+}
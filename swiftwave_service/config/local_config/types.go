@@ -13,6 +13,7 @@ type Config struct {
 	LocalImageRegistryConfig       LocalImageRegistryConfig       `yaml:"local_image_registry"`
 	EnvironmentVariables           EnvironmentVariables           `yaml:"environment_variables"`
 	ManagementNodeTunnellingConfig ManagementNodeTunnellingConfig `yaml:"management_node_tunnelling"`
+	EncryptionConfig               EncryptionConfig               `yaml:"encryption"`
 }
 
 type ServiceConfig struct {
@@ -79,3 +80,11 @@ type ManagementNodeTunnellingConfig struct {
 	LocalImageRegistryNodeAddress string `yaml:"local_image_registry_node_address"`
 	LocalImageRegistryNodePort    int    `yaml:"local_image_registry_node_port"`
 }
+
+// EncryptionConfig : master key to encrypt the secrets stored in database
+// If SWIFTWAVE_MASTER_KEY environment variable is set, it will be used instead of MasterKey
+// PreviousMasterKey is only set while rotating the master key, to decrypt the values encrypted by older key
+type EncryptionConfig struct {
+	MasterKey         string `yaml:"master_key"`
+	PreviousMasterKey string `yaml:"previous_master_key,omitempty"`
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/swiftwave-org/swiftwave/ssh_toolkit"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/encryption"
 	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
 	"io"
@@ -86,6 +87,11 @@ func readConfigFile(path string) (*Config, error) {
 	ssh_toolkit.UpdateTCPTimeout(config.ServiceConfig.SSHTimeout)
 	// write config
 	_ = Update(&config)
+	// load master key for encryption of secrets
+	err = config.SetupEncryption()
+	if err != nil {
+		return nil, errors.New("failed to load master key > " + err.Error())
+	}
 	return &config, nil
 }
 
@@ -134,9 +140,36 @@ func FillDefaults(config *Config) error {
 	if strings.Compare(config.EnvironmentVariables.SshKnownHosts, "") == 0 {
 		config.EnvironmentVariables.SshKnownHosts = os.Getenv("SSH_KNOWN_HOSTS")
 	}
+	// generate master key, if not provided by environment variable
+	if strings.Compare(config.EncryptionConfig.MasterKey, "") == 0 && !config.IsMasterKeyFromEnvironment() {
+		masterKey, err := encryption.GenerateMasterKey()
+		if err != nil {
+			return errors.New("failed to generate master key")
+		}
+		config.EncryptionConfig.MasterKey = masterKey
+	}
 	return nil
 }
 
+// IsMasterKeyFromEnvironment : check if the master key is provided by environment variable
+func (config *Config) IsMasterKeyFromEnvironment() bool {
+	return strings.TrimSpace(os.Getenv(encryption.MasterKeyEnvironmentVariable)) != ""
+}
+
+// ActiveMasterKey : master key used to encrypt the secrets
+func (config *Config) ActiveMasterKey() string {
+	if config.IsMasterKeyFromEnvironment() {
+		return strings.TrimSpace(os.Getenv(encryption.MasterKeyEnvironmentVariable))
+	}
+	return config.EncryptionConfig.MasterKey
+}
+
+// SetupEncryption : configure the encryption package with the master keys
+// The master key of the config file is kept as fallback, if the environment variable is set
+func (config *Config) SetupEncryption() error {
+	return encryption.SetMasterKeys(config.ActiveMasterKey(), config.EncryptionConfig.MasterKey, config.EncryptionConfig.PreviousMasterKey)
+}
+
 func (p PostgresqlConfig) DSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s TimeZone=%s sslmode=%s", p.Host, p.Port, p.User, p.Password, p.Database, p.TimeZone, p.SSLMode)
 }
@@ -32,6 +32,8 @@ type SystemConfigurationPayload struct {
 	PvBackupConfig       PvBackupConfig      `json:"pv_backup_config"`
 	PubsubConfig         PubsubConfig        `json:"pubsub_config"`
 	TaskQueueConfig      TaskQueueConfig     `json:"task_queue_config"`
+	OIDCConfig           OIDCConfig          `json:"oidc_config"`
+	RetentionConfig      RetentionConfig     `json:"retention_config"`
 	NewAdminCredential   NewAdminCredential  `json:"new_admin_credential"`
 }
 
@@ -104,6 +106,24 @@ type AmqpConfig struct {
 	Vhost    string                 `json:"vhost"`
 }
 
+type OIDCConfig struct {
+	Enabled       bool   `json:"enabled"`
+	IssuerURL     string `json:"issuer_url"`
+	ClientID      string `json:"client_id"`
+	ClientSecret  string `json:"client_secret"`
+	RedirectURL   string `json:"redirect_url"`
+	Scopes        string `json:"scopes"`
+	UsernameClaim string `json:"username_claim"`
+	RoleClaim     string `json:"role_claim"`
+	RoleMapping   string `json:"role_mapping"`
+	DefaultRole   string `json:"default_role"`
+}
+
+type RetentionConfig struct {
+	KeepLastDeployments uint `json:"keep_last_deployments"`
+	KeepDays            uint `json:"keep_days"`
+}
+
 type NewAdminCredential struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
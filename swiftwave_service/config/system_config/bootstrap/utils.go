@@ -107,6 +107,13 @@ func generateRandomStringIfEmpty(s string, length int) string {
 	return s
 }
 
+func defaultStringIfEmpty(s string, defaultValue string) string {
+	if isEmptyString(s) {
+		return defaultValue
+	}
+	return strings.TrimSpace(s)
+}
+
 func payloadToDBRecord(payload SystemConfigurationPayload) (system_config.SystemConfig, error) {
 	// validations
 	if isEmptyString(payload.NetworkName) {
@@ -158,6 +165,27 @@ func payloadToDBRecord(payload SystemConfigurationPayload) (system_config.System
 		payload.TaskQueueConfig.RemoteTaskQueueType = NoneRemoteQueue
 	}
 
+	oidcConfig := system_config.OIDCConfig{
+		Enabled:       payload.OIDCConfig.Enabled,
+		IssuerURL:     strings.TrimSpace(payload.OIDCConfig.IssuerURL),
+		ClientID:      strings.TrimSpace(payload.OIDCConfig.ClientID),
+		ClientSecret:  payload.OIDCConfig.ClientSecret,
+		RedirectURL:   strings.TrimSpace(payload.OIDCConfig.RedirectURL),
+		Scopes:        defaultStringIfEmpty(payload.OIDCConfig.Scopes, "openid profile email"),
+		UsernameClaim: defaultStringIfEmpty(payload.OIDCConfig.UsernameClaim, "preferred_username"),
+		RoleClaim:     defaultStringIfEmpty(payload.OIDCConfig.RoleClaim, "groups"),
+		RoleMapping:   strings.TrimSpace(payload.OIDCConfig.RoleMapping),
+		DefaultRole:   strings.TrimSpace(payload.OIDCConfig.DefaultRole),
+	}
+	if oidcConfig.Enabled {
+		if isEmptyString(oidcConfig.IssuerURL) || isEmptyString(oidcConfig.ClientID) || isEmptyString(oidcConfig.RedirectURL) {
+			return system_config.SystemConfig{}, errors.New("oidc issuer url, client id and redirect url are required")
+		}
+		if _, err := oidcConfig.ParseRoleMapping(); err != nil {
+			return system_config.SystemConfig{}, err
+		}
+	}
+
 	return system_config.SystemConfig{
 		NetworkName:     payload.NetworkName,
 		ConfigVersion:   1,
@@ -219,6 +247,11 @@ func payloadToDBRecord(payload SystemConfigurationPayload) (system_config.System
 			},
 		},
 		ImageRegistryConfig: imageRegistryConfig,
+		OIDCConfig:          oidcConfig,
+		RetentionConfig: system_config.RetentionConfig{
+			KeepLastDeployments: payload.RetentionConfig.KeepLastDeployments,
+			KeepDays:            payload.RetentionConfig.KeepDays,
+		},
 	}, nil
 }
 
@@ -306,6 +339,22 @@ func dbRecordToPayload(record *system_config.SystemConfig) SystemConfigurationPa
 		},
 		PubsubConfig:    pubsubConfig,
 		TaskQueueConfig: taskQueueConfig,
+		OIDCConfig: OIDCConfig{
+			Enabled:       record.OIDCConfig.Enabled,
+			IssuerURL:     record.OIDCConfig.IssuerURL,
+			ClientID:      record.OIDCConfig.ClientID,
+			ClientSecret:  record.OIDCConfig.ClientSecret,
+			RedirectURL:   record.OIDCConfig.RedirectURL,
+			Scopes:        record.OIDCConfig.Scopes,
+			UsernameClaim: record.OIDCConfig.UsernameClaim,
+			RoleClaim:     record.OIDCConfig.RoleClaim,
+			RoleMapping:   record.OIDCConfig.RoleMapping,
+			DefaultRole:   record.OIDCConfig.DefaultRole,
+		},
+		RetentionConfig: RetentionConfig{
+			KeepLastDeployments: record.RetentionConfig.KeepLastDeployments,
+			KeepDays:            record.RetentionConfig.KeepDays,
+		},
 		NewAdminCredential: NewAdminCredential{
 			Username: "hidden",
 			Password: "hidden",
@@ -1,6 +1,9 @@
 package system_config
 
-import "github.com/lib/pq"
+import (
+	"github.com/lib/pq"
+	_ "github.com/swiftwave-org/swiftwave/swiftwave_service/encryption" // register serializer for encrypted fields
+)
 
 // ************************************************************************************* //
 //                                Swiftwave System Configuration 		   			     //
@@ -13,7 +16,7 @@ type SystemConfig struct {
 	NetworkName                  string                       `json:"network_name"`
 	RestrictedPorts              pq.Int64Array                `json:"restricted_ports" gorm:"type:integer[]"`
 	JWTSecretKey                 string                       `json:"jwt_secret_key"`
-	SshPrivateKey                string                       `json:"ssh_private_key"`
+	SshPrivateKey                string                       `json:"ssh_private_key" gorm:"serializer:encrypted"`
 	LetsEncryptConfig            LetsEncryptConfig            `json:"lets_encrypt_config" gorm:"embedded;embeddedPrefix:lets_encrypt_config_"`
 	HAProxyConfig                HAProxyConfig                `json:"haproxy_config" gorm:"embedded;embeddedPrefix:haproxy_config_"`
 	UDPProxyConfig               UDPProxyConfig               `json:"udp_proxy_config" gorm:"embedded;embeddedPrefix:udp_proxy_config_"`
@@ -21,4 +24,6 @@ type SystemConfig struct {
 	PubSubConfig                 PubSubConfig                 `json:"pub_sub_config" gorm:"embedded;embeddedPrefix:pub_sub_config_"`
 	TaskQueueConfig              TaskQueueConfig              `json:"task_queue_config" gorm:"embedded;embeddedPrefix:task_queue_config_"`
 	ImageRegistryConfig          ImageRegistryConfig          `json:"image_registry_config" gorm:"embedded;embeddedPrefix:image_registry_config_"`
+	OIDCConfig                   OIDCConfig                   `json:"oidc_config" gorm:"embedded;embeddedPrefix:oidc_config_"`
+	RetentionConfig              RetentionConfig              `json:"retention_config" gorm:"embedded;embeddedPrefix:retention_config_"`
 }
@@ -40,12 +40,36 @@ type ImageRegistryConfig struct {
 	Namespace string `json:"namespace"`
 }
 
+// OIDCConfig : configuration for OpenID Connect single sign-on
+// RoleMapping is a comma separated list of <claim value>=<role>, e.g. "platform-team=admin,developers=manager"
+// If no value of RoleClaim matches, DefaultRole is assigned. If DefaultRole is empty, login is denied
+type OIDCConfig struct {
+	Enabled      bool   `json:"enabled" gorm:"default:false"`
+	IssuerURL    string `json:"issuer_url"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret" gorm:"serializer:encrypted"`
+	RedirectURL  string `json:"redirect_url"`
+	Scopes       string `json:"scopes" gorm:"default:'openid profile email'"`
+	// UsernameClaim - only used as the username of the users created on first login, users are identified by `iss` + `sub`
+	UsernameClaim string `json:"username_claim" gorm:"default:'preferred_username'"`
+	RoleClaim     string `json:"role_claim" gorm:"default:'groups'"`
+	RoleMapping   string `json:"role_mapping"`
+	DefaultRole   string `json:"default_role"`
+}
+
+// RetentionConfig : system wide retention of old deployments, their logs and images
+// Applications can override it with their own retention policy, zero value means no limit
+type RetentionConfig struct {
+	KeepLastDeployments uint `json:"keep_last_deployments"`
+	KeepDays            uint `json:"keep_days"`
+}
+
 // LetsEncryptConfig : hold information about lets encrypt configuration
 type LetsEncryptConfig struct {
 	ID         uint   `json:"id" gorm:"primaryKey"`
 	Staging    bool   `json:"staging" gorm:"default:false"`
 	EmailID    string `json:"email_id"`
-	PrivateKey string `json:"private_key"`
+	PrivateKey string `json:"private_key" gorm:"serializer:encrypted"`
 }
 
 // FirewallConfig : hold information about firewall configuration
@@ -67,7 +91,7 @@ type S3BackupConfig struct {
 	Region          string `json:"region"`
 	Bucket          string `json:"bucket"`
 	AccessKeyID     string `json:"access_key_id"`
-	SecretAccessKey string `json:"secret_access_key"`
+	SecretAccessKey string `json:"secret_access_key" gorm:"serializer:encrypted"`
 	ForcePathStyle  bool   `json:"force_path_style"`
 }
 
@@ -70,9 +70,37 @@ func (config *SystemConfig) Update(db *gorm.DB) error {
 	if tx.Error != nil {
 		return tx.Error
 	}
+	// Updates skip zero values, so update the oidc and retention config explicitly to allow disabling them
+	tx = db.Model(&SystemConfig{}).Where("id = ?", config.ID).Updates(map[string]interface{}{
+		"oidc_config_enabled":                    config.OIDCConfig.Enabled,
+		"oidc_config_role_mapping":               config.OIDCConfig.RoleMapping,
+		"oidc_config_default_role":               config.OIDCConfig.DefaultRole,
+		"retention_config_keep_last_deployments": config.RetentionConfig.KeepLastDeployments,
+		"retention_config_keep_days":             config.RetentionConfig.KeepDays,
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
 	return nil
 }
 
+// ParseRoleMapping : parse the role mapping of oidc config to map of <claim value> -> <role>
+func (c OIDCConfig) ParseRoleMapping() (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, item := range strings.Split(c.RoleMapping, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid oidc role mapping > %s", item)
+		}
+		mapping[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return mapping, nil
+}
+
 func (a AMQPConfig) URI() string {
 	return fmt.Sprintf("%s://%s:%s@%s:%d/%s", a.Protocol, a.User, a.Password, a.Host, a.Port, a.VHost)
 }
@@ -77,6 +77,14 @@ func (config *Config) ImageRegistryPassword() string {
 	return config.LocalConfig.LocalImageRegistryConfig.Password
 }
 
+// ImageRegistryUseTLS : remote registry is always accessed over TLS
+func (config *Config) ImageRegistryUseTLS() bool {
+	if config.SystemConfig.ImageRegistryConfig.IsConfigured() {
+		return true
+	}
+	return config.LocalConfig.ServiceConfig.UseTLS
+}
+
 // private functions
 func isPortAdded(port int, ports pq.Int64Array) bool {
 	for _, p := range ports {
@@ -9,6 +9,7 @@ import (
 	"github.com/swiftwave-org/swiftwave/ssh_toolkit"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/manager"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/rest"
 	"golang.org/x/net/websocket"
 	"net/http"
 	"strconv"
@@ -17,9 +18,9 @@ import (
 // Initialize : Initialize the server and its routes
 func (server *Server) Initialize() {
 	server.initiateAssetRoutes()
-	server.EchoServer.POST("/console/token/server/:id", server.generateAuthTokenForServer)
-	server.EchoServer.POST("/console/token/application/:id/:server_id", server.generateAuthTokenForApplication)
-	server.EchoServer.GET("/console/application/:id/servers", server.fetchServersForApplication)
+	server.EchoServer.POST("/console/token/server/:id", server.generateAuthTokenForServer, rest.RecordAuditLog(server.ServiceManager.DbClient, core.AuditLogSourceConsole, "openServerConsole", "id"), rest.RequireRole(server.ServiceManager.DbClient, core.AdministratorRole))
+	server.EchoServer.POST("/console/token/application/:id/:server_id", server.generateAuthTokenForApplication, rest.RecordAuditLog(server.ServiceManager.DbClient, core.AuditLogSourceConsole, "openApplicationConsole", "id"), rest.RequireApplicationAccess(server.ServiceManager.DbClient, "id", core.WriteAccess))
+	server.EchoServer.GET("/console/application/:id/servers", server.fetchServersForApplication, rest.RequireApplicationAccess(server.ServiceManager.DbClient, "id", core.ReadAccess))
 	server.EchoServer.GET("/console/ws/:requestId/:token/:rows/:cols", server.consoleWebsocket)
 }
 
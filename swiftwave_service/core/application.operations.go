@@ -53,6 +53,13 @@ func FindAllApplications(_ context.Context, db gorm.DB, includeGroupedApplicatio
 	return applications, tx.Error
 }
 
+// FindGitPollingApplications : applications with git polling enabled, those are not deleted or sleeping
+func FindGitPollingApplications(_ context.Context, db gorm.DB) ([]*Application, error) {
+	var applications = make([]*Application, 0)
+	tx := db.Where("git_polling_enabled = ? AND is_deleted = ? AND is_sleeping = ?", true, false, false).Find(&applications)
+	return applications, tx.Error
+}
+
 type ApplicationDeploymentInfo struct {
 	ApplicationID string
 	DeploymentID  string
@@ -106,6 +113,47 @@ func (application *Application) Create(ctx context.Context, db gorm.DB, dockerMa
 	if application.ReservedResource.MemoryMB != 0 && application.ReservedResource.MemoryMB < 6 {
 		return errors.New("reserved memory should be at least 6 MB or 0 for unlimited")
 	}
+	// check secret environment variables
+	if err := validateSecretEnvironmentVariables(application.EnvironmentVariables); err != nil {
+		return err
+	}
+	// check dockerfile target and build secrets
+	if err := application.LatestDeployment.ValidateBuildConfig(application.EnvironmentVariables); err != nil {
+		return err
+	}
+	// check update config
+	if err := application.UpdateConfig.Validate(); err != nil {
+		return err
+	}
+	// check deployment strategy
+	if application.DeploymentStrategy == "" {
+		application.DeploymentStrategy = DeploymentStrategyRolling
+	}
+	if !application.DeploymentStrategy.IsValid() {
+		return errors.New("invalid deployment strategy")
+	}
+	if application.DeploymentStrategy.UsesCandidate() && application.DeploymentMode == DeploymentModeGlobal {
+		return errors.New("blue-green and canary deployment strategies are not supported for global deployment")
+	}
+	if application.DeploymentStrategy.UsesCandidate() && application.DeploymentMode.IsJob() {
+		return errors.New("blue-green and canary deployment strategies are not supported for job")
+	}
+	// check job config
+	if err := application.JobConfig.Validate(application.DeploymentMode); err != nil {
+		return err
+	}
+	// check git reference
+	if err := application.LatestDeployment.ValidateGitReference(); err != nil {
+		return err
+	}
+	// check git polling
+	if err := application.GitPolling.Validate(application.LatestDeployment.UpstreamType); err != nil {
+		return err
+	}
+	// check preview config
+	if err := application.PreviewConfig.Validate(application.LatestDeployment.UpstreamType, application.DeploymentMode); err != nil {
+		return err
+	}
 	// Verify the PreferredServerHostnames
 	if len(application.PreferredServerHostnames) > 0 {
 		for _, preferredServerHostname := range application.PreferredServerHostnames {
@@ -124,8 +172,7 @@ func (application *Application) Create(ctx context.Context, db gorm.DB, dockerMa
 	// For UpstreamType = Git, verify git record id
 	if application.LatestDeployment.UpstreamType == UpstreamTypeGit {
 		if application.LatestDeployment.GitCredentialID != nil {
-			var gitCredential = &GitCredential{}
-			err := gitCredential.FindById(ctx, db, *application.LatestDeployment.GitCredentialID)
+			err := application.LatestDeployment.CheckGitCredentialAccess(ctx, db)
 			if err != nil {
 				return err
 			}
@@ -166,6 +213,7 @@ func (application *Application) Create(ctx context.Context, db gorm.DB, dockerMa
 		DeploymentMode:           application.DeploymentMode,
 		Replicas:                 application.Replicas,
 		WebhookToken:             uuid.NewString(),
+		WebhookSecret:            uuid.NewString(),
 		Hostname:                 application.Hostname,
 		Command:                  application.Command,
 		Capabilities:             application.Capabilities,
@@ -176,6 +224,14 @@ func (application *Application) Create(ctx context.Context, db gorm.DB, dockerMa
 		DockerProxy:              application.DockerProxy,
 		PreferredServerHostnames: application.PreferredServerHostnames,
 		CustomHealthCheck:        application.CustomHealthCheck,
+		AutoRollback:             application.AutoRollback,
+		UpdateConfig:             application.UpdateConfig,
+		DeploymentStrategy:       application.DeploymentStrategy,
+		ReleaseCommands:          application.ReleaseCommands,
+		JobConfig:                application.JobConfig,
+		RetentionPolicy:          application.RetentionPolicy,
+		GitPolling:               application.GitPolling,
+		PreviewConfig:            application.PreviewConfig,
 	}
 	tx := db.Create(&createdApplication)
 	if tx.Error != nil {
@@ -188,6 +244,8 @@ func (application *Application) Create(ctx context.Context, db gorm.DB, dockerMa
 			ApplicationID: createdApplication.ID,
 			Key:           environmentVariable.Key,
 			Value:         environmentVariable.Value,
+			IsSecret:      environmentVariable.IsSecret,
+			SecretFileEnv: environmentVariable.SecretFileEnv,
 		}
 		createdEnvironmentVariables = append(createdEnvironmentVariables, createdEnvironmentVariable)
 	}
@@ -197,6 +255,11 @@ func (application *Application) Create(ctx context.Context, db gorm.DB, dockerMa
 			return tx.Error
 		}
 	}
+	// create overrides of environment variables for preview environments
+	err = ReplacePreviewEnvironmentVariables(ctx, db, createdApplication.ID, application.PreviewEnvironmentVariables)
+	if err != nil {
+		return err
+	}
 	// create persistent volume bindings
 	createdPersistentVolumeBindings := make([]PersistentVolumeBinding, 0)
 	persistedVolumeBindingsMountingPathSet := set.From[string](make([]string, 0))
@@ -269,13 +332,18 @@ func (application *Application) Create(ctx context.Context, db gorm.DB, dockerMa
 		RepositoryBranch: application.LatestDeployment.RepositoryBranch,
 		CommitHash:       application.LatestDeployment.CommitHash,
 		CodePath:         application.LatestDeployment.CodePath,
+		GitReferenceType: application.LatestDeployment.GitReferenceType,
+		RepositoryTag:    application.LatestDeployment.RepositoryTag,
+		GitTagPattern:    application.LatestDeployment.GitTagPattern,
+		GitCloneConfig:   application.LatestDeployment.GitCloneConfig,
 		// Fields for UpstreamType = SourceCode
 		SourceCodeCompressedFileName: application.LatestDeployment.SourceCodeCompressedFileName,
 		// Fields for UpstreamType = Image
 		DockerImage:               application.LatestDeployment.DockerImage,
 		ImageRegistryCredentialID: imageRegistryCredentialID,
 		// other fields
-		Dockerfile: application.LatestDeployment.Dockerfile,
+		Dockerfile:       application.LatestDeployment.Dockerfile,
+		DockerfileTarget: application.LatestDeployment.DockerfileTarget,
 	}
 	err = createdDeployment.Create(ctx, db)
 	if err != nil {
@@ -297,6 +365,20 @@ func (application *Application) Create(ctx context.Context, db gorm.DB, dockerMa
 			return tx.Error
 		}
 	}
+	// add build secrets to deployment
+	createdBuildSecrets := make([]BuildSecret, 0)
+	for _, buildSecret := range application.LatestDeployment.BuildSecrets {
+		createdBuildSecrets = append(createdBuildSecrets, BuildSecret{
+			DeploymentID: createdDeployment.ID,
+			Key:          buildSecret.Key,
+		})
+	}
+	if len(createdBuildSecrets) > 0 {
+		tx = db.Create(&createdBuildSecrets)
+		if tx.Error != nil {
+			return tx.Error
+		}
+	}
 	// update application details
 	*application = createdApplication
 	return nil
@@ -319,6 +401,51 @@ func (application *Application) Update(ctx context.Context, db gorm.DB, _ contai
 	if application.ReservedResource.MemoryMB != 0 && application.ReservedResource.MemoryMB < 6 {
 		return nil, errors.New("reserved memory should be at least 6 MB or 0 for unlimited")
 	}
+	// check secret environment variables
+	if err := validateSecretEnvironmentVariables(application.EnvironmentVariables); err != nil {
+		return nil, err
+	}
+	// check dockerfile target and build secrets
+	if err := application.LatestDeployment.ValidateBuildConfig(application.EnvironmentVariables); err != nil {
+		return nil, err
+	}
+	// check update config
+	if err := application.UpdateConfig.Validate(); err != nil {
+		return nil, err
+	}
+	// check deployment strategy
+	if application.DeploymentStrategy == "" {
+		application.DeploymentStrategy = DeploymentStrategyRolling
+	}
+	if !application.DeploymentStrategy.IsValid() {
+		return nil, errors.New("invalid deployment strategy")
+	}
+	if application.DeploymentStrategy.UsesCandidate() && application.DeploymentMode == DeploymentModeGlobal {
+		return nil, errors.New("blue-green and canary deployment strategies are not supported for global deployment")
+	}
+	if application.DeploymentStrategy.UsesCandidate() && application.DeploymentMode.IsJob() {
+		return nil, errors.New("blue-green and canary deployment strategies are not supported for job")
+	}
+	// check job config
+	if err := application.JobConfig.Validate(application.DeploymentMode); err != nil {
+		return nil, err
+	}
+	// check git reference
+	if err := application.LatestDeployment.ValidateGitReference(); err != nil {
+		return nil, err
+	}
+	// check git credential is allowed for the repository
+	if err := application.LatestDeployment.CheckGitCredentialAccess(ctx, db); err != nil {
+		return nil, err
+	}
+	// check git polling
+	if err := application.GitPolling.Validate(application.LatestDeployment.UpstreamType); err != nil {
+		return nil, err
+	}
+	// check preview config
+	if err := application.PreviewConfig.Validate(application.LatestDeployment.UpstreamType, application.DeploymentMode); err != nil {
+		return nil, err
+	}
 	// Verify the PreferredServerHostnames
 	if len(application.PreferredServerHostnames) > 0 {
 		for _, preferredServerHostname := range application.PreferredServerHostnames {
@@ -345,6 +472,16 @@ func (application *Application) Update(ctx context.Context, db gorm.DB, _ contai
 	}
 	// check if DeploymentMode is changed
 	if applicationExistingFull.DeploymentMode != application.DeploymentMode {
+		// job has no long-running service to route the traffic to
+		if application.DeploymentMode.IsJob() {
+			ingressRules, err := FindIngressRulesByApplicationID(ctx, db, application.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(ingressRules) > 0 {
+				return nil, errors.New("delete the ingress rules of the application before changing it to job")
+			}
+		}
 		// update deployment mode
 		err = db.Model(&applicationExistingFull).Update("deployment_mode", application.DeploymentMode).Error
 		if err != nil {
@@ -399,19 +536,23 @@ func (application *Application) Update(ctx context.Context, db gorm.DB, _ contai
 		isReloadRequired = true
 	}
 	// create array of environment variables
-	var newEnvironmentVariableMap = make(map[string]string)
+	var newEnvironmentVariableMap = make(map[string]EnvironmentVariable)
 	for _, environmentVariable := range application.EnvironmentVariables {
-		newEnvironmentVariableMap[environmentVariable.Key] = environmentVariable.Value
+		newEnvironmentVariableMap[environmentVariable.Key] = environmentVariable
 	}
 	// update environment variables -- if required
 	if applicationExistingFull.EnvironmentVariables != nil {
 		for _, environmentVariable := range applicationExistingFull.EnvironmentVariables {
 			// check if environment variable is present in new environment variables
-			if _, ok := newEnvironmentVariableMap[environmentVariable.Key]; ok {
+			if newEnvironmentVariable, ok := newEnvironmentVariableMap[environmentVariable.Key]; ok {
+				// masked value of secret is submitted back, keep the existing value
+				newEnvironmentVariable = keepMaskedSecretValue(environmentVariable, newEnvironmentVariable)
 				// check if value is changed
-				if environmentVariable.Value != newEnvironmentVariableMap[environmentVariable.Key] {
+				if environmentVariable.Value != newEnvironmentVariable.Value || environmentVariable.IsSecret != newEnvironmentVariable.IsSecret || environmentVariable.SecretFileEnv != newEnvironmentVariable.SecretFileEnv {
 					// update environment variable
-					environmentVariable.Value = newEnvironmentVariableMap[environmentVariable.Key]
+					environmentVariable.Value = newEnvironmentVariable.Value
+					environmentVariable.IsSecret = newEnvironmentVariable.IsSecret
+					environmentVariable.SecretFileEnv = newEnvironmentVariable.SecretFileEnv
 					err = environmentVariable.Update(ctx, db)
 					if err != nil {
 						return nil, err
@@ -436,11 +577,13 @@ func (application *Application) Update(ctx context.Context, db gorm.DB, _ contai
 		}
 	}
 	// add new environment variables which are not present
-	for key, value := range newEnvironmentVariableMap {
+	for key, newEnvironmentVariable := range newEnvironmentVariableMap {
 		environmentVariable := EnvironmentVariable{
 			ApplicationID: application.ID,
 			Key:           key,
-			Value:         value,
+			Value:         newEnvironmentVariable.Value,
+			IsSecret:      newEnvironmentVariable.IsSecret,
+			SecretFileEnv: newEnvironmentVariable.SecretFileEnv,
 		}
 		err := environmentVariable.Create(ctx, db)
 		if err != nil {
@@ -623,6 +766,74 @@ func (application *Application) Update(ctx context.Context, db gorm.DB, _ contai
 		// reload application
 		isReloadRequired = true
 	}
+	// check for changes in auto rollback, applied from next deployment
+	if !application.AutoRollback.Equal(&applicationExistingFull.AutoRollback) {
+		err = db.Model(&applicationExistingFull).Select("auto_rollback_enabled", "auto_rollback_window_seconds").Updates(application).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	// check for changes in deployment strategy, applied from next deployment
+	if application.DeploymentStrategy != applicationExistingFull.DeploymentStrategy {
+		err = db.Model(&applicationExistingFull).Update("deployment_strategy", application.DeploymentStrategy).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	// check for changes in release commands, applied from next deployment
+	if !application.ReleaseCommands.Equal(&applicationExistingFull.ReleaseCommands) {
+		err = db.Model(&applicationExistingFull).Select("release_commands_pre_deploy", "release_commands_post_deploy",
+			"release_commands_timeout_seconds").Updates(application).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	// check for changes in job config, applied from next run
+	if !application.JobConfig.Equal(&applicationExistingFull.JobConfig) {
+		err = db.Model(&applicationExistingFull).Select("job_schedule", "job_timeout_seconds").Updates(application).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	// check for changes in retention policy, applied by the retention cronjob
+	if !application.RetentionPolicy.Equal(&applicationExistingFull.RetentionPolicy) {
+		err = db.Model(&applicationExistingFull).Select("retention_keep_last_deployments", "retention_keep_days").Updates(application).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	// check for changes in git polling, applied by the git polling cronjob
+	if !application.GitPolling.Equal(&applicationExistingFull.GitPolling) {
+		err = db.Model(&applicationExistingFull).Select("git_polling_enabled", "git_polling_interval_seconds").Updates(application).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	// check for changes in preview config, applied to the previews created afterwards
+	if !application.PreviewConfig.Equal(&applicationExistingFull.PreviewConfig) {
+		err = db.Model(&applicationExistingFull).Select("preview_enabled", "preview_base_domain", "preview_target_port", "preview_copy_secrets", "preview_run_release_commands").Updates(application).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	// nil means the overrides are not provided, so kept as it is
+	if application.PreviewEnvironmentVariables != nil {
+		err = ReplacePreviewEnvironmentVariables(ctx, db, application.ID, application.PreviewEnvironmentVariables)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// check for changes in update config
+	if !application.UpdateConfig.Equal(&applicationExistingFull.UpdateConfig) {
+		err = db.Model(&applicationExistingFull).Select("update_config_parallelism", "update_config_delay_seconds",
+			"update_config_order", "update_config_failure_action", "update_config_monitor_seconds",
+			"update_config_max_failure_ratio").Updates(application).Error
+		if err != nil {
+			return nil, err
+		}
+		// reload application
+		isReloadRequired = true
+	}
 	// update deployment -- if required
 	currentDeploymentID, err := FindCurrentDeployedDeploymentIDByApplicationId(ctx, db, application.ID)
 	if err != nil {
@@ -662,6 +873,14 @@ func (application *Application) SoftDelete(ctx context.Context, db gorm.DB, _ co
 	if len(ingressRules) > 0 {
 		return errors.New("application has ingress rules associated with it")
 	}
+	// ensure there is no preview environment, those would be left without the source application
+	previews, err := FindApplicationPreviewsByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		return err
+	}
+	if len(previews) > 0 {
+		return errors.New("application has preview environments, delete them before deleting the application")
+	}
 	// do soft delete
 	tx := db.Model(&application).Update("is_deleted", true)
 	return tx.Error
@@ -719,7 +938,30 @@ func (application *Application) IsApplicationDeleted(_ context.Context, db gorm.
 	return false, nil
 }
 
-func (application *Application) RebuildApplication(ctx context.Context, db gorm.DB) (deploymentId string, error error) {
+// RebuildApplication : create a new deployment from the current deployment, which needs to be built
+// For git upstream, the commit is checked out if commitHash is provided, otherwise the latest commit of the branch
+// Deployments pinned to a tag or commit are rebuilt from the same commit, if commitHash is not provided
+func (application *Application) RebuildApplication(ctx context.Context, db gorm.DB, commitHash string) (deploymentId string, error error) {
+	return application.rebuildApplication(ctx, db, func(deployment *Deployment) {
+		if deployment.GitReferenceType.IsPinned() && commitHash == "" {
+			return
+		}
+		deployment.CommitHash = commitHash
+		deployment.CommitMessage = ""
+	})
+}
+
+// RebuildApplicationFromTag : create a new deployment from the current deployment, for the tag
+// The commit of the tag is resolved at build time
+func (application *Application) RebuildApplicationFromTag(ctx context.Context, db gorm.DB, tag string) (deploymentId string, error error) {
+	return application.rebuildApplication(ctx, db, func(deployment *Deployment) {
+		deployment.RepositoryTag = tag
+		deployment.CommitHash = ""
+		deployment.CommitMessage = ""
+	})
+}
+
+func (application *Application) rebuildApplication(ctx context.Context, db gorm.DB, updateUpstream func(deployment *Deployment)) (deploymentId string, error error) {
 	// fetch record
 	err := application.FindById(ctx, db, application.ID)
 	if err != nil {
@@ -739,6 +981,14 @@ func (application *Application) RebuildApplication(ctx context.Context, db gorm.
 	if err != nil {
 		return "", err
 	}
+	// fetch build secrets
+	buildSecrets, err := FindBuildSecretsByDeploymentId(ctx, db, latestDeployment.ID)
+	if err != nil {
+		return "", err
+	}
+	// rebuild the image from source, even if latest deployment is a rollback
+	latestDeployment.OriginalDeploymentID = nil
+	updateUpstream(latestDeployment)
 	// add new deployment
 	err = latestDeployment.Create(ctx, db)
 	if err != nil {
@@ -755,18 +1005,110 @@ func (application *Application) RebuildApplication(ctx context.Context, db gorm.
 			return "", err
 		}
 	}
+	// update build secrets
+	for _, buildSecret := range buildSecrets {
+		buildSecret.ID = 0
+		buildSecret.DeploymentID = latestDeployment.ID
+	}
+	if len(buildSecrets) > 0 {
+		err = db.Create(&buildSecrets).Error
+		if err != nil {
+			return "", err
+		}
+	}
 	return latestDeployment.ID, nil
 }
 
+// RollbackApplication : create a new deployment which reuses the image of an older deployment
+// The new deployment is ready to be deployed, no build is required
+func (application *Application) RollbackApplication(ctx context.Context, db gorm.DB, deploymentId string) (newDeploymentId string, error error) {
+	// fetch record
+	err := application.FindById(ctx, db, application.ID)
+	if err != nil {
+		return "", err
+	}
+	// fetch the deployment to rollback to
+	targetDeployment := &Deployment{}
+	err = targetDeployment.FindById(ctx, db, deploymentId)
+	if err != nil || targetDeployment.ApplicationID != application.ID {
+		return "", errors.New("deployment not found")
+	}
+	if !targetDeployment.CanRollbackTo() {
+		return "", errors.New("deployment has never been deployed, can't rollback to " + string(targetDeployment.Status) + " deployment")
+	}
+	// tag of the image may have been moved since, redeploying it would not rollback anything
+	if targetDeployment.UpstreamType == UpstreamTypeImage && strings.Compare(targetDeployment.ImageDigest, "") == 0 && !strings.Contains(targetDeployment.DockerImage, "@") {
+		return "", errors.New("digest of the image was not recorded for the deployment, deploy the image with a specific tag or digest instead")
+	}
+	currentDeploymentId, err := FindCurrentDeployedDeploymentIDByApplicationId(ctx, db, application.ID)
+	if err == nil && currentDeploymentId == targetDeployment.ID {
+		return "", errors.New("deployment is already the current deployment")
+	}
+	// fetch build args
+	buildArgs, err := FindBuildArgsByDeploymentId(ctx, db, targetDeployment.ID)
+	if err != nil {
+		return "", err
+	}
+	// fetch build secrets
+	buildSecrets, err := FindBuildSecretsByDeploymentId(ctx, db, targetDeployment.ID)
+	if err != nil {
+		return "", err
+	}
+	// add new deployment, pointing to the deployment for which the image was built
+	originalDeploymentId := targetDeployment.ImageDeploymentID()
+	rollbackDeployment := targetDeployment
+	rollbackDeployment.OriginalDeploymentID = &originalDeploymentId
+	err = rollbackDeployment.Create(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	err = rollbackDeployment.UpdateStatus(ctx, db, DeploymentStatusDeployPending)
+	if err != nil {
+		return "", err
+	}
+	// copy build args and secrets, to keep the record same as the original deployment
+	for _, buildArg := range buildArgs {
+		buildArg.ID = 0
+		buildArg.DeploymentID = rollbackDeployment.ID
+	}
+	if len(buildArgs) > 0 {
+		err = db.Create(&buildArgs).Error
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, buildSecret := range buildSecrets {
+		buildSecret.ID = 0
+		buildSecret.DeploymentID = rollbackDeployment.ID
+	}
+	if len(buildSecrets) > 0 {
+		err = db.Create(&buildSecrets).Error
+		if err != nil {
+			return "", err
+		}
+	}
+	return rollbackDeployment.ID, nil
+}
+
 func (application *Application) RegenerateWebhookToken(ctx context.Context, db gorm.DB) error {
 	// fetch record
 	err := application.FindById(ctx, db, application.ID)
 	if err != nil {
 		return err
 	}
-	// update webhook token
+	// update webhook token and secret
+	// webhook has to be reconfigured with the new secret, so unsigned webhooks are not accepted anymore
 	application.WebhookToken = uuid.NewString()
-	tx := db.Model(&application).Update("webhook_token", application.WebhookToken)
+	application.WebhookSecret = uuid.NewString()
+	application.AllowUnsignedWebhooks = false
+	tx := db.Model(&application).Select("webhook_token", "webhook_secret", "allow_unsigned_webhooks").Updates(application)
+	return tx.Error
+}
+
+// SetAllowUnsignedWebhooks : opt in or out of accepting git webhooks without signature
+func (application *Application) SetAllowUnsignedWebhooks(ctx context.Context, db gorm.DB, allow bool) error {
+	application.AllowUnsignedWebhooks = allow
+	tx := db.Model(&application).Update("allow_unsigned_webhooks", allow)
 	return tx.Error
 }
 
@@ -798,6 +1140,53 @@ func (application *Application) MarkAsWake(ctx context.Context, db gorm.DB) erro
 	return tx.Error
 }
 
+// SetCandidate : mark the deployment as candidate, which runs next to the current version
+func (application *Application) SetCandidate(_ context.Context, db gorm.DB, deploymentID string, trafficPercent uint) error {
+	if trafficPercent > 100 {
+		return errors.New("traffic percent should be between 0 and 100")
+	}
+	err := db.Model(&application).Select("candidate_deployment_id", "candidate_traffic_percent").Updates(Application{
+		CandidateDeploymentID:   &deploymentID,
+		CandidateTrafficPercent: trafficPercent,
+	}).Error
+	if err != nil {
+		return err
+	}
+	application.CandidateDeploymentID = &deploymentID
+	application.CandidateTrafficPercent = trafficPercent
+	return nil
+}
+
+// UpdateCandidateTrafficPercent : update the share of the traffic routed to the candidate
+func (application *Application) UpdateCandidateTrafficPercent(_ context.Context, db gorm.DB, trafficPercent uint) error {
+	if !application.HasCandidate() {
+		return errors.New("application has no candidate deployment")
+	}
+	if trafficPercent > 100 {
+		return errors.New("traffic percent should be between 0 and 100")
+	}
+	err := db.Model(&application).Update("candidate_traffic_percent", trafficPercent).Error
+	if err != nil {
+		return err
+	}
+	application.CandidateTrafficPercent = trafficPercent
+	return nil
+}
+
+// ClearCandidate : remove the candidate reference after promotion or abort
+func (application *Application) ClearCandidate(_ context.Context, db gorm.DB) error {
+	err := db.Model(&application).Select("candidate_deployment_id", "candidate_traffic_percent").Updates(map[string]interface{}{
+		"candidate_deployment_id":   nil,
+		"candidate_traffic_percent": 0,
+	}).Error
+	if err != nil {
+		return err
+	}
+	application.CandidateDeploymentID = nil
+	application.CandidateTrafficPercent = 0
+	return nil
+}
+
 func (application *Application) UpdateGroup(ctx context.Context, db gorm.DB, groupId *string) error {
 	err := application.FindById(ctx, db, application.ID)
 	if err != nil {
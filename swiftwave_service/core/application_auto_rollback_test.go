@@ -0,0 +1,20 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationAutoRollbackWindow(t *testing.T) {
+	assert.Equal(t, DefaultAutoRollbackWindowSeconds*time.Second, (&ApplicationAutoRollback{Enabled: true}).Window())
+	assert.Equal(t, 45*time.Second, (&ApplicationAutoRollback{Enabled: true, WindowSeconds: 45}).Window())
+}
+
+func TestApplicationAutoRollbackEqual(t *testing.T) {
+	config := ApplicationAutoRollback{Enabled: true, WindowSeconds: 60}
+	assert.True(t, config.Equal(&ApplicationAutoRollback{Enabled: true, WindowSeconds: 60}))
+	assert.False(t, config.Equal(&ApplicationAutoRollback{Enabled: false, WindowSeconds: 60}))
+	assert.False(t, config.Equal(&ApplicationAutoRollback{Enabled: true, WindowSeconds: 90}))
+}
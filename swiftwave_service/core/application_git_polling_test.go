@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationGitPollingValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		polling      ApplicationGitPolling
+		upstreamType UpstreamType
+		valid        bool
+	}{
+		{"disabled for image upstream", ApplicationGitPolling{Enabled: false, IntervalSeconds: 10}, UpstreamTypeImage, true},
+		{"enabled for git upstream", ApplicationGitPolling{Enabled: true, IntervalSeconds: 300}, UpstreamTypeGit, true},
+		{"minimum interval", ApplicationGitPolling{Enabled: true, IntervalSeconds: MinGitPollingIntervalSeconds}, UpstreamTypeGit, true},
+		{"interval too short", ApplicationGitPolling{Enabled: true, IntervalSeconds: MinGitPollingIntervalSeconds - 1}, UpstreamTypeGit, false},
+		{"interval not set", ApplicationGitPolling{Enabled: true}, UpstreamTypeGit, false},
+		{"enabled for image upstream", ApplicationGitPolling{Enabled: true, IntervalSeconds: 300}, UpstreamTypeImage, false},
+		{"enabled for source code upstream", ApplicationGitPolling{Enabled: true, IntervalSeconds: 300}, UpstreamTypeSourceCode, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.polling.Validate(test.upstreamType)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestApplicationGitPollingInterval(t *testing.T) {
+	assert.Equal(t, DefaultGitPollingIntervalSeconds*time.Second, (&ApplicationGitPolling{Enabled: true}).Interval())
+	assert.Equal(t, 90*time.Second, (&ApplicationGitPolling{Enabled: true, IntervalSeconds: 90}).Interval())
+	assert.True(t, (&ApplicationGitPolling{Enabled: true, IntervalSeconds: 90}).Equal(&ApplicationGitPolling{Enabled: true, IntervalSeconds: 90}))
+	assert.False(t, (&ApplicationGitPolling{Enabled: true, IntervalSeconds: 90}).Equal(&ApplicationGitPolling{Enabled: true, IntervalSeconds: 120}))
+}
+
+func TestFindGitPollingApplications(t *testing.T) {
+	db := newTestDB(t, &Application{})
+	polling := ApplicationGitPolling{Enabled: true, IntervalSeconds: 300}
+	for _, application := range []Application{
+		{ID: "polled", Name: "polled", GitPolling: polling},
+		{ID: "not-polled", Name: "not-polled"},
+		{ID: "deleted", Name: "deleted", GitPolling: polling, IsDeleted: true},
+		{ID: "sleeping", Name: "sleeping", GitPolling: polling, IsSleeping: true},
+	} {
+		if err := db.Create(&application).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	applications, err := FindGitPollingApplications(context.Background(), db)
+	assert.NoError(t, err)
+	if assert.Len(t, applications, 1) {
+		assert.Equal(t, "polled", applications[0].ID)
+	}
+}
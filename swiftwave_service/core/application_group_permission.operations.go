@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+)
+
+// This file contains the operations for the ApplicationGroupPermission model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+// FindApplicationGroupPermissionsByUserID : find all application group permissions of user
+func FindApplicationGroupPermissionsByUserID(_ context.Context, db gorm.DB, userID uint) ([]*ApplicationGroupPermission, error) {
+	var permissions []*ApplicationGroupPermission
+	err := db.Where("user_id = ?", userID).Find(&permissions).Error
+	return permissions, err
+}
+
+func (permission *ApplicationGroupPermission) FindById(_ context.Context, db gorm.DB, id uint) error {
+	return db.Where("id = ?", id).First(permission).Error
+}
+
+// Create : create or update the permission of user on the application group
+func (permission *ApplicationGroupPermission) Create(ctx context.Context, db gorm.DB) error {
+	if !permission.Access.IsValid() {
+		return errors.New("invalid access level")
+	}
+	// verify user
+	user, err := FindUserByID(ctx, db, permission.UserID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.Role == AdministratorRole {
+		return errors.New("admin user has access to all application groups")
+	}
+	// verify application group
+	applicationGroup := &ApplicationGroup{}
+	if err := applicationGroup.FindById(ctx, db, permission.ApplicationGroupID); err != nil {
+		return errors.New("application group not found")
+	}
+	// if permission already exists, update the access level
+	existingPermission := &ApplicationGroupPermission{}
+	err = db.Where("user_id = ? AND application_group_id = ?", permission.UserID, permission.ApplicationGroupID).First(existingPermission).Error
+	if err == nil {
+		permission.ID = existingPermission.ID
+		return db.Model(existingPermission).Update("access", permission.Access).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return db.Create(permission).Error
+}
+
+func (permission *ApplicationGroupPermission) Delete(_ context.Context, db gorm.DB) error {
+	return db.Delete(permission).Error
+}
+
+// IsRestrictedToApplicationGroups : check if user can only access the applications of granted application groups
+func (user *User) IsRestrictedToApplicationGroups(ctx context.Context, db gorm.DB) (bool, error) {
+	if user.Role == AdministratorRole {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&ApplicationGroupPermission{}).Where("user_id = ?", user.ID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CanAccessApplicationGroup : check if user has the required access on the application group
+// applicationGroupID can be nil for the applications which are not part of any group
+// User without any application group permission is not restricted and only limited by their role
+func (user *User) CanAccessApplicationGroup(ctx context.Context, db gorm.DB, applicationGroupID *string, access AccessLevel) (bool, error) {
+	if user.Role == AdministratorRole {
+		return true, nil
+	}
+	if access == WriteAccess && !user.HasRole(ManagerRole) {
+		return false, nil
+	}
+	permissions, err := FindApplicationGroupPermissionsByUserID(ctx, db, user.ID)
+	if err != nil {
+		return false, err
+	}
+	if len(permissions) == 0 {
+		return true, nil
+	}
+	if applicationGroupID == nil {
+		return false, nil
+	}
+	for _, permission := range permissions {
+		if permission.ApplicationGroupID == *applicationGroupID && permission.Access.Allows(access) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CanAccessApplication : check if user has the required access on the application
+func (user *User) CanAccessApplication(ctx context.Context, db gorm.DB, applicationID string, access AccessLevel) (bool, error) {
+	application := &Application{}
+	err := db.Select("id", "application_group_id").Where("id = ?", applicationID).First(application).Error
+	if err != nil {
+		return false, err
+	}
+	return user.CanAccessApplicationGroup(ctx, db, application.ApplicationGroupID, access)
+}
+
+// FindAccessibleApplicationIDs : find the ids of the applications, which are part of the application groups granted to user
+func (user *User) FindAccessibleApplicationIDs(_ context.Context, db gorm.DB) ([]string, error) {
+	var applicationIDs []string
+	grantedApplicationGroupIDs := db.Model(&ApplicationGroupPermission{}).Select("application_group_id").Where("user_id = ?", user.ID)
+	err := db.Model(&Application{}).Where("application_group_id IN (?)", grantedApplicationGroupIDs).Pluck("id", &applicationIDs).Error
+	return applicationIDs, err
+}
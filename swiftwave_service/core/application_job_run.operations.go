@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// This file contains the operations for the ApplicationJobRun model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+// maxJobRunLogsSize : logs of a run are truncated to keep the last part, if exceeds this size
+const maxJobRunLogsSize = 1024 * 1024
+
+func (jobRun *ApplicationJobRun) FindById(_ context.Context, db gorm.DB, id uint) error {
+	tx := db.Where("id = ?", id).First(&jobRun)
+	return tx.Error
+}
+
+// Create : create a pending run with the current deployment of the job application
+func (jobRun *ApplicationJobRun) Create(ctx context.Context, db gorm.DB) error {
+	application := &Application{}
+	err := application.FindById(ctx, db, jobRun.ApplicationID)
+	if err != nil {
+		return err
+	}
+	if !application.DeploymentMode.IsJob() {
+		return errors.New("application is not a job")
+	}
+	deploymentID, err := FindCurrentDeployedDeploymentIDByApplicationId(ctx, db, jobRun.ApplicationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("job has no deployed version to run")
+		}
+		return err
+	}
+	jobRun.ID = 0
+	jobRun.DeploymentID = deploymentID
+	jobRun.Status = JobRunStatusPending
+	jobRun.ExitCode = 0
+	jobRun.Logs = ""
+	jobRun.CreatedAt = time.Now()
+	jobRun.StartedAt = nil
+	jobRun.CompletedAt = nil
+	tx := db.Create(&jobRun)
+	return tx.Error
+}
+
+func (jobRun *ApplicationJobRun) MarkAsRunning(_ context.Context, db gorm.DB) error {
+	now := time.Now()
+	tx := db.Model(&jobRun).Select("status", "started_at").Updates(ApplicationJobRun{
+		Status:    JobRunStatusRunning,
+		StartedAt: &now,
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	jobRun.Status = JobRunStatusRunning
+	jobRun.StartedAt = &now
+	return nil
+}
+
+// MarkAsCompleted : store the result of the run, status is decided by the exit code
+func (jobRun *ApplicationJobRun) MarkAsCompleted(_ context.Context, db gorm.DB, exitCode int, logs string) error {
+	if len(logs) > maxJobRunLogsSize {
+		logs = "[Truncated]\n" + strings.ToValidUTF8(logs[len(logs)-maxJobRunLogsSize:], "")
+	}
+	status := JobRunStatusSucceeded
+	if exitCode != 0 {
+		status = JobRunStatusFailed
+	}
+	now := time.Now()
+	tx := db.Model(&jobRun).Select("status", "exit_code", "logs", "completed_at").Updates(ApplicationJobRun{
+		Status:      status,
+		ExitCode:    exitCode,
+		Logs:        logs,
+		CompletedAt: &now,
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	jobRun.Status = status
+	jobRun.ExitCode = exitCode
+	jobRun.Logs = logs
+	jobRun.CompletedAt = &now
+	return nil
+}
+
+// FindApplicationJobRunsByApplicationId : runs of the application, latest first
+// This will not send the logs of the runs
+func FindApplicationJobRunsByApplicationId(_ context.Context, db gorm.DB, applicationId string) ([]*ApplicationJobRun, error) {
+	var jobRuns = make([]*ApplicationJobRun, 0)
+	tx := db.Select("id", "application_id", "deployment_id", "trigger", "status", "exit_code", "created_at", "started_at", "completed_at").
+		Where("application_id = ?", applicationId).Order("id desc").Find(&jobRuns)
+	return jobRuns, tx.Error
+}
+
+// FetchApplicationJobRunLogsByID : logs of the run
+func FetchApplicationJobRunLogsByID(_ context.Context, db gorm.DB, id uint) (string, error) {
+	var jobRun ApplicationJobRun
+	tx := db.Select("logs").Where("id = ?", id).First(&jobRun)
+	return jobRun.Logs, tx.Error
+}
+
+// IsApplicationJobRunActive : check if any run of the application is pending or running
+func IsApplicationJobRunActive(_ context.Context, db gorm.DB, applicationId string) (bool, error) {
+	var count int64
+	tx := db.Model(&ApplicationJobRun{}).Where("application_id = ? AND status IN ?", applicationId, []JobRunStatus{JobRunStatusPending, JobRunStatusRunning}).Count(&count)
+	return count > 0, tx.Error
+}
+
+// IsScheduledJobRunCreatedSince : check if the schedule has already triggered a run of the application since the time
+func IsScheduledJobRunCreatedSince(_ context.Context, db gorm.DB, applicationId string, since time.Time) (bool, error) {
+	var count int64
+	tx := db.Model(&ApplicationJobRun{}).Where("application_id = ? AND trigger = ? AND created_at >= ?", applicationId, JobRunTriggerSchedule, since).Count(&count)
+	return count > 0, tx.Error
+}
+
+// FindCronJobApplications : cron job applications those are not deleted or sleeping
+func FindCronJobApplications(_ context.Context, db gorm.DB) ([]*Application, error) {
+	var applications = make([]*Application, 0)
+	tx := db.Where("deployment_mode = ? AND is_deleted = ? AND is_sleeping = ?", DeploymentModeCronJob, false, false).Find(&applications)
+	return applications, tx.Error
+}
+
+// MarkStaleApplicationJobRunsAsFailed : mark the runs of the application, which are not completed and created before the time, as failed
+// Run can be left incomplete if the service is restarted while the job is running
+func MarkStaleApplicationJobRunsAsFailed(_ context.Context, db gorm.DB, applicationId string, createdBefore time.Time) error {
+	now := time.Now()
+	tx := db.Model(&ApplicationJobRun{}).Where("application_id = ? AND status IN ? AND created_at < ?", applicationId, []JobRunStatus{JobRunStatusPending, JobRunStatusRunning}, createdBefore).
+		Select("status", "exit_code", "completed_at").Updates(ApplicationJobRun{
+		Status:      JobRunStatusFailed,
+		ExitCode:    -1,
+		CompletedAt: &now,
+	})
+	return tx.Error
+}
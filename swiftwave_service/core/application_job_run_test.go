@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateApplicationJobRun(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &Application{}, &Deployment{}, &ApplicationJobRun{})
+	service := Application{ID: "service", Name: "service", DeploymentMode: DeploymentModeReplicated}
+	notDeployed := Application{ID: "not-deployed", Name: "not-deployed", DeploymentMode: DeploymentModeCronJob}
+	job := Application{ID: "job", Name: "job", DeploymentMode: DeploymentModeCronJob}
+	assert.NoError(t, db.Create(&[]Application{service, notDeployed, job}).Error)
+	assert.NoError(t, db.Create(&[]Deployment{
+		{ID: "service-deployment", ApplicationID: service.ID, Status: DeploymentStatusDeployed},
+		{ID: "pending-deployment", ApplicationID: notDeployed.ID, Status: DeploymentStatusPending},
+		{ID: "job-deployment", ApplicationID: job.ID, Status: DeploymentStatusDeployed},
+	}).Error)
+
+	assert.Error(t, (&ApplicationJobRun{ApplicationID: service.ID, Trigger: JobRunTriggerManual}).Create(ctx, db))
+	assert.Error(t, (&ApplicationJobRun{ApplicationID: notDeployed.ID, Trigger: JobRunTriggerManual}).Create(ctx, db))
+
+	jobRun := &ApplicationJobRun{ApplicationID: job.ID, Trigger: JobRunTriggerSchedule, Status: JobRunStatusSucceeded, Logs: "stale"}
+	if !assert.NoError(t, jobRun.Create(ctx, db)) {
+		return
+	}
+	assert.Equal(t, "job-deployment", jobRun.DeploymentID)
+	assert.Equal(t, JobRunStatusPending, jobRun.Status)
+	assert.Empty(t, jobRun.Logs)
+}
+
+func TestApplicationJobRunLifecycle(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &ApplicationJobRun{})
+	jobRun := &ApplicationJobRun{ApplicationID: "job", Trigger: JobRunTriggerSchedule, Status: JobRunStatusPending, CreatedAt: time.Now()}
+	assert.NoError(t, db.Create(jobRun).Error)
+
+	isActive, err := IsApplicationJobRunActive(ctx, db, "job")
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+
+	assert.NoError(t, jobRun.MarkAsRunning(ctx, db))
+	isActive, err = IsApplicationJobRunActive(ctx, db, "job")
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+
+	assert.NoError(t, jobRun.MarkAsCompleted(ctx, db, 2, "failed\n"))
+	assert.Equal(t, JobRunStatusFailed, jobRun.Status)
+	isActive, err = IsApplicationJobRunActive(ctx, db, "job")
+	assert.NoError(t, err)
+	assert.False(t, isActive)
+
+	logs, err := FetchApplicationJobRunLogsByID(ctx, db, jobRun.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "failed\n", logs)
+
+	succeeded := &ApplicationJobRun{ApplicationID: "job", Trigger: JobRunTriggerManual, Status: JobRunStatusRunning, CreatedAt: time.Now()}
+	assert.NoError(t, db.Create(succeeded).Error)
+	assert.NoError(t, succeeded.MarkAsCompleted(ctx, db, 0, strings.Repeat("a", maxJobRunLogsSize+10)))
+	assert.Equal(t, JobRunStatusSucceeded, succeeded.Status)
+	assert.True(t, strings.HasPrefix(succeeded.Logs, "[Truncated]\n"))
+	assert.Len(t, succeeded.Logs, len("[Truncated]\n")+maxJobRunLogsSize)
+}
+
+func TestScheduledApplicationJobRuns(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &ApplicationJobRun{})
+	minute := time.Now().Truncate(time.Minute)
+	assert.NoError(t, db.Create(&[]ApplicationJobRun{
+		{ApplicationID: "job", Trigger: JobRunTriggerSchedule, Status: JobRunStatusRunning, CreatedAt: minute.Add(-2 * time.Hour)},
+		{ApplicationID: "job", Trigger: JobRunTriggerManual, Status: JobRunStatusPending, CreatedAt: minute.Add(time.Second)},
+		{ApplicationID: "other", Trigger: JobRunTriggerSchedule, Status: JobRunStatusRunning, CreatedAt: minute.Add(time.Second)},
+	}).Error)
+
+	// manual runs don't count for the schedule
+	isTriggered, err := IsScheduledJobRunCreatedSince(ctx, db, "job", minute)
+	assert.NoError(t, err)
+	assert.False(t, isTriggered)
+	isTriggered, err = IsScheduledJobRunCreatedSince(ctx, db, "other", minute)
+	assert.NoError(t, err)
+	assert.True(t, isTriggered)
+
+	// only the runs created before the time are marked as failed
+	assert.NoError(t, MarkStaleApplicationJobRunsAsFailed(ctx, db, "job", minute.Add(-time.Hour)))
+	jobRuns, err := FindApplicationJobRunsByApplicationId(ctx, db, "job")
+	if assert.NoError(t, err) && assert.Len(t, jobRuns, 2) {
+		assert.Equal(t, JobRunStatusPending, jobRuns[0].Status)
+		assert.Equal(t, JobRunStatusFailed, jobRuns[1].Status)
+		assert.Equal(t, -1, jobRuns[1].ExitCode)
+		assert.NotNil(t, jobRuns[1].CompletedAt)
+	}
+}
+
+func TestFindCronJobApplications(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &Application{})
+	assert.NoError(t, db.Create(&[]Application{
+		{ID: "cron", Name: "cron", DeploymentMode: DeploymentModeCronJob},
+		{ID: "sleeping", Name: "sleeping", DeploymentMode: DeploymentModeCronJob, IsSleeping: true},
+		{ID: "deleted", Name: "deleted", DeploymentMode: DeploymentModeCronJob, IsDeleted: true},
+		{ID: "run-once", Name: "run-once", DeploymentMode: DeploymentModeRunOnceJob},
+		{ID: "service", Name: "service", DeploymentMode: DeploymentModeReplicated},
+	}).Error)
+	applications, err := FindCronJobApplications(ctx, db)
+	if assert.NoError(t, err) && assert.Len(t, applications, 1) {
+		assert.Equal(t, "cron", applications[0].ID)
+	}
+}
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	containermanger "github.com/swiftwave-org/swiftwave/container_manager"
+	"gorm.io/gorm"
+)
+
+// This file contains the operations for the ApplicationPreview model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+func FindApplicationPreviewsByApplicationId(_ context.Context, db gorm.DB, applicationId string) ([]*ApplicationPreview, error) {
+	var previews = make([]*ApplicationPreview, 0)
+	tx := db.Where("application_id = ?", applicationId).Order("pull_request_number").Find(&previews)
+	return previews, tx.Error
+}
+
+func FindApplicationPreviewsByStatus(_ context.Context, db gorm.DB, status ApplicationPreviewStatus) ([]*ApplicationPreview, error) {
+	var previews = make([]*ApplicationPreview, 0)
+	tx := db.Where("status = ?", status).Find(&previews)
+	return previews, tx.Error
+}
+
+func (preview *ApplicationPreview) FindById(_ context.Context, db gorm.DB, id uint) error {
+	tx := db.Where("id = ?", id).First(&preview)
+	return tx.Error
+}
+
+func (preview *ApplicationPreview) FindByPullRequest(_ context.Context, db gorm.DB, applicationId string, pullRequestNumber uint) error {
+	tx := db.Where("application_id = ? AND pull_request_number = ?", applicationId, pullRequestNumber).First(&preview)
+	return tx.Error
+}
+
+// CreatePreview : create the preview environment of the pull request, by cloning the application
+// The preview application is built from the pull request branch, and exposed over HTTP at the preview domain
+// Persistent volumes are not attached, so that the preview can't modify the data of the application
+// Secret environment variables and release commands are copied only if opted in the preview config
+func (application *Application) CreatePreview(ctx context.Context, db gorm.DB, dockerManager containermanger.Manager, pullRequestNumber uint, branch string, commitHash string) (*ApplicationPreview, error) {
+	err := application.FindById(ctx, db, application.ID)
+	if err != nil {
+		return nil, err
+	}
+	if application.IsDeleted {
+		return nil, errors.New("application is deleted")
+	}
+	if !application.PreviewConfig.Enabled {
+		return nil, errors.New("preview environments are not enabled for the application")
+	}
+	// clone from the current deployment
+	deployment, err := FindCurrentDeployedDeploymentByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		deployment, err = FindLatestDeploymentByApplicationId(ctx, db, application.ID)
+		if err != nil {
+			return nil, errors.New("failed to fetch latest deployment")
+		}
+	}
+	if deployment.UpstreamType != UpstreamTypeGit {
+		return nil, errors.New("preview environments are supported only for git upstream")
+	}
+	buildArgs, err := FindBuildArgsByDeploymentId(ctx, db, deployment.ID)
+	if err != nil {
+		return nil, err
+	}
+	buildSecrets, err := FindBuildSecretsByDeploymentId(ctx, db, deployment.ID)
+	if err != nil {
+		return nil, err
+	}
+	environmentVariables, err := FindEnvironmentVariablesByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := FindPreviewEnvironmentVariablesByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		return nil, err
+	}
+	configMounts, err := FindConfigMountsByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		return nil, err
+	}
+	domainName := application.PreviewConfig.DomainName(pullRequestNumber)
+	// environment variables with the overrides applied
+	placeholderReplacer := strings.NewReplacer(
+		"{{PR_NUMBER}}", strconv.Itoa(int(pullRequestNumber)),
+		"{{PR_BRANCH}}", branch,
+		"{{PREVIEW_DOMAIN}}", domainName,
+	)
+	previewEnvironmentVariables := application.PreviewConfig.environmentVariables(environmentVariables, overrides, placeholderReplacer)
+	previewConfigMounts := make([]ConfigMount, 0)
+	for _, configMount := range configMounts {
+		previewConfigMounts = append(previewConfigMounts, ConfigMount{
+			Content:      configMount.Content,
+			MountingPath: configMount.MountingPath,
+			Uid:          configMount.Uid,
+			Gid:          configMount.Gid,
+			FileMode:     configMount.FileMode,
+		})
+	}
+	previewBuildArgs := make([]BuildArg, 0)
+	for _, buildArg := range buildArgs {
+		previewBuildArgs = append(previewBuildArgs, BuildArg{
+			Key:   buildArg.Key,
+			Value: buildArg.Value,
+		})
+	}
+	previewBuildSecrets := make([]BuildSecret, 0)
+	for _, buildSecret := range buildSecrets {
+		previewBuildSecrets = append(previewBuildSecrets, BuildSecret{
+			Key: buildSecret.Key,
+		})
+	}
+	// create the preview application
+	previewApplication := Application{
+		Name:                     fmt.Sprintf("%s-pr-%d", application.Name, pullRequestNumber),
+		ApplicationGroupID:       application.ApplicationGroupID,
+		EnvironmentVariables:     previewEnvironmentVariables,
+		ConfigMounts:             previewConfigMounts,
+		DeploymentMode:           DeploymentModeReplicated,
+		Replicas:                 1,
+		Hostname:                 application.Hostname,
+		Command:                  application.Command,
+		Capabilities:             application.Capabilities,
+		Sysctls:                  application.Sysctls,
+		ResourceLimit:            application.ResourceLimit,
+		ReservedResource:         application.ReservedResource,
+		PreferredServerHostnames: application.PreferredServerHostnames,
+		CustomHealthCheck:        application.CustomHealthCheck,
+		AutoRollback:             application.AutoRollback,
+		UpdateConfig:             application.UpdateConfig,
+		DeploymentStrategy:       DeploymentStrategyRolling,
+		ReleaseCommands:          application.PreviewConfig.releaseCommands(application.ReleaseCommands),
+		RetentionPolicy:          application.RetentionPolicy,
+		LatestDeployment: Deployment{
+			UpstreamType:     UpstreamTypeGit,
+			GitCredentialID:  deployment.GitCredentialID,
+			GitType:          deployment.GitType,
+			GitProvider:      deployment.GitProvider,
+			GitEndpoint:      deployment.GitEndpoint,
+			GitSshUser:       deployment.GitSshUser,
+			RepositoryOwner:  deployment.RepositoryOwner,
+			RepositoryName:   deployment.RepositoryName,
+			RepositoryBranch: branch,
+			GitReferenceType: GitReferenceBranch,
+			CommitHash:       commitHash,
+			CodePath:         deployment.CodePath,
+			GitCloneConfig:   deployment.GitCloneConfig,
+			Dockerfile:       deployment.Dockerfile,
+			DockerfileTarget: deployment.DockerfileTarget,
+			BuildArgs:        previewBuildArgs,
+			BuildSecrets:     previewBuildSecrets,
+		},
+	}
+	err = previewApplication.Create(ctx, db, dockerManager, "")
+	if err != nil {
+		return nil, err
+	}
+	// domain is shared, if it already exists
+	domain := Domain{}
+	err = db.Where("name = ?", domainName).First(&domain).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		domain = Domain{
+			Name:      domainName,
+			SSLStatus: DomainSSLStatusNone,
+		}
+		err = domain.Create(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ingressRule := IngressRule{
+		DomainID:      &domain.ID,
+		Protocol:      HTTPProtocol,
+		Port:          80,
+		TargetPort:    application.PreviewConfig.TargetPort,
+		TargetType:    ApplicationIngressRule,
+		ApplicationID: &previewApplication.ID,
+		Authentication: IngressRuleAuthentication{
+			AuthType: IngressRuleNoAuthentication,
+		},
+		Status: IngressRuleStatusPending,
+	}
+	err = ingressRule.Create(ctx, db, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expose preview at %s > %s", domainName, err.Error())
+	}
+	preview := ApplicationPreview{
+		ApplicationID:        application.ID,
+		PullRequestNumber:    pullRequestNumber,
+		PreviewApplicationID: previewApplication.ID,
+		Branch:               branch,
+		CommitHash:           commitHash,
+		Domain:               domainName,
+		DomainID:             &domain.ID,
+		IngressRuleID:        &ingressRule.ID,
+		Status:               ApplicationPreviewStatusActive,
+	}
+	tx := db.Create(&preview)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &preview, nil
+}
+
+// UpdateCommit : record the commit of the pull request, which is being built for the preview
+func (preview *ApplicationPreview) UpdateCommit(_ context.Context, db gorm.DB, branch string, commitHash string) error {
+	preview.Branch = branch
+	preview.CommitHash = commitHash
+	tx := db.Model(&preview).Select("branch", "commit_hash").Updates(preview)
+	return tx.Error
+}
+
+// MarkAsDeleting : start the tear down of the preview, the ingress rule is marked for deletion
+// Rest of the resources are removed by the cleanup cronjob, once the ingress rule is deleted
+func (preview *ApplicationPreview) MarkAsDeleting(ctx context.Context, db gorm.DB) error {
+	if preview.IngressRuleID != nil {
+		ingressRule := &IngressRule{}
+		err := ingressRule.FindById(ctx, db, *preview.IngressRuleID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			err = ingressRule.Delete(ctx, db, false)
+			if err != nil && !errors.Is(err, IngressRuleDeletingError) {
+				return err
+			}
+		}
+	}
+	preview.Status = ApplicationPreviewStatusDeleting
+	tx := db.Model(&preview).Update("status", ApplicationPreviewStatusDeleting)
+	return tx.Error
+}
+
+func (preview *ApplicationPreview) Delete(_ context.Context, db gorm.DB) error {
+	tx := db.Delete(&preview)
+	return tx.Error
+}
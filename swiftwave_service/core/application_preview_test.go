@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationPreviewConfigEnvironmentVariables(t *testing.T) {
+	environmentVariables := []*EnvironmentVariable{
+		{Key: "PORT", Value: "3000"},
+		{Key: "DATABASE_URL", Value: "postgres://production", IsSecret: true},
+		{Key: "API_KEY", Value: "production-key", IsSecret: true},
+	}
+	overrides := []*PreviewEnvironmentVariable{
+		{Key: "DATABASE_URL", Value: "postgres://preview-{{PR_NUMBER}}"},
+		{Key: "BASE_URL", Value: "https://{{PREVIEW_DOMAIN}}"},
+	}
+	placeholderReplacer := strings.NewReplacer("{{PR_NUMBER}}", "7", "{{PREVIEW_DOMAIN}}", "pr-7.preview.example.com")
+	valueOf := func(variables []EnvironmentVariable) map[string]string {
+		values := make(map[string]string)
+		for _, variable := range variables {
+			values[variable.Key] = variable.Value
+		}
+		return values
+	}
+
+	t.Run("secrets are not copied by default", func(t *testing.T) {
+		config := ApplicationPreviewConfig{Enabled: true}
+		variables := config.environmentVariables(environmentVariables, overrides, placeholderReplacer)
+		assert.Equal(t, map[string]string{
+			"PORT":         "3000",
+			"DATABASE_URL": "postgres://preview-7",
+			"BASE_URL":     "https://pr-7.preview.example.com",
+		}, valueOf(variables))
+		// override of the skipped secret doesn't leak as plain environment variable
+		for _, variable := range variables {
+			assert.Equal(t, variable.Key == "DATABASE_URL", variable.IsSecret, variable.Key)
+		}
+	})
+
+	t.Run("secret override of plain environment variable", func(t *testing.T) {
+		config := ApplicationPreviewConfig{Enabled: true}
+		variables := config.environmentVariables(environmentVariables, []*PreviewEnvironmentVariable{
+			{Key: "PORT", Value: "4000", IsSecret: true},
+			{Key: "TOKEN", Value: "preview-token", IsSecret: true},
+		}, placeholderReplacer)
+		for _, variable := range variables {
+			assert.True(t, variable.IsSecret, variable.Key)
+		}
+	})
+
+	t.Run("secrets are copied when opted in", func(t *testing.T) {
+		config := ApplicationPreviewConfig{Enabled: true, CopySecrets: true}
+		variables := config.environmentVariables(environmentVariables, overrides, placeholderReplacer)
+		assert.Equal(t, map[string]string{
+			"PORT":         "3000",
+			"DATABASE_URL": "postgres://preview-7",
+			"API_KEY":      "production-key",
+			"BASE_URL":     "https://pr-7.preview.example.com",
+		}, valueOf(variables))
+		for _, variable := range variables {
+			if variable.Key == "DATABASE_URL" {
+				assert.True(t, variable.IsSecret)
+			}
+		}
+	})
+}
+
+func TestApplicationPreviewConfigReleaseCommands(t *testing.T) {
+	releaseCommands := ApplicationReleaseCommands{
+		PreDeploy:      "npm run migrate",
+		PostDeploy:     "npm run notify",
+		TimeoutSeconds: 120,
+	}
+	config := ApplicationPreviewConfig{Enabled: true}
+	assert.Equal(t, ApplicationReleaseCommands{}, config.releaseCommands(releaseCommands))
+	config.RunReleaseCommands = true
+	assert.Equal(t, releaseCommands, config.releaseCommands(releaseCommands))
+}
+
+func TestReplacePreviewEnvironmentVariables(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &EnvironmentVariable{}, &PreviewEnvironmentVariable{})
+	if err := db.Create(&[]EnvironmentVariable{
+		{ApplicationID: "app", Key: "PORT", Value: "3000"},
+		{ApplicationID: "app", Key: "DATABASE_URL", Value: "postgres://production", IsSecret: true},
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+	valueOf := func() map[string]PreviewEnvironmentVariable {
+		records, err := FindPreviewEnvironmentVariablesByApplicationId(ctx, db, "app")
+		assert.NoError(t, err)
+		values := make(map[string]PreviewEnvironmentVariable)
+		for _, record := range records {
+			values[record.Key] = *record
+		}
+		return values
+	}
+
+	err := ReplacePreviewEnvironmentVariables(ctx, db, "app", []PreviewEnvironmentVariable{
+		{Key: "PORT", Value: "4000"},
+		{Key: "DATABASE_URL", Value: "postgres://preview"},
+		{Key: "TOKEN", Value: "preview-token", IsSecret: true},
+	})
+	assert.NoError(t, err)
+	values := valueOf()
+	assert.False(t, values["PORT"].IsSecret)
+	assert.True(t, values["DATABASE_URL"].IsSecret, "override of secret is secret")
+	assert.True(t, values["TOKEN"].IsSecret)
+
+	// masked values are submitted back, existing secrets are kept
+	err = ReplacePreviewEnvironmentVariables(ctx, db, "app", []PreviewEnvironmentVariable{
+		{Key: "PORT", Value: SecretEnvironmentVariableMask},
+		{Key: "DATABASE_URL", Value: SecretEnvironmentVariableMask},
+		{Key: "TOKEN", Value: SecretEnvironmentVariableMask},
+	})
+	assert.NoError(t, err)
+	values = valueOf()
+	assert.Equal(t, SecretEnvironmentVariableMask, values["PORT"].Value)
+	assert.Equal(t, "postgres://preview", values["DATABASE_URL"].Value)
+	assert.Equal(t, "preview-token", values["TOKEN"].Value)
+	assert.True(t, values["TOKEN"].IsSecret, "secret stays secret with masked value")
+
+	err = ReplacePreviewEnvironmentVariables(ctx, db, "app", []PreviewEnvironmentVariable{
+		{Key: "../TOKEN", Value: "preview-token", IsSecret: true},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// This file contains the operations for the AuditLog model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+// AuditLogFilter : filter for fetching audit logs, empty fields are ignored
+type AuditLogFilter struct {
+	Username string
+	Source   AuditLogSource
+	Action   string
+	TargetID string
+	Outcome  AuditLogOutcome
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
+
+// redactedAuditLogValue : replacement of the sensitive values in the arguments
+const redactedAuditLogValue = "[REDACTED]"
+
+// sensitiveAuditLogKeys : argument keys containing any of these words will be redacted
+var sensitiveAuditLogKeys = []string{"password", "secret", "token", "privatekey", "private_key", "totp", "value"}
+
+// SanitizeAuditLogArguments : convert the arguments to json after redacting the sensitive values
+func SanitizeAuditLogArguments(arguments interface{}) string {
+	if arguments == nil {
+		return "{}"
+	}
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		return "{}"
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return "{}"
+	}
+	sanitized, err := json.Marshal(redactAuditLogArguments(decoded))
+	if err != nil {
+		return "{}"
+	}
+	return string(sanitized)
+}
+
+func redactAuditLogArguments(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveAuditLogKey(key) {
+				v[key] = redactedAuditLogValue
+			} else {
+				v[key] = redactAuditLogArguments(item)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactAuditLogArguments(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSensitiveAuditLogKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitiveKey := range sensitiveAuditLogKeys {
+		if strings.Contains(key, sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// Create : save the audit log
+func (auditLog *AuditLog) Create(_ context.Context, db gorm.DB) error {
+	if auditLog.Arguments == "" {
+		auditLog.Arguments = "{}"
+	}
+	if auditLog.Outcome == "" {
+		auditLog.Outcome = AuditLogOutcomeSuccess
+	}
+	return db.Create(auditLog).Error
+}
+
+func (filter *AuditLogFilter) apply(db *gorm.DB) *gorm.DB {
+	tx := db.Model(&AuditLog{})
+	if filter == nil {
+		return tx
+	}
+	if filter.Username != "" {
+		tx = tx.Where("username = ?", filter.Username)
+	}
+	if filter.Source != "" {
+		tx = tx.Where("source = ?", filter.Source)
+	}
+	if filter.Action != "" {
+		tx = tx.Where("action = ?", filter.Action)
+	}
+	if filter.TargetID != "" {
+		tx = tx.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Outcome != "" {
+		tx = tx.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.From != nil {
+		tx = tx.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		tx = tx.Where("created_at <= ?", *filter.To)
+	}
+	return tx
+}
+
+// FindAuditLogs : find audit logs matching the filter, latest first
+func FindAuditLogs(_ context.Context, db gorm.DB, filter *AuditLogFilter) ([]*AuditLog, error) {
+	var auditLogs = make([]*AuditLog, 0)
+	tx := filter.apply(&db).Order("created_at desc, id desc")
+	if filter != nil && filter.Limit > 0 {
+		tx = tx.Limit(filter.Limit)
+	}
+	if filter != nil && filter.Offset > 0 {
+		tx = tx.Offset(filter.Offset)
+	}
+	err := tx.Find(&auditLogs).Error
+	return auditLogs, err
+}
+
+// ExportAuditLogs : call the callback for each audit log matching the filter, oldest first
+// Records are fetched in batches, so it's safe to export a large number of records
+func ExportAuditLogs(_ context.Context, db gorm.DB, filter *AuditLogFilter, callback func(auditLog *AuditLog) error) error {
+	var auditLogs []*AuditLog
+	return filter.apply(&db).FindInBatches(&auditLogs, 500, func(tx *gorm.DB, batch int) error {
+		for _, auditLog := range auditLogs {
+			if err := callback(auditLog); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
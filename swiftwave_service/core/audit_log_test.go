@@ -0,0 +1,37 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeAuditLogArguments(t *testing.T) {
+	sanitized := SanitizeAuditLogArguments(map[string]interface{}{
+		"id": "app-1",
+		"input": map[string]interface{}{
+			"name":          "api",
+			"gitPassword":   "hunter2",
+			"sshPrivateKey": "-----BEGIN KEY-----",
+			"environmentVariables": []interface{}{
+				map[string]interface{}{"key": "DATABASE_URL", "value": "postgres://user:pass@db"},
+			},
+		},
+	})
+	assert.NotContains(t, sanitized, "hunter2")
+	assert.NotContains(t, sanitized, "BEGIN KEY")
+	assert.NotContains(t, sanitized, "postgres://")
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(sanitized), &decoded))
+	input := decoded["input"].(map[string]interface{})
+	assert.Equal(t, "app-1", decoded["id"])
+	assert.Equal(t, "api", input["name"])
+	assert.Equal(t, redactedAuditLogValue, input["gitPassword"])
+	variable := input["environmentVariables"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "DATABASE_URL", variable["key"])
+	assert.Equal(t, redactedAuditLogValue, variable["value"])
+
+	assert.Equal(t, "{}", SanitizeAuditLogArguments(nil))
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// This file contains the operations for the AuthThrottle model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+const (
+	// authThrottleFreeAttempts : failed attempts allowed before applying the backoff
+	authThrottleFreeAttempts = 3
+	// authThrottleBaseBackoff : backoff after the first throttled attempt, doubled on each failed attempt
+	authThrottleBaseBackoff = time.Second
+	// authThrottleMaxBackoff : maximum backoff between two attempts
+	authThrottleMaxBackoff = 15 * time.Minute
+	// authThrottleResetWindow : failed attempts are forgotten if there is no failed attempt in this window
+	authThrottleResetWindow = time.Hour
+	// authLockoutThreshold : account is locked after this many consecutive failed attempts
+	authLockoutThreshold = 10
+	// authLockoutDuration : duration of the account lockout
+	authLockoutDuration = 30 * time.Minute
+)
+
+// AuthThrottleError : returned if the attempt is not allowed due to too many failed attempts
+type AuthThrottleError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *AuthThrottleError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account is locked due to too many failed attempts, try again after %s or contact administrator", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed attempts, try again after %s", e.RetryAfter.Round(time.Second))
+}
+
+func authThrottleIPKey(ip string) string {
+	return "ip:" + ip
+}
+
+func authThrottleUsernameKey(username string) string {
+	return "username:" + username
+}
+
+// CheckAuthThrottle : check if the ip or username is allowed to attempt the login
+// Returns *AuthThrottleError if the attempt is throttled or the account is locked
+func CheckAuthThrottle(_ context.Context, db gorm.DB, ip string, username string) error {
+	var records []*AuthThrottle
+	err := db.Where("key IN ?", []string{authThrottleIPKey(ip), authThrottleUsernameKey(username)}).Find(&records).Error
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var throttleErr *AuthThrottleError
+	for _, record := range records {
+		if record.LockedUntil != nil && now.Before(*record.LockedUntil) {
+			return &AuthThrottleError{Locked: true, RetryAfter: record.LockedUntil.Sub(now)}
+		}
+		if now.Before(record.BlockedUntil) {
+			retryAfter := record.BlockedUntil.Sub(now)
+			if throttleErr == nil || retryAfter > throttleErr.RetryAfter {
+				throttleErr = &AuthThrottleError{RetryAfter: retryAfter}
+			}
+		}
+	}
+	if throttleErr != nil {
+		return throttleErr
+	}
+	return nil
+}
+
+// RecordFailedAuthAttempt : increase the failed attempts of the ip and username, and apply the backoff
+// Username is locked out after authLockoutThreshold consecutive failed attempts
+func RecordFailedAuthAttempt(_ context.Context, db gorm.DB, ip string, username string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := recordFailedAuthAttempt(tx, authThrottleIPKey(ip), false)
+		if err != nil {
+			return err
+		}
+		if username == "" {
+			return nil
+		}
+		return recordFailedAuthAttempt(tx, authThrottleUsernameKey(username), true)
+	})
+}
+
+func recordFailedAuthAttempt(tx *gorm.DB, key string, lockout bool) error {
+	// create the record if not exists
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&AuthThrottle{Key: key}).Error
+	if err != nil {
+		return err
+	}
+	// lock the record to avoid lost updates from concurrent attempts
+	var record AuthThrottle
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&record).Error
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	isLocked := record.LockedUntil != nil && now.Before(*record.LockedUntil)
+	if !isLocked && now.Sub(record.LastFailedAt) > authThrottleResetWindow {
+		record.FailedAttempts = 0
+		record.LockedUntil = nil
+	}
+	record.FailedAttempts++
+	record.LastFailedAt = now
+	if record.FailedAttempts > authThrottleFreeAttempts {
+		record.BlockedUntil = now.Add(authThrottleBackoff(record.FailedAttempts - authThrottleFreeAttempts))
+	}
+	if lockout && record.FailedAttempts >= authLockoutThreshold {
+		lockedUntil := now.Add(authLockoutDuration)
+		record.LockedUntil = &lockedUntil
+	}
+	return tx.Save(&record).Error
+}
+
+// authThrottleBackoff : exponential backoff for the nth throttled attempt
+func authThrottleBackoff(n uint) time.Duration {
+	if n > 20 {
+		return authThrottleMaxBackoff
+	}
+	backoff := authThrottleBaseBackoff * time.Duration(1<<(n-1))
+	if backoff > authThrottleMaxBackoff {
+		return authThrottleMaxBackoff
+	}
+	return backoff
+}
+
+// ResetAuthThrottle : reset the failed attempts of the username after successful login
+func ResetAuthThrottle(_ context.Context, db gorm.DB, username string) error {
+	return db.Where("key = ?", authThrottleUsernameKey(username)).Delete(&AuthThrottle{}).Error
+}
+
+// UnlockUser : remove the lockout and failed attempts of the user
+func UnlockUser(_ context.Context, db gorm.DB, username string) error {
+	tx := db.Where("key = ?", authThrottleUsernameKey(username)).Delete(&AuthThrottle{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errors.New("user is not locked")
+	}
+	return nil
+}
+
+// DeleteExpiredAuthThrottles : delete the records which have no effect anymore
+// Records are created for every attempted ip and username, including the usernames which don't exist,
+// so they need to be cleaned up periodically to keep the table small
+func DeleteExpiredAuthThrottles(_ context.Context, db gorm.DB) (int64, error) {
+	now := time.Now()
+	tx := db.Where("last_failed_at < ? AND blocked_until < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-authThrottleResetWindow), now, now).Delete(&AuthThrottle{})
+	return tx.RowsAffected, tx.Error
+}
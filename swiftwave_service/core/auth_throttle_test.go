@@ -0,0 +1,166 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// failAuthAttempts : record n failed attempts, ignoring the backoff in between
+func failAuthAttempts(t *testing.T, db gorm.DB, ip string, username string, n int) {
+	for i := 0; i < n; i++ {
+		if err := RecordFailedAuthAttempt(context.Background(), db, ip, username); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func findAuthThrottle(t *testing.T, db gorm.DB, key string) AuthThrottle {
+	var record AuthThrottle
+	if err := db.Where("key = ?", key).First(&record).Error; err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+func TestAuthThrottleBackoff(t *testing.T) {
+	tests := []struct {
+		n    uint
+		want time.Duration
+	}{
+		{n: 1, want: time.Second},
+		{n: 2, want: 2 * time.Second},
+		{n: 5, want: 16 * time.Second},
+		{n: 10, want: 512 * time.Second},
+		{n: 11, want: authThrottleMaxBackoff},
+		{n: 64, want: authThrottleMaxBackoff},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, authThrottleBackoff(tt.n), "attempt %d", tt.n)
+	}
+}
+
+func TestCheckAuthThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("free attempts are not throttled", func(t *testing.T) {
+		db := newTestDB(t, &AuthThrottle{})
+		failAuthAttempts(t, db, "203.0.113.7", "admin", authThrottleFreeAttempts)
+		assert.NoError(t, CheckAuthThrottle(ctx, db, "203.0.113.7", "admin"))
+	})
+
+	t.Run("backoff after free attempts", func(t *testing.T) {
+		db := newTestDB(t, &AuthThrottle{})
+		failAuthAttempts(t, db, "203.0.113.7", "admin", authThrottleFreeAttempts+1)
+		var throttleErr *AuthThrottleError
+		err := CheckAuthThrottle(ctx, db, "203.0.113.7", "admin")
+		assert.True(t, errors.As(err, &throttleErr))
+		assert.False(t, throttleErr.Locked)
+		assert.LessOrEqual(t, throttleErr.RetryAfter, authThrottleBaseBackoff)
+	})
+
+	t.Run("ip is throttled for other usernames", func(t *testing.T) {
+		db := newTestDB(t, &AuthThrottle{})
+		for i := 0; i <= authThrottleFreeAttempts; i++ {
+			failAuthAttempts(t, db, "203.0.113.7", "user"+string(rune('a'+i)), 1)
+		}
+		assert.Error(t, CheckAuthThrottle(ctx, db, "203.0.113.7", "admin"))
+		assert.NoError(t, CheckAuthThrottle(ctx, db, "198.51.100.1", "admin"))
+	})
+
+	t.Run("username is throttled from other ips", func(t *testing.T) {
+		db := newTestDB(t, &AuthThrottle{})
+		for i := 0; i <= authThrottleFreeAttempts; i++ {
+			failAuthAttempts(t, db, "203.0.113."+string(rune('1'+i)), "admin", 1)
+		}
+		assert.Error(t, CheckAuthThrottle(ctx, db, "198.51.100.1", "admin"))
+	})
+
+	t.Run("account is locked after threshold", func(t *testing.T) {
+		db := newTestDB(t, &AuthThrottle{})
+		failAuthAttempts(t, db, "203.0.113.7", "admin", authLockoutThreshold)
+		var throttleErr *AuthThrottleError
+		err := CheckAuthThrottle(ctx, db, "198.51.100.1", "admin")
+		assert.True(t, errors.As(err, &throttleErr))
+		assert.True(t, throttleErr.Locked)
+		assert.InDelta(t, authLockoutDuration.Seconds(), throttleErr.RetryAfter.Seconds(), 5)
+		// ip is only throttled, never locked
+		assert.Nil(t, findAuthThrottle(t, db, authThrottleIPKey("203.0.113.7")).LockedUntil)
+	})
+
+	t.Run("lockout expires", func(t *testing.T) {
+		db := newTestDB(t, &AuthThrottle{})
+		failAuthAttempts(t, db, "203.0.113.7", "admin", authLockoutThreshold)
+		past := time.Now().Add(-time.Minute)
+		assert.NoError(t, db.Model(&AuthThrottle{}).Where("1 = 1").Updates(map[string]interface{}{
+			"locked_until":  past,
+			"blocked_until": past,
+		}).Error)
+		assert.NoError(t, CheckAuthThrottle(ctx, db, "203.0.113.7", "admin"))
+	})
+
+	t.Run("unlock and reset", func(t *testing.T) {
+		db := newTestDB(t, &AuthThrottle{})
+		failAuthAttempts(t, db, "203.0.113.7", "admin", authLockoutThreshold)
+		assert.NoError(t, UnlockUser(ctx, db, "admin"))
+		assert.Error(t, UnlockUser(ctx, db, "admin"))
+		// ip is still throttled
+		assert.Error(t, CheckAuthThrottle(ctx, db, "203.0.113.7", "admin"))
+		assert.NoError(t, CheckAuthThrottle(ctx, db, "198.51.100.1", "admin"))
+
+		failAuthAttempts(t, db, "198.51.100.1", "viewer", authThrottleFreeAttempts+1)
+		assert.NoError(t, ResetAuthThrottle(ctx, db, "viewer"))
+		assert.NoError(t, CheckAuthThrottle(ctx, db, "192.0.2.1", "viewer"))
+	})
+}
+
+func TestRecordFailedAuthAttemptResetWindow(t *testing.T) {
+	db := newTestDB(t, &AuthThrottle{})
+	key := authThrottleUsernameKey("admin")
+	failAuthAttempts(t, db, "203.0.113.7", "admin", authThrottleFreeAttempts+2)
+	assert.Equal(t, uint(authThrottleFreeAttempts+2), findAuthThrottle(t, db, key).FailedAttempts)
+
+	// failed attempts are forgotten after the reset window
+	assert.NoError(t, db.Model(&AuthThrottle{}).Where("key = ?", key).
+		Update("last_failed_at", time.Now().Add(-authThrottleResetWindow-time.Minute)).Error)
+	failAuthAttempts(t, db, "203.0.113.7", "admin", 1)
+	assert.Equal(t, uint(1), findAuthThrottle(t, db, key).FailedAttempts)
+
+	// but not while the account is locked
+	lockedUntil := time.Now().Add(time.Minute)
+	assert.NoError(t, db.Model(&AuthThrottle{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"failed_attempts": authLockoutThreshold,
+		"locked_until":    lockedUntil,
+		"last_failed_at":  time.Now().Add(-authThrottleResetWindow - time.Minute),
+	}).Error)
+	failAuthAttempts(t, db, "203.0.113.7", "admin", 1)
+	assert.Equal(t, uint(authLockoutThreshold+1), findAuthThrottle(t, db, key).FailedAttempts)
+}
+
+func TestDeleteExpiredAuthThrottles(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &AuthThrottle{})
+	now := time.Now()
+	old := now.Add(-authThrottleResetWindow - time.Minute)
+	lockedUntil := now.Add(time.Minute)
+	records := []AuthThrottle{
+		{Key: "username:recent", FailedAttempts: 1, LastFailedAt: now},
+		{Key: "username:does-not-exist", FailedAttempts: 2, LastFailedAt: old, BlockedUntil: old},
+		{Key: "ip:203.0.113.7", FailedAttempts: 20, LastFailedAt: old, BlockedUntil: old},
+		{Key: "ip:198.51.100.1", FailedAttempts: 20, LastFailedAt: old, BlockedUntil: now.Add(time.Minute)},
+		{Key: "username:locked", FailedAttempts: 10, LastFailedAt: old, BlockedUntil: old, LockedUntil: &lockedUntil},
+	}
+	assert.NoError(t, db.Create(&records).Error)
+
+	count, err := DeleteExpiredAuthThrottles(ctx, db)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	var keys []string
+	assert.NoError(t, db.Model(&AuthThrottle{}).Order("key").Pluck("key", &keys).Error)
+	assert.Equal(t, []string{"ip:198.51.100.1", "username:locked", "username:recent"}, keys)
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+)
+
+// This file contains the operations for the BuildSecret model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+func FindBuildSecretsByDeploymentId(ctx context.Context, db gorm.DB, deploymentId string) ([]*BuildSecret, error) {
+	var buildSecrets []*BuildSecret
+	tx := db.Where("deployment_id = ?", deploymentId).Find(&buildSecrets)
+	return buildSecrets, tx.Error
+}
+
+func DeleteBuildSecretsByDeploymentId(ctx context.Context, db gorm.DB, deploymentId string) error {
+	tx := db.Where("deployment_id = ?", deploymentId).Delete(&BuildSecret{})
+	return tx.Error
+}
+
+// FetchBuildSecretValues : values of the build secrets, from the secret environment variables of the application
+func FetchBuildSecretValues(ctx context.Context, db gorm.DB, deployment *Deployment) (map[string]string, error) {
+	buildSecrets, err := FindBuildSecretsByDeploymentId(ctx, db, deployment.ID)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if len(buildSecrets) == 0 {
+		return values, nil
+	}
+	environmentVariables, err := FindEnvironmentVariablesByApplicationId(ctx, db, deployment.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	secretEnvironmentVariables := make(map[string]string)
+	for _, environmentVariable := range environmentVariables {
+		if environmentVariable.IsSecret {
+			secretEnvironmentVariables[environmentVariable.Key] = environmentVariable.Value
+		}
+	}
+	for _, buildSecret := range buildSecrets {
+		value, ok := secretEnvironmentVariables[buildSecret.Key]
+		if !ok {
+			return nil, errors.New("secret environment variable " + buildSecret.Key + " not found for build secret")
+		}
+		values[buildSecret.Key] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule : parsed cron expression with 5 fields (minute hour day-of-month month day-of-week)
+// Supports *, lists (1,2), ranges (1-5), steps (*/15, 1-30/5), month and weekday names and the @hourly like macros
+type CronSchedule struct {
+	minutes       uint64
+	hours         uint64
+	daysOfMonth   uint64
+	months        uint64
+	daysOfWeek    uint64
+	anyDayOfWeek  bool
+	anyDayOfMonth bool
+}
+
+type cronField struct {
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronMinuteField     = cronField{min: 0, max: 59}
+	cronHourField       = cronField{min: 0, max: 23}
+	cronDayOfMonthField = cronField{min: 1, max: 31}
+	cronMonthField      = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is also accepted as sunday
+	cronDayOfWeekField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCronSchedule : parse the cron expression
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := cronMacros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, errors.New("cron schedule should have 5 fields (minute hour day-of-month month day-of-week)")
+	}
+	schedule := &CronSchedule{}
+	var err error
+	if schedule.minutes, err = cronMinuteField.parse(fields[0]); err != nil {
+		return nil, errors.New("invalid minute in cron schedule > " + err.Error())
+	}
+	if schedule.hours, err = cronHourField.parse(fields[1]); err != nil {
+		return nil, errors.New("invalid hour in cron schedule > " + err.Error())
+	}
+	if schedule.daysOfMonth, err = cronDayOfMonthField.parse(fields[2]); err != nil {
+		return nil, errors.New("invalid day of month in cron schedule > " + err.Error())
+	}
+	if schedule.months, err = cronMonthField.parse(fields[3]); err != nil {
+		return nil, errors.New("invalid month in cron schedule > " + err.Error())
+	}
+	if schedule.daysOfWeek, err = cronDayOfWeekField.parse(fields[4]); err != nil {
+		return nil, errors.New("invalid day of week in cron schedule > " + err.Error())
+	}
+	// sunday can be written as 0 or 7
+	if schedule.daysOfWeek&(1<<7) != 0 {
+		schedule.daysOfWeek |= 1
+	}
+	schedule.anyDayOfMonth = strings.HasPrefix(fields[2], "*")
+	schedule.anyDayOfWeek = strings.HasPrefix(fields[4], "*")
+	return schedule, nil
+}
+
+// Matches : check if the schedule is due at the minute of the time
+func (s *CronSchedule) Matches(t time.Time) bool {
+	if s.minutes&(1<<uint(t.Minute())) == 0 || s.hours&(1<<uint(t.Hour())) == 0 || s.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dayOfMonthMatch := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeekMatch := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+	// like standard cron, if both day fields are restricted, either of them should match
+	if !s.anyDayOfMonth && !s.anyDayOfWeek {
+		return dayOfMonthMatch || dayOfWeekMatch
+	}
+	return dayOfMonthMatch && dayOfWeekMatch
+}
+
+// Next : first time after the given time when the schedule is due
+// Returns zero time if the schedule is not due in next 5 years (e.g. 30th February)
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.Matches(time.Date(t.Year(), t.Month(), t.Day(), firstBit(s.hours), firstBit(s.minutes), 0, 0, t.Location())) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.Matches(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+// parse : parse the field into a bitset of allowed values
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangeAndStep := strings.SplitN(part, "/", 2); len(rangeAndStep) == 2 {
+			var err error
+			step, err = strconv.Atoi(rangeAndStep[1])
+			if err != nil || step <= 0 {
+				return 0, errors.New("invalid step " + rangeAndStep[1])
+			}
+			part = rangeAndStep[0]
+		}
+		start, end := f.min, f.max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			start, err = f.value(bounds[0])
+			if err != nil {
+				return 0, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = f.value(bounds[1])
+				if err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				// 5/15 means every 15th starting from 5
+				end = f.max
+			}
+			if start > end {
+				return 0, errors.New("invalid range " + part)
+			}
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+// value : parse the number or name in the field
+func (f cronField) value(value string) (int, error) {
+	if number, ok := f.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.New("invalid value " + value)
+	}
+	if number < f.min || number > f.max {
+		return 0, errors.New("value " + value + " out of range " + strconv.Itoa(f.min) + "-" + strconv.Itoa(f.max))
+	}
+	return number, nil
+}
+
+// firstBit : lowest value set in the bitset
+func firstBit(bits uint64) int {
+	for i := 0; i < 64; i++ {
+		if bits&(1<<uint(i)) != 0 {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cronTime(value string) time.Time {
+	layout := "2006-01-02 15:04"
+	if len(value) > len(layout) {
+		layout += ":05"
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{"empty", ""},
+		{"too few fields", "* * * *"},
+		{"too many fields", "* * * * * *"},
+		{"unknown macro", "@every5m"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "0 24 * * *"},
+		{"day of month zero", "0 0 0 * *"},
+		{"day of month out of range", "0 0 32 * *"},
+		{"month zero", "0 0 1 0 *"},
+		{"month out of range", "0 0 1 13 *"},
+		{"day of week out of range", "0 0 * * 8"},
+		{"unknown name", "0 0 * foo *"},
+		{"month name in day of week", "0 0 * * jan"},
+		{"reversed range", "0 5-1 * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"negative step", "*/-5 * * * *"},
+		{"non numeric step", "*/x * * * *"},
+		{"empty list item", "1,,2 * * * *"},
+		{"negative value", "-1 * * * *"},
+		{"open range", "1- * * * *"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseCronSchedule(test.expression)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCronScheduleMatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		matches    []string
+		misses     []string
+	}{
+		{
+			name:       "every minute",
+			expression: "* * * * *",
+			matches:    []string{"2024-01-01 00:00", "2024-12-31 23:59"},
+		},
+		{
+			name:       "range",
+			expression: "0 9-17 * * *",
+			matches:    []string{"2024-03-04 09:00", "2024-03-04 17:00"},
+			misses:     []string{"2024-03-04 08:00", "2024-03-04 18:00", "2024-03-04 09:01"},
+		},
+		{
+			name:       "step over all values",
+			expression: "*/15 * * * *",
+			matches:    []string{"2024-03-04 10:00", "2024-03-04 10:15", "2024-03-04 10:45"},
+			misses:     []string{"2024-03-04 10:05", "2024-03-04 10:59"},
+		},
+		{
+			name:       "step over range",
+			expression: "10-30/10 * * * *",
+			matches:    []string{"2024-03-04 10:10", "2024-03-04 10:20", "2024-03-04 10:30"},
+			misses:     []string{"2024-03-04 10:00", "2024-03-04 10:40"},
+		},
+		{
+			name:       "step from start value",
+			expression: "5/20 * * * *",
+			matches:    []string{"2024-03-04 10:05", "2024-03-04 10:25", "2024-03-04 10:45"},
+			misses:     []string{"2024-03-04 10:00", "2024-03-04 10:20"},
+		},
+		{
+			name:       "list",
+			expression: "0 1,13,22 * * *",
+			matches:    []string{"2024-03-04 01:00", "2024-03-04 13:00", "2024-03-04 22:00"},
+			misses:     []string{"2024-03-04 02:00"},
+		},
+		{
+			name:       "list of ranges and values",
+			expression: "0,30-31,59 * * * *",
+			matches:    []string{"2024-03-04 10:00", "2024-03-04 10:31", "2024-03-04 10:59"},
+			misses:     []string{"2024-03-04 10:32"},
+		},
+		{
+			name:       "month and weekday names",
+			expression: "0 0 * JAN-mar mon-FRI",
+			matches:    []string{"2024-01-01 00:00", "2024-03-29 00:00"},
+			misses:     []string{"2024-01-06 00:00", "2024-04-01 00:00"},
+		},
+		{
+			name:       "sunday as 7",
+			expression: "0 0 * * 7",
+			matches:    []string{"2024-03-03 00:00"},
+			misses:     []string{"2024-03-04 00:00"},
+		},
+		{
+			name:       "sunday as 0",
+			expression: "0 0 * * 0",
+			matches:    []string{"2024-03-03 00:00"},
+			misses:     []string{"2024-03-09 00:00"},
+		},
+		{
+			name:       "only day of month restricted",
+			expression: "0 0 13 * *",
+			matches:    []string{"2024-09-13 00:00", "2024-03-13 00:00"},
+			misses:     []string{"2024-09-14 00:00"},
+		},
+		{
+			name:       "only day of week restricted",
+			expression: "0 0 * * 5",
+			matches:    []string{"2024-09-13 00:00", "2024-09-20 00:00"},
+			misses:     []string{"2024-09-14 00:00"},
+		},
+		{
+			// like standard cron, either of the day fields should match if both are restricted
+			name:       "both day fields restricted",
+			expression: "0 0 13 * 5",
+			matches:    []string{"2024-09-13 00:00", "2024-09-20 00:00", "2024-03-13 00:00"},
+			misses:     []string{"2024-09-14 00:00"},
+		},
+		{
+			// day of month starting with * is treated as unrestricted, both fields should match
+			name:       "day of month step with restricted day of week",
+			expression: "0 0 */2 * 1",
+			matches:    []string{"2024-09-09 00:00"},
+			misses:     []string{"2024-09-16 00:00", "2024-09-11 00:00"},
+		},
+		{
+			name:       "macro",
+			expression: "@Weekly",
+			matches:    []string{"2024-03-03 00:00"},
+			misses:     []string{"2024-03-04 00:00", "2024-03-03 00:01"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(test.expression)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, value := range test.matches {
+				assert.True(t, schedule.Matches(cronTime(value)), "expected match at %s", value)
+			}
+			for _, value := range test.misses {
+				assert.False(t, schedule.Matches(cronTime(value)), "expected no match at %s", value)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		after      string
+		next       string
+	}{
+		{"next minute", "* * * * *", "2024-03-04 10:00", "2024-03-04 10:01"},
+		{"seconds are ignored", "* * * * *", "2024-03-04 10:00:59", "2024-03-04 10:01"},
+		{"later in the hour", "*/15 * * * *", "2024-03-04 10:16", "2024-03-04 10:30"},
+		{"hour rollover", "*/15 * * * *", "2024-03-04 10:50", "2024-03-04 11:00"},
+		{"day rollover", "30 9 * * *", "2024-03-04 10:00", "2024-03-05 09:30"},
+		{"month rollover", "0 0 1 * *", "2024-01-31 12:00", "2024-02-01 00:00"},
+		{"year rollover", "@yearly", "2024-12-31 23:59", "2025-01-01 00:00"},
+		{"skips months without the day", "0 0 31 * *", "2024-04-01 00:00", "2024-05-31 00:00"},
+		{"leap day", "0 0 29 2 *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"restricted month", "0 12 * jun *", "2024-07-01 00:00", "2025-06-01 12:00"},
+		{"weekday", "0 8 * * mon", "2024-03-04 08:00", "2024-03-11 08:00"},
+		{"either day field", "0 0 15 * fri", "2024-03-09 00:00", "2024-03-15 00:00"},
+		{"never due", "0 0 30 2 *", "2024-01-01 00:00", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(test.expression)
+			if !assert.NoError(t, err) {
+				return
+			}
+			next := schedule.Next(cronTime(test.after))
+			if test.next == "" {
+				assert.True(t, next.IsZero(), "expected zero time, got %s", next)
+				return
+			}
+			assert.Equal(t, cronTime(test.next), next)
+			assert.True(t, schedule.Matches(next))
+		})
+	}
+}
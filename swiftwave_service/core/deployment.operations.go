@@ -78,6 +78,8 @@ func (deployment *Deployment) Create(ctx context.Context, db gorm.DB) error {
 	deployment.ID = uuid.NewString()
 	deployment.CreatedAt = time.Now()
 	deployment.Status = DeploymentStatusPending
+	// snapshot is taken, when the deployment is deployed
+	deployment.ConfigSnapshot = ""
 	tx := db.Create(&deployment)
 	return tx.Error
 }
@@ -89,7 +91,7 @@ func (deployment *Deployment) Create(ctx context.Context, db gorm.DB) error {
 func (deployment *Deployment) Update(ctx context.Context, db gorm.DB) (*DeploymentUpdateResult, error) {
 	// fetch latest deployment
 	latestDeployment := &Deployment{}
-	tx := db.Preload("BuildArgs").Find(&latestDeployment, "id = ?", deployment.ID)
+	tx := db.Preload("BuildArgs").Preload("BuildSecrets").Find(&latestDeployment, "id = ?", deployment.ID)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -109,12 +111,17 @@ func (deployment *Deployment) Update(ctx context.Context, db gorm.DB) (*Deployme
 		deployment.RepositoryOwner != latestDeployment.RepositoryOwner ||
 		deployment.RepositoryName != latestDeployment.RepositoryName ||
 		deployment.RepositoryBranch != latestDeployment.RepositoryBranch ||
+		deployment.GitReferenceType != latestDeployment.GitReferenceType ||
+		deployment.RepositoryTag != latestDeployment.RepositoryTag ||
+		deployment.GitTagPattern != latestDeployment.GitTagPattern ||
+		deployment.GitCloneConfig != latestDeployment.GitCloneConfig ||
 		deployment.CommitHash != latestDeployment.CommitHash ||
 		deployment.CodePath != latestDeployment.CodePath ||
 		deployment.SourceCodeCompressedFileName != latestDeployment.SourceCodeCompressedFileName ||
 		deployment.DockerImage != latestDeployment.DockerImage ||
 		deployment.ImageRegistryCredentialID != latestDeployment.ImageRegistryCredentialID ||
-		deployment.Dockerfile != latestDeployment.Dockerfile {
+		deployment.Dockerfile != latestDeployment.Dockerfile ||
+		deployment.DockerfileTarget != latestDeployment.DockerfileTarget {
 		recreationRequired = true
 	}
 	// verify build args
@@ -134,6 +141,21 @@ func (deployment *Deployment) Update(ctx context.Context, db gorm.DB) (*Deployme
 			}
 		}
 	}
+	// verify build secrets
+	if len(deployment.BuildSecrets) != len(latestDeployment.BuildSecrets) {
+		recreationRequired = true
+	} else {
+		latestBuildSecretKeys := make(map[string]bool)
+		for _, buildSecret := range latestDeployment.BuildSecrets {
+			latestBuildSecretKeys[buildSecret.Key] = true
+		}
+		for _, buildSecret := range deployment.BuildSecrets {
+			if !latestBuildSecretKeys[buildSecret.Key] {
+				recreationRequired = true
+				break
+			}
+		}
+	}
 
 	// recreate deployment
 	if recreationRequired {
@@ -162,6 +184,11 @@ func (deployment *Deployment) Delete(ctx context.Context, db gorm.DB) error {
 	if tx.Error != nil {
 		return tx.Error
 	}
+	// delete all build secrets
+	tx = db.Where("deployment_id = ?", deployment.ID).Delete(&BuildSecret{})
+	if tx.Error != nil {
+		return tx.Error
+	}
 	// delete all logs
 	tx = db.Where("deployment_id = ?", deployment.ID).Delete(&DeploymentLog{})
 	if tx.Error != nil {
@@ -172,15 +199,45 @@ func (deployment *Deployment) Delete(ctx context.Context, db gorm.DB) error {
 	return tx.Error
 }
 
+// IsRollback : check if the deployment reuses the image of an older deployment
+func (deployment *Deployment) IsRollback() bool {
+	return deployment.OriginalDeploymentID != nil && strings.Compare(*deployment.OriginalDeploymentID, "") != 0
+}
+
+// ImageDeploymentID : id of the deployment for which the image has been built
+func (deployment *Deployment) ImageDeploymentID() string {
+	if deployment.IsRollback() {
+		return *deployment.OriginalDeploymentID
+	}
+	return deployment.ID
+}
+
+// UpdateImageDigest : record the digest of the image resolved for the deployment
+func (deployment *Deployment) UpdateImageDigest(ctx context.Context, db gorm.DB, digest string) error {
+	tx := db.Model(&deployment).Update("image_digest", digest)
+	return tx.Error
+}
+
+// CanRollbackTo : only the deployments which were deployed at some point have a usable image
+func (deployment *Deployment) CanRollbackTo() bool {
+	return deployment.Status == DeploymentStatusDeployed ||
+		deployment.Status == DeploymentStalled ||
+		deployment.Status == DeploymentStatusStopped
+}
+
 func (deployment *Deployment) DeployableDockerImageURI(remoteRegistryPrefix string) string {
 	isRemoteRegistryPrefixEmpty := strings.Compare(remoteRegistryPrefix, "") == 0
 	if !isRemoteRegistryPrefixEmpty && strings.HasSuffix(remoteRegistryPrefix, "/") {
 		remoteRegistryPrefix = remoteRegistryPrefix[:len(remoteRegistryPrefix)-1]
 	}
 	if deployment.UpstreamType == UpstreamTypeImage {
+		// <image>:<tag>@<digest> is pulled by digest, tag is kept for readability
+		if strings.Compare(deployment.ImageDigest, "") != 0 && !strings.Contains(deployment.DockerImage, "@") {
+			return deployment.DockerImage + "@" + deployment.ImageDigest
+		}
 		return deployment.DockerImage
 	} else if deployment.UpstreamType == UpstreamTypeGit || deployment.UpstreamType == UpstreamTypeSourceCode {
-		imageURI := deployment.ApplicationID + ":" + deployment.ID
+		imageURI := deployment.ApplicationID + ":" + deployment.ImageDeploymentID()
 		if !isRemoteRegistryPrefixEmpty {
 			imageURI = remoteRegistryPrefix + "/" + imageURI
 		}
@@ -221,6 +278,80 @@ func (deployment *Deployment) GitRepositoryURL() string {
 	return ""
 }
 
+// GitCloneReference : branch or tag to clone, both are empty for the deployment pinned to a commit
+func (deployment *Deployment) GitCloneReference() (branch string, tag string) {
+	switch deployment.GitReferenceType {
+	case GitReferenceTag:
+		return "", deployment.RepositoryTag
+	case GitReferenceCommit:
+		return "", ""
+	default:
+		return deployment.RepositoryBranch, ""
+	}
+}
+
+// HasBuiltImage : image has been built and pushed to the registry for the deployment
+// Rollback deployments reuse the image of the original deployment
+func (deployment *Deployment) HasBuiltImage() bool {
+	return (deployment.UpstreamType == UpstreamTypeGit || deployment.UpstreamType == UpstreamTypeSourceCode) && !deployment.IsRollback()
+}
+
+// FindExpiredDeploymentsByApplicationId : deployments of the application which are beyond the retention policy
+// These are always kept -
+// - the latest deployment and the deployments in progress
+// - the deployed and candidate deployments
+// - the last deployment which was live before the current one, swarm rolls back to it
+// - the deployments whose image is reused by a kept deployment
+//
+// Replaced candidates are stalled as well, but those never served from the main service.
+// So the rollback target is looked up only among the deployments older than the current one.
+func FindExpiredDeploymentsByApplicationId(ctx context.Context, db gorm.DB, id string, policy ApplicationRetentionPolicy) ([]*Deployment, error) {
+	expiredDeployments := make([]*Deployment, 0)
+	if !policy.IsEnabled() {
+		return expiredDeployments, nil
+	}
+	deployments, err := FindDeploymentsByApplicationId(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().AddDate(0, 0, -int(policy.KeepDays))
+	// if nothing is deployed at the moment, the latest one which was live is the rollback target
+	currentDeploymentIndex := -1
+	for index, deployment := range deployments {
+		if deployment.Status == DeploymentStatusDeployed {
+			currentDeploymentIndex = index
+			break
+		}
+	}
+	isRollbackTargetFound := false
+	keptImageDeploymentIds := make(map[string]bool)
+	candidates := make([]*Deployment, 0)
+	for index, deployment := range deployments {
+		isProtected := index == 0 ||
+			deployment.Status == DeploymentStatusPending ||
+			deployment.Status == DeploymentStatusDeployPending ||
+			deployment.Status == DeploymentStatusDeployed ||
+			deployment.Status == DeploymentStatusCandidate
+		if !isProtected && !isRollbackTargetFound && index > currentDeploymentIndex && deployment.CanRollbackTo() {
+			isProtected = true
+			isRollbackTargetFound = true
+		}
+		isExpired := (policy.KeepLastDeployments > 0 && uint(index) >= policy.KeepLastDeployments) ||
+			(policy.KeepDays > 0 && deployment.CreatedAt.Before(cutoff))
+		if isProtected || !isExpired {
+			keptImageDeploymentIds[deployment.ImageDeploymentID()] = true
+		} else {
+			candidates = append(candidates, deployment)
+		}
+	}
+	for _, deployment := range candidates {
+		if !keptImageDeploymentIds[deployment.ID] {
+			expiredDeployments = append(expiredDeployments, deployment)
+		}
+	}
+	return expiredDeployments, nil
+}
+
 // Extra functions for resolvers
 
 func FindDeploymentsByImageRegistryCredentialId(ctx context.Context, db gorm.DB, id uint) ([]*Deployment, error) {
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// FetchApplicationConfigSnapshot : fetch the current config of the application, which is not part of the deployment record
+func FetchApplicationConfigSnapshot(ctx context.Context, db gorm.DB, applicationId string) (*DeploymentConfigSnapshot, error) {
+	application := &Application{}
+	err := application.FindById(ctx, db, applicationId)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &DeploymentConfigSnapshot{
+		EnvironmentVariables:     make(map[string]string),
+		SecretEnvironmentKeys:    make([]string, 0),
+		ConfigMounts:             make(map[string]string),
+		PersistentVolumeBindings: make(map[string]string),
+		ResourceLimitMemoryMB:    application.ResourceLimit.MemoryMB,
+		ReservedResourceMemoryMB: application.ReservedResource.MemoryMB,
+		Replicas:                 application.Replicas,
+		Command:                  application.Command,
+	}
+	environmentVariables, err := FindEnvironmentVariablesByApplicationId(ctx, db, applicationId)
+	if err != nil {
+		return nil, err
+	}
+	for _, environmentVariable := range environmentVariables {
+		snapshot.EnvironmentVariables[environmentVariable.Key] = environmentVariable.Value
+		if environmentVariable.IsSecret {
+			snapshot.SecretEnvironmentKeys = append(snapshot.SecretEnvironmentKeys, environmentVariable.Key)
+		}
+	}
+	configMounts, err := FindConfigMountsByApplicationId(ctx, db, applicationId)
+	if err != nil {
+		return nil, err
+	}
+	for _, configMount := range configMounts {
+		snapshot.ConfigMounts[configMount.MountingPath] = configMount.Content
+	}
+	persistentVolumeBindings, err := FindPersistentVolumeBindingsByApplicationId(ctx, db, applicationId)
+	if err != nil {
+		return nil, err
+	}
+	for _, persistentVolumeBinding := range persistentVolumeBindings {
+		persistentVolume := &PersistentVolume{}
+		err = persistentVolume.FindById(ctx, db, persistentVolumeBinding.PersistentVolumeID)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.PersistentVolumeBindings[persistentVolumeBinding.MountingPath] = persistentVolume.Name
+	}
+	return snapshot, nil
+}
+
+// SaveConfigSnapshot : record the config of the application, with which the deployment is being deployed
+func (deployment *Deployment) SaveConfigSnapshot(_ context.Context, db gorm.DB, snapshot *DeploymentConfigSnapshot) error {
+	snapshotJson, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	deployment.ConfigSnapshot = string(snapshotJson)
+	tx := db.Model(&deployment).Update("config_snapshot", deployment.ConfigSnapshot)
+	return tx.Error
+}
+
+// ParsedConfigSnapshot : config of the application, with which the deployment was deployed
+// Returns nil, if the deployment has never been deployed
+func (deployment *Deployment) ParsedConfigSnapshot() (*DeploymentConfigSnapshot, error) {
+	if deployment.ConfigSnapshot == "" {
+		return nil, nil
+	}
+	snapshot := &DeploymentConfigSnapshot{}
+	err := json.Unmarshal([]byte(deployment.ConfigSnapshot), snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// IsSensitive : value of the change can contain secrets, so it should be masked for non-admin users
+// Config mounts often hold credentials (e.g. config files of the application), so their contents are masked as well
+func (change DeploymentChange) IsSensitive() bool {
+	switch change.Category {
+	case DeploymentChangeEnvironmentVariable, DeploymentChangeBuildArg, DeploymentChangeConfigMount:
+		return true
+	default:
+		return false
+	}
+}
+
+// FindDeploymentDiff : compare two deployments of the same application
+// If toDeploymentId is empty, the deployment is compared with the current config of the application
+func FindDeploymentDiff(ctx context.Context, db gorm.DB, fromDeploymentId string, toDeploymentId string) (*DeploymentDiff, error) {
+	fromDeployment := &Deployment{}
+	err := fromDeployment.FindById(ctx, db, fromDeploymentId)
+	if err != nil {
+		return nil, err
+	}
+	fromSnapshot, err := fromDeployment.ParsedConfigSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	var toDeployment *Deployment
+	var toSnapshot *DeploymentConfigSnapshot
+	if toDeploymentId == "" {
+		// the latest deployment holds the upstream config, which will be used for next deployment
+		toDeployment, err = FindLatestDeploymentByApplicationId(ctx, db, fromDeployment.ApplicationID)
+		if err != nil {
+			return nil, err
+		}
+		toSnapshot, err = FetchApplicationConfigSnapshot(ctx, db, fromDeployment.ApplicationID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		toDeployment = &Deployment{}
+		err = toDeployment.FindById(ctx, db, toDeploymentId)
+		if err != nil {
+			return nil, err
+		}
+		if toDeployment.ApplicationID != fromDeployment.ApplicationID {
+			return nil, errors.New("deployments belong to different applications")
+		}
+		toSnapshot, err = toDeployment.ParsedConfigSnapshot()
+		if err != nil {
+			return nil, err
+		}
+	}
+	fromBuildArgs, err := FindBuildArgsByDeploymentId(ctx, db, fromDeployment.ID)
+	if err != nil {
+		return nil, err
+	}
+	toBuildArgs, err := FindBuildArgsByDeploymentId(ctx, db, toDeployment.ID)
+	if err != nil {
+		return nil, err
+	}
+	fromBuildSecrets, err := FindBuildSecretsByDeploymentId(ctx, db, fromDeployment.ID)
+	if err != nil {
+		return nil, err
+	}
+	toBuildSecrets, err := FindBuildSecretsByDeploymentId(ctx, db, toDeployment.ID)
+	if err != nil {
+		return nil, err
+	}
+	diff := &DeploymentDiff{
+		Changes:                   make([]DeploymentChange, 0),
+		IsConfigSnapshotAvailable: fromSnapshot != nil && toSnapshot != nil,
+	}
+	// upstream and build, image needs to be rebuilt for these changes
+	diff.addValueChange(DeploymentChangeUpstream, "upstream_type", string(fromDeployment.UpstreamType), string(toDeployment.UpstreamType))
+	diff.addValueChange(DeploymentChangeUpstream, "git_provider", fromDeployment.GitProvider, toDeployment.GitProvider)
+	diff.addValueChange(DeploymentChangeUpstream, "repository_url", fromDeployment.GitRepositoryURL(), toDeployment.GitRepositoryURL())
+	diff.addValueChange(DeploymentChangeUpstream, "repository_branch", fromDeployment.RepositoryBranch, toDeployment.RepositoryBranch)
+	diff.addValueChange(DeploymentChangeUpstream, "git_reference_type", string(fromDeployment.GitReferenceType), string(toDeployment.GitReferenceType))
+	diff.addValueChange(DeploymentChangeUpstream, "repository_tag", fromDeployment.RepositoryTag, toDeployment.RepositoryTag)
+	diff.addValueChange(DeploymentChangeUpstream, "commit_hash", fromDeployment.CommitHash, toDeployment.CommitHash)
+	diff.addValueChange(DeploymentChangeUpstream, "commit_message", fromDeployment.CommitMessage, toDeployment.CommitMessage)
+	diff.addValueChange(DeploymentChangeUpstream, "code_path", fromDeployment.CodePath, toDeployment.CodePath)
+	diff.addValueChange(DeploymentChangeUpstream, "git_clone_submodules", strconv.FormatBool(fromDeployment.GitCloneConfig.Submodules), strconv.FormatBool(toDeployment.GitCloneConfig.Submodules))
+	diff.addValueChange(DeploymentChangeUpstream, "git_clone_lfs", strconv.FormatBool(fromDeployment.GitCloneConfig.LFS), strconv.FormatBool(toDeployment.GitCloneConfig.LFS))
+	diff.addValueChange(DeploymentChangeUpstream, "git_clone_shallow", strconv.FormatBool(fromDeployment.GitCloneConfig.Shallow), strconv.FormatBool(toDeployment.GitCloneConfig.Shallow))
+	diff.addValueChange(DeploymentChangeUpstream, "source_code_compressed_file_name", fromDeployment.SourceCodeCompressedFileName, toDeployment.SourceCodeCompressedFileName)
+	diff.addValueChange(DeploymentChangeUpstream, "docker_image", fromDeployment.DockerImage, toDeployment.DockerImage)
+	diff.addValueChange(DeploymentChangeDockerfile, "dockerfile", fromDeployment.Dockerfile, toDeployment.Dockerfile)
+	diff.addValueChange(DeploymentChangeDockerfile, "dockerfile_target", fromDeployment.DockerfileTarget, toDeployment.DockerfileTarget)
+	diff.addMapChanges(DeploymentChangeBuildArg, buildArgsMap(fromBuildArgs), buildArgsMap(toBuildArgs), nil)
+	diff.addMapChanges(DeploymentChangeBuildSecret, buildSecretsMap(fromBuildSecrets), buildSecretsMap(toBuildSecrets), nil)
+	diff.RebuildRequired = len(diff.Changes) > 0
+	// application config, service needs to be updated for these changes
+	if diff.IsConfigSnapshotAvailable {
+		rebuildChangesCount := len(diff.Changes)
+		secretKeys := make(map[string]bool)
+		for _, key := range append(fromSnapshot.SecretEnvironmentKeys, toSnapshot.SecretEnvironmentKeys...) {
+			secretKeys[key] = true
+		}
+		diff.addMapChanges(DeploymentChangeEnvironmentVariable, fromSnapshot.EnvironmentVariables, toSnapshot.EnvironmentVariables, secretKeys)
+		diff.addMapChanges(DeploymentChangeConfigMount, fromSnapshot.ConfigMounts, toSnapshot.ConfigMounts, nil)
+		diff.addMapChanges(DeploymentChangePersistentVolumeBinding, fromSnapshot.PersistentVolumeBindings, toSnapshot.PersistentVolumeBindings, nil)
+		diff.addValueChange(DeploymentChangeResource, "resource_limit_memory_mb", strconv.Itoa(fromSnapshot.ResourceLimitMemoryMB), strconv.Itoa(toSnapshot.ResourceLimitMemoryMB))
+		diff.addValueChange(DeploymentChangeResource, "reserved_resource_memory_mb", strconv.Itoa(fromSnapshot.ReservedResourceMemoryMB), strconv.Itoa(toSnapshot.ReservedResourceMemoryMB))
+		diff.addValueChange(DeploymentChangeResource, "replicas", strconv.Itoa(int(fromSnapshot.Replicas)), strconv.Itoa(int(toSnapshot.Replicas)))
+		diff.addValueChange(DeploymentChangeResource, "command", fromSnapshot.Command, toSnapshot.Command)
+		diff.ReloadRequired = len(diff.Changes) > rebuildChangesCount
+	}
+	return diff, nil
+}
+
+func (diff *DeploymentDiff) addValueChange(category DeploymentChangeCategory, field string, oldValue string, newValue string) {
+	if oldValue == newValue {
+		return
+	}
+	changeType := DeploymentChangeModified
+	if oldValue == "" {
+		changeType = DeploymentChangeAdded
+	} else if newValue == "" {
+		changeType = DeploymentChangeRemoved
+	}
+	diff.Changes = append(diff.Changes, DeploymentChange{
+		Category: category,
+		Type:     changeType,
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+	})
+}
+
+// addMapChanges : add the changes of the keyed values, sorted by key
+// Changes of the secret keys are flagged, so that the values are never shown
+func (diff *DeploymentDiff) addMapChanges(category DeploymentChangeCategory, oldValues map[string]string, newValues map[string]string, secretKeys map[string]bool) {
+	keys := make([]string, 0, len(oldValues)+len(newValues))
+	for key := range oldValues {
+		keys = append(keys, key)
+	}
+	for key := range newValues {
+		if _, ok := oldValues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		oldValue, isOldExist := oldValues[key]
+		newValue, isNewExist := newValues[key]
+		change := DeploymentChange{
+			Category: category,
+			Field:    key,
+			OldValue: oldValue,
+			NewValue: newValue,
+			IsSecret: secretKeys[key],
+		}
+		if !isOldExist {
+			change.Type = DeploymentChangeAdded
+		} else if !isNewExist {
+			change.Type = DeploymentChangeRemoved
+		} else if oldValue != newValue {
+			change.Type = DeploymentChangeModified
+		} else {
+			continue
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+}
+
+func buildArgsMap(buildArgs []*BuildArg) map[string]string {
+	values := make(map[string]string)
+	for _, buildArg := range buildArgs {
+		values[buildArg.Key] = buildArg.Value
+	}
+	return values
+}
+
+// buildSecretsMap : keys of the build secrets, values are resolved at build time
+func buildSecretsMap(buildSecrets []*BuildSecret) map[string]string {
+	values := make(map[string]string)
+	for _, buildSecret := range buildSecrets {
+		values[buildSecret.Key] = ""
+	}
+	return values
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployableDockerImageURI(t *testing.T) {
+	originalDeploymentID := "deployment-1"
+	tests := []struct {
+		name       string
+		deployment Deployment
+		want       string
+	}{
+		{
+			name:       "image without digest",
+			deployment: Deployment{UpstreamType: UpstreamTypeImage, DockerImage: "nginx:latest"},
+			want:       "nginx:latest",
+		},
+		{
+			name:       "image pinned to digest",
+			deployment: Deployment{UpstreamType: UpstreamTypeImage, DockerImage: "nginx:latest", ImageDigest: "sha256:abc"},
+			want:       "nginx:latest@sha256:abc",
+		},
+		{
+			name:       "image with digest in reference",
+			deployment: Deployment{UpstreamType: UpstreamTypeImage, DockerImage: "nginx@sha256:def", ImageDigest: "sha256:def"},
+			want:       "nginx@sha256:def",
+		},
+		{
+			name:       "git",
+			deployment: Deployment{ID: "deployment-2", ApplicationID: "app", UpstreamType: UpstreamTypeGit},
+			want:       "registry.local/app:deployment-2",
+		},
+		{
+			name:       "git rollback",
+			deployment: Deployment{ID: "deployment-2", ApplicationID: "app", UpstreamType: UpstreamTypeGit, OriginalDeploymentID: &originalDeploymentID},
+			want:       "registry.local/app:deployment-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.deployment.DeployableDockerImageURI("registry.local/"))
+		})
+	}
+}
+
+func TestRollbackApplicationOfImageDeployment(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &Application{}, &Deployment{}, &BuildArg{}, &BuildSecret{})
+	application := Application{ID: "app", Name: "app"}
+	assert.NoError(t, db.Create(&application).Error)
+
+	pinned := Deployment{ID: "pinned", ApplicationID: application.ID, UpstreamType: UpstreamTypeImage, DockerImage: "nginx:latest", ImageDigest: "sha256:abc", Status: DeploymentStatusStopped}
+	unpinned := Deployment{ID: "unpinned", ApplicationID: application.ID, UpstreamType: UpstreamTypeImage, DockerImage: "nginx:latest", Status: DeploymentStatusStopped}
+	current := Deployment{ID: "current", ApplicationID: application.ID, UpstreamType: UpstreamTypeImage, DockerImage: "nginx:latest", ImageDigest: "sha256:def", Status: DeploymentStatusDeployed}
+	assert.NoError(t, db.Create(&[]Deployment{pinned, unpinned, current}).Error)
+
+	_, err := application.RollbackApplication(ctx, db, unpinned.ID)
+	assert.Error(t, err)
+
+	rollbackDeploymentID, err := application.RollbackApplication(ctx, db, pinned.ID)
+	assert.NoError(t, err)
+	rollbackDeployment := &Deployment{}
+	assert.NoError(t, rollbackDeployment.FindById(ctx, db, rollbackDeploymentID))
+	assert.Equal(t, "sha256:abc", rollbackDeployment.ImageDigest)
+	assert.Equal(t, "nginx:latest@sha256:abc", rollbackDeployment.DeployableDockerImageURI(""))
+}
+
+func TestFindExpiredDeploymentsByApplicationId(t *testing.T) {
+	type deploymentFixture struct {
+		id         string
+		status     DeploymentStatus
+		ageDays    int
+		originalID string
+	}
+	// fixtures are listed from the latest to the oldest
+	tests := []struct {
+		name        string
+		deployments []deploymentFixture
+		policy      ApplicationRetentionPolicy
+		expired     []string
+	}{
+		{
+			name: "disabled policy",
+			deployments: []deploymentFixture{
+				{id: "d2", status: DeploymentStatusDeployed},
+				{id: "d1", status: DeploymentStalled},
+			},
+			policy:  ApplicationRetentionPolicy{},
+			expired: []string{},
+		},
+		{
+			name: "keep last deployments",
+			deployments: []deploymentFixture{
+				{id: "d5", status: DeploymentStatusDeployed},
+				{id: "d4", status: DeploymentStalled},
+				{id: "d3", status: DeploymentStalled},
+				{id: "d2", status: DeploymentStatusFailed},
+				{id: "d1", status: DeploymentStalled},
+			},
+			policy:  ApplicationRetentionPolicy{KeepLastDeployments: 2},
+			expired: []string{"d3", "d2", "d1"},
+		},
+		{
+			name: "latest, current and rollback target are kept",
+			deployments: []deploymentFixture{
+				{id: "d4", status: DeploymentStatusFailed},
+				{id: "d3", status: DeploymentStatusDeployed},
+				{id: "d2", status: DeploymentStalled},
+				{id: "d1", status: DeploymentStalled},
+			},
+			policy:  ApplicationRetentionPolicy{KeepLastDeployments: 1},
+			expired: []string{"d1"},
+		},
+		{
+			name: "replaced candidate is not the rollback target",
+			deployments: []deploymentFixture{
+				{id: "d6", status: DeploymentStatusCandidate},
+				{id: "d5", status: DeploymentStalled},
+				{id: "d4", status: DeploymentStatusDeployed},
+				{id: "d3", status: DeploymentStalled},
+				{id: "d2", status: DeploymentStalled},
+			},
+			policy:  ApplicationRetentionPolicy{KeepLastDeployments: 1},
+			expired: []string{"d5", "d2"},
+		},
+		{
+			name: "rollback target without current deployment",
+			deployments: []deploymentFixture{
+				{id: "d3", status: DeploymentStatusFailed},
+				{id: "d2", status: DeploymentStatusStopped},
+				{id: "d1", status: DeploymentStalled},
+			},
+			policy:  ApplicationRetentionPolicy{KeepLastDeployments: 1},
+			expired: []string{"d1"},
+		},
+		{
+			name: "deployments in progress are kept",
+			deployments: []deploymentFixture{
+				{id: "d4", status: DeploymentStatusPending},
+				{id: "d3", status: DeploymentStatusDeployPending},
+				{id: "d2", status: DeploymentStatusDeployed},
+				{id: "d1", status: DeploymentStatusFailed},
+			},
+			policy:  ApplicationRetentionPolicy{KeepLastDeployments: 1},
+			expired: []string{"d1"},
+		},
+		{
+			name: "image reused by a kept rollback deployment",
+			deployments: []deploymentFixture{
+				{id: "d4", status: DeploymentStatusDeployed, originalID: "d1"},
+				{id: "d3", status: DeploymentStalled},
+				{id: "d2", status: DeploymentStalled},
+				{id: "d1", status: DeploymentStalled},
+			},
+			policy:  ApplicationRetentionPolicy{KeepLastDeployments: 1},
+			expired: []string{"d2"},
+		},
+		{
+			name: "keep days",
+			deployments: []deploymentFixture{
+				{id: "d4", status: DeploymentStatusDeployed, ageDays: 20},
+				{id: "d3", status: DeploymentStatusFailed, ageDays: 3},
+				{id: "d2", status: DeploymentStalled, ageDays: 30},
+				{id: "d1", status: DeploymentStalled, ageDays: 40},
+			},
+			policy:  ApplicationRetentionPolicy{KeepDays: 7},
+			expired: []string{"d1"},
+		},
+		{
+			name: "either of the limits expires",
+			deployments: []deploymentFixture{
+				{id: "d4", status: DeploymentStatusDeployed},
+				{id: "d3", status: DeploymentStatusFailed},
+				{id: "d2", status: DeploymentStatusFailed, ageDays: 1},
+				{id: "d1", status: DeploymentStatusFailed, ageDays: 10},
+			},
+			policy:  ApplicationRetentionPolicy{KeepLastDeployments: 3, KeepDays: 7},
+			expired: []string{"d1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newTestDB(t, &Deployment{})
+			now := time.Now()
+			for index, fixture := range tt.deployments {
+				deployment := Deployment{ID: fixture.id, ApplicationID: "app", UpstreamType: UpstreamTypeGit, Status: fixture.status}
+				if fixture.originalID != "" {
+					originalID := fixture.originalID
+					deployment.OriginalDeploymentID = &originalID
+				}
+				// keep the order of the fixtures, even if the age is same
+				deployment.CreatedAt = now.AddDate(0, 0, -fixture.ageDays).Add(-time.Duration(index) * time.Minute)
+				assert.NoError(t, db.Create(&deployment).Error)
+			}
+			expired, err := FindExpiredDeploymentsByApplicationId(ctx, db, "app", tt.policy)
+			assert.NoError(t, err)
+			expiredIds := make([]string, 0, len(expired))
+			for _, deployment := range expired {
+				expiredIds = append(expiredIds, deployment.ID)
+			}
+			assert.Equal(t, tt.expired, expiredIds)
+		})
+	}
+}
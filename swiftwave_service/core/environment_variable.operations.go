@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"errors"
 	"gorm.io/gorm"
+	"regexp"
 )
 
 // This file contains the operations for the EnvironmentVariable model.
@@ -11,6 +13,45 @@ import (
 // Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
 // context used to pass some data to the function e.g. user id, auth info, etc.
 
+// SecretEnvironmentVariableMask : value of secret environment variables shown to the user
+// If the masked value is submitted back on update, the stored value is kept as it is
+const SecretEnvironmentVariableMask = "********"
+
+// SecretEnvironmentVariablesDirectory : directory where the secrets are mounted in the container
+const SecretEnvironmentVariablesDirectory = "/run/secrets"
+
+// secretEnvironmentVariableKeyRegex : secret key is used as the file name, so restrict it to safe characters
+var secretEnvironmentVariableKeyRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.-]*$`)
+
+func validateSecretEnvironmentVariables(environmentVariables []EnvironmentVariable) error {
+	keys := make(map[string]bool)
+	for _, environmentVariable := range environmentVariables {
+		keys[environmentVariable.Key] = true
+	}
+	for _, environmentVariable := range environmentVariables {
+		if !environmentVariable.IsSecret {
+			continue
+		}
+		if !secretEnvironmentVariableKeyRegex.MatchString(environmentVariable.Key) {
+			return errors.New("secret environment variable key can contain only alphanumeric characters, '_', '.' and '-'")
+		}
+		if environmentVariable.SecretFileEnv && keys[environmentVariable.SecretFileEnvKey()] {
+			return errors.New("environment variable " + environmentVariable.SecretFileEnvKey() + " conflicts with the file path of secret " + environmentVariable.Key)
+		}
+	}
+	return nil
+}
+
+// keepMaskedSecretValue : if the masked value of an existing secret is submitted back, keep the stored value
+// The variable stays secret, otherwise the mask itself would be stored as plain value
+func keepMaskedSecretValue(existing EnvironmentVariable, submitted EnvironmentVariable) EnvironmentVariable {
+	if existing.IsSecret && submitted.Value == SecretEnvironmentVariableMask {
+		submitted.Value = existing.Value
+		submitted.IsSecret = true
+	}
+	return submitted
+}
+
 func FindEnvironmentVariablesByApplicationId(ctx context.Context, db gorm.DB, applicationId string) ([]*EnvironmentVariable, error) {
 	var environmentVariables []*EnvironmentVariable
 	tx := db.Where("application_id = ?", applicationId).Find(&environmentVariables)
@@ -36,3 +77,13 @@ func (e *EnvironmentVariable) Delete(ctx context.Context, db gorm.DB) error {
 	tx := db.Delete(e)
 	return tx.Error
 }
+
+// SecretFilePath : path of the secret file in the container
+func (e *EnvironmentVariable) SecretFilePath() string {
+	return SecretEnvironmentVariablesDirectory + "/" + e.Key
+}
+
+// SecretFileEnvKey : name of the environment variable pointing to the secret file
+func (e *EnvironmentVariable) SecretFileEnvKey() string {
+	return e.Key + "_FILE"
+}
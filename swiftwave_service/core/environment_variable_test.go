@@ -0,0 +1,111 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSecretEnvironmentVariables(t *testing.T) {
+	tests := []struct {
+		name                 string
+		environmentVariables []EnvironmentVariable
+		valid                bool
+	}{
+		{
+			name: "plain variables are not restricted",
+			environmentVariables: []EnvironmentVariable{
+				{Key: "MY VAR", Value: "value"},
+				{Key: "PASSWORD_FILE", Value: "/tmp/password"},
+			},
+			valid: true,
+		},
+		{
+			name: "secret with safe key",
+			environmentVariables: []EnvironmentVariable{
+				{Key: "db.password-1", Value: "secret", IsSecret: true, SecretFileEnv: true},
+			},
+			valid: true,
+		},
+		{
+			name:                 "secret key with path separator",
+			environmentVariables: []EnvironmentVariable{{Key: "../PASSWORD", Value: "secret", IsSecret: true}},
+		},
+		{
+			name:                 "secret key starting with digit",
+			environmentVariables: []EnvironmentVariable{{Key: "1PASSWORD", Value: "secret", IsSecret: true}},
+		},
+		{
+			name: "file env conflicts with existing variable",
+			environmentVariables: []EnvironmentVariable{
+				{Key: "PASSWORD", Value: "secret", IsSecret: true, SecretFileEnv: true},
+				{Key: "PASSWORD_FILE", Value: "/tmp/password"},
+			},
+		},
+		{
+			name: "existing _FILE variable is fine without file env",
+			environmentVariables: []EnvironmentVariable{
+				{Key: "PASSWORD", Value: "secret", IsSecret: true},
+				{Key: "PASSWORD_FILE", Value: "/tmp/password"},
+			},
+			valid: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateSecretEnvironmentVariables(test.environmentVariables)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestSecretEnvironmentVariableFile(t *testing.T) {
+	environmentVariable := EnvironmentVariable{Key: "DB_PASSWORD", IsSecret: true, SecretFileEnv: true}
+	assert.Equal(t, "/run/secrets/DB_PASSWORD", environmentVariable.SecretFilePath())
+	assert.Equal(t, "DB_PASSWORD_FILE", environmentVariable.SecretFileEnvKey())
+}
+
+func TestKeepMaskedSecretValue(t *testing.T) {
+	secret := EnvironmentVariable{Key: "DB_PASSWORD", Value: "secret", IsSecret: true}
+	plain := EnvironmentVariable{Key: "DB_HOST", Value: "localhost"}
+	tests := []struct {
+		name      string
+		existing  EnvironmentVariable
+		submitted EnvironmentVariable
+		expected  EnvironmentVariable
+	}{
+		{
+			name:      "masked secret keeps the stored value",
+			existing:  secret,
+			submitted: EnvironmentVariable{Key: "DB_PASSWORD", Value: SecretEnvironmentVariableMask, IsSecret: true},
+			expected:  EnvironmentVariable{Key: "DB_PASSWORD", Value: "secret", IsSecret: true},
+		},
+		{
+			name:      "masked secret with is secret turned off keeps the stored value",
+			existing:  secret,
+			submitted: EnvironmentVariable{Key: "DB_PASSWORD", Value: SecretEnvironmentVariableMask},
+			expected:  EnvironmentVariable{Key: "DB_PASSWORD", Value: "secret", IsSecret: true},
+		},
+		{
+			name:      "new value of secret is stored",
+			existing:  secret,
+			submitted: EnvironmentVariable{Key: "DB_PASSWORD", Value: "changed"},
+			expected:  EnvironmentVariable{Key: "DB_PASSWORD", Value: "changed"},
+		},
+		{
+			name:      "mask as value of plain variable is stored",
+			existing:  plain,
+			submitted: EnvironmentVariable{Key: "DB_HOST", Value: SecretEnvironmentVariableMask},
+			expected:  EnvironmentVariable{Key: "DB_HOST", Value: SecretEnvironmentVariableMask},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, keepMaskedSecretValue(test.existing, test.submitted))
+		})
+	}
+}
@@ -2,8 +2,13 @@ package core
 
 import (
 	"context"
-	"gorm.io/gorm"
+	"errors"
+	"fmt"
+	"net"
 	"strings"
+
+	gitmanager "github.com/swiftwave-org/swiftwave/git_manager"
+	"gorm.io/gorm"
 )
 
 // This file contains the operations for the GitCredential model.
@@ -24,14 +29,22 @@ func (gitCredential *GitCredential) FindById(ctx context.Context, db gorm.DB, id
 }
 
 func (gitCredential *GitCredential) Create(ctx context.Context, db gorm.DB) error {
+	err := gitCredential.normalizeAllowedHosts()
+	if err != nil {
+		return err
+	}
 	tx := db.Create(&gitCredential)
 	return tx.Error
 }
 
 func (gitCredential *GitCredential) Update(ctx context.Context, db gorm.DB) error {
+	err := gitCredential.normalizeAllowedHosts()
+	if err != nil {
+		return err
+	}
 	// fetch old record
 	var oldGitCredential = &GitCredential{}
-	err := oldGitCredential.FindById(ctx, db, gitCredential.ID)
+	err = oldGitCredential.FindById(ctx, db, gitCredential.ID)
 	if err != nil {
 		return err
 	}
@@ -54,3 +67,74 @@ func (gitCredential *GitCredential) Delete(ctx context.Context, db gorm.DB) erro
 	tx := db.Delete(&gitCredential)
 	return tx.Error
 }
+
+// CheckRepositoryAccess : credential can be used only for the repositories hosted on its allowed hosts
+func (gitCredential *GitCredential) CheckRepositoryAccess(repositoryUrl string) error {
+	repoInfo, err := gitmanager.ParseGitRepoInfo(repositoryUrl)
+	if err != nil {
+		return errors.New("invalid git repository url")
+	}
+	host := gitHost(repoInfo.Endpoint)
+	for _, allowedHost := range gitCredential.AllowedHosts {
+		if allowedHost == host {
+			return nil
+		}
+	}
+	return fmt.Errorf("git credential %s is not allowed to be used for %s", gitCredential.Name, host)
+}
+
+// CheckGitCredentialAccess : verify that the git credential of the deployment exists and is allowed for the repository
+func (deployment *Deployment) CheckGitCredentialAccess(ctx context.Context, db gorm.DB) error {
+	if deployment.UpstreamType != UpstreamTypeGit || deployment.GitCredentialID == nil {
+		return nil
+	}
+	gitCredential := &GitCredential{}
+	err := gitCredential.FindById(ctx, db, *deployment.GitCredentialID)
+	if err != nil {
+		return err
+	}
+	return gitCredential.CheckRepositoryAccess(deployment.GitRepositoryURL())
+}
+
+// normalizeAllowedHosts : keep only the hostname of the allowed hosts, at least one host is required
+func (gitCredential *GitCredential) normalizeAllowedHosts() error {
+	allowedHosts := make([]string, 0, len(gitCredential.AllowedHosts))
+	isAdded := make(map[string]bool)
+	for _, allowedHost := range gitCredential.AllowedHosts {
+		host := gitHost(allowedHost)
+		if host == "" {
+			continue
+		}
+		if strings.ContainsAny(host, " *:") {
+			return fmt.Errorf("invalid allowed host %s, e.g. github.com", allowedHost)
+		}
+		if isAdded[host] {
+			continue
+		}
+		isAdded[host] = true
+		allowedHosts = append(allowedHosts, host)
+	}
+	if len(allowedHosts) == 0 {
+		return errors.New("at least one allowed host is required for git credential, e.g. github.com")
+	}
+	gitCredential.AllowedHosts = allowedHosts
+	return nil
+}
+
+// gitHost : hostname of the git endpoint, without the scheme, user and port
+func gitHost(endpoint string) string {
+	host := strings.ToLower(strings.TrimSpace(endpoint))
+	for _, scheme := range []string{"https://", "http://", "ssh://"} {
+		host = strings.TrimPrefix(host, scheme)
+	}
+	if index := strings.Index(host, "/"); index >= 0 {
+		host = host[:index]
+	}
+	if index := strings.LastIndex(host, "@"); index >= 0 {
+		host = host[index+1:]
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	return host
+}
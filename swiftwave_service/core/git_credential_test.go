@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitCredentialCheckRepositoryAccess(t *testing.T) {
+	gitCredential := &GitCredential{Name: "internal", AllowedHosts: []string{"github.com", "git.example.com"}}
+	tests := []struct {
+		repositoryUrl string
+		allowed       bool
+	}{
+		{"https://github.com/owner/repo", true},
+		{"http://GitHub.com/owner/repo.git", true},
+		{"github.com/owner/repo", true},
+		{"git@github.com:owner/repo.git", true},
+		{"ssh://git@git.example.com:2222/owner/repo.git", true},
+		{"https://git.example.com:8443/group/subgroup/repo", true},
+		{"https://gitlab.com/owner/repo", false},
+		{"https://github.com.attacker.example.com/owner/repo", false},
+		{"https://attacker.example.com/github.com/repo", false},
+		{"git@attacker.example.com:owner/repo.git", false},
+		{"not a url", false},
+	}
+	for _, test := range tests {
+		t.Run(test.repositoryUrl, func(t *testing.T) {
+			err := gitCredential.CheckRepositoryAccess(test.repositoryUrl)
+			if test.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestGitCredentialAllowedHostsAreNormalized(t *testing.T) {
+	db := newTestDB(t, &GitCredential{}, &Deployment{})
+	ctx := context.Background()
+
+	gitCredential := &GitCredential{Name: "empty", Type: GitHttp}
+	assert.Error(t, gitCredential.Create(ctx, db))
+	gitCredential = &GitCredential{Name: "wildcard", Type: GitHttp, AllowedHosts: []string{"*.example.com"}}
+	assert.Error(t, gitCredential.Create(ctx, db))
+
+	gitCredential = &GitCredential{
+		Name:         "github",
+		Type:         GitHttp,
+		Password:     "token",
+		AllowedHosts: []string{" GitHub.com ", "https://github.com/", "git@git.example.com:2222", ""},
+	}
+	if !assert.NoError(t, gitCredential.Create(ctx, db)) {
+		return
+	}
+	saved := &GitCredential{}
+	if !assert.NoError(t, saved.FindById(ctx, db, gitCredential.ID)) {
+		return
+	}
+	assert.Equal(t, []string{"github.com", "git.example.com"}, []string(saved.AllowedHosts))
+
+	saved.AllowedHosts = []string{}
+	assert.Error(t, saved.Update(ctx, db))
+	saved.AllowedHosts = []string{"gitlab.com"}
+	saved.Password = ""
+	if !assert.NoError(t, saved.Update(ctx, db)) {
+		return
+	}
+	updated := &GitCredential{}
+	if !assert.NoError(t, updated.FindById(ctx, db, gitCredential.ID)) {
+		return
+	}
+	assert.Equal(t, []string{"gitlab.com"}, []string(updated.AllowedHosts))
+	assert.Equal(t, "token", updated.Password)
+}
+
+func TestDeploymentCheckGitCredentialAccess(t *testing.T) {
+	db := newTestDB(t, &GitCredential{}, &Deployment{})
+	ctx := context.Background()
+	gitCredential := &GitCredential{Name: "github", Type: GitHttp, AllowedHosts: []string{"github.com"}}
+	if !assert.NoError(t, gitCredential.Create(ctx, db)) {
+		return
+	}
+	deployment := &Deployment{
+		UpstreamType:    UpstreamTypeGit,
+		GitType:         GitHttp,
+		GitEndpoint:     "https://github.com",
+		RepositoryOwner: "owner",
+		RepositoryName:  "repo",
+		GitCredentialID: &gitCredential.ID,
+	}
+	assert.NoError(t, deployment.CheckGitCredentialAccess(ctx, db))
+	deployment.GitEndpoint = "https://attacker.example.com"
+	assert.Error(t, deployment.CheckGitCredentialAccess(ctx, db))
+	missingID := gitCredential.ID + 1
+	deployment.GitCredentialID = &missingID
+	assert.Error(t, deployment.CheckGitCredentialAccess(ctx, db))
+	deployment.GitCredentialID = nil
+	assert.NoError(t, deployment.CheckGitCredentialAccess(ctx, db))
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB : sqlite database with the tables of the provided models
+func newTestDB(t *testing.T, models ...interface{}) gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "swiftwave.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return *db
+}
+
+func createTestUser(t *testing.T, db gorm.DB, username string, role UserRole) User {
+	user := User{Username: username, Role: role}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
@@ -85,6 +85,9 @@ func (ingressRule *IngressRule) IsValidNewIngressRule(ctx context.Context, db go
 		if err != nil {
 			return err
 		}
+		if application.DeploymentMode.IsJob() {
+			return errors.New("ingress rule can't be added to job application")
+		}
 	}
 
 	// validation
@@ -2,6 +2,7 @@ package core
 
 import (
 	"github.com/lib/pq"
+	_ "github.com/swiftwave-org/swiftwave/swiftwave_service/encryption" // register serializer for encrypted fields
 	"gorm.io/gorm"
 	"time"
 )
@@ -15,6 +16,8 @@ type Server struct {
 	SSHPort               int                    `json:"ssh_port" gorm:"default:22"`
 	MaintenanceMode       bool                   `json:"maintenance_mode" gorm:"default:false"`
 	ScheduleDeployments   bool                   `json:"schedule_deployments" gorm:"default:true"`
+	IsBuildNode           bool                   `json:"is_build_node" gorm:"default:false"`
+	MaxConcurrentBuilds   uint                   `json:"max_concurrent_builds" gorm:"default:1"`
 	DockerUnixSocketPath  string                 `json:"docker_unix_socket_path"`
 	SwarmMode             SwarmMode              `json:"swarm_mode"`
 	ProxyConfig           ProxyConfig            `json:"proxy_config" gorm:"embedded;embeddedPrefix:proxy_"`
@@ -39,10 +42,77 @@ type ServerLog struct {
 type User struct {
 	ID           uint     `json:"id" gorm:"primaryKey"`
 	Username     string   `json:"username" gorm:"unique"`
-	Role         UserRole `json:"role" gorm:"default:'user'"`
+	Role         UserRole `json:"role" gorm:"default:'manager'"`
 	PasswordHash string   `json:"password_hash"`
 	TotpEnabled  bool     `json:"totp_enabled" gorm:"default:false"`
 	TotpSecret   string   `json:"totp_secret"`
+	// OIDCIssuer, OIDCSubject - identity of the user at the single sign-on provider
+	// Username can be changed at the identity provider, so only `iss` + `sub` is used to find the user
+	OIDCIssuer  string `json:"oidc_issuer" gorm:"column:oidc_issuer;index:idx_user_oidc_identity"`
+	OIDCSubject string `json:"oidc_subject" gorm:"column:oidc_subject;index:idx_user_oidc_identity"`
+	// IsSSOManaged - user has been created by single sign-on, role is kept in sync with the identity provider
+	IsSSOManaged bool `json:"is_sso_managed" gorm:"default:false"`
+	// ApplicationGroupPermissions - if set, user can only access the applications of these application groups
+	ApplicationGroupPermissions []ApplicationGroupPermission `json:"application_group_permissions" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// PersonalAccessTokens - long-lived tokens to access the api from scripts and CI pipelines
+	PersonalAccessTokens []PersonalAccessToken `json:"personal_access_tokens" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// TotpRecoveryCodes - one-time codes to login if the totp device is lost
+	TotpRecoveryCodes []TotpRecoveryCode `json:"totp_recovery_codes" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// WebAuthnCredentials - registered authenticators (security keys, passkeys) for second factor
+	WebAuthnCredentials []WebAuthnCredential `json:"webauthn_credentials" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// Sessions - login sessions of the user, deleted along with the user
+	Sessions []UserSession `json:"sessions" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TotpRecoveryCode hold one-time recovery code of user, generated on enabling totp
+// Only the sha256 hash of the code is stored
+type TotpRecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	CodeHash  string     `json:"code_hash"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// WebAuthnCredential hold the public key credential of an authenticator registered by user
+type WebAuthnCredential struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"index"`
+	Name            string         `json:"name"`
+	CredentialID    []byte         `json:"credential_id" gorm:"unique"`
+	PublicKey       []byte         `json:"public_key"`
+	AttestationType string         `json:"attestation_type"`
+	Transports      pq.StringArray `json:"transports" gorm:"type:text[]"`
+	AAGUID          []byte         `json:"aaguid" gorm:"column:aaguid"`
+	SignCount       uint32         `json:"sign_count"`
+	BackupEligible  bool           `json:"backup_eligible"`
+	BackupState     bool           `json:"backup_state"`
+	LastUsedAt      *time.Time     `json:"last_used_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+// UserSession hold a login session of user
+// Access tokens (jwt) are short-lived and carry the session id, so revoking the session invalidates them
+// Refresh token is rotated on every use, only the sha256 hash of the current and previous refresh token is stored
+type UserSession struct {
+	ID                       string     `json:"id" gorm:"primaryKey"`
+	UserID                   uint       `json:"user_id" gorm:"index"`
+	RefreshTokenHash         string     `json:"refresh_token_hash" gorm:"unique"`
+	PreviousRefreshTokenHash string     `json:"previous_refresh_token_hash" gorm:"index"` // to detect reuse of a rotated refresh token
+	IP                       string     `json:"ip"`
+	UserAgent                string     `json:"user_agent"`
+	ExpiresAt                time.Time  `json:"expires_at"`
+	LastRefreshedAt          time.Time  `json:"last_refreshed_at"`
+	RevokedAt                *time.Time `json:"revoked_at"`
+	CreatedAt                time.Time  `json:"created_at"`
+}
+
+// ApplicationGroupPermission hold access of a user on an application group
+type ApplicationGroupPermission struct {
+	ID                 uint        `json:"id" gorm:"primaryKey"`
+	UserID             uint        `json:"user_id" gorm:"uniqueIndex:idx_user_application_group"`
+	ApplicationGroupID string      `json:"application_group_id" gorm:"uniqueIndex:idx_user_application_group"`
+	Access             AccessLevel `json:"access" gorm:"default:'read'"`
 }
 
 // ************************************************************************************* //
@@ -75,9 +145,12 @@ type GitCredential struct {
 	Name          string  `json:"name"`
 	Type          GitType `json:"type" gorm:"default:'http'"`
 	Username      string  `json:"username"`
-	Password      string  `json:"password"`
-	SshPrivateKey string  `json:"ssh_private_key"`
+	Password      string  `json:"password" gorm:"serializer:encrypted"`
+	SshPrivateKey string  `json:"ssh_private_key" gorm:"serializer:encrypted"`
 	SshPublicKey  string  `json:"ssh_public_key"`
+	// Hosts of the repositories, for which the credential can be used (e.g. github.com)
+	// So that the credential is never sent to an arbitrary server
+	AllowedHosts pq.StringArray `json:"allowed_hosts" gorm:"type:text[]"`
 
 	Deployments []Deployment `json:"deployments" gorm:"foreignKey:GitCredentialID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" `
 }
@@ -87,7 +160,7 @@ type ImageRegistryCredential struct {
 	ID          uint         `json:"id" gorm:"primaryKey"`
 	Url         string       `json:"url"`
 	Username    string       `json:"username"`
-	Password    string       `json:"password"`
+	Password    string       `json:"password" gorm:"serializer:encrypted"`
 	Deployments []Deployment `json:"deployments" gorm:"foreignKey:ImageRegistryCredentialID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
@@ -96,7 +169,7 @@ type Domain struct {
 	ID            uint            `json:"id" gorm:"primaryKey"`
 	Name          string          `json:"name" gorm:"unique"`
 	SSLStatus     DomainSSLStatus `json:"ssl_status"`
-	SSLPrivateKey string          `json:"ssl_private_key"`
+	SSLPrivateKey string          `json:"ssl_private_key" gorm:"serializer:encrypted"`
 	SSLFullChain  string          `json:"ssl_full_chain"`
 	SSLIssuedAt   time.Time       `json:"ssl_issued_at"`
 	SSLExpiredAt  time.Time       `json:"ssl_expired_at"`
@@ -188,7 +261,9 @@ type EnvironmentVariable struct {
 	ID            uint   `json:"id" gorm:"primaryKey"`
 	ApplicationID string `json:"application_id"`
 	Key           string `json:"key"`
-	Value         string `json:"value"`
+	Value         string `json:"value" gorm:"serializer:encrypted"`
+	IsSecret      bool   `json:"is_secret" gorm:"default:false"`
+	SecretFileEnv bool   `json:"secret_file_env" gorm:"default:false"` // set <KEY>_FILE env with the path of the secret file
 }
 
 // BuildArg hold information about build args
@@ -199,6 +274,15 @@ type BuildArg struct {
 	Value        string `json:"value"`
 }
 
+// BuildSecret hold information about build secrets
+// Value is taken from the secret environment variable of the application with the same key at build time,
+// and available to `RUN --mount=type=secret,id=<key>` only, so it doesn't end up in the image history
+type BuildSecret struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	DeploymentID string `json:"deployment_id"`
+	Key          string `json:"key"`
+}
+
 // ConfigMount hold information of config mount
 type ConfigMount struct {
 	ID            uint   `json:"id" gorm:"primaryKey"`
@@ -213,11 +297,12 @@ type ConfigMount struct {
 
 // ApplicationGroup hold information about application-group
 type ApplicationGroup struct {
-	ID           string        `json:"id" gorm:"primaryKey"`
-	Name         string        `json:"name"`
-	Logo         string        `json:"logo"`
-	StackContent string        `json:"stack_content"`
-	Applications []Application `json:"applications" gorm:"foreignKey:ApplicationGroupID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ID           string                       `json:"id" gorm:"primaryKey"`
+	Name         string                       `json:"name"`
+	Logo         string                       `json:"logo"`
+	StackContent string                       `json:"stack_content"`
+	Applications []Application                `json:"applications" gorm:"foreignKey:ApplicationGroupID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Permissions  []ApplicationGroupPermission `json:"permissions" gorm:"foreignKey:ApplicationGroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 // Application hold information about application
@@ -262,6 +347,10 @@ type Application struct {
 	IsDeleted bool `json:"is_deleted" gorm:"default:false"`
 	// Webhook token
 	WebhookToken string `json:"webhook_token"`
+	// Webhook secret - used by git providers to sign the payload
+	WebhookSecret string `json:"webhook_secret" gorm:"serializer:encrypted"`
+	// Allow unsigned webhooks - opt-in to accept git webhooks without signature, only the webhook token is checked then
+	AllowUnsignedWebhooks bool `json:"allow_unsigned_webhooks" gorm:"default:false"`
 	// Sleeping
 	IsSleeping bool `json:"is_sleeping" gorm:"default:false"`
 	// Resource Stats
@@ -272,6 +361,32 @@ type Application struct {
 	CustomHealthCheck ApplicationCustomHealthCheck `json:"custom_health_check" gorm:"embedded;embeddedPrefix:custom_health_check_"`
 	// DockerProxy configuration
 	DockerProxy DockerProxyConfig `json:"docker_proxy" gorm:"embedded;embeddedPrefix:docker_proxy_"`
+	// AutoRollback - rollback to the previous version if the new version doesn't become healthy after deployment
+	AutoRollback ApplicationAutoRollback `json:"auto_rollback" gorm:"embedded;embeddedPrefix:auto_rollback_"`
+	// UpdateConfig - rolling update strategy of the service
+	UpdateConfig ApplicationUpdateConfig `json:"update_config" gorm:"embedded;embeddedPrefix:update_config_"`
+	// DeploymentStrategy - rolling update or run the new version as candidate next to the current version
+	DeploymentStrategy DeploymentStrategy `json:"deployment_strategy" gorm:"default:'rolling'"`
+	// CandidateDeploymentID - deployment running as candidate, for blue-green or canary strategy
+	CandidateDeploymentID *string `json:"candidate_deployment_id" gorm:"default:null"`
+	// CandidateTrafficPercent - share of the traffic routed to the candidate
+	CandidateTrafficPercent uint `json:"candidate_traffic_percent" gorm:"default:0"`
+	// ReleaseCommands - one-off commands to run with the new version, before and after the rollout
+	ReleaseCommands ApplicationReleaseCommands `json:"release_commands" gorm:"embedded;embeddedPrefix:release_commands_"`
+	// JobConfig - schedule and timeout, for cron job and run once job
+	JobConfig ApplicationJobConfig `json:"job_config" gorm:"embedded;embeddedPrefix:job_"`
+	// JobRuns - history of the runs of job application
+	JobRuns []ApplicationJobRun `json:"job_runs" gorm:"foreignKey:ApplicationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// RetentionPolicy - how long old deployments and their images are kept
+	RetentionPolicy ApplicationRetentionPolicy `json:"retention_policy" gorm:"embedded;embeddedPrefix:retention_"`
+	// GitPolling - rebuild on new commits in the branch, for repositories which can't reach the webhook
+	GitPolling ApplicationGitPolling `json:"git_polling" gorm:"embedded;embeddedPrefix:git_polling_"`
+	// PreviewConfig - temporary deployment for each pull request
+	PreviewConfig ApplicationPreviewConfig `json:"preview_config" gorm:"embedded;embeddedPrefix:preview_"`
+	// PreviewEnvironmentVariables - overrides of the environment variables in the preview environments
+	PreviewEnvironmentVariables []PreviewEnvironmentVariable `json:"preview_environment_variables" gorm:"foreignKey:ApplicationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// Previews - preview environments of the open pull requests
+	Previews []ApplicationPreview `json:"previews" gorm:"foreignKey:ApplicationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 // Deployment hold information about deployment of application
@@ -291,16 +406,31 @@ type Deployment struct {
 	CodePath         string  `json:"code_path"`
 	CommitHash       string  `json:"commit_hash"`
 	CommitMessage    string  `json:"commit_message"`
+	// GitReferenceType - branch, tag or commit to deploy, commit is set in CommitHash
+	GitReferenceType GitReferenceType `json:"git_reference_type" gorm:"default:'branch'"`
+	RepositoryTag    string           `json:"repository_tag"`
+	GitTagPattern    string           `json:"git_tag_pattern"` // glob pattern e.g. v*, newer tags matching it are deployed automatically
+	GitCloneConfig   GitCloneConfig   `json:"git_clone_config" gorm:"embedded;embeddedPrefix:git_clone_"`
 	// Fields for UpstreamType = SourceCode
 	SourceCodeCompressedFileName string `json:"source_code_compressed_file_name"`
 	// Fields for UpstreamType = Image
 	DockerImage               string `json:"docker_image"`
 	ImageRegistryCredentialID *uint  `json:"image_registry_credential_id"`
+	// ImageDigest - digest of the docker image resolved while building the deployment
+	// Tag of the image can be moved, so the deployment is pinned to the digest to redeploy or rollback the same image
+	ImageDigest string `json:"image_digest"`
 	// Common Fields
-	BuildArgs  []BuildArg `json:"build_args" gorm:"foreignKey:DeploymentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Dockerfile string     `json:"dockerfile"`
+	BuildArgs    []BuildArg    `json:"build_args" gorm:"foreignKey:DeploymentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	BuildSecrets []BuildSecret `json:"build_secrets" gorm:"foreignKey:DeploymentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Dockerfile   string        `json:"dockerfile"`
+	// DockerfileTarget - stage of the multi-stage Dockerfile to build, empty to build the last stage
+	DockerfileTarget string `json:"dockerfile_target"`
 	// Logs
 	Logs []DeploymentLog `json:"logs" gorm:"foreignKey:DeploymentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// OriginalDeploymentID - set for rollback, the image built for the original deployment is reused
+	OriginalDeploymentID *string `json:"original_deployment_id" gorm:"default:null"`
+	// ConfigSnapshot - json of DeploymentConfigSnapshot, the application config with which it was last deployed
+	ConfigSnapshot string `json:"config_snapshot" gorm:"serializer:encrypted"`
 	// Deployment Status
 	Status DeploymentStatus `json:"status"`
 	// Created At
@@ -315,6 +445,49 @@ type DeploymentLog struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+// ApplicationJobRun hold information about a run of job application
+// Each run uses the deployment which was live when the run was triggered
+type ApplicationJobRun struct {
+	ID            uint          `json:"id" gorm:"primaryKey"`
+	ApplicationID string        `json:"application_id" gorm:"index"`
+	DeploymentID  string        `json:"deployment_id"`
+	Trigger       JobRunTrigger `json:"trigger"`
+	Status        JobRunStatus  `json:"status"`
+	ExitCode      int           `json:"exit_code"`
+	Logs          string        `json:"logs"`
+	CreatedAt     time.Time     `json:"created_at"`
+	StartedAt     *time.Time    `json:"started_at"`
+	CompletedAt   *time.Time    `json:"completed_at"`
+}
+
+// PreviewEnvironmentVariable hold the override of environment variable in the preview environments
+// Value can contain {{PR_NUMBER}}, {{PR_BRANCH}} and {{PREVIEW_DOMAIN}} placeholders
+// Override of a secret environment variable is always treated as secret
+type PreviewEnvironmentVariable struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	ApplicationID string `json:"application_id" gorm:"index"`
+	Key           string `json:"key"`
+	Value         string `json:"value" gorm:"serializer:encrypted"`
+	IsSecret      bool   `json:"is_secret" gorm:"default:false"`
+}
+
+// ApplicationPreview hold information about the preview environment of a pull request
+// The preview is deployed as a separate application, cloned from the application
+type ApplicationPreview struct {
+	ID                   uint                     `json:"id" gorm:"primaryKey"`
+	ApplicationID        string                   `json:"application_id" gorm:"uniqueIndex:idx_application_preview_pull_request"`
+	PullRequestNumber    uint                     `json:"pull_request_number" gorm:"uniqueIndex:idx_application_preview_pull_request"`
+	PreviewApplicationID string                   `json:"preview_application_id"`
+	Branch               string                   `json:"branch"`
+	CommitHash           string                   `json:"commit_hash"`
+	Domain               string                   `json:"domain"`
+	DomainID             *uint                    `json:"domain_id"`
+	IngressRuleID        *uint                    `json:"ingress_rule_id"`
+	Status               ApplicationPreviewStatus `json:"status"`
+	CreatedAt            time.Time                `json:"created_at"`
+	UpdatedAt            time.Time                `json:"updated_at"`
+}
+
 // ConsoleToken hold information about console auth tokens, used in establishing websocket connection
 // Note this
 // If Target == ConsoleTargetTypeServer, ServerID denote which server to ssh into
@@ -330,6 +503,45 @@ type ConsoleToken struct {
 	ExpiresAt     time.Time     `json:"expires_at"`
 }
 
+// PersonalAccessToken hold named api tokens of user, can be used in place of jwt token
+// Only the sha256 hash of the token is stored, the token is shown once at creation time
+// If ExpiresAt is nil, the token never expires
+type PersonalAccessToken struct {
+	ID         string         `json:"id" gorm:"primaryKey"`
+	UserID     uint           `json:"user_id" gorm:"uniqueIndex:idx_user_personal_access_token_name"`
+	Name       string         `json:"name" gorm:"uniqueIndex:idx_user_personal_access_token_name"`
+	TokenHash  string         `json:"token_hash" gorm:"unique"`
+	Scopes     pq.StringArray `json:"scopes" gorm:"type:text[]"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// AuditLog hold the record of a mutating action performed by user
+// Arguments are stored in sanitized form (json), secrets are redacted before saving
+type AuditLog struct {
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	Username  string          `json:"username" gorm:"index"`
+	IP        string          `json:"ip"`
+	Source    AuditLogSource  `json:"source"`
+	Action    string          `json:"action" gorm:"index"`
+	TargetID  string          `json:"target_id" gorm:"index"`
+	Arguments string          `json:"arguments"`
+	Outcome   AuditLogOutcome `json:"outcome"`
+	Error     string          `json:"error"`
+	CreatedAt time.Time       `json:"created_at" gorm:"index"`
+}
+
+// AuthThrottle hold the failed login attempts of an ip or username to slow down brute-force attacks
+// Key is in the format <ip|username>:<value>, e.g. ip:10.0.0.1 or username:admin
+type AuthThrottle struct {
+	Key            string     `json:"key" gorm:"primaryKey"`
+	FailedAttempts uint       `json:"failed_attempts" gorm:"default:0"`
+	LastFailedAt   time.Time  `json:"last_failed_at"`
+	BlockedUntil   time.Time  `json:"blocked_until"`
+	LockedUntil    *time.Time `json:"locked_until"` // set when the account is locked out, only for username
+}
+
 type AnalyticsServiceToken struct {
 	ID        string    `json:"id" gorm:"primaryKey"`
 	Token     string    `json:"token" gorm:"unique"`
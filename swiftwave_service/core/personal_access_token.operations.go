@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/gommon/random"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// This file contains the operations for the PersonalAccessToken model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+// PersonalAccessTokenPrefix : prefix of personal access tokens, used to distinguish them from jwt tokens
+const PersonalAccessTokenPrefix = "swp_"
+
+// IsPersonalAccessToken : check if the token is a personal access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+func hashPersonalAccessToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// FindPersonalAccessTokensByUserID : find all personal access tokens of user
+func FindPersonalAccessTokensByUserID(_ context.Context, db gorm.DB, userID uint) ([]*PersonalAccessToken, error) {
+	var tokens []*PersonalAccessToken
+	err := db.Where("user_id = ?", userID).Order("created_at desc").Find(&tokens).Error
+	return tokens, err
+}
+
+func (token *PersonalAccessToken) FindById(_ context.Context, db gorm.DB, id string) error {
+	return db.Where("id = ?", id).First(token).Error
+}
+
+// Create : create a new personal access token and return the plain token
+func (token *PersonalAccessToken) Create(ctx context.Context, db gorm.DB) (string, error) {
+	token.Name = strings.TrimSpace(token.Name)
+	if token.Name == "" {
+		return "", errors.New("name of token cannot be empty")
+	}
+	if _, err := FindUserByID(ctx, db, token.UserID); err != nil {
+		return "", errors.New("user not found")
+	}
+	// if no scope provided, allow everything
+	if len(token.Scopes) == 0 {
+		token.Scopes = []string{string(PersonalAccessTokenScopeRead), string(PersonalAccessTokenScopeWrite)}
+	}
+	for _, scope := range token.Scopes {
+		if !PersonalAccessTokenScope(scope).IsValid() {
+			return "", errors.New("invalid scope > " + scope)
+		}
+	}
+	if token.ExpiresAt != nil && token.ExpiresAt.Before(time.Now()) {
+		return "", errors.New("expiry time should be in future")
+	}
+	// check for duplicate name
+	var count int64
+	err := db.Model(&PersonalAccessToken{}).Where("user_id = ? AND name = ?", token.UserID, token.Name).Count(&count).Error
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", errors.New("token with same name already exists")
+	}
+	plainToken := PersonalAccessTokenPrefix + random.String(48)
+	token.ID = uuid.NewString()
+	token.TokenHash = hashPersonalAccessToken(plainToken)
+	token.LastUsedAt = nil
+	if err := db.Create(token).Error; err != nil {
+		return "", err
+	}
+	return plainToken, nil
+}
+
+func (token *PersonalAccessToken) Delete(_ context.Context, db gorm.DB) error {
+	return db.Delete(token).Error
+}
+
+// HasScope : check if the token has the scope
+func (token *PersonalAccessToken) HasScope(scope PersonalAccessTokenScope) bool {
+	for _, s := range token.Scopes {
+		if PersonalAccessTokenScope(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired : check if the token has been expired
+func (token *PersonalAccessToken) IsExpired() bool {
+	return token.ExpiresAt != nil && token.ExpiresAt.Before(time.Now())
+}
+
+// ValidatePersonalAccessToken : validate the plain token and return the token record with the owner
+func ValidatePersonalAccessToken(ctx context.Context, db gorm.DB, plainToken string) (*PersonalAccessToken, *User, error) {
+	if !IsPersonalAccessToken(plainToken) {
+		return nil, nil, errors.New("invalid personal access token")
+	}
+	token := &PersonalAccessToken{}
+	err := db.Where("token_hash = ?", hashPersonalAccessToken(plainToken)).First(token).Error
+	if err != nil {
+		return nil, nil, errors.New("invalid personal access token")
+	}
+	if token.IsExpired() {
+		return nil, nil, errors.New("personal access token expired")
+	}
+	user, err := FindUserByID(ctx, db, token.UserID)
+	if err != nil {
+		return nil, nil, errors.New("invalid personal access token")
+	}
+	// update last used time, failure is not critical
+	now := time.Now()
+	if db.Model(token).Update("last_used_at", now).Error == nil {
+		token.LastUsedAt = &now
+	}
+	return token, &user, nil
+}
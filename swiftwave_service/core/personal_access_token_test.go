@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersonalAccessToken(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &User{}, &PersonalAccessToken{})
+	user := createTestUser(t, db, "ci", ManagerRole)
+
+	t.Run("token is validated and returns the owner", func(t *testing.T) {
+		token := &PersonalAccessToken{UserID: user.ID, Name: "deploy"}
+		plainToken, err := token.Create(ctx, db)
+		assert.NoError(t, err)
+		assert.True(t, IsPersonalAccessToken(plainToken))
+		assert.NotContains(t, token.TokenHash, plainToken)
+
+		record, owner, err := ValidatePersonalAccessToken(ctx, db, plainToken)
+		assert.NoError(t, err)
+		assert.Equal(t, token.ID, record.ID)
+		assert.Equal(t, user.Username, owner.Username)
+		assert.NotNil(t, record.LastUsedAt)
+	})
+
+	t.Run("all scopes are granted if no scope provided", func(t *testing.T) {
+		token := &PersonalAccessToken{UserID: user.ID, Name: "all-scopes"}
+		_, err := token.Create(ctx, db)
+		assert.NoError(t, err)
+		assert.True(t, token.HasScope(PersonalAccessTokenScopeRead))
+		assert.True(t, token.HasScope(PersonalAccessTokenScopeWrite))
+	})
+
+	t.Run("read only token doesn't have write scope", func(t *testing.T) {
+		token := &PersonalAccessToken{UserID: user.ID, Name: "read-only", Scopes: []string{string(PersonalAccessTokenScopeRead)}}
+		plainToken, err := token.Create(ctx, db)
+		assert.NoError(t, err)
+		record, _, err := ValidatePersonalAccessToken(ctx, db, plainToken)
+		assert.NoError(t, err)
+		assert.True(t, record.HasScope(PersonalAccessTokenScopeRead))
+		assert.False(t, record.HasScope(PersonalAccessTokenScopeWrite))
+	})
+
+	t.Run("invalid scope is rejected", func(t *testing.T) {
+		token := &PersonalAccessToken{UserID: user.ID, Name: "admin", Scopes: []string{"admin"}}
+		_, err := token.Create(ctx, db)
+		assert.Error(t, err)
+	})
+
+	t.Run("expired token is rejected", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		token := &PersonalAccessToken{UserID: user.ID, Name: "short-lived", ExpiresAt: &expiresAt}
+		plainToken, err := token.Create(ctx, db)
+		assert.NoError(t, err)
+		_, _, err = ValidatePersonalAccessToken(ctx, db, plainToken)
+		assert.NoError(t, err)
+
+		assert.NoError(t, db.Model(token).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+		_, _, err = ValidatePersonalAccessToken(ctx, db, plainToken)
+		assert.EqualError(t, err, "personal access token expired")
+	})
+
+	t.Run("token can't be created with expiry in past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		token := &PersonalAccessToken{UserID: user.ID, Name: "expired", ExpiresAt: &expiresAt}
+		_, err := token.Create(ctx, db)
+		assert.Error(t, err)
+	})
+
+	t.Run("revoked token is rejected", func(t *testing.T) {
+		token := &PersonalAccessToken{UserID: user.ID, Name: "revoked"}
+		plainToken, err := token.Create(ctx, db)
+		assert.NoError(t, err)
+		assert.NoError(t, token.Delete(ctx, db))
+		_, _, err = ValidatePersonalAccessToken(ctx, db, plainToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown token is rejected", func(t *testing.T) {
+		_, _, err := ValidatePersonalAccessToken(ctx, db, PersonalAccessTokenPrefix+"unknown")
+		assert.Error(t, err)
+		_, _, err = ValidatePersonalAccessToken(ctx, db, "eyJhbGciOiJIUzI1NiJ9")
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicate name is rejected", func(t *testing.T) {
+		token := &PersonalAccessToken{UserID: user.ID, Name: "deploy"}
+		_, err := token.Create(ctx, db)
+		assert.Error(t, err)
+	})
+}
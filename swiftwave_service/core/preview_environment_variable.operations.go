@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// This file contains the operations for the PreviewEnvironmentVariable model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+func FindPreviewEnvironmentVariablesByApplicationId(_ context.Context, db gorm.DB, applicationId string) ([]*PreviewEnvironmentVariable, error) {
+	var environmentVariables = make([]*PreviewEnvironmentVariable, 0)
+	tx := db.Where("application_id = ?", applicationId).Order("id").Find(&environmentVariables)
+	return environmentVariables, tx.Error
+}
+
+// ReplacePreviewEnvironmentVariables : replace all the overrides of the application
+// Override of a secret environment variable of the application is stored as secret
+// If the masked value of a secret override is submitted back, the stored value is kept as it is and stays secret
+func ReplacePreviewEnvironmentVariables(ctx context.Context, db gorm.DB, applicationId string, environmentVariables []PreviewEnvironmentVariable) error {
+	applicationEnvironmentVariables, err := FindEnvironmentVariablesByApplicationId(ctx, db, applicationId)
+	if err != nil {
+		return err
+	}
+	secretKeys := make(map[string]bool)
+	for _, environmentVariable := range applicationEnvironmentVariables {
+		if environmentVariable.IsSecret {
+			secretKeys[environmentVariable.Key] = true
+		}
+	}
+	existingEnvironmentVariables, err := FindPreviewEnvironmentVariablesByApplicationId(ctx, db, applicationId)
+	if err != nil {
+		return err
+	}
+	existingSecretValues := make(map[string]string)
+	for _, environmentVariable := range existingEnvironmentVariables {
+		if environmentVariable.IsSecret || secretKeys[environmentVariable.Key] {
+			existingSecretValues[environmentVariable.Key] = environmentVariable.Value
+		}
+	}
+	keys := make(map[string]bool)
+	records := make([]PreviewEnvironmentVariable, 0)
+	for _, environmentVariable := range environmentVariables {
+		key := strings.TrimSpace(environmentVariable.Key)
+		if key == "" {
+			return errors.New("key of preview environment variable can't be empty")
+		}
+		if keys[key] {
+			return errors.New("duplicate preview environment variable " + key)
+		}
+		keys[key] = true
+		isSecret := environmentVariable.IsSecret || secretKeys[key]
+		value := environmentVariable.Value
+		if existingValue, ok := existingSecretValues[key]; ok && value == SecretEnvironmentVariableMask {
+			// kept value is still a secret, even if the override is not marked as secret anymore
+			value = existingValue
+			isSecret = true
+		}
+		if isSecret && !secretEnvironmentVariableKeyRegex.MatchString(key) {
+			return errors.New("secret preview environment variable key can contain only alphanumeric characters, '_', '.' and '-'")
+		}
+		records = append(records, PreviewEnvironmentVariable{
+			ApplicationID: applicationId,
+			Key:           key,
+			Value:         value,
+			IsSecret:      isSecret,
+		})
+	}
+	tx := db.Delete(&PreviewEnvironmentVariable{}, "application_id = ?", applicationId)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	tx = db.Create(&records)
+	return tx.Error
+}
@@ -134,6 +134,25 @@ func FetchAllOnlineServers(db *gorm.DB) ([]Server, error) {
 	return servers, err
 }
 
+// FetchBuildServers fetches all online build servers, which are not in maintenance mode
+func FetchBuildServers(db *gorm.DB) ([]Server, error) {
+	var servers []Server
+	err := db.Where("status = ?", ServerOnline).Where("is_build_node = ?", true).Where("maintenance_mode = ?", false).Order("id").Find(&servers).Error
+	return servers, err
+}
+
+// IsAnyBuildServerConfigured checks if any server has been marked as build server irrespective of status
+func IsAnyBuildServerConfigured(db *gorm.DB) (bool, error) {
+	var count int64
+	err := db.Model(&Server{}).Where("is_build_node = ?", true).Count(&count).Error
+	return count > 0, err
+}
+
+// ChangeBuildNodeConfig changes the build node config of server in the database
+func ChangeBuildNodeConfig(db *gorm.DB, server *Server, isBuildNode bool, maxConcurrentBuilds uint) error {
+	return db.Model(server).Updates(map[string]interface{}{"is_build_node": isBuildNode, "max_concurrent_builds": maxConcurrentBuilds}).Error
+}
+
 // FetchSwarmManager fetches the swarm manager from the database
 func FetchSwarmManager(db *gorm.DB) (Server, error) {
 	var server Server
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// This file contains the operations for the TotpRecoveryCode model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+// totpRecoveryCodesCount : number of recovery codes generated for the user
+const totpRecoveryCodesCount = 10
+
+var totpRecoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func hashTotpRecoveryCode(code string) string {
+	hash := sha256.Sum256([]byte(normalizeTotpRecoveryCode(code)))
+	return hex.EncodeToString(hash[:])
+}
+
+// normalizeTotpRecoveryCode : allow the code to be entered in any case and with or without the separator
+func normalizeTotpRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// GenerateTotpRecoveryCodes : replace the existing recovery codes of the user with new ones
+// Returns the plain codes, which should be shown to the user only once
+func GenerateTotpRecoveryCodes(_ context.Context, db gorm.DB, userID uint) ([]string, error) {
+	codes := make([]string, 0, totpRecoveryCodesCount)
+	records := make([]TotpRecoveryCode, 0, totpRecoveryCodesCount)
+	for i := 0; i < totpRecoveryCodesCount; i++ {
+		randomBytes := make([]byte, 10)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(totpRecoveryCodeEncoding.EncodeToString(randomBytes))
+		code = code[:8] + "-" + code[8:]
+		codes = append(codes, code)
+		records = append(records, TotpRecoveryCode{
+			UserID:   userID,
+			CodeHash: hashTotpRecoveryCode(code),
+		})
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&TotpRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseTotpRecoveryCode : consume the recovery code of the user, returns false if code is invalid or already used
+func UseTotpRecoveryCode(_ context.Context, db gorm.DB, userID uint, code string) (bool, error) {
+	if normalizeTotpRecoveryCode(code) == "" {
+		return false, nil
+	}
+	tx := db.Model(&TotpRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashTotpRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+// CountUnusedTotpRecoveryCodes : number of recovery codes left for the user
+func CountUnusedTotpRecoveryCodes(_ context.Context, db gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&TotpRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// DeleteTotpRecoveryCodesByUserID : delete all the recovery codes of the user
+func DeleteTotpRecoveryCodesByUserID(_ context.Context, db gorm.DB, userID uint) error {
+	return db.Where("user_id = ?", userID).Delete(&TotpRecoveryCode{}).Error
+}
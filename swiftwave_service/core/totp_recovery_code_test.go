@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTotpRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &User{}, &TotpRecoveryCode{})
+	user := createTestUser(t, db, "admin", AdministratorRole)
+
+	codes, err := GenerateTotpRecoveryCodes(ctx, db, user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, codes, totpRecoveryCodesCount)
+	unique := make(map[string]bool)
+	for _, code := range codes {
+		assert.Len(t, code, 17)
+		unique[code] = true
+	}
+	assert.Len(t, unique, totpRecoveryCodesCount)
+
+	// only the hash is stored
+	var records []TotpRecoveryCode
+	assert.NoError(t, db.Where("user_id = ?", user.ID).Find(&records).Error)
+	for _, record := range records {
+		assert.False(t, unique[record.CodeHash])
+	}
+}
+
+func TestUseTotpRecoveryCode(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &User{}, &TotpRecoveryCode{})
+	user := createTestUser(t, db, "admin", AdministratorRole)
+	otherUser := createTestUser(t, db, "viewer", ViewerRole)
+	codes, err := GenerateTotpRecoveryCodes(ctx, db, user.ID)
+	assert.NoError(t, err)
+
+	t.Run("code can be used only once", func(t *testing.T) {
+		used, err := UseTotpRecoveryCode(ctx, db, user.ID, codes[0])
+		assert.NoError(t, err)
+		assert.True(t, used)
+		used, err = UseTotpRecoveryCode(ctx, db, user.ID, codes[0])
+		assert.NoError(t, err)
+		assert.False(t, used)
+		count, err := CountUnusedTotpRecoveryCodes(ctx, db, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(totpRecoveryCodesCount-1), count)
+	})
+
+	t.Run("code is normalized", func(t *testing.T) {
+		used, err := UseTotpRecoveryCode(ctx, db, user.ID, " "+strings.ToUpper(strings.ReplaceAll(codes[1], "-", ""))+" ")
+		assert.NoError(t, err)
+		assert.True(t, used)
+	})
+
+	t.Run("code of other user is rejected", func(t *testing.T) {
+		used, err := UseTotpRecoveryCode(ctx, db, otherUser.ID, codes[2])
+		assert.NoError(t, err)
+		assert.False(t, used)
+	})
+
+	t.Run("empty and unknown codes are rejected", func(t *testing.T) {
+		for _, code := range []string{"", "  ", "-", "aaaaaaaa-aaaaaaaa"} {
+			used, err := UseTotpRecoveryCode(ctx, db, user.ID, code)
+			assert.NoError(t, err)
+			assert.False(t, used, code)
+		}
+	})
+
+	t.Run("regenerating invalidates existing codes", func(t *testing.T) {
+		newCodes, err := GenerateTotpRecoveryCodes(ctx, db, user.ID)
+		assert.NoError(t, err)
+		used, err := UseTotpRecoveryCode(ctx, db, user.ID, codes[3])
+		assert.NoError(t, err)
+		assert.False(t, used)
+		used, err = UseTotpRecoveryCode(ctx, db, user.ID, newCodes[0])
+		assert.NoError(t, err)
+		assert.True(t, used)
+	})
+
+	t.Run("disabling totp deletes the codes", func(t *testing.T) {
+		assert.NoError(t, DisableTotp(ctx, db, user.Username))
+		count, err := CountUnusedTotpRecoveryCodes(ctx, db, user.ID)
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
+
+func TestEnableTotp(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &User{}, &TotpRecoveryCode{})
+	user := createTestUser(t, db, "admin", AdministratorRole)
+
+	codes, err := EnableTotp(ctx, db, user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, codes, totpRecoveryCodesCount)
+	updatedUser, err := FindUserByID(ctx, db, user.ID)
+	assert.NoError(t, err)
+	assert.True(t, updatedUser.TotpEnabled)
+	count, err := CountUnusedTotpRecoveryCodes(ctx, db, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(totpRecoveryCodesCount), count)
+
+	_, err = EnableTotp(ctx, db, user.ID+100)
+	assert.Error(t, err)
+}
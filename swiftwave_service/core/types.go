@@ -18,8 +18,70 @@ const (
 	// ManagerRole : manager user can perform any operation on the system
 	// except user management, system configuration and server management
 	ManagerRole UserRole = "manager"
+	// ViewerRole : viewer user can only see the resources, but can't mutate anything
+	ViewerRole UserRole = "viewer"
 )
 
+// privilegeLevel : higher value means more privileged role
+func (r UserRole) privilegeLevel() int {
+	switch r {
+	case AdministratorRole:
+		return 3
+	case ManagerRole:
+		return 2
+	case ViewerRole:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AccessLevel : level of access granted on a resource
+type AccessLevel string
+
+const (
+	ReadAccess  AccessLevel = "read"
+	WriteAccess AccessLevel = "write"
+)
+
+// IsValid : check if the access level is a known access level
+func (a AccessLevel) IsValid() bool {
+	return a == ReadAccess || a == WriteAccess
+}
+
+// Allows : check if the access level is sufficient for the required access level
+func (a AccessLevel) Allows(required AccessLevel) bool {
+	if a == WriteAccess {
+		return true
+	}
+	return a == ReadAccess && required == ReadAccess
+}
+
+// IsValid : check if the role is a known role
+func (r UserRole) IsValid() bool {
+	return r.privilegeLevel() > 0
+}
+
+// IsMorePrivilegedThan : check if the role has more privilege than the other role
+func (r UserRole) IsMorePrivilegedThan(other UserRole) bool {
+	return r.privilegeLevel() > other.privilegeLevel()
+}
+
+// PersonalAccessTokenScope : operations allowed with a personal access token
+type PersonalAccessTokenScope string
+
+const (
+	// PersonalAccessTokenScopeRead : allow queries, subscriptions and GET requests
+	PersonalAccessTokenScopeRead PersonalAccessTokenScope = "read"
+	// PersonalAccessTokenScopeWrite : allow mutations and non-GET requests
+	PersonalAccessTokenScopeWrite PersonalAccessTokenScope = "write"
+)
+
+// IsValid : check if the scope is a known scope
+func (s PersonalAccessTokenScope) IsValid() bool {
+	return s == PersonalAccessTokenScopeRead || s == PersonalAccessTokenScopeWrite
+}
+
 // ServerStatus : status of the server
 type ServerStatus string
 
@@ -86,6 +148,7 @@ const (
 	DeploymentStatusStopped       DeploymentStatus = "stopped"
 	DeploymentStatusFailed        DeploymentStatus = "failed"
 	DeploymentStalled             DeploymentStatus = "stalled"
+	DeploymentStatusCandidate     DeploymentStatus = "candidate"
 )
 
 // GitType type of git credential
@@ -96,6 +159,25 @@ const (
 	GitSsh  GitType = "ssh"
 )
 
+// GitReferenceType : git reference, which is deployed
+type GitReferenceType string
+
+const (
+	// GitReferenceBranch : latest commit of the branch
+	GitReferenceBranch GitReferenceType = "branch"
+	// GitReferenceTag : commit of the tag, newer tags matching the pattern are deployed automatically
+	GitReferenceTag GitReferenceType = "tag"
+	// GitReferenceCommit : the commit, pinned
+	GitReferenceCommit GitReferenceType = "commit"
+)
+
+// GitCloneConfig : features of the git clone, while building the deployment
+type GitCloneConfig struct {
+	Submodules bool `json:"submodules"` // checkout the submodules recursively, git credential is used only for the submodules on its allowed hosts
+	LFS        bool `json:"lfs"`        // fetch the files tracked by git lfs
+	Shallow    bool `json:"shallow"`    // clone only the commit to build, instead of the whole history
+}
+
 // ProtocolType : type of protocol for ingress rule
 type ProtocolType string
 
@@ -142,12 +224,40 @@ const (
 	RedirectRuleStatusDeleting RedirectRuleStatus = "deleting"
 )
 
-// DeploymentMode : mode of deployment of application (replicated or global)
+// DeploymentMode : mode of deployment of application (replicated, global or job)
 type DeploymentMode string
 
 const (
 	DeploymentModeReplicated DeploymentMode = "replicated"
 	DeploymentModeGlobal     DeploymentMode = "global"
+	// DeploymentModeCronJob : run to completion on the schedule, no long-running service
+	DeploymentModeCronJob DeploymentMode = "cron_job"
+	// DeploymentModeRunOnceJob : run to completion on each trigger, no long-running service
+	DeploymentModeRunOnceJob DeploymentMode = "run_once_job"
+)
+
+// ApplicationJobConfig : configuration of job applications
+type ApplicationJobConfig struct {
+	Schedule       string `json:"schedule"`                            // Cron expression, only for cron job
+	TimeoutSeconds uint   `json:"timeout_seconds" gorm:"default:3600"` // Maximum time to allow each run, run is marked as failed afterwards
+}
+
+// JobRunStatus : status of a run of job application
+type JobRunStatus string
+
+const (
+	JobRunStatusPending   JobRunStatus = "pending"
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+)
+
+// JobRunTrigger : what started the run of job application
+type JobRunTrigger string
+
+const (
+	JobRunTriggerSchedule JobRunTrigger = "schedule"
+	JobRunTriggerManual   JobRunTrigger = "manual"
 )
 
 // ApplicationUpdateResult : result of application update
@@ -217,7 +327,7 @@ type CIFSConfig struct {
 	Share    string `json:"share"`
 	Host     string `json:"host"`
 	Username string `json:"username"`
-	Password string `json:"password"`
+	Password string `json:"password" gorm:"serializer:encrypted"`
 	FileMode string `json:"file_mode"`
 	DirMode  string `json:"dir_mode"`
 	Uid      int    `json:"uid" gorm:"default:0"`
@@ -287,6 +397,23 @@ const (
 	ConsoleTargetTypeApplication ConsoleTarget = "application"
 )
 
+// AuditLogSource : interface through which the action was performed
+type AuditLogSource string
+
+const (
+	AuditLogSourceGraphql AuditLogSource = "graphql"
+	AuditLogSourceRest    AuditLogSource = "rest"
+	AuditLogSourceConsole AuditLogSource = "console"
+)
+
+// AuditLogOutcome : outcome of the audited action
+type AuditLogOutcome string
+
+const (
+	AuditLogOutcomeSuccess AuditLogOutcome = "success"
+	AuditLogOutcomeFailure AuditLogOutcome = "failure"
+)
+
 // ************************************************************************************* //
 //                              	Server Related Stats       		   			         //
 // ************************************************************************************* //
@@ -356,6 +483,93 @@ type ApplicationCustomHealthCheck struct {
 	Retries              uint64 `json:"retries" gorm:"default:0"`                // Consecutive failures needed to report unhealthy
 }
 
+// ApplicationAutoRollback : watch the tasks of the new version for the window after each deployment
+// If the new version doesn't become healthy within the window, the service is rolled back to the previous version
+type ApplicationAutoRollback struct {
+	Enabled       bool `json:"enabled"`
+	WindowSeconds uint `json:"window_seconds" gorm:"default:120"`
+}
+
+// ApplicationReleaseCommands : commands run as one-off job with the image, environment and volumes of the new version
+// Pre-deploy command runs before the service is updated, the deployment fails if it exits with non-zero code
+// Post-deploy command runs after the rollout, failure is only reported in the deployment log
+type ApplicationReleaseCommands struct {
+	PreDeploy      string `json:"pre_deploy"`
+	PostDeploy     string `json:"post_deploy"`
+	TimeoutSeconds uint   `json:"timeout_seconds" gorm:"default:600"` // Maximum time to allow each command to run
+}
+
+// ApplicationRetentionPolicy : retention of old deployments, their logs and images of the application
+// Zero value of a field means the system wide retention config is used
+// The currently deployed deployment and the rollback targets are always kept
+type ApplicationRetentionPolicy struct {
+	KeepLastDeployments uint `json:"keep_last_deployments"` // Keep only the last N deployments
+	KeepDays            uint `json:"keep_days"`             // Keep deployments only for D days
+}
+
+// ApplicationGitPolling : poll the git branch of the application periodically, and rebuild if there is a new commit
+type ApplicationGitPolling struct {
+	Enabled         bool `json:"enabled"`
+	IntervalSeconds uint `json:"interval_seconds" gorm:"default:300"`
+}
+
+// ApplicationPreviewConfig : temporary deployment of the application for each open pull request
+// Preview is exposed at pr-<number>.<base domain>, wildcard DNS record of the base domain should point to the proxy servers
+type ApplicationPreviewConfig struct {
+	Enabled    bool   `json:"enabled" gorm:"default:false"`
+	BaseDomain string `json:"base_domain"` // e.g. preview.example.com
+	TargetPort uint   `json:"target_port"` // port of the application, to route the traffic
+	// Code of the pull request is not reviewed yet, so secrets and release commands are not copied by default
+	// Secret environment variables can be provided to the previews as overrides as well
+	CopySecrets        bool `json:"copy_secrets" gorm:"default:false"`         // copy the secret environment variables of the application
+	RunReleaseCommands bool `json:"run_release_commands" gorm:"default:false"` // run the pre-deploy and post-deploy commands of the application
+}
+
+// ApplicationPreviewStatus : status of the preview environment of a pull request
+type ApplicationPreviewStatus string
+
+const (
+	ApplicationPreviewStatusActive   ApplicationPreviewStatus = "active"
+	ApplicationPreviewStatusDeleting ApplicationPreviewStatus = "deleting"
+)
+
+// DeploymentStrategy : how the new version of the application replaces the current version
+type DeploymentStrategy string
+
+const (
+	// DeploymentStrategyRolling : update the service in place, as per the update config
+	DeploymentStrategyRolling DeploymentStrategy = "rolling"
+	// DeploymentStrategyBlueGreen : run the new version as candidate without traffic, switch all traffic on promotion
+	DeploymentStrategyBlueGreen DeploymentStrategy = "blue_green"
+	// DeploymentStrategyCanary : run the new version as candidate with a small share of traffic, shift traffic gradually
+	DeploymentStrategyCanary DeploymentStrategy = "canary"
+)
+
+// ApplicationUpdateConfig : strategy to replace the tasks on update, same settings are used for rollback
+type ApplicationUpdateConfig struct {
+	Parallelism     uint64              `json:"parallelism"`                           // Number of tasks updated simultaneously, 0 to update all at once
+	DelaySeconds    uint64              `json:"delay_seconds" gorm:"default:0"`        // Delay between updating each batch of tasks
+	Order           UpdateOrder         `json:"order" gorm:"default:'stop-first'"`     // Stop the old task before starting the new one or vice versa
+	FailureAction   UpdateFailureAction `json:"failure_action" gorm:"default:'pause'"` // Action to take if an updated task fails to run
+	MonitorSeconds  uint64              `json:"monitor_seconds" gorm:"default:5"`      // Duration to monitor each updated task for failure
+	MaxFailureRatio float32             `json:"max_failure_ratio" gorm:"default:0"`    // Failure rate to tolerate during an update
+}
+
+type UpdateOrder string
+
+const (
+	UpdateOrderStopFirst  UpdateOrder = "stop-first"
+	UpdateOrderStartFirst UpdateOrder = "start-first"
+)
+
+type UpdateFailureAction string
+
+const (
+	UpdateFailureActionPause    UpdateFailureAction = "pause"
+	UpdateFailureActionContinue UpdateFailureAction = "continue"
+	UpdateFailureActionRollback UpdateFailureAction = "rollback"
+)
+
 // ************************************************************************************* //
 //                                Docker Proxy Related     		       		 	 	     //
 // ************************************************************************************* //
@@ -401,3 +615,63 @@ type DockerProxyPermission struct {
 	Tasks        DockerProxyPermissionType `json:"tasks" gorm:"default:none"`
 	Volumes      DockerProxyPermissionType `json:"volumes" gorm:"default:none"`
 }
+
+// ************************************************************************************* //
+//                                Deployment Diff Related     		       		 	     //
+// ************************************************************************************* //
+
+// DeploymentConfigSnapshot : configuration of the application, which is not part of the deployment record
+type DeploymentConfigSnapshot struct {
+	EnvironmentVariables     map[string]string `json:"environment_variables"`      // key -> value
+	SecretEnvironmentKeys    []string          `json:"secret_environment_keys"`    // keys of secret environment variables
+	ConfigMounts             map[string]string `json:"config_mounts"`              // mounting path -> content
+	PersistentVolumeBindings map[string]string `json:"persistent_volume_bindings"` // mounting path -> persistent volume name
+	ResourceLimitMemoryMB    int               `json:"resource_limit_memory_mb"`
+	ReservedResourceMemoryMB int               `json:"reserved_resource_memory_mb"`
+	Replicas                 uint              `json:"replicas"`
+	Command                  string            `json:"command"`
+}
+
+// DeploymentChangeCategory : part of the application, which has been changed
+type DeploymentChangeCategory string
+
+const (
+	DeploymentChangeUpstream                DeploymentChangeCategory = "upstream"
+	DeploymentChangeDockerfile              DeploymentChangeCategory = "dockerfile"
+	DeploymentChangeBuildArg                DeploymentChangeCategory = "build_arg"
+	DeploymentChangeBuildSecret             DeploymentChangeCategory = "build_secret" // only the keys, values are never part of the diff
+	DeploymentChangeEnvironmentVariable     DeploymentChangeCategory = "environment_variable"
+	DeploymentChangeConfigMount             DeploymentChangeCategory = "config_mount"
+	DeploymentChangePersistentVolumeBinding DeploymentChangeCategory = "persistent_volume_binding"
+	DeploymentChangeResource                DeploymentChangeCategory = "resource"
+)
+
+// DeploymentChangeType : type of the change
+type DeploymentChangeType string
+
+const (
+	DeploymentChangeAdded    DeploymentChangeType = "added"
+	DeploymentChangeRemoved  DeploymentChangeType = "removed"
+	DeploymentChangeModified DeploymentChangeType = "modified"
+)
+
+// DeploymentChange : a change between two deployments
+type DeploymentChange struct {
+	Category DeploymentChangeCategory
+	Type     DeploymentChangeType
+	Field    string // name of the field, key of the variable or mounting path
+	OldValue string
+	NewValue string
+	IsSecret bool // value of secret environment variable, never shown to the user
+}
+
+// DeploymentDiff : changes between two deployments, or a deployment and the current config of the application
+type DeploymentDiff struct {
+	Changes []DeploymentChange
+	// RebuildRequired - image needs to be built again for the changes, as in Application.Update
+	RebuildRequired bool
+	// ReloadRequired - service needs to be updated for the changes, as in Application.Update
+	ReloadRequired bool
+	// IsConfigSnapshotAvailable - false, if any side was never deployed, then only upstream and build changes are reported
+	IsConfigSnapshotAvailable bool
+}
@@ -3,7 +3,9 @@ package core
 import (
 	"context"
 	"errors"
+	"github.com/labstack/gommon/random"
 	"gorm.io/gorm"
+	"strings"
 )
 
 // This file contains the operations for the Application model.
@@ -41,12 +43,124 @@ func CreateUser(ctx context.Context, db gorm.DB, user User) (User, error) {
 	if user.PasswordHash == "" {
 		return User{}, errors.New("password cannot be empty")
 	}
+	if user.Role != "" && !user.Role.IsValid() {
+		return User{}, errors.New("invalid user role")
+	}
 	err := db.Create(&user).Error
 	return user, err
 }
 
+// ErrSSOIdentityNotLinked : returned when the username of the single sign-on user is already taken by another account
+var ErrSSOIdentityNotLinked = errors.New("user with same username already exists, ask an administrator to link the account with single sign-on")
+
+// FindUserBySSOIdentity : find user by the issuer and subject of the single sign-on provider
+func FindUserBySSOIdentity(ctx context.Context, db gorm.DB, issuer string, subject string) (User, error) {
+	var user User
+	err := db.Where("oidc_issuer = ? AND oidc_subject = ?", normalizeOIDCIssuer(issuer), subject).First(&user).Error
+	return user, err
+}
+
+// FindOrCreateSSOUser : find the user logged in by single sign-on, create if not exists
+// Users are identified by `iss` + `sub` of the id token, username is only used while creating the user
+// Existing accounts are never taken over by matching username, administrator needs to link them explicitly
+// Role is kept in sync with the identity provider only for the users created by single sign-on
+func FindOrCreateSSOUser(ctx context.Context, db gorm.DB, issuer string, subject string, username string, role UserRole) (User, error) {
+	if issuer == "" || subject == "" {
+		return User{}, errors.New("issuer and subject cannot be empty")
+	}
+	if username == "" {
+		return User{}, errors.New("username cannot be empty")
+	}
+	if !role.IsValid() {
+		return User{}, errors.New("invalid user role")
+	}
+	user, err := FindUserBySSOIdentity(ctx, db, issuer, subject)
+	if err == nil {
+		if user.IsSSOManaged && user.Role != role {
+			if err := UpdateUserRole(ctx, db, user.ID, role); err != nil {
+				return User{}, err
+			}
+			user.Role = role
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return User{}, err
+	}
+	_, err = FindUserByUsername(ctx, db, username)
+	if err == nil {
+		return User{}, ErrSSOIdentityNotLinked
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return User{}, err
+	}
+	// password login is not possible for sso users, so set a random password
+	user = User{
+		Username:     username,
+		Role:         role,
+		OIDCIssuer:   normalizeOIDCIssuer(issuer),
+		OIDCSubject:  subject,
+		IsSSOManaged: true,
+	}
+	if err := user.SetPassword(random.String(32)); err != nil {
+		return User{}, errors.New("failed to set password")
+	}
+	return CreateUser(ctx, db, user)
+}
+
+// LinkUserToSSOIdentity : allow an existing user to login by single sign-on
+// Role of the linked user is managed in swiftwave, not by the identity provider
+func LinkUserToSSOIdentity(ctx context.Context, db gorm.DB, id uint, issuer string, subject string) error {
+	if issuer == "" || subject == "" {
+		return errors.New("issuer and subject cannot be empty")
+	}
+	linkedUser, err := FindUserBySSOIdentity(ctx, db, issuer, subject)
+	if err == nil {
+		if linkedUser.ID == id {
+			return nil
+		}
+		return errors.New("single sign-on identity is already linked with another user")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	tx := db.Model(&User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"oidc_issuer":  normalizeOIDCIssuer(issuer),
+		"oidc_subject": subject,
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// UnlinkUserFromSSOIdentity : remove the single sign-on identity of the user
+func UnlinkUserFromSSOIdentity(ctx context.Context, db gorm.DB, id uint) error {
+	tx := db.Model(&User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"oidc_issuer":    "",
+		"oidc_subject":   "",
+		"is_sso_managed": false,
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// normalizeOIDCIssuer : issuer url with and without trailing slash refers to the same provider
+func normalizeOIDCIssuer(issuer string) string {
+	return strings.TrimSuffix(issuer, "/")
+}
+
 // DeleteUser : delete user by id
 func DeleteUser(ctx context.Context, db gorm.DB, id uint) error {
+	// sessions of the user are deleted by cascade, so the issued access tokens are rejected immediately
 	err := db.Delete(&User{}, id).Error
 	// Don't return error if record not found -- assume it's already deleted
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -55,6 +169,21 @@ func DeleteUser(ctx context.Context, db gorm.DB, id uint) error {
 	return err
 }
 
+// UpdateUserRole : update role of user
+func UpdateUserRole(ctx context.Context, db gorm.DB, id uint, role UserRole) error {
+	if !role.IsValid() {
+		return errors.New("invalid user role")
+	}
+	tx := db.Model(&User{}).Where("id = ?", id).Update("role", role)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
 // ChangePassword : change user password
 func ChangePassword(ctx context.Context, db gorm.DB, username string, oldPassword string, newPassword string) error {
 	// Fetch user
@@ -74,7 +203,33 @@ func ChangePassword(ctx context.Context, db gorm.DB, username string, oldPasswor
 	}
 	// Update user
 	err = db.Save(&user).Error
-	return err
+	if err != nil {
+		return err
+	}
+	// Logout from all sessions, as the old password may have been compromised
+	return RevokeUserSessions(ctx, db, user.ID)
+}
+
+// EnableTotp : enable Totp for user, along with a new set of recovery codes
+// Both are saved together, so that Totp is never enabled without the recovery codes
+func EnableTotp(ctx context.Context, db gorm.DB, userID uint) ([]string, error) {
+	var recoveryCodes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		update := tx.Model(&User{}).Where("id = ?", userID).Update("totp_enabled", true)
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return errors.New("user not found")
+		}
+		var err error
+		recoveryCodes, err = GenerateTotpRecoveryCodes(ctx, *tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
 }
 
 // DisableTotp : disable Totp for user
@@ -86,5 +241,9 @@ func DisableTotp(ctx context.Context, db gorm.DB, username string) error {
 	// disable TOTP
 	user.TotpEnabled = false
 	err = db.Save(&user).Error
-	return err
+	if err != nil {
+		return err
+	}
+	// recovery codes are of no use without totp
+	return DeleteTotpRecoveryCodesByUserID(ctx, db, user.ID)
 }
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/gommon/random"
+	"gorm.io/gorm"
+)
+
+// This file contains the operations for the UserSession model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+const (
+	// AccessTokenLifetime : lifetime of the jwt token issued for a session
+	AccessTokenLifetime = 15 * time.Minute
+	// RefreshTokenLifetime : session expires if the refresh token is not used within this duration
+	RefreshTokenLifetime = 7 * 24 * time.Hour
+	// UserSessionMaxLifetime : session can't be extended beyond this duration from login
+	UserSessionMaxLifetime = 30 * 24 * time.Hour
+	// RefreshTokenPrefix : prefix of refresh tokens, used to distinguish them from other tokens
+	RefreshTokenPrefix = "swr_"
+)
+
+var (
+	ErrUserSessionNotFound = errors.New("session not found")
+	ErrUserSessionExpired  = errors.New("session expired or revoked, please login again")
+)
+
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func generateRefreshToken() string {
+	return RefreshTokenPrefix + random.String(48)
+}
+
+// IsActive : check if the session is neither revoked nor expired
+func (session *UserSession) IsActive() bool {
+	return session.RevokedAt == nil && session.ExpiresAt.After(time.Now())
+}
+
+// CreateUserSession : create a new session for the user on login
+// Returns the plain refresh token, which should be sent to the client only once
+func CreateUserSession(ctx context.Context, db gorm.DB, userID uint, ip string, userAgent string) (*UserSession, string, error) {
+	if _, err := FindUserByID(ctx, db, userID); err != nil {
+		return nil, "", errors.New("user not found")
+	}
+	now := time.Now()
+	refreshToken := generateRefreshToken()
+	session := &UserSession{
+		ID:               uuid.NewString(),
+		UserID:           userID,
+		RefreshTokenHash: hashRefreshToken(refreshToken),
+		IP:               ip,
+		UserAgent:        userAgent,
+		ExpiresAt:        now.Add(RefreshTokenLifetime),
+		LastRefreshedAt:  now,
+		CreatedAt:        now,
+	}
+	if err := db.Create(session).Error; err != nil {
+		return nil, "", err
+	}
+	return session, refreshToken, nil
+}
+
+// RefreshUserSession : rotate the refresh token of the session
+// If an already rotated refresh token is presented, the token has probably leaked, so the session is revoked
+func RefreshUserSession(ctx context.Context, db gorm.DB, refreshToken string, ip string, userAgent string) (*UserSession, string, error) {
+	if !strings.HasPrefix(refreshToken, RefreshTokenPrefix) {
+		return nil, "", ErrUserSessionExpired
+	}
+	tokenHash := hashRefreshToken(refreshToken)
+	session := &UserSession{}
+	err := db.Where("refresh_token_hash = ?", tokenHash).First(session).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", err
+		}
+		// check for reuse of rotated refresh token
+		err = db.Where("previous_refresh_token_hash = ?", tokenHash).First(session).Error
+		if err == nil {
+			_ = session.Revoke(ctx, db)
+		}
+		return nil, "", ErrUserSessionExpired
+	}
+	if !session.IsActive() {
+		return nil, "", ErrUserSessionExpired
+	}
+	now := time.Now()
+	expiresAt := now.Add(RefreshTokenLifetime)
+	if maxExpiresAt := session.CreatedAt.Add(UserSessionMaxLifetime); expiresAt.After(maxExpiresAt) {
+		expiresAt = maxExpiresAt
+	}
+	newRefreshToken := generateRefreshToken()
+	// conditional update, so concurrent refresh with the same token can succeed only once
+	tx := db.Model(&UserSession{}).Where("id = ? AND refresh_token_hash = ?", session.ID, tokenHash).Updates(map[string]interface{}{
+		"refresh_token_hash":          hashRefreshToken(newRefreshToken),
+		"previous_refresh_token_hash": tokenHash,
+		"ip":                          ip,
+		"user_agent":                  userAgent,
+		"expires_at":                  expiresAt,
+		"last_refreshed_at":           now,
+	})
+	if tx.Error != nil {
+		return nil, "", tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, "", ErrUserSessionExpired
+	}
+	session.RefreshTokenHash = hashRefreshToken(newRefreshToken)
+	session.PreviousRefreshTokenHash = tokenHash
+	session.IP = ip
+	session.UserAgent = userAgent
+	session.ExpiresAt = expiresAt
+	session.LastRefreshedAt = now
+	return session, newRefreshToken, nil
+}
+
+// ValidateUserSession : check if the session referred by the access token is still active and belongs to the user
+func ValidateUserSession(_ context.Context, db gorm.DB, sessionID string, username string) error {
+	if sessionID == "" {
+		return ErrUserSessionExpired
+	}
+	var count int64
+	err := db.Model(&UserSession{}).
+		Joins("JOIN users ON users.id = user_sessions.user_id").
+		Where("user_sessions.id = ? AND users.username = ? AND user_sessions.revoked_at IS NULL AND user_sessions.expires_at > ?", sessionID, username, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUserSessionExpired
+	}
+	return nil
+}
+
+// FindActiveUserSessionsByUserID : find all active sessions of user, latest first
+func FindActiveUserSessionsByUserID(_ context.Context, db gorm.DB, userID uint) ([]*UserSession, error) {
+	var sessions = make([]*UserSession, 0)
+	err := db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).Order("last_refreshed_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+func (session *UserSession) FindById(_ context.Context, db gorm.DB, id string) error {
+	err := db.Where("id = ?", id).First(session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserSessionNotFound
+	}
+	return err
+}
+
+// FindUserSessionByRefreshToken : find the session of the refresh token, if it's the current one
+func FindUserSessionByRefreshToken(_ context.Context, db gorm.DB, refreshToken string) (*UserSession, error) {
+	session := &UserSession{}
+	err := db.Where("refresh_token_hash = ?", hashRefreshToken(refreshToken)).First(session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserSessionNotFound
+	}
+	return session, err
+}
+
+// Revoke : revoke the session, access tokens of the session will be rejected immediately
+func (session *UserSession) Revoke(_ context.Context, db gorm.DB) error {
+	now := time.Now()
+	err := db.Model(&UserSession{}).Where("id = ? AND revoked_at IS NULL", session.ID).Update("revoked_at", now).Error
+	if err != nil {
+		return err
+	}
+	session.RevokedAt = &now
+	return nil
+}
+
+// RevokeUserSessions : revoke all active sessions of the user
+func RevokeUserSessions(_ context.Context, db gorm.DB, userID uint) error {
+	return db.Model(&UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testOIDCIssuer = "https://sso.example.com"
+
+func TestFindOrCreateSSOUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates user on first login", func(t *testing.T) {
+		db := newTestDB(t, &User{})
+		user, err := FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-1", "alice", ManagerRole)
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", user.Username)
+		assert.Equal(t, ManagerRole, user.Role)
+		assert.True(t, user.IsSSOManaged)
+
+		again, err := FindOrCreateSSOUser(ctx, db, testOIDCIssuer+"/", "sub-1", "alice", ManagerRole)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, again.ID)
+	})
+
+	t.Run("refuses to take over existing password account", func(t *testing.T) {
+		db := newTestDB(t, &User{})
+		admin := createTestUser(t, db, "admin", AdministratorRole)
+		_, err := FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "attacker", "admin", ViewerRole)
+		assert.ErrorIs(t, err, ErrSSOIdentityNotLinked)
+
+		stored, err := FindUserByID(ctx, db, admin.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, AdministratorRole, stored.Role)
+		assert.Empty(t, stored.OIDCSubject)
+	})
+
+	t.Run("refuses username collision with another sso user", func(t *testing.T) {
+		db := newTestDB(t, &User{})
+		_, err := FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-1", "alice", AdministratorRole)
+		assert.NoError(t, err)
+		_, err = FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-2", "alice", ViewerRole)
+		assert.ErrorIs(t, err, ErrSSOIdentityNotLinked)
+		_, err = FindOrCreateSSOUser(ctx, db, "https://other.example.com", "sub-1", "alice", ViewerRole)
+		assert.ErrorIs(t, err, ErrSSOIdentityNotLinked)
+	})
+
+	t.Run("username change at provider keeps the same user", func(t *testing.T) {
+		db := newTestDB(t, &User{})
+		user, err := FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-1", "alice", ManagerRole)
+		assert.NoError(t, err)
+		renamed, err := FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-1", "alice.smith", ManagerRole)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, renamed.ID)
+		assert.Equal(t, "alice", renamed.Username)
+	})
+
+	t.Run("role is synced only for sso created users", func(t *testing.T) {
+		db := newTestDB(t, &User{})
+		user, err := FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-1", "alice", ViewerRole)
+		assert.NoError(t, err)
+		user, err = FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-1", "alice", ManagerRole)
+		assert.NoError(t, err)
+		assert.Equal(t, ManagerRole, user.Role)
+
+		admin := createTestUser(t, db, "admin", AdministratorRole)
+		assert.NoError(t, LinkUserToSSOIdentity(ctx, db, admin.ID, testOIDCIssuer, "sub-admin"))
+		linked, err := FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-admin", "admin", ViewerRole)
+		assert.NoError(t, err)
+		assert.Equal(t, admin.ID, linked.ID)
+		assert.Equal(t, AdministratorRole, linked.Role)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		db := newTestDB(t, &User{})
+		_, err := FindOrCreateSSOUser(ctx, db, "", "sub-1", "alice", ViewerRole)
+		assert.Error(t, err)
+		_, err = FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "", "alice", ViewerRole)
+		assert.Error(t, err)
+		_, err = FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-1", "", ViewerRole)
+		assert.Error(t, err)
+		_, err = FindOrCreateSSOUser(ctx, db, testOIDCIssuer, "sub-1", "alice", UserRole("root"))
+		assert.Error(t, err)
+	})
+}
+
+func TestLinkUserToSSOIdentity(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &User{})
+	alice := createTestUser(t, db, "alice", ManagerRole)
+	bob := createTestUser(t, db, "bob", ManagerRole)
+
+	assert.NoError(t, LinkUserToSSOIdentity(ctx, db, alice.ID, testOIDCIssuer, "sub-alice"))
+	// linking again to the same user is a no-op
+	assert.NoError(t, LinkUserToSSOIdentity(ctx, db, alice.ID, testOIDCIssuer, "sub-alice"))
+	// same identity can't be linked with another user
+	assert.Error(t, LinkUserToSSOIdentity(ctx, db, bob.ID, testOIDCIssuer, "sub-alice"))
+	assert.Error(t, LinkUserToSSOIdentity(ctx, db, 999, testOIDCIssuer, "sub-unknown"))
+
+	user, err := FindUserBySSOIdentity(ctx, db, testOIDCIssuer, "sub-alice")
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID, user.ID)
+	assert.False(t, user.IsSSOManaged)
+
+	assert.NoError(t, UnlinkUserFromSSOIdentity(ctx, db, alice.ID))
+	_, err = FindUserBySSOIdentity(ctx, db, testOIDCIssuer, "sub-alice")
+	assert.Error(t, err)
+	assert.NoError(t, LinkUserToSSOIdentity(ctx, db, bob.ID, testOIDCIssuer, "sub-alice"))
+}
+
+func TestUserHasRole(t *testing.T) {
+	roles := []UserRole{ViewerRole, ManagerRole, AdministratorRole}
+	for i, userRole := range roles {
+		user := User{Role: userRole}
+		for j, requiredRole := range roles {
+			assert.Equal(t, i >= j, user.HasRole(requiredRole), "%s user requires %s", userRole, requiredRole)
+		}
+	}
+	unknown := User{Role: "owner"}
+	assert.False(t, unknown.HasRole(ViewerRole))
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &User{})
+	user := createTestUser(t, db, "alice", ViewerRole)
+
+	assert.NoError(t, UpdateUserRole(ctx, db, user.ID, ManagerRole))
+	updated, err := FindUserByID(ctx, db, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ManagerRole, updated.Role)
+
+	assert.Error(t, UpdateUserRole(ctx, db, user.ID, "owner"))
+	assert.Error(t, UpdateUserRole(ctx, db, user.ID+100, ManagerRole))
+
+	_, err = CreateUser(ctx, db, User{Username: "bob", PasswordHash: "hash", Role: "owner"})
+	assert.Error(t, err)
+}
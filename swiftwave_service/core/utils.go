@@ -2,8 +2,10 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -25,13 +27,19 @@ func (user *User) CheckPassword(password string) bool {
 	return err == nil
 }
 
-// GenerateJWT : generate jwt token for user
-func (user *User) GenerateJWT(jwtSecret string) (string, error) {
+// HasRole : check if user has the given role or a more privileged one
+func (user *User) HasRole(role UserRole) bool {
+	return user.Role.privilegeLevel() >= role.privilegeLevel()
+}
+
+// GenerateJWT : generate short-lived jwt token for the session of user
+func (user *User) GenerateJWT(jwtSecret string, sessionID string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"nbf":      time.Now().Unix(),
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+		"exp":      time.Now().Add(AccessTokenLifetime).Unix(),
 		"iat":      time.Now().Unix(),
 		"username": user.Username,
+		"sid":      sessionID,
 	})
 
 	// Sign and get the complete encoded token as a string using the secret
@@ -98,6 +106,422 @@ func (c *ApplicationCustomHealthCheck) Equal(other *ApplicationCustomHealthCheck
 		c.Retries == other.Retries
 }
 
+// DefaultAutoRollbackWindowSeconds : window to watch the health of the new version, if not configured
+const DefaultAutoRollbackWindowSeconds = 120
+
+// Window : duration to watch the health of the new version after deployment
+func (c *ApplicationAutoRollback) Window() time.Duration {
+	if c.WindowSeconds == 0 {
+		return DefaultAutoRollbackWindowSeconds * time.Second
+	}
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+func (c *ApplicationAutoRollback) Equal(other *ApplicationAutoRollback) bool {
+	return c.Enabled == other.Enabled &&
+		c.WindowSeconds == other.WindowSeconds
+}
+
+// DefaultReleaseCommandTimeoutSeconds : maximum time to allow a release command to run, if not configured
+const DefaultReleaseCommandTimeoutSeconds = 600
+
+// Timeout : maximum time to allow each release command to run
+func (c *ApplicationReleaseCommands) Timeout() time.Duration {
+	if c.TimeoutSeconds == 0 {
+		return DefaultReleaseCommandTimeoutSeconds * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+func (c *ApplicationReleaseCommands) Equal(other *ApplicationReleaseCommands) bool {
+	return c.PreDeploy == other.PreDeploy &&
+		c.PostDeploy == other.PostDeploy &&
+		c.TimeoutSeconds == other.TimeoutSeconds
+}
+
+// IsJob : job applications run to completion, there is no long-running service
+func (m DeploymentMode) IsJob() bool {
+	return m == DeploymentModeCronJob || m == DeploymentModeRunOnceJob
+}
+
+// DefaultJobTimeoutSeconds : maximum time to allow a run of job application, if not configured
+const DefaultJobTimeoutSeconds = 3600
+
+// Timeout : maximum time to allow each run of job application
+func (c *ApplicationJobConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds == 0 {
+		return DefaultJobTimeoutSeconds * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// Validate : schedule is required for cron job
+func (c *ApplicationJobConfig) Validate(deploymentMode DeploymentMode) error {
+	if deploymentMode != DeploymentModeCronJob {
+		return nil
+	}
+	if c.Schedule == "" {
+		return errors.New("schedule is required for cron job")
+	}
+	_, err := ParseCronSchedule(c.Schedule)
+	return err
+}
+
+func (c *ApplicationJobConfig) Equal(other *ApplicationJobConfig) bool {
+	return c.Schedule == other.Schedule &&
+		c.TimeoutSeconds == other.TimeoutSeconds
+}
+
+func (p *ApplicationRetentionPolicy) Equal(other *ApplicationRetentionPolicy) bool {
+	return p.KeepLastDeployments == other.KeepLastDeployments &&
+		p.KeepDays == other.KeepDays
+}
+
+// Merge : fields not set in the application's policy are taken from the system wide policy
+func (p *ApplicationRetentionPolicy) Merge(keepLastDeployments uint, keepDays uint) ApplicationRetentionPolicy {
+	policy := *p
+	if policy.KeepLastDeployments == 0 {
+		policy.KeepLastDeployments = keepLastDeployments
+	}
+	if policy.KeepDays == 0 {
+		policy.KeepDays = keepDays
+	}
+	return policy
+}
+
+// IsEnabled : deployments are kept forever, if no limit is set
+func (p *ApplicationRetentionPolicy) IsEnabled() bool {
+	return p.KeepLastDeployments > 0 || p.KeepDays > 0
+}
+
+// DefaultGitPollingIntervalSeconds : interval to poll the git branch, if not configured
+const DefaultGitPollingIntervalSeconds = 300
+
+// MinGitPollingIntervalSeconds : git server shouldn't be polled more frequently than this
+const MinGitPollingIntervalSeconds = 60
+
+// Interval : time between two polls of the git branch
+func (p *ApplicationGitPolling) Interval() time.Duration {
+	if p.IntervalSeconds == 0 {
+		return DefaultGitPollingIntervalSeconds * time.Second
+	}
+	return time.Duration(p.IntervalSeconds) * time.Second
+}
+
+// Validate : polling is only possible for git upstream
+func (p *ApplicationGitPolling) Validate(upstreamType UpstreamType) error {
+	if !p.Enabled {
+		return nil
+	}
+	if upstreamType != UpstreamTypeGit {
+		return errors.New("git polling can be enabled only for git upstream")
+	}
+	if p.IntervalSeconds < MinGitPollingIntervalSeconds {
+		return fmt.Errorf("git polling interval should be at least %d seconds", MinGitPollingIntervalSeconds)
+	}
+	return nil
+}
+
+func (p *ApplicationGitPolling) Equal(other *ApplicationGitPolling) bool {
+	return p.Enabled == other.Enabled &&
+		p.IntervalSeconds == other.IntervalSeconds
+}
+
+// commitHashRegex : full commit hash, abbreviated hash can't be resolved without cloning the repository
+var commitHashRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// dockerfileTargetRegex : name of the stage in the dockerfile
+var dockerfileTargetRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]*$`)
+
+// IsPinned : pinned deployments are rebuilt from the same commit
+func (t GitReferenceType) IsPinned() bool {
+	return t == GitReferenceTag || t == GitReferenceCommit
+}
+
+// ValidateGitReference : branch, tag or commit is required as per the reference type
+func (deployment *Deployment) ValidateGitReference() error {
+	if deployment.UpstreamType != UpstreamTypeGit {
+		return nil
+	}
+	if deployment.GitReferenceType == "" {
+		deployment.GitReferenceType = GitReferenceBranch
+	}
+	switch deployment.GitReferenceType {
+	case GitReferenceBranch:
+		if strings.TrimSpace(deployment.RepositoryBranch) == "" {
+			return errors.New("branch is required to deploy from branch")
+		}
+	case GitReferenceTag:
+		if strings.TrimSpace(deployment.RepositoryTag) == "" {
+			return errors.New("tag is required to deploy from tag")
+		}
+		if deployment.GitTagPattern != "" {
+			if _, err := path.Match(deployment.GitTagPattern, ""); err != nil {
+				return errors.New("invalid tag pattern, e.g. v*")
+			}
+		}
+	case GitReferenceCommit:
+		if !commitHashRegex.MatchString(deployment.CommitHash) {
+			return errors.New("full commit hash of 40 characters is required to deploy from commit")
+		}
+	default:
+		return errors.New("invalid git reference type")
+	}
+	if deployment.GitReferenceType != GitReferenceTag && deployment.GitTagPattern != "" {
+		return errors.New("tag pattern can be set only for deployment from tag")
+	}
+	return nil
+}
+
+// ValidateBuildConfig : check the dockerfile target and the build secrets
+// Build secrets should refer to the secret environment variables of the application
+func (deployment *Deployment) ValidateBuildConfig(environmentVariables []EnvironmentVariable) error {
+	deployment.DockerfileTarget = strings.TrimSpace(deployment.DockerfileTarget)
+	if deployment.DockerfileTarget != "" && !dockerfileTargetRegex.MatchString(deployment.DockerfileTarget) {
+		return errors.New("invalid dockerfile target, it should be the name of a stage in the dockerfile")
+	}
+	secretEnvironmentVariableKeys := make(map[string]bool)
+	for _, environmentVariable := range environmentVariables {
+		if environmentVariable.IsSecret {
+			secretEnvironmentVariableKeys[environmentVariable.Key] = true
+		}
+	}
+	buildSecretKeys := make(map[string]bool)
+	for _, buildSecret := range deployment.BuildSecrets {
+		if buildSecretKeys[buildSecret.Key] {
+			return errors.New("build secret " + buildSecret.Key + " is added multiple times")
+		}
+		buildSecretKeys[buildSecret.Key] = true
+		if !secretEnvironmentVariableKeys[buildSecret.Key] {
+			return errors.New("build secret " + buildSecret.Key + " should be a secret environment variable of the application")
+		}
+	}
+	return nil
+}
+
+// IsAutoDeployTag : check if the tag matches the tag pattern and is newer than the deployed tag
+func (deployment *Deployment) IsAutoDeployTag(tag string) bool {
+	if deployment.GitReferenceType != GitReferenceTag || deployment.GitTagPattern == "" {
+		return false
+	}
+	isMatched, err := path.Match(deployment.GitTagPattern, tag)
+	if err != nil || !isMatched {
+		return false
+	}
+	return compareGitTags(tag, deployment.RepositoryTag) > 0
+}
+
+// LatestAutoDeployTag : newest tag, which should be deployed automatically
+// Returns empty string, if there is no such tag
+func (deployment *Deployment) LatestAutoDeployTag(tags []string) string {
+	latestTag := ""
+	for _, tag := range tags {
+		if deployment.IsAutoDeployTag(tag) && (latestTag == "" || compareGitTags(tag, latestTag) > 0) {
+			latestTag = tag
+		}
+	}
+	return latestTag
+}
+
+// compareGitTags : numeric parts are compared as numbers, so that v1.10.0 is newer than v1.9.0
+func compareGitTags(a string, b string) int {
+	for a != "" && b != "" {
+		aPart, aIsNumber := splitTagPart(a)
+		bPart, bIsNumber := splitTagPart(b)
+		a = a[len(aPart):]
+		b = b[len(bPart):]
+		if aIsNumber && bIsNumber {
+			aPart = strings.TrimLeft(aPart, "0")
+			bPart = strings.TrimLeft(bPart, "0")
+			if len(aPart) != len(bPart) {
+				if len(aPart) > len(bPart) {
+					return 1
+				}
+				return -1
+			}
+		}
+		if result := strings.Compare(aPart, bPart); result != 0 {
+			return result
+		}
+	}
+	// pre-release is older than the release, e.g. v1.0.0-rc1 is older than v1.0.0
+	if strings.HasPrefix(a, "-") {
+		return -1
+	}
+	if strings.HasPrefix(b, "-") {
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// splitTagPart : leading run of digits or non-digits of the tag
+func splitTagPart(tag string) (part string, isNumber bool) {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	isNumber = isDigit(tag[0])
+	i := 1
+	for i < len(tag) && isDigit(tag[i]) == isNumber {
+		i++
+	}
+	return tag[:i], isNumber
+}
+
+// Validate : preview is built from the pull request branch, so only possible for git upstream
+func (p *ApplicationPreviewConfig) Validate(upstreamType UpstreamType, deploymentMode DeploymentMode) error {
+	if !p.Enabled {
+		return nil
+	}
+	if upstreamType != UpstreamTypeGit {
+		return errors.New("preview environments can be enabled only for git upstream")
+	}
+	if deploymentMode.IsJob() {
+		return errors.New("preview environments are not supported for job")
+	}
+	baseDomain := strings.TrimSpace(p.BaseDomain)
+	if baseDomain == "" || strings.ContainsAny(baseDomain, "/:* ") || strings.HasPrefix(baseDomain, ".") || strings.HasSuffix(baseDomain, ".") {
+		return errors.New("invalid base domain for preview environments, e.g. preview.example.com")
+	}
+	if p.TargetPort == 0 || p.TargetPort > 65535 {
+		return errors.New("invalid target port for preview environments")
+	}
+	return nil
+}
+
+func (p *ApplicationPreviewConfig) Equal(other *ApplicationPreviewConfig) bool {
+	return p.Enabled == other.Enabled &&
+		p.BaseDomain == other.BaseDomain &&
+		p.TargetPort == other.TargetPort &&
+		p.CopySecrets == other.CopySecrets &&
+		p.RunReleaseCommands == other.RunReleaseCommands
+}
+
+// DomainName : domain of the preview environment of the pull request
+func (p *ApplicationPreviewConfig) DomainName(pullRequestNumber uint) string {
+	return fmt.Sprintf("pr-%d.%s", pullRequestNumber, strings.ToLower(strings.TrimSpace(p.BaseDomain)))
+}
+
+// environmentVariables : environment variables of the preview with the overrides applied
+// Secret environment variables of the application are skipped unless CopySecrets is enabled
+// Override of a secret environment variable stays secret, even if the secret itself is not copied
+func (p *ApplicationPreviewConfig) environmentVariables(environmentVariables []*EnvironmentVariable, overrides []*PreviewEnvironmentVariable, placeholderReplacer *strings.Replacer) []EnvironmentVariable {
+	previewEnvironmentVariables := make([]EnvironmentVariable, 0)
+	environmentVariableIndex := make(map[string]int)
+	skippedSecrets := make(map[string]*EnvironmentVariable)
+	for _, environmentVariable := range environmentVariables {
+		if environmentVariable.IsSecret && !p.CopySecrets {
+			skippedSecrets[environmentVariable.Key] = environmentVariable
+			continue
+		}
+		environmentVariableIndex[environmentVariable.Key] = len(previewEnvironmentVariables)
+		previewEnvironmentVariables = append(previewEnvironmentVariables, EnvironmentVariable{
+			Key:           environmentVariable.Key,
+			Value:         environmentVariable.Value,
+			IsSecret:      environmentVariable.IsSecret,
+			SecretFileEnv: environmentVariable.SecretFileEnv,
+		})
+	}
+	for _, override := range overrides {
+		value := placeholderReplacer.Replace(override.Value)
+		if index, ok := environmentVariableIndex[override.Key]; ok {
+			previewEnvironmentVariables[index].Value = value
+			previewEnvironmentVariables[index].IsSecret = previewEnvironmentVariables[index].IsSecret || override.IsSecret
+		} else if secret, ok := skippedSecrets[override.Key]; ok {
+			previewEnvironmentVariables = append(previewEnvironmentVariables, EnvironmentVariable{
+				Key:           override.Key,
+				Value:         value,
+				IsSecret:      true,
+				SecretFileEnv: secret.SecretFileEnv,
+			})
+		} else {
+			previewEnvironmentVariables = append(previewEnvironmentVariables, EnvironmentVariable{
+				Key:      override.Key,
+				Value:    value,
+				IsSecret: override.IsSecret,
+			})
+		}
+	}
+	return previewEnvironmentVariables
+}
+
+// releaseCommands : release commands of the preview, empty unless RunReleaseCommands is enabled
+func (p *ApplicationPreviewConfig) releaseCommands(releaseCommands ApplicationReleaseCommands) ApplicationReleaseCommands {
+	if !p.RunReleaseCommands {
+		return ApplicationReleaseCommands{}
+	}
+	return releaseCommands
+}
+
+// DefaultGitCloneConfig : submodules are checked out from shallow clone, git lfs files are not fetched
+func DefaultGitCloneConfig() GitCloneConfig {
+	return GitCloneConfig{
+		Submodules: true,
+		LFS:        false,
+		Shallow:    true,
+	}
+}
+
+// DefaultApplicationUpdateConfig : docker's default update config
+func DefaultApplicationUpdateConfig() ApplicationUpdateConfig {
+	return ApplicationUpdateConfig{
+		Parallelism:     1,
+		DelaySeconds:    0,
+		Order:           UpdateOrderStopFirst,
+		FailureAction:   UpdateFailureActionPause,
+		MonitorSeconds:  5,
+		MaxFailureRatio: 0,
+	}
+}
+
+func (c *ApplicationUpdateConfig) Validate() error {
+	if c.Order != UpdateOrderStopFirst && c.Order != UpdateOrderStartFirst {
+		return errors.New("invalid update order > " + string(c.Order))
+	}
+	if c.FailureAction != UpdateFailureActionPause && c.FailureAction != UpdateFailureActionContinue && c.FailureAction != UpdateFailureActionRollback {
+		return errors.New("invalid update failure action > " + string(c.FailureAction))
+	}
+	if c.MaxFailureRatio < 0 || c.MaxFailureRatio > 1 {
+		return errors.New("max failure ratio should be between 0 and 1")
+	}
+	return nil
+}
+
+func (c *ApplicationUpdateConfig) Equal(other *ApplicationUpdateConfig) bool {
+	return c.Parallelism == other.Parallelism &&
+		c.DelaySeconds == other.DelaySeconds &&
+		c.Order == other.Order &&
+		c.FailureAction == other.FailureAction &&
+		c.MonitorSeconds == other.MonitorSeconds &&
+		c.MaxFailureRatio == other.MaxFailureRatio
+}
+
+// DefaultCanaryTrafficPercent : share of the traffic routed to a new canary candidate
+const DefaultCanaryTrafficPercent = 10
+
+func (s DeploymentStrategy) IsValid() bool {
+	return s == DeploymentStrategyRolling || s == DeploymentStrategyBlueGreen || s == DeploymentStrategyCanary
+}
+
+// UsesCandidate : new version runs as a separate service next to the current version
+func (s DeploymentStrategy) UsesCandidate() bool {
+	return s == DeploymentStrategyBlueGreen || s == DeploymentStrategyCanary
+}
+
+// InitialCandidateTrafficPercent : share of the traffic routed to a new candidate
+func (s DeploymentStrategy) InitialCandidateTrafficPercent() uint {
+	if s == DeploymentStrategyCanary {
+		return DefaultCanaryTrafficPercent
+	}
+	return 0
+}
+
+func (application *Application) HasCandidate() bool {
+	return application.CandidateDeploymentID != nil && *application.CandidateDeploymentID != ""
+}
+
+// CandidateServiceName : name of the swarm service running the candidate version
+func (application *Application) CandidateServiceName() string {
+	return application.ID + "-candidate"
+}
+
 func (application *Application) DockerProxyServiceName() string {
 	return application.ID + "-dp"
 }
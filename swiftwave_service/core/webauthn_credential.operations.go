@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+)
+
+// This file contains the operations for the WebAuthnCredential model.
+// This functions will perform necessary validation before doing the actual database operation.
+
+// Each function's argument format should be (ctx context.Context, db gorm.DB, ...)
+// context used to pass some data to the function e.g. user id, auth info, etc.
+
+// WebAuthnUser : adapter of User with registered credentials for webauthn ceremonies
+type WebAuthnUser struct {
+	User        *User
+	Credentials []*WebAuthnCredential
+}
+
+// NewWebAuthnUser : load the registered credentials of the user
+func NewWebAuthnUser(ctx context.Context, db gorm.DB, user *User) (*WebAuthnUser, error) {
+	credentials, err := FindWebAuthnCredentialsByUserID(ctx, db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &WebAuthnUser{
+		User:        user,
+		Credentials: credentials,
+	}, nil
+}
+
+// WebAuthnUserHandle : opaque user handle used in webauthn ceremonies
+func WebAuthnUserHandle(userID uint) []byte {
+	return []byte(strconv.FormatUint(uint64(userID), 10))
+}
+
+func (u *WebAuthnUser) WebAuthnID() []byte {
+	return WebAuthnUserHandle(u.User.ID)
+}
+
+func (u *WebAuthnUser) WebAuthnName() string {
+	return u.User.Username
+}
+
+func (u *WebAuthnUser) WebAuthnDisplayName() string {
+	return u.User.Username
+}
+
+func (u *WebAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *WebAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.Credentials))
+	for _, credential := range u.Credentials {
+		credentials = append(credentials, credential.toWebAuthnCredential())
+	}
+	return credentials
+}
+
+func (credential *WebAuthnCredential) toWebAuthnCredential() webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(credential.Transports))
+	for _, transport := range credential.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(transport))
+	}
+	return webauthn.Credential{
+		ID:              credential.CredentialID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: credential.BackupEligible,
+			BackupState:    credential.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    credential.AAGUID,
+			SignCount: credential.SignCount,
+		},
+	}
+}
+
+// NewWebAuthnCredential : create the record from the credential verified in registration ceremony
+func NewWebAuthnCredential(userID uint, name string, credential *webauthn.Credential) *WebAuthnCredential {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+	return &WebAuthnCredential{
+		UserID:          userID,
+		Name:            strings.TrimSpace(name),
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+}
+
+func FindWebAuthnCredentialsByUserID(_ context.Context, db gorm.DB, userID uint) ([]*WebAuthnCredential, error) {
+	var credentials = make([]*WebAuthnCredential, 0)
+	tx := db.Where("user_id = ?", userID).Order("created_at").Find(&credentials)
+	return credentials, tx.Error
+}
+
+func HasWebAuthnCredentials(_ context.Context, db gorm.DB, userID uint) (bool, error) {
+	var count int64
+	tx := db.Model(&WebAuthnCredential{}).Where("user_id = ?", userID).Count(&count)
+	return count > 0, tx.Error
+}
+
+func (credential *WebAuthnCredential) FindById(_ context.Context, db gorm.DB, id uint) error {
+	tx := db.Where("id = ?", id).First(credential)
+	return tx.Error
+}
+
+func (credential *WebAuthnCredential) Create(_ context.Context, db gorm.DB) error {
+	if credential.Name == "" {
+		return errors.New("name of authenticator is required")
+	}
+	if len(credential.CredentialID) == 0 || len(credential.PublicKey) == 0 {
+		return errors.New("invalid credential")
+	}
+	tx := db.Create(credential)
+	return tx.Error
+}
+
+// MarkAsUsed : update the signature counter after successful assertion
+func (credential *WebAuthnCredential) MarkAsUsed(_ context.Context, db gorm.DB, signCount uint32) error {
+	now := time.Now()
+	credential.SignCount = signCount
+	credential.LastUsedAt = &now
+	tx := db.Model(credential).Updates(map[string]interface{}{
+		"sign_count":   signCount,
+		"last_used_at": now,
+	})
+	return tx.Error
+}
+
+func (credential *WebAuthnCredential) Delete(_ context.Context, db gorm.DB) error {
+	tx := db.Delete(credential)
+	return tx.Error
+}
+
+func DeleteWebAuthnCredentialsByUserID(_ context.Context, db gorm.DB, userID uint) error {
+	tx := db.Where("user_id = ?", userID).Delete(&WebAuthnCredential{})
+	return tx.Error
+}
@@ -0,0 +1,114 @@
+package cronjob
+
+import (
+	"context"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/logger"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/manager"
+	"time"
+)
+
+func (m Manager) ApplyRetentionPolicies() {
+	logger.CronJobLogger.Println("Starting retention of old deployments [cronjob]")
+	for {
+		time.Sleep(1 * time.Hour)
+		m.applyRetentionPolicies()
+	}
+}
+
+func (m Manager) applyRetentionPolicies() {
+	ctx := context.Background()
+	db := m.ServiceManager.DbClient
+	applications, err := core.FindAllApplications(ctx, db, true)
+	if err != nil {
+		logger.CronJobLoggerError.Println("Error while fetching applications \n", err)
+		return
+	}
+	retentionConfig := m.Config.SystemConfig.RetentionConfig
+	for _, application := range applications {
+		if application.IsDeleted {
+			continue
+		}
+		policy := application.RetentionPolicy.Merge(retentionConfig.KeepLastDeployments, retentionConfig.KeepDays)
+		if !policy.IsEnabled() {
+			continue
+		}
+		deployments, err := core.FindExpiredDeploymentsByApplicationId(ctx, db, application.ID, policy)
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while fetching expired deployments of application ", application.Name, " \n", err)
+			continue
+		}
+		if len(deployments) == 0 {
+			continue
+		}
+		retainedDeployments, err := core.FindDeploymentsByApplicationId(ctx, db, application.ID)
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while fetching deployments of application ", application.Name, " \n", err)
+			continue
+		}
+		m.removeDeploymentImages(ctx, deployments, retainedDeployments)
+		for _, deployment := range deployments {
+			err = deployment.Delete(ctx, db)
+			if err != nil {
+				logger.CronJobLoggerError.Println("Error while deleting deployment ", deployment.ID, " of application ", application.Name, " \n", err)
+			}
+		}
+		logger.CronJobLogger.Println("Removed ", len(deployments), " old deployments of application ", application.Name)
+	}
+}
+
+// removeDeploymentImages : remove the images built for the deployments from the registry
+// Registry deletes the manifest by digest, which removes all the tags pointing to it.
+// So the image is kept if any of the retained deployments has the same digest (e.g. rebuild of the same commit)
+// Failure is only logged, the deployments are removed anyway
+func (m Manager) removeDeploymentImages(ctx context.Context, deployments []*core.Deployment, allDeployments []*core.Deployment) {
+	swarmManager, err := core.FetchSwarmManager(&m.ServiceManager.DbClient)
+	if err != nil {
+		logger.CronJobLoggerError.Println("Failed to fetch swarm manager to remove images \n", err)
+		return
+	}
+	dockerManager, err := manager.DockerClient(ctx, swarmManager)
+	if err != nil {
+		logger.CronJobLoggerError.Println("Failed to create docker client to remove images \n", err)
+		return
+	}
+	imageRegistryUsername := m.Config.ImageRegistryUsername()
+	imageRegistryPassword := m.Config.ImageRegistryPassword()
+	expiredDeploymentIds := make(map[string]bool)
+	for _, deployment := range deployments {
+		expiredDeploymentIds[deployment.ID] = true
+	}
+	// digests of the images in use
+	retainedDigests := make(map[string]bool)
+	for _, deployment := range allDeployments {
+		if expiredDeploymentIds[deployment.ID] || !deployment.HasBuiltImage() {
+			continue
+		}
+		imageURI := deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI())
+		digest, err := dockerManager.ImageDigest(ctx, imageURI, imageRegistryUsername, imageRegistryPassword)
+		if err != nil {
+			// build might have failed before pushing the image
+			continue
+		}
+		retainedDigests[digest] = true
+	}
+	for _, deployment := range deployments {
+		if !deployment.HasBuiltImage() {
+			continue
+		}
+		imageURI := deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI())
+		// build might have failed before pushing the image
+		digest, err := dockerManager.ImageDigest(ctx, imageURI, imageRegistryUsername, imageRegistryPassword)
+		if err != nil {
+			continue
+		}
+		if retainedDigests[digest] {
+			logger.CronJobLogger.Println("Keeping image ", imageURI, " as it's used by a retained deployment")
+			continue
+		}
+		err = dockerManager.RemoveImageFromRegistry(ctx, imageURI, imageRegistryUsername, imageRegistryPassword, m.Config.ImageRegistryUseTLS())
+		if err != nil {
+			logger.CronJobLoggerError.Println("Failed to remove image ", imageURI, " from registry \n", err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	containermanger "github.com/swiftwave-org/swiftwave/container_manager"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/logger"
+	"gorm.io/gorm"
+	"time"
+)
+
+// stuckIngressRuleDeletionTimeout : deletion of ingress rule is re-queued, if it's not completed within this time
+const stuckIngressRuleDeletionTimeout = 10 * time.Minute
+
+func (m Manager) CleanupApplicationPreviews() {
+	logger.CronJobLogger.Println("Starting cleanup of closed preview environments [cronjob]")
+	for {
+		m.cleanupApplicationPreviews()
+		time.Sleep(1 * time.Minute)
+	}
+}
+
+func (m Manager) cleanupApplicationPreviews() {
+	ctx := context.Background()
+	previews, err := core.FindApplicationPreviewsByStatus(ctx, m.ServiceManager.DbClient, core.ApplicationPreviewStatusDeleting)
+	if err != nil {
+		logger.CronJobLoggerError.Println("Error while fetching preview environments to cleanup \n", err)
+		return
+	}
+	for _, preview := range previews {
+		err = m.cleanupApplicationPreview(ctx, preview)
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while cleaning up preview environment ", preview.Domain, " \n", err)
+		}
+	}
+}
+
+// cleanupApplicationPreview : remove the resources of the preview, one by one
+// Ingress rule is deleted first, as the domain and the application can't be removed while it exists
+func (m Manager) cleanupApplicationPreview(ctx context.Context, preview *core.ApplicationPreview) error {
+	db := m.ServiceManager.DbClient
+	if preview.IngressRuleID != nil {
+		ingressRule := &core.IngressRule{}
+		err := ingressRule.FindById(ctx, db, *preview.IngressRuleID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			// wait for the ingress rule to be deleted, retry if failed or stuck
+			if ingressRule.Status == core.IngressRuleStatusDeleting && time.Since(ingressRule.UpdatedAt) < stuckIngressRuleDeletionTimeout {
+				return nil
+			}
+			if ingressRule.Status != core.IngressRuleStatusDeleting {
+				err = ingressRule.Delete(ctx, db, false)
+				if err != nil {
+					return err
+				}
+			} else {
+				// refresh the time, so that it's not re-queued again in next run
+				err = ingressRule.UpdateStatus(ctx, db, core.IngressRuleStatusDeleting)
+				if err != nil {
+					return err
+				}
+			}
+			return m.WorkerManager.EnqueueIngressRuleDeleteRequest(ingressRule.ID)
+		}
+	}
+	// domain is kept, if any other rule is using it
+	if preview.DomainID != nil {
+		domain := &core.Domain{}
+		err := domain.FindById(ctx, db, *preview.DomainID)
+		if err == nil {
+			err = domain.Delete(ctx, db)
+			if err != nil {
+				logger.CronJobLogger.Println("Domain ", domain.Name, " of preview environment is not removed > ", err.Error())
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	application := &core.Application{}
+	err := application.FindById(ctx, db, preview.PreviewApplicationID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && !application.IsDeleted {
+		deployments, err := core.FindDeploymentsByApplicationId(ctx, db, application.ID)
+		if err != nil {
+			return err
+		}
+		m.removeDeploymentImages(ctx, deployments, deployments)
+		err = application.SoftDelete(ctx, db, containermanger.Manager{})
+		if err != nil {
+			return err
+		}
+		err = m.WorkerManager.EnqueueDeleteApplicationRequest(application.ID)
+		if err != nil {
+			return err
+		}
+	}
+	err = preview.Delete(ctx, db)
+	if err != nil {
+		return err
+	}
+	logger.CronJobLogger.Println("Preview environment ", preview.Domain, " has been removed")
+	return nil
+}
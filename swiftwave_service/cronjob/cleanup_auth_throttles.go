@@ -0,0 +1,27 @@
+package cronjob
+
+import (
+	"context"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/logger"
+	"time"
+)
+
+func (m Manager) CleanupAuthThrottles() {
+	logger.CronJobLogger.Println("Starting cleanup of expired login throttles [cronjob]")
+	for {
+		m.cleanupAuthThrottles()
+		time.Sleep(10 * time.Minute)
+	}
+}
+
+func (m Manager) cleanupAuthThrottles() {
+	count, err := core.DeleteExpiredAuthThrottles(context.Background(), m.ServiceManager.DbClient)
+	if err != nil {
+		logger.CronJobLoggerError.Println("Error while deleting expired login throttles \n", err)
+		return
+	}
+	if count > 0 {
+		logger.CronJobLogger.Println("Deleted", count, "expired login throttles")
+	}
+}
@@ -43,6 +43,16 @@ func (m Manager) Start(nowait bool) {
 	}
 	m.wg.Add(1)
 	go m.EnqueueTimedoutTasks()
+	m.wg.Add(1)
+	go m.ScheduleApplicationJobs()
+	m.wg.Add(1)
+	go m.ApplyRetentionPolicies()
+	m.wg.Add(1)
+	go m.PollGitRepositories()
+	m.wg.Add(1)
+	go m.CleanupApplicationPreviews()
+	m.wg.Add(1)
+	go m.CleanupAuthThrottles()
 	if !nowait {
 		m.wg.Wait()
 	}
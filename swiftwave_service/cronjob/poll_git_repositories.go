@@ -0,0 +1,174 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	gitmanager "github.com/swiftwave-org/swiftwave/git_manager"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/logger"
+	"time"
+)
+
+// maxGitPollingBackoff : upper limit of the delay between polls, after repeated authentication failures
+const maxGitPollingBackoff = 24 * time.Hour
+
+// gitPollingState : schedule of the next poll of an application
+type gitPollingState struct {
+	nextPollAt       time.Time
+	authFailureCount uint
+}
+
+func (m Manager) PollGitRepositories() {
+	logger.CronJobLogger.Println("Starting git polling of applications [cronjob]")
+	// only accessed by this goroutine
+	states := make(map[string]*gitPollingState)
+	for {
+		m.pollGitRepositories(states)
+		time.Sleep(30 * time.Second)
+	}
+}
+
+func (m Manager) pollGitRepositories(states map[string]*gitPollingState) {
+	ctx := context.Background()
+	applications, err := core.FindGitPollingApplications(ctx, m.ServiceManager.DbClient)
+	if err != nil {
+		logger.CronJobLoggerError.Println("Error while fetching applications with git polling \n", err)
+		return
+	}
+	isPolled := make(map[string]bool)
+	for _, application := range applications {
+		isPolled[application.ID] = true
+		state, ok := states[application.ID]
+		if !ok {
+			state = &gitPollingState{}
+			states[application.ID] = state
+		}
+		now := time.Now()
+		if now.Before(state.nextPollAt) {
+			continue
+		}
+		err := m.pollGitRepository(ctx, application)
+		if err != nil && gitmanager.IsAuthError(err) {
+			// backoff exponentially, till the credential is fixed
+			state.authFailureCount++
+			backoff := application.GitPolling.Interval() * time.Duration(1<<min(state.authFailureCount, 10))
+			if backoff > maxGitPollingBackoff {
+				backoff = maxGitPollingBackoff
+			}
+			state.nextPollAt = now.Add(backoff)
+			logger.CronJobLoggerError.Println("Git authentication failed for application ", application.Name, ", next poll after ", backoff.String(), " \n", err)
+			continue
+		}
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while polling git repository of application ", application.Name, " \n", err)
+		}
+		state.authFailureCount = 0
+		state.nextPollAt = now.Add(application.GitPolling.Interval())
+	}
+	// forget the applications, which are not polled anymore
+	for applicationId := range states {
+		if !isPolled[applicationId] {
+			delete(states, applicationId)
+		}
+	}
+}
+
+// pollGitRepository : trigger rebuild of the application, if there is a new commit in the branch or a new tag matching the tag pattern
+func (m Manager) pollGitRepository(ctx context.Context, application *core.Application) error {
+	db := m.ServiceManager.DbClient
+	latestDeployment, err := core.FindLatestDeploymentByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		return err
+	}
+	// wait for the ongoing build to complete
+	if latestDeployment.Status == core.DeploymentStatusPending || latestDeployment.Status == core.DeploymentStatusDeployPending {
+		return nil
+	}
+	// rebuild is done from the current deployment, so compare with it
+	deployment, err := core.FindCurrentDeployedDeploymentByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		deployment = latestDeployment
+	}
+	if deployment.UpstreamType != core.UpstreamTypeGit {
+		return nil
+	}
+	// commit is pinned, tag is followed only if tag pattern is set
+	if deployment.GitReferenceType == core.GitReferenceCommit ||
+		(deployment.GitReferenceType == core.GitReferenceTag && deployment.GitTagPattern == "") {
+		return nil
+	}
+	gitUsername := ""
+	gitPassword := ""
+	gitPrivateKey := ""
+	if deployment.GitCredentialID != nil {
+		var gitCredential core.GitCredential
+		if err := gitCredential.FindById(ctx, db, *deployment.GitCredentialID); err != nil {
+			return errors.New("failed to fetch git credential")
+		}
+		if err := gitCredential.CheckRepositoryAccess(deployment.GitRepositoryURL()); err != nil {
+			return err
+		}
+		gitUsername = gitCredential.Username
+		gitPassword = gitCredential.Password
+		gitPrivateKey = gitCredential.SshPrivateKey
+	}
+	if deployment.GitReferenceType == core.GitReferenceTag {
+		return m.pollGitTags(ctx, application, deployment, latestDeployment, gitUsername, gitPassword, gitPrivateKey)
+	}
+	commitHash, err := gitmanager.FetchLatestCommitHash(deployment.GitRepositoryURL(), deployment.RepositoryBranch, gitUsername, gitPassword, gitPrivateKey)
+	if err != nil {
+		return err
+	}
+	// skip, if the commit is already deployed or has been tried in the latest deployment
+	if commitHash == deployment.CommitHash || commitHash == latestDeployment.CommitHash {
+		return nil
+	}
+	tx := db.Begin()
+	deploymentId, err := application.RebuildApplication(ctx, *tx, commitHash)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = m.WorkerManager.EnqueueBuildApplicationRequest(application.ID, deploymentId)
+	if err != nil {
+		return err
+	}
+	logger.CronJobLogger.Println("New commit ", commitHash, " found in branch ", deployment.RepositoryBranch, " of application ", application.Name, ", rebuild triggered")
+	return nil
+}
+
+// pollGitTags : trigger deployment of the newest tag matching the tag pattern, if it's newer than the deployed tag
+func (m Manager) pollGitTags(ctx context.Context, application *core.Application, deployment *core.Deployment, latestDeployment *core.Deployment, gitUsername string, gitPassword string, gitPrivateKey string) error {
+	db := m.ServiceManager.DbClient
+	tags, err := gitmanager.FetchTags(deployment.GitRepositoryURL(), gitUsername, gitPassword, gitPrivateKey)
+	if err != nil {
+		return err
+	}
+	tag := deployment.LatestAutoDeployTag(tags)
+	// skip, if the tag has been tried in the latest deployment
+	if tag == "" || tag == latestDeployment.RepositoryTag {
+		return nil
+	}
+	tx := db.Begin()
+	deploymentId, err := application.RebuildApplicationFromTag(ctx, *tx, tag)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = m.WorkerManager.EnqueueBuildApplicationRequest(application.ID, deploymentId)
+	if err != nil {
+		return err
+	}
+	logger.CronJobLogger.Println("New tag ", tag, " matching ", deployment.GitTagPattern, " found for application ", application.Name, ", deployment triggered")
+	return nil
+}
@@ -0,0 +1,107 @@
+package cronjob
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/service_manager"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestManager(t *testing.T) Manager {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "swiftwave.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&core.Application{}, &core.Deployment{}); err != nil {
+		t.Fatal(err)
+	}
+	return Manager{ServiceManager: &service_manager.ServiceManager{DbClient: *db}}
+}
+
+func createPolledApplication(t *testing.T, m Manager, id string, deployment core.Deployment) {
+	db := m.ServiceManager.DbClient
+	application := core.Application{
+		ID:         id,
+		Name:       id,
+		GitPolling: core.ApplicationGitPolling{Enabled: true, IntervalSeconds: 120},
+	}
+	if err := db.Create(&application).Error; err != nil {
+		t.Fatal(err)
+	}
+	deployment.ID = id + "-deployment"
+	deployment.ApplicationID = id
+	deployment.UpstreamType = core.UpstreamTypeGit
+	deployment.Status = core.DeploymentStatusDeployed
+	if err := db.Create(&deployment).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPollGitRepositoriesSchedule(t *testing.T) {
+	// git server rejecting the credentials
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	m := newTestManager(t)
+	createPolledApplication(t, m, "pinned", core.Deployment{
+		GitType:          core.GitHttp,
+		GitEndpoint:      server.URL,
+		RepositoryOwner:  "owner",
+		RepositoryName:   "pinned",
+		GitReferenceType: core.GitReferenceCommit,
+		CommitHash:       "0123456789abcdef0123456789abcdef01234567",
+	})
+	createPolledApplication(t, m, "unauthorized", core.Deployment{
+		GitType:          core.GitHttp,
+		GitEndpoint:      server.URL,
+		RepositoryOwner:  "owner",
+		RepositoryName:   "unauthorized",
+		RepositoryBranch: "main",
+		GitReferenceType: core.GitReferenceBranch,
+	})
+	states := map[string]*gitPollingState{
+		"removed": {nextPollAt: time.Now().Add(time.Hour)},
+	}
+
+	m.pollGitRepositories(states)
+	assert.NotContains(t, states, "removed")
+
+	// pinned commit is skipped, and checked again after the interval
+	if assert.Contains(t, states, "pinned") {
+		assert.Zero(t, states["pinned"].authFailureCount)
+		assert.WithinDuration(t, time.Now().Add(2*time.Minute), states["pinned"].nextPollAt, 5*time.Second)
+	}
+	// authentication failure backs off exponentially
+	if assert.Contains(t, states, "unauthorized") {
+		assert.Equal(t, uint(1), states["unauthorized"].authFailureCount)
+		assert.WithinDuration(t, time.Now().Add(4*time.Minute), states["unauthorized"].nextPollAt, 5*time.Second)
+	}
+
+	// not due yet, so nothing changes
+	m.pollGitRepositories(states)
+	assert.Equal(t, uint(1), states["unauthorized"].authFailureCount)
+
+	states["unauthorized"].nextPollAt = time.Time{}
+	m.pollGitRepositories(states)
+	assert.Equal(t, uint(2), states["unauthorized"].authFailureCount)
+	assert.WithinDuration(t, time.Now().Add(8*time.Minute), states["unauthorized"].nextPollAt, 5*time.Second)
+
+	// backoff is capped
+	states["unauthorized"].authFailureCount = 20
+	states["unauthorized"].nextPollAt = time.Time{}
+	m.pollGitRepositories(states)
+	assert.WithinDuration(t, time.Now().Add(maxGitPollingBackoff), states["unauthorized"].nextPollAt, 5*time.Second)
+}
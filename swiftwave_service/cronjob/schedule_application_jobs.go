@@ -0,0 +1,76 @@
+package cronjob
+
+import (
+	"context"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/logger"
+	"time"
+)
+
+func (m Manager) ScheduleApplicationJobs() {
+	logger.CronJobLogger.Println("Starting application job scheduler [cronjob]")
+	for {
+		// wait till the start of next minute
+		now := time.Now()
+		time.Sleep(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+		m.scheduleApplicationJobs(time.Now().Truncate(time.Minute))
+	}
+}
+
+func (m Manager) scheduleApplicationJobs(minute time.Time) {
+	ctx := context.Background()
+	db := m.ServiceManager.DbClient
+	applications, err := core.FindCronJobApplications(ctx, db)
+	if err != nil {
+		logger.CronJobLoggerError.Println("Error while fetching cron job applications \n", err)
+		return
+	}
+	for _, application := range applications {
+		schedule, err := core.ParseCronSchedule(application.JobConfig.Schedule)
+		if err != nil {
+			logger.CronJobLoggerError.Println("Invalid schedule of cron job ", application.Name, " \n", err)
+			continue
+		}
+		if !schedule.Matches(minute) {
+			continue
+		}
+		// skip if already triggered in this minute
+		isTriggered, err := core.IsScheduledJobRunCreatedSince(ctx, db, application.ID, minute)
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while checking runs of cron job ", application.Name, " \n", err)
+			continue
+		}
+		if isTriggered {
+			continue
+		}
+		// runs don't overlap, a run left incomplete for long is considered failed
+		err = core.MarkStaleApplicationJobRunsAsFailed(ctx, db, application.ID, time.Now().Add(-2*application.JobConfig.Timeout()))
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while marking stale runs of cron job ", application.Name, " as failed \n", err)
+		}
+		isActive, err := core.IsApplicationJobRunActive(ctx, db, application.ID)
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while checking runs of cron job ", application.Name, " \n", err)
+			continue
+		}
+		if isActive {
+			logger.CronJobLogger.Println("Skipping cron job ", application.Name, " as previous run is not completed")
+			continue
+		}
+		jobRun := &core.ApplicationJobRun{
+			ApplicationID: application.ID,
+			Trigger:       core.JobRunTriggerSchedule,
+		}
+		err = jobRun.Create(ctx, db)
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while creating run of cron job ", application.Name, " \n", err)
+			continue
+		}
+		err = m.WorkerManager.EnqueueRunApplicationJobRequest(jobRun.ID)
+		if err != nil {
+			logger.CronJobLoggerError.Println("Error while enqueueing run of cron job ", application.Name, " \n", err)
+		} else {
+			logger.CronJobLogger.Println("Cron job ", application.Name, " is enqueued for run")
+		}
+	}
+}
@@ -0,0 +1,4 @@
+-- modify "users" table
+ALTER TABLE "public"."users" ALTER COLUMN "role" SET DEFAULT 'manager';
+-- existing users had unrestricted access before roles were enforced, keep them as admin
+UPDATE "public"."users" SET "role" = 'admin' WHERE "role" IS NULL OR "role" NOT IN ('admin', 'manager');
@@ -0,0 +1,4 @@
+-- reverse: create index "idx_user_application_group" to table: "application_group_permissions"
+DROP INDEX "public"."idx_user_application_group";
+-- reverse: create "application_group_permissions" table
+DROP TABLE "public"."application_group_permissions";
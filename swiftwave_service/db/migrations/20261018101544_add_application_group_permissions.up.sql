@@ -0,0 +1,12 @@
+-- create "application_group_permissions" table
+CREATE TABLE "public"."application_group_permissions" (
+  "id" bigserial NOT NULL,
+  "user_id" bigint NULL,
+  "application_group_id" text NULL,
+  "access" text NULL DEFAULT 'read',
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_application_groups_permissions" FOREIGN KEY ("application_group_id") REFERENCES "public"."application_groups" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "fk_users_application_group_permissions" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_user_application_group" to table: "application_group_permissions"
+CREATE UNIQUE INDEX "idx_user_application_group" ON "public"."application_group_permissions" ("user_id", "application_group_id");
@@ -0,0 +1,4 @@
+-- reverse: create index "idx_user_personal_access_token_name" to table: "personal_access_tokens"
+DROP INDEX "public"."idx_user_personal_access_token_name";
+-- reverse: create "personal_access_tokens" table
+DROP TABLE "public"."personal_access_tokens";
@@ -0,0 +1,16 @@
+-- create "personal_access_tokens" table
+CREATE TABLE "public"."personal_access_tokens" (
+  "id" text NOT NULL,
+  "user_id" bigint NULL,
+  "name" text NULL,
+  "token_hash" text NULL,
+  "scopes" text[] NULL,
+  "expires_at" timestamptz NULL,
+  "last_used_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_personal_access_tokens_token_hash" UNIQUE ("token_hash"),
+  CONSTRAINT "fk_users_personal_access_tokens" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_user_personal_access_token_name" to table: "personal_access_tokens"
+CREATE UNIQUE INDEX "idx_user_personal_access_token_name" ON "public"."personal_access_tokens" ("user_id", "name");
@@ -0,0 +1,10 @@
+-- reverse: create index "idx_audit_logs_username" to table: "audit_logs"
+DROP INDEX "public"."idx_audit_logs_username";
+-- reverse: create index "idx_audit_logs_target_id" to table: "audit_logs"
+DROP INDEX "public"."idx_audit_logs_target_id";
+-- reverse: create index "idx_audit_logs_created_at" to table: "audit_logs"
+DROP INDEX "public"."idx_audit_logs_created_at";
+-- reverse: create index "idx_audit_logs_action" to table: "audit_logs"
+DROP INDEX "public"."idx_audit_logs_action";
+-- reverse: create "audit_logs" table
+DROP TABLE "public"."audit_logs";
@@ -0,0 +1,22 @@
+-- create "audit_logs" table
+CREATE TABLE "public"."audit_logs" (
+  "id" bigserial NOT NULL,
+  "username" text NULL,
+  "ip" text NULL,
+  "source" text NULL,
+  "action" text NULL,
+  "target_id" text NULL,
+  "arguments" text NULL,
+  "outcome" text NULL,
+  "error" text NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- create index "idx_audit_logs_action" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_action" ON "public"."audit_logs" ("action");
+-- create index "idx_audit_logs_created_at" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_created_at" ON "public"."audit_logs" ("created_at");
+-- create index "idx_audit_logs_target_id" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_target_id" ON "public"."audit_logs" ("target_id");
+-- create index "idx_audit_logs_username" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_username" ON "public"."audit_logs" ("username");
@@ -0,0 +1,2 @@
+-- reverse: modify "system_configs" table
+ALTER TABLE "public"."system_configs" DROP COLUMN "oidc_config_default_role", DROP COLUMN "oidc_config_role_mapping", DROP COLUMN "oidc_config_role_claim", DROP COLUMN "oidc_config_username_claim", DROP COLUMN "oidc_config_scopes", DROP COLUMN "oidc_config_redirect_url", DROP COLUMN "oidc_config_client_secret", DROP COLUMN "oidc_config_client_id", DROP COLUMN "oidc_config_issuer_url", DROP COLUMN "oidc_config_enabled";
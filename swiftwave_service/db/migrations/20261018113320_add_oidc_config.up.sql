@@ -0,0 +1,2 @@
+-- modify "system_configs" table
+ALTER TABLE "public"."system_configs" ADD COLUMN "oidc_config_enabled" boolean NULL DEFAULT false, ADD COLUMN "oidc_config_issuer_url" text NULL, ADD COLUMN "oidc_config_client_id" text NULL, ADD COLUMN "oidc_config_client_secret" text NULL, ADD COLUMN "oidc_config_redirect_url" text NULL, ADD COLUMN "oidc_config_scopes" text NULL DEFAULT 'openid profile email', ADD COLUMN "oidc_config_username_claim" text NULL DEFAULT 'preferred_username', ADD COLUMN "oidc_config_role_claim" text NULL DEFAULT 'groups', ADD COLUMN "oidc_config_role_mapping" text NULL, ADD COLUMN "oidc_config_default_role" text NULL;
@@ -0,0 +1,2 @@
+-- modify "environment_variables" table
+ALTER TABLE "public"."environment_variables" ADD COLUMN "is_secret" boolean NULL DEFAULT false, ADD COLUMN "secret_file_env" boolean NULL DEFAULT false;
@@ -0,0 +1,2 @@
+-- reverse: create "auth_throttles" table
+DROP TABLE "public"."auth_throttles";
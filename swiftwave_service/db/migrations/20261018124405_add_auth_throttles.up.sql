@@ -0,0 +1,9 @@
+-- create "auth_throttles" table
+CREATE TABLE "public"."auth_throttles" (
+  "key" text NOT NULL,
+  "failed_attempts" bigint NULL DEFAULT 0,
+  "last_failed_at" timestamptz NULL,
+  "blocked_until" timestamptz NULL,
+  "locked_until" timestamptz NULL,
+  PRIMARY KEY ("key")
+);
@@ -0,0 +1,8 @@
+-- reverse: create index "idx_web_authn_credentials_user_id" to table: "web_authn_credentials"
+DROP INDEX "public"."idx_web_authn_credentials_user_id";
+-- reverse: create "web_authn_credentials" table
+DROP TABLE "public"."web_authn_credentials";
+-- reverse: create index "idx_totp_recovery_codes_user_id" to table: "totp_recovery_codes"
+DROP INDEX "public"."idx_totp_recovery_codes_user_id";
+-- reverse: create "totp_recovery_codes" table
+DROP TABLE "public"."totp_recovery_codes";
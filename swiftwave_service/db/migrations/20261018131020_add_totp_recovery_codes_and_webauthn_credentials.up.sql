@@ -0,0 +1,33 @@
+-- create "totp_recovery_codes" table
+CREATE TABLE "public"."totp_recovery_codes" (
+  "id" bigserial NOT NULL,
+  "user_id" bigint NULL,
+  "code_hash" text NULL,
+  "used_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_users_totp_recovery_codes" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_totp_recovery_codes_user_id" to table: "totp_recovery_codes"
+CREATE INDEX "idx_totp_recovery_codes_user_id" ON "public"."totp_recovery_codes" ("user_id");
+-- create "web_authn_credentials" table
+CREATE TABLE "public"."web_authn_credentials" (
+  "id" bigserial NOT NULL,
+  "user_id" bigint NULL,
+  "name" text NULL,
+  "credential_id" bytea NULL,
+  "public_key" bytea NULL,
+  "attestation_type" text NULL,
+  "transports" text[] NULL,
+  "aaguid" bytea NULL,
+  "sign_count" bigint NULL,
+  "backup_eligible" boolean NULL,
+  "backup_state" boolean NULL,
+  "last_used_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_web_authn_credentials_credential_id" UNIQUE ("credential_id"),
+  CONSTRAINT "fk_users_web_authn_credentials" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_web_authn_credentials_user_id" to table: "web_authn_credentials"
+CREATE INDEX "idx_web_authn_credentials_user_id" ON "public"."web_authn_credentials" ("user_id");
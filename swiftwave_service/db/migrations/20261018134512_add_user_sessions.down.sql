@@ -0,0 +1,6 @@
+-- reverse: create index "idx_user_sessions_user_id" to table: "user_sessions"
+DROP INDEX "public"."idx_user_sessions_user_id";
+-- reverse: create index "idx_user_sessions_previous_refresh_token_hash" to table: "user_sessions"
+DROP INDEX "public"."idx_user_sessions_previous_refresh_token_hash";
+-- reverse: create "user_sessions" table
+DROP TABLE "public"."user_sessions";
@@ -0,0 +1,20 @@
+-- create "user_sessions" table
+CREATE TABLE "public"."user_sessions" (
+  "id" text NOT NULL,
+  "user_id" bigint NULL,
+  "refresh_token_hash" text NULL,
+  "previous_refresh_token_hash" text NULL,
+  "ip" text NULL,
+  "user_agent" text NULL,
+  "expires_at" timestamptz NULL,
+  "last_refreshed_at" timestamptz NULL,
+  "revoked_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_user_sessions_refresh_token_hash" UNIQUE ("refresh_token_hash"),
+  CONSTRAINT "fk_users_sessions" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_user_sessions_previous_refresh_token_hash" to table: "user_sessions"
+CREATE INDEX "idx_user_sessions_previous_refresh_token_hash" ON "public"."user_sessions" ("previous_refresh_token_hash");
+-- create index "idx_user_sessions_user_id" to table: "user_sessions"
+CREATE INDEX "idx_user_sessions_user_id" ON "public"."user_sessions" ("user_id");
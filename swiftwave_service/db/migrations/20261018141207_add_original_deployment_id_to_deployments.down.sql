@@ -0,0 +1,2 @@
+-- reverse: modify "deployments" table
+ALTER TABLE "public"."deployments" DROP COLUMN "original_deployment_id";
@@ -0,0 +1,2 @@
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "auto_rollback_window_seconds", DROP COLUMN "auto_rollback_enabled";
@@ -0,0 +1,4 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "auto_rollback_enabled" boolean NULL, ADD COLUMN "auto_rollback_window_seconds" bigint NULL DEFAULT 120;
+-- enable auto rollback for existing applications
+UPDATE "public"."applications" SET "auto_rollback_enabled" = true;
@@ -0,0 +1,2 @@
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "update_config_max_failure_ratio", DROP COLUMN "update_config_monitor_seconds", DROP COLUMN "update_config_failure_action", DROP COLUMN "update_config_order", DROP COLUMN "update_config_delay_seconds", DROP COLUMN "update_config_parallelism";
@@ -0,0 +1,4 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "update_config_parallelism" bigint NULL, ADD COLUMN "update_config_delay_seconds" bigint NULL DEFAULT 0, ADD COLUMN "update_config_order" text NULL DEFAULT 'stop-first', ADD COLUMN "update_config_failure_action" text NULL DEFAULT 'pause', ADD COLUMN "update_config_monitor_seconds" bigint NULL DEFAULT 5, ADD COLUMN "update_config_max_failure_ratio" numeric NULL DEFAULT 0;
+-- keep docker's default parallelism for existing applications
+UPDATE "public"."applications" SET "update_config_parallelism" = 1;
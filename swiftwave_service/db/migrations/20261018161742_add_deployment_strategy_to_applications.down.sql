@@ -0,0 +1,2 @@
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "candidate_traffic_percent", DROP COLUMN "candidate_deployment_id", DROP COLUMN "deployment_strategy";
@@ -0,0 +1,2 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "deployment_strategy" text NULL DEFAULT 'rolling', ADD COLUMN "candidate_deployment_id" text NULL, ADD COLUMN "candidate_traffic_percent" bigint NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "release_commands_timeout_seconds", DROP COLUMN "release_commands_post_deploy", DROP COLUMN "release_commands_pre_deploy";
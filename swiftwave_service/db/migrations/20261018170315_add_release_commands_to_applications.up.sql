@@ -0,0 +1,2 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "release_commands_pre_deploy" text NULL, ADD COLUMN "release_commands_post_deploy" text NULL, ADD COLUMN "release_commands_timeout_seconds" bigint NULL DEFAULT 600;
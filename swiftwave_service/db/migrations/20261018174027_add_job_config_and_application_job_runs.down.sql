@@ -0,0 +1,6 @@
+-- reverse: create index "idx_application_job_runs_application_id" to table: "application_job_runs"
+DROP INDEX "public"."idx_application_job_runs_application_id";
+-- reverse: create "application_job_runs" table
+DROP TABLE "public"."application_job_runs";
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "job_timeout_seconds", DROP COLUMN "job_schedule";
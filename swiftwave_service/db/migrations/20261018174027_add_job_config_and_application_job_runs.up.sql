@@ -0,0 +1,19 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "job_schedule" text NULL, ADD COLUMN "job_timeout_seconds" bigint NULL DEFAULT 3600;
+-- create "application_job_runs" table
+CREATE TABLE "public"."application_job_runs" (
+  "id" bigserial NOT NULL,
+  "application_id" text NULL,
+  "deployment_id" text NULL,
+  "trigger" text NULL,
+  "status" text NULL,
+  "exit_code" bigint NULL,
+  "logs" text NULL,
+  "created_at" timestamptz NULL,
+  "started_at" timestamptz NULL,
+  "completed_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_applications_job_runs" FOREIGN KEY ("application_id") REFERENCES "public"."applications" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_application_job_runs_application_id" to table: "application_job_runs"
+CREATE INDEX "idx_application_job_runs_application_id" ON "public"."application_job_runs" ("application_id");
@@ -0,0 +1,4 @@
+-- reverse: modify "system_configs" table
+ALTER TABLE "public"."system_configs" DROP COLUMN "retention_config_keep_days", DROP COLUMN "retention_config_keep_last_deployments";
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "retention_keep_days", DROP COLUMN "retention_keep_last_deployments";
@@ -0,0 +1,4 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "retention_keep_last_deployments" bigint NULL, ADD COLUMN "retention_keep_days" bigint NULL;
+-- modify "system_configs" table
+ALTER TABLE "public"."system_configs" ADD COLUMN "retention_config_keep_last_deployments" bigint NULL, ADD COLUMN "retention_config_keep_days" bigint NULL;
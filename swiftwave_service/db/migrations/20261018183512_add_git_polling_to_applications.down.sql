@@ -0,0 +1,2 @@
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "git_polling_interval_seconds", DROP COLUMN "git_polling_enabled";
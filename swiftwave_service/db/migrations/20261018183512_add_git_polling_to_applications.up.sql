@@ -0,0 +1,2 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "git_polling_enabled" boolean NULL, ADD COLUMN "git_polling_interval_seconds" bigint NULL DEFAULT 300;
@@ -0,0 +1,4 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "webhook_secret" text NULL;
+-- generate webhook secret for existing applications
+UPDATE "public"."applications" SET webhook_secret = gen_random_uuid() WHERE webhook_secret IS NULL;
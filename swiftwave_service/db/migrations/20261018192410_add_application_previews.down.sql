@@ -0,0 +1,10 @@
+-- reverse: create index "idx_application_preview_pull_request" to table: "application_previews"
+DROP INDEX "public"."idx_application_preview_pull_request";
+-- reverse: create "application_previews" table
+DROP TABLE "public"."application_previews";
+-- reverse: create index "idx_preview_environment_variables_application_id" to table: "preview_environment_variables"
+DROP INDEX "public"."idx_preview_environment_variables_application_id";
+-- reverse: create "preview_environment_variables" table
+DROP TABLE "public"."preview_environment_variables";
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "preview_target_port", DROP COLUMN "preview_base_domain", DROP COLUMN "preview_enabled";
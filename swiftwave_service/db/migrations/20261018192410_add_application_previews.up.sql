@@ -0,0 +1,32 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "preview_enabled" boolean NULL DEFAULT false, ADD COLUMN "preview_base_domain" text NULL, ADD COLUMN "preview_target_port" bigint NULL;
+-- create "preview_environment_variables" table
+CREATE TABLE "public"."preview_environment_variables" (
+  "id" bigserial NOT NULL,
+  "application_id" text NULL,
+  "key" text NULL,
+  "value" text NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_applications_preview_environment_variables" FOREIGN KEY ("application_id") REFERENCES "public"."applications" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_preview_environment_variables_application_id" to table: "preview_environment_variables"
+CREATE INDEX "idx_preview_environment_variables_application_id" ON "public"."preview_environment_variables" ("application_id");
+-- create "application_previews" table
+CREATE TABLE "public"."application_previews" (
+  "id" bigserial NOT NULL,
+  "application_id" text NULL,
+  "pull_request_number" bigint NULL,
+  "preview_application_id" text NULL,
+  "branch" text NULL,
+  "commit_hash" text NULL,
+  "domain" text NULL,
+  "domain_id" bigint NULL,
+  "ingress_rule_id" bigint NULL,
+  "status" text NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_applications_previews" FOREIGN KEY ("application_id") REFERENCES "public"."applications" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_application_preview_pull_request" to table: "application_previews"
+CREATE UNIQUE INDEX "idx_application_preview_pull_request" ON "public"."application_previews" ("application_id", "pull_request_number");
@@ -0,0 +1,2 @@
+-- modify "deployments" table
+ALTER TABLE "public"."deployments" ADD COLUMN "config_snapshot" text NULL;
@@ -0,0 +1,2 @@
+-- reverse: modify "deployments" table
+ALTER TABLE "public"."deployments" DROP COLUMN "git_tag_pattern", DROP COLUMN "repository_tag", DROP COLUMN "git_reference_type";
@@ -0,0 +1,2 @@
+-- modify "deployments" table
+ALTER TABLE "public"."deployments" ADD COLUMN "git_reference_type" text NULL DEFAULT 'branch', ADD COLUMN "repository_tag" text NULL, ADD COLUMN "git_tag_pattern" text NULL;
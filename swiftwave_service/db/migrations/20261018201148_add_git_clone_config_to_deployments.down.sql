@@ -0,0 +1,2 @@
+-- reverse: modify "deployments" table
+ALTER TABLE "public"."deployments" DROP COLUMN "git_clone_shallow", DROP COLUMN "git_clone_lfs", DROP COLUMN "git_clone_submodules";
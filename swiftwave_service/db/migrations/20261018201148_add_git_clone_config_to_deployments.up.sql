@@ -0,0 +1,4 @@
+-- modify "deployments" table
+ALTER TABLE "public"."deployments" ADD COLUMN "git_clone_submodules" boolean NULL DEFAULT true, ADD COLUMN "git_clone_lfs" boolean NULL DEFAULT false, ADD COLUMN "git_clone_shallow" boolean NULL DEFAULT true;
+-- existing deployments keep the submodules and shallow clone, new deployments set the config explicitly
+ALTER TABLE "public"."deployments" ALTER COLUMN "git_clone_submodules" DROP DEFAULT, ALTER COLUMN "git_clone_lfs" DROP DEFAULT, ALTER COLUMN "git_clone_shallow" DROP DEFAULT;
@@ -0,0 +1,4 @@
+-- reverse: create "build_secrets" table
+DROP TABLE "public"."build_secrets";
+-- reverse: modify "deployments" table
+ALTER TABLE "public"."deployments" DROP COLUMN "dockerfile_target";
@@ -0,0 +1,10 @@
+-- modify "deployments" table
+ALTER TABLE "public"."deployments" ADD COLUMN "dockerfile_target" text NULL;
+-- create "build_secrets" table
+CREATE TABLE "public"."build_secrets" (
+  "id" bigserial NOT NULL,
+  "deployment_id" text NULL,
+  "key" text NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_deployments_build_secrets" FOREIGN KEY ("deployment_id") REFERENCES "public"."deployments" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
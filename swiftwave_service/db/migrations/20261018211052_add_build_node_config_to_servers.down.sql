@@ -0,0 +1,2 @@
+-- reverse: modify "servers" table
+ALTER TABLE "public"."servers" DROP COLUMN "max_concurrent_builds", DROP COLUMN "is_build_node";
@@ -0,0 +1,2 @@
+-- modify "servers" table
+ALTER TABLE "public"."servers" ADD COLUMN "is_build_node" boolean NULL DEFAULT false, ADD COLUMN "max_concurrent_builds" bigint NULL DEFAULT 1;
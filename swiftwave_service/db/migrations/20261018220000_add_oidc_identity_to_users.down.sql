@@ -0,0 +1,4 @@
+-- reverse: create index "idx_user_oidc_identity" to table: "users"
+DROP INDEX "public"."idx_user_oidc_identity";
+-- reverse: modify "users" table
+ALTER TABLE "public"."users" DROP COLUMN "is_sso_managed", DROP COLUMN "oidc_subject", DROP COLUMN "oidc_issuer";
@@ -0,0 +1,4 @@
+-- modify "users" table
+ALTER TABLE "public"."users" ADD COLUMN "oidc_issuer" text NULL, ADD COLUMN "oidc_subject" text NULL, ADD COLUMN "is_sso_managed" boolean NULL DEFAULT false;
+-- create index "idx_user_oidc_identity" to table: "users"
+CREATE INDEX "idx_user_oidc_identity" ON "public"."users" ("oidc_issuer", "oidc_subject");
@@ -0,0 +1,3 @@
+-- modify "applications" table
+-- existing applications accept unsigned git webhooks, till the first signed webhook is received
+ALTER TABLE "public"."applications" ADD COLUMN "webhook_signature_required" boolean NULL DEFAULT false;
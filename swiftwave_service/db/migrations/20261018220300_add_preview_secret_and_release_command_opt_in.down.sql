@@ -0,0 +1,2 @@
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" DROP COLUMN "preview_run_release_commands", DROP COLUMN "preview_copy_secrets";
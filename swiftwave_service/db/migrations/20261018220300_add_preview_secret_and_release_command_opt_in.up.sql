@@ -0,0 +1,2 @@
+-- modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "preview_copy_secrets" boolean NULL DEFAULT false, ADD COLUMN "preview_run_release_commands" boolean NULL DEFAULT false;
@@ -0,0 +1,2 @@
+-- reverse: modify "git_credentials" table
+ALTER TABLE "public"."git_credentials" DROP COLUMN "allowed_hosts";
@@ -0,0 +1,8 @@
+-- modify "git_credentials" table
+ALTER TABLE "public"."git_credentials" ADD COLUMN "allowed_hosts" text[] NULL;
+-- allow the existing credentials for the hosts of the repositories those are already used for
+UPDATE "public"."git_credentials" SET "allowed_hosts" = (
+  SELECT array_agg(DISTINCT regexp_replace(lower("deployments"."git_endpoint"), '^(https?://|ssh://)?([^@/]*@)?([^:/]+).*$', '\3'))
+  FROM "public"."deployments"
+  WHERE "deployments"."git_credential_id" = "git_credentials"."id" AND "deployments"."git_endpoint" <> ''
+);
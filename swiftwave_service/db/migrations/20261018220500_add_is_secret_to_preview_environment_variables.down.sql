@@ -0,0 +1,2 @@
+-- reverse: modify "preview_environment_variables" table
+ALTER TABLE "public"."preview_environment_variables" DROP COLUMN "is_secret";
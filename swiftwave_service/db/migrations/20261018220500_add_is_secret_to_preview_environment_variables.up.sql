@@ -0,0 +1,4 @@
+-- modify "preview_environment_variables" table
+ALTER TABLE "public"."preview_environment_variables" ADD COLUMN "is_secret" boolean NULL DEFAULT false;
+-- overrides of secret environment variables are secret as well
+UPDATE "public"."preview_environment_variables" SET "is_secret" = true FROM "public"."environment_variables" WHERE "environment_variables"."application_id" = "preview_environment_variables"."application_id" AND "environment_variables"."key" = "preview_environment_variables"."key" AND "environment_variables"."is_secret" = true;
@@ -0,0 +1,4 @@
+-- reverse: modify "applications" table
+ALTER TABLE "public"."applications" ADD COLUMN "webhook_signature_required" boolean NULL DEFAULT false;
+UPDATE "public"."applications" SET "webhook_signature_required" = NOT COALESCE("allow_unsigned_webhooks", false);
+ALTER TABLE "public"."applications" DROP COLUMN "allow_unsigned_webhooks";
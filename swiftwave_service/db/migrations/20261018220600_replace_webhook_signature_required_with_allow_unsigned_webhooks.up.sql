@@ -0,0 +1,4 @@
+-- modify "applications" table
+-- unsigned git webhooks are rejected for every application, accepting them is an explicit opt-in of the application
+-- applications which accepted unsigned webhooks till now (webhook_signature_required = false) have to configure the webhook secret in the git provider, or opt in to unsigned webhooks
+ALTER TABLE "public"."applications" DROP COLUMN "webhook_signature_required", ADD COLUMN "allow_unsigned_webhooks" boolean NULL DEFAULT false;
@@ -16,6 +16,7 @@ func main() {
 		&core.Server{},
 		&core.ServerLog{},
 		&core.User{},
+		&core.ApplicationGroupPermission{},
 		&core.Domain{},
 		&core.RedirectRule{},
 		&core.PersistentVolume{},
@@ -29,12 +30,22 @@ func main() {
 		&core.PersistentVolumeBinding{},
 		&core.Deployment{},
 		&core.BuildArg{},
+		&core.BuildSecret{},
 		&core.DeploymentLog{},
+		&core.ApplicationJobRun{},
+		&core.PreviewEnvironmentVariable{},
+		&core.ApplicationPreview{},
 		&SSL.KeyAuthorizationToken{},
 		&core.PersistentVolumeBackup{},
 		&core.PersistentVolumeRestore{},
 		&core.ConsoleToken{},
 		&core.AnalyticsServiceToken{},
+		&core.PersonalAccessToken{},
+		&core.TotpRecoveryCode{},
+		&core.WebAuthnCredential{},
+		&core.UserSession{},
+		&core.AuditLog{},
+		&core.AuthThrottle{},
 		&core.ServerResourceStat{},
 		&core.ApplicationServiceResourceStat{},
 		&core.AppBasicAuthAccessControlList{},
@@ -0,0 +1,125 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format of the encrypted value
+// swenc:v1:<master key id>:<data key encrypted by master key>:<value encrypted by data key>
+// Each value is encrypted by a random data key, and the data key is encrypted by the master key
+const encryptedValuePrefix = "swenc:v1:"
+
+// Format of the plaintext value, stored when encryption is not enabled
+// Plaintext is stored as it is, unless it starts with one of the prefixes. Then it's stored as swplain:<value>,
+// so that a plaintext value can never be mistaken as an encrypted value
+const plaintextValuePrefix = "swplain:"
+
+// IsEncrypted : check if the stored value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+// Encrypt : encrypt the plaintext with a new data key, return the value to store
+// The argument is always treated as plaintext, even if it looks like an encrypted value
+// If encryption is not enabled, plaintext is returned as it is (escaped if required)
+func Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return plaintext, nil
+	}
+	masterKeyID, masterKey, ok := masterKeys.activeKey()
+	if !ok {
+		if IsEncrypted(plaintext) || strings.HasPrefix(plaintext, plaintextValuePrefix) {
+			return plaintextValuePrefix + plaintext, nil
+		}
+		return plaintext, nil
+	}
+	dataKey := make([]byte, masterKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	encryptedDataKey, err := seal(masterKey, dataKey)
+	if err != nil {
+		return "", err
+	}
+	encryptedValue, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s:%s:%s", encryptedValuePrefix, masterKeyID,
+		base64.RawStdEncoding.EncodeToString(encryptedDataKey),
+		base64.RawStdEncoding.EncodeToString(encryptedValue)), nil
+}
+
+// Decrypt : decrypt the stored value, return the plaintext as is if it's not encrypted
+func Decrypt(value string) (string, error) {
+	if strings.HasPrefix(value, plaintextValuePrefix) {
+		return strings.TrimPrefix(value, plaintextValuePrefix), nil
+	}
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted value")
+	}
+	masterKey, ok := masterKeys.key(parts[0])
+	if !ok {
+		return "", fmt.Errorf("master key %s not found to decrypt the value", parts[0])
+	}
+	encryptedDataKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.New("malformed encrypted value")
+	}
+	encryptedValue, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.New("malformed encrypted value")
+	}
+	dataKey, err := open(masterKey, encryptedDataKey)
+	if err != nil {
+		return "", errors.New("failed to decrypt data key, master key is invalid")
+	}
+	plaintext, err := open(dataKey, encryptedValue)
+	if err != nil {
+		return "", errors.New("failed to decrypt the value")
+	}
+	return string(plaintext), nil
+}
+
+// seal : encrypt with AES-256-GCM, nonce is prepended to the ciphertext
+func seal(key []byte, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key []byte, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
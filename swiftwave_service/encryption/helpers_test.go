@@ -0,0 +1,23 @@
+package encryption
+
+import (
+	"testing"
+)
+
+// useMasterKeys : configure the master keys for the test, encryption is disabled again on cleanup
+func useMasterKeys(t *testing.T, activeKey string, fallbackKeys ...string) {
+	if err := SetMasterKeys(activeKey, fallbackKeys...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = SetMasterKeys("")
+	})
+}
+
+func mustGenerateMasterKey(t *testing.T) string {
+	key, err := GenerateMasterKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
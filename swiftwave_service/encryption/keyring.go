@@ -0,0 +1,104 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// MasterKeyEnvironmentVariable : environment variable to provide the master key, takes precedence over the config file
+const MasterKeyEnvironmentVariable = "SWIFTWAVE_MASTER_KEY"
+
+// masterKeySize : size of the master key in bytes (AES-256)
+const masterKeySize = 32
+
+type keyring struct {
+	mutex       sync.RWMutex
+	activeKeyID string
+	keys        map[string][]byte
+}
+
+var masterKeys = &keyring{
+	keys: make(map[string][]byte),
+}
+
+// GenerateMasterKey : generate a new random master key, encoded in base64
+func GenerateMasterKey() (string, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseMasterKey : decode the base64 encoded master key
+func ParseMasterKey(encodedKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, errors.New("master key should be base64 encoded")
+	}
+	if len(key) != masterKeySize {
+		return nil, errors.New("master key should be of 32 bytes")
+	}
+	return key, nil
+}
+
+// SetMasterKeys : set the master key used for encryption
+// fallbackKeys are only used to decrypt the values encrypted by the older keys (e.g. during key rotation)
+// If activeKey is empty, values are stored in plaintext
+func SetMasterKeys(activeKey string, fallbackKeys ...string) error {
+	keys := make(map[string][]byte)
+	activeKeyID := ""
+	for i, encodedKey := range append([]string{activeKey}, fallbackKeys...) {
+		if strings.TrimSpace(encodedKey) == "" {
+			continue
+		}
+		key, err := ParseMasterKey(encodedKey)
+		if err != nil {
+			return err
+		}
+		id := keyID(key)
+		keys[id] = key
+		if i == 0 {
+			activeKeyID = id
+		}
+	}
+	masterKeys.mutex.Lock()
+	defer masterKeys.mutex.Unlock()
+	masterKeys.activeKeyID = activeKeyID
+	masterKeys.keys = keys
+	return nil
+}
+
+// IsEnabled : check if a master key is configured for encryption
+func IsEnabled() bool {
+	masterKeys.mutex.RLock()
+	defer masterKeys.mutex.RUnlock()
+	return masterKeys.activeKeyID != ""
+}
+
+func (k *keyring) activeKey() (string, []byte, bool) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	if k.activeKeyID == "" {
+		return "", nil, false
+	}
+	return k.activeKeyID, k.keys[k.activeKeyID], true
+}
+
+func (k *keyring) key(id string) ([]byte, bool) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	key, ok := k.keys[id]
+	return key, ok
+}
+
+// keyID : short fingerprint of the master key, stored with the value to find the key for decryption
+func keyID(key []byte) string {
+	hash := sha256.Sum256(key)
+	return hex.EncodeToString(hash[:4])
+}
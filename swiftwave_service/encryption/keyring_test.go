@@ -0,0 +1,63 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMasterKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "generated key", key: mustGenerateMasterKey(t)},
+		{name: "surrounding whitespace", key: " " + mustGenerateMasterKey(t) + "\n"},
+		{name: "not base64", key: "not-a-key!", wantErr: true},
+		{name: "short key", key: base64.StdEncoding.EncodeToString(make([]byte, 16)), wantErr: true},
+		{name: "empty", key: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseMasterKey(tt.key)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, key, masterKeySize)
+		})
+	}
+}
+
+func TestSetMasterKeys(t *testing.T) {
+	activeKey := mustGenerateMasterKey(t)
+	previousKey := mustGenerateMasterKey(t)
+
+	t.Run("disabled without active key", func(t *testing.T) {
+		useMasterKeys(t, "", previousKey)
+		assert.False(t, IsEnabled())
+		_, _, ok := masterKeys.activeKey()
+		assert.False(t, ok)
+	})
+
+	t.Run("active and fallback keys", func(t *testing.T) {
+		useMasterKeys(t, activeKey, previousKey, "")
+		assert.True(t, IsEnabled())
+		activeID, _, ok := masterKeys.activeKey()
+		assert.True(t, ok)
+		parsedActiveKey, _ := ParseMasterKey(activeKey)
+		parsedPreviousKey, _ := ParseMasterKey(previousKey)
+		assert.Equal(t, keyID(parsedActiveKey), activeID)
+		_, ok = masterKeys.key(keyID(parsedPreviousKey))
+		assert.True(t, ok)
+	})
+
+	t.Run("invalid key keeps the current keys", func(t *testing.T) {
+		useMasterKeys(t, activeKey)
+		assert.Error(t, SetMasterKeys(activeKey, "invalid"))
+		assert.True(t, IsEnabled())
+	})
+}
@@ -0,0 +1,85 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+)
+
+// SerializerName : name of the gorm serializer, use `gorm:"serializer:encrypted"` on string fields to encrypt at rest
+const SerializerName = "encrypted"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Serializer : gorm serializer to transparently encrypt and decrypt string fields
+type Serializer struct{}
+
+// Scan implements serializer interface
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+		value = ""
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported type %T for encrypted field %s", dbValue, field.Name)
+	}
+	plaintext, err := Decrypt(value)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s > %w", field.Name, err)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+// Value implements serializer interface
+func (Serializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T for encrypted field %s", fieldValue, field.Name)
+	}
+	return Encrypt(value)
+}
+
+// ReEncrypt : re-encrypt the encrypted fields of all the records of the models with the active master key
+// Plaintext values are encrypted as well. Run it inside a transaction
+func ReEncrypt(db *gorm.DB, models ...interface{}) (int64, error) {
+	var count int64
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return count, err
+		}
+		columns := make([]string, 0)
+		for _, field := range stmt.Schema.Fields {
+			if _, ok := field.Serializer.(Serializer); ok {
+				columns = append(columns, field.DBName)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		records := reflect.New(reflect.SliceOf(reflect.TypeOf(model)))
+		err := db.Model(model).FindInBatches(records.Interface(), 100, func(_ *gorm.DB, _ int) error {
+			for i := 0; i < records.Elem().Len(); i++ {
+				record := records.Elem().Index(i).Interface()
+				if err := db.Session(&gorm.Session{NewDB: true}).Model(record).Select(columns).Updates(record).Error; err != nil {
+					return err
+				}
+				count++
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return count, fmt.Errorf("failed to re-encrypt %s > %w", stmt.Schema.Table, err)
+		}
+	}
+	return count, nil
+}
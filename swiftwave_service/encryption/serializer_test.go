@@ -0,0 +1,130 @@
+package encryption
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testSecret struct {
+	ID    uint   `gorm:"primaryKey"`
+	Name  string `json:"name"`
+	Value string `json:"value" gorm:"serializer:encrypted"`
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "swiftwave.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testSecret{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// storedValue : raw value of the column, without going through the serializer
+func storedValue(t *testing.T, db *gorm.DB, id uint) string {
+	var value string
+	if err := db.Raw("SELECT value FROM test_secrets WHERE id = ?", id).Scan(&value).Error; err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestSerializerRoundTrip(t *testing.T) {
+	useMasterKeys(t, mustGenerateMasterKey(t))
+	db := newTestDB(t)
+	secret := testSecret{Name: "token", Value: "secret-value"}
+	assert.NoError(t, db.Create(&secret).Error)
+
+	stored := storedValue(t, db, secret.ID)
+	assert.True(t, IsEncrypted(stored))
+	assert.NotContains(t, stored, "secret-value")
+
+	var found testSecret
+	assert.NoError(t, db.First(&found, secret.ID).Error)
+	assert.Equal(t, "secret-value", found.Value)
+}
+
+func TestSerializerDoesNotTrustEncryptedLookingPlaintext(t *testing.T) {
+	db := newTestDB(t)
+	value := encryptedValuePrefix + "unknown:key:value"
+
+	t.Run("encryption disabled", func(t *testing.T) {
+		useMasterKeys(t, "")
+		secret := testSecret{Value: value}
+		assert.NoError(t, db.Create(&secret).Error)
+		var found testSecret
+		assert.NoError(t, db.First(&found, secret.ID).Error)
+		assert.Equal(t, value, found.Value)
+	})
+
+	t.Run("encryption enabled", func(t *testing.T) {
+		useMasterKeys(t, mustGenerateMasterKey(t))
+		secret := testSecret{Value: value}
+		assert.NoError(t, db.Create(&secret).Error)
+		assert.NotEqual(t, value, storedValue(t, db, secret.ID))
+		var found testSecret
+		assert.NoError(t, db.First(&found, secret.ID).Error)
+		assert.Equal(t, value, found.Value)
+	})
+}
+
+func TestReEncryptRotation(t *testing.T) {
+	previousKey := mustGenerateMasterKey(t)
+	newKey := mustGenerateMasterKey(t)
+	db := newTestDB(t)
+
+	// plaintext record, written before encryption was enabled
+	useMasterKeys(t, "")
+	plaintextSecret := testSecret{Name: "plaintext", Value: "plaintext-value"}
+	assert.NoError(t, db.Create(&plaintextSecret).Error)
+	// record encrypted by the previous key
+	useMasterKeys(t, previousKey)
+	encryptedSecret := testSecret{Name: "encrypted", Value: "encrypted-value"}
+	assert.NoError(t, db.Create(&encryptedSecret).Error)
+	assert.NoError(t, db.Create(&testSecret{Name: "empty"}).Error)
+
+	useMasterKeys(t, newKey, previousKey)
+	var count int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = ReEncrypt(tx, &testSecret{})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// previous key is not required anymore
+	useMasterKeys(t, newKey)
+	for _, secret := range []testSecret{plaintextSecret, encryptedSecret} {
+		assert.True(t, IsEncrypted(storedValue(t, db, secret.ID)))
+		var found testSecret
+		assert.NoError(t, db.First(&found, secret.ID).Error)
+		assert.Equal(t, secret.Value, found.Value)
+	}
+}
+
+func TestReEncryptFailsWithoutPreviousKey(t *testing.T) {
+	previousKey := mustGenerateMasterKey(t)
+	db := newTestDB(t)
+	useMasterKeys(t, previousKey)
+	secret := testSecret{Value: "encrypted-value"}
+	assert.NoError(t, db.Create(&secret).Error)
+	stored := storedValue(t, db, secret.ID)
+
+	useMasterKeys(t, mustGenerateMasterKey(t))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := ReEncrypt(tx, &testSecret{})
+		return err
+	})
+	assert.Error(t, err)
+	assert.Equal(t, stored, storedValue(t, db, secret.ID))
+}
@@ -10,8 +10,10 @@ import (
 	"time"
 
 	gitmanager "github.com/swiftwave-org/swiftwave/git_manager"
+	haproxymanager "github.com/swiftwave-org/swiftwave/haproxy_manager"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+	"gorm.io/gorm"
 )
 
 // EnvironmentVariables is the resolver for the environmentVariables field.
@@ -147,8 +149,75 @@ func (r *applicationResolver) ApplicationGroup(ctx context.Context, obj *model.A
 	return applicationGroupToGraphqlObject(record), nil
 }
 
+// CandidateDeployment is the resolver for the candidateDeployment field.
+func (r *applicationResolver) CandidateDeployment(ctx context.Context, obj *model.Application) (*model.Deployment, error) {
+	if obj.CandidateDeploymentID == nil {
+		return nil, nil
+	}
+	var record = &core.Deployment{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, *obj.CandidateDeploymentID)
+	if err != nil {
+		return nil, err
+	}
+	return deploymentToGraphqlObject(record), nil
+}
+
+// JobRuns is the resolver for the jobRuns field.
+func (r *applicationResolver) JobRuns(ctx context.Context, obj *model.Application) ([]*model.ApplicationJobRun, error) {
+	records, err := core.FindApplicationJobRunsByApplicationId(ctx, r.ServiceManager.DbClient, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*model.ApplicationJobRun, 0)
+	for _, record := range records {
+		result = append(result, applicationJobRunToGraphqlObject(record))
+	}
+	return result, nil
+}
+
+// PreviewEnvironmentVariables is the resolver for the previewEnvironmentVariables field.
+func (r *applicationResolver) PreviewEnvironmentVariables(ctx context.Context, obj *model.Application) ([]*model.PreviewEnvironmentVariable, error) {
+	records, err := core.FindPreviewEnvironmentVariablesByApplicationId(ctx, r.ServiceManager.DbClient, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	// environment variable can be turned to secret after the override has been saved
+	environmentVariables, err := core.FindEnvironmentVariablesByApplicationId(ctx, r.ServiceManager.DbClient, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	secretKeys := make(map[string]bool)
+	for _, environmentVariable := range environmentVariables {
+		if environmentVariable.IsSecret {
+			secretKeys[environmentVariable.Key] = true
+		}
+	}
+	var result = make([]*model.PreviewEnvironmentVariable, 0)
+	for _, record := range records {
+		record.IsSecret = record.IsSecret || secretKeys[record.Key]
+		result = append(result, previewEnvironmentVariableToGraphqlObject(record))
+	}
+	return result, nil
+}
+
+// Previews is the resolver for the previews field.
+func (r *applicationResolver) Previews(ctx context.Context, obj *model.Application) ([]*model.ApplicationPreview, error) {
+	records, err := core.FindApplicationPreviewsByApplicationId(ctx, r.ServiceManager.DbClient, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*model.ApplicationPreview, 0)
+	for _, record := range records {
+		result = append(result, applicationPreviewToGraphqlObject(record))
+	}
+	return result, nil
+}
+
 // CreateApplication is the resolver for the createApplication field.
 func (r *mutationResolver) CreateApplication(ctx context.Context, input model.ApplicationInput) (*model.Application, error) {
+	if err := r.checkApplicationGroupAccess(ctx, input.ApplicationGroupID, core.WriteAccess); err != nil {
+		return nil, err
+	}
 	record := applicationInputToDatabaseObject(&input)
 	// create transaction
 	transaction := r.ServiceManager.DbClient.Begin()
@@ -180,6 +249,12 @@ func (r *mutationResolver) CreateApplication(ctx context.Context, input model.Ap
 
 // UpdateApplication is the resolver for the updateApplication field.
 func (r *mutationResolver) UpdateApplication(ctx context.Context, id string, input model.ApplicationInput) (*model.Application, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return nil, err
+	}
+	if err := r.checkApplicationGroupAccess(ctx, input.ApplicationGroupID, core.WriteAccess); err != nil {
+		return nil, err
+	}
 	// fetch record
 	var record = &core.Application{}
 	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
@@ -199,16 +274,31 @@ func (r *mutationResolver) UpdateApplication(ctx context.Context, id string, inp
 			if err := gitCredential.FindById(ctx, r.ServiceManager.DbClient, *databaseObject.LatestDeployment.GitCredentialID); err != nil {
 				return nil, errors.New("invalid git credential provided")
 			}
+			if err := gitCredential.CheckRepositoryAccess(databaseObject.LatestDeployment.GitRepositoryURL()); err != nil {
+				return nil, err
+			}
 			gitUsername = gitCredential.Username
 			gitPassword = gitCredential.Password
 			gitPrivateKey = gitCredential.SshPrivateKey
 		}
 
-		commitHash, err := gitmanager.FetchLatestCommitHash(databaseObject.LatestDeployment.GitRepositoryURL(), databaseObject.LatestDeployment.RepositoryBranch, gitUsername, gitPassword, gitPrivateKey)
-		if err != nil {
-			return nil, errors.New("failed to fetch latest commit hash")
+		switch databaseObject.LatestDeployment.GitReferenceType {
+		case core.GitReferenceBranch:
+			commitHash, err := gitmanager.FetchLatestCommitHash(databaseObject.LatestDeployment.GitRepositoryURL(), databaseObject.LatestDeployment.RepositoryBranch, gitUsername, gitPassword, gitPrivateKey)
+			if err != nil {
+				return nil, errors.New("failed to fetch latest commit hash")
+			}
+			databaseObject.LatestDeployment.CommitHash = commitHash
+		case core.GitReferenceTag:
+			// keep the commit of the deployed tag, even if the tag has been moved
+			latestDeployment, err := core.FindLatestDeploymentByApplicationId(ctx, r.ServiceManager.DbClient, record.ID)
+			if err != nil {
+				return nil, errors.New("failed to fetch latest deployment")
+			}
+			if latestDeployment.GitReferenceType == core.GitReferenceTag && latestDeployment.RepositoryTag == databaseObject.LatestDeployment.RepositoryTag {
+				databaseObject.LatestDeployment.CommitHash = latestDeployment.CommitHash
+			}
 		}
-		databaseObject.LatestDeployment.CommitHash = commitHash
 	}
 
 	// fetch docker manager
@@ -244,6 +334,12 @@ func (r *mutationResolver) UpdateApplication(ctx context.Context, id string, inp
 
 // UpdateApplicationGroup is the resolver for the updateApplicationGroup field.
 func (r *mutationResolver) UpdateApplicationGroup(ctx context.Context, id string, groupID *string) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
+	if err := r.checkApplicationGroupAccess(ctx, groupID, core.WriteAccess); err != nil {
+		return false, err
+	}
 	var application = &core.Application{}
 	err := application.FindById(ctx, r.ServiceManager.DbClient, id)
 	if err != nil {
@@ -255,6 +351,9 @@ func (r *mutationResolver) UpdateApplicationGroup(ctx context.Context, id string
 
 // DeleteApplication is the resolver for the deleteApplication field.
 func (r *mutationResolver) DeleteApplication(ctx context.Context, id string) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
 	// fetch record
 	var record = &core.Application{}
 	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
@@ -281,13 +380,16 @@ func (r *mutationResolver) DeleteApplication(ctx context.Context, id string) (bo
 
 // RebuildApplication is the resolver for the rebuildApplication field.
 func (r *mutationResolver) RebuildApplication(ctx context.Context, id string) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
 	// Start transaction
 	tx := r.ServiceManager.DbClient.Begin()
 	// fetch record
 	var record = &core.Application{
 		ID: id,
 	}
-	deploymentId, err := record.RebuildApplication(ctx, *tx)
+	deploymentId, err := record.RebuildApplication(ctx, *tx, "")
 	if err != nil {
 		tx.Rollback()
 		return false, err
@@ -306,8 +408,200 @@ func (r *mutationResolver) RebuildApplication(ctx context.Context, id string) (b
 	return true, nil
 }
 
+// RollbackApplication is the resolver for the rollbackApplication field.
+func (r *mutationResolver) RollbackApplication(ctx context.Context, deploymentID string) (bool, error) {
+	// fetch deployment
+	var deployment = &core.Deployment{}
+	err := deployment.FindById(ctx, r.ServiceManager.DbClient, deploymentID)
+	if err != nil {
+		return false, errors.New("deployment not found")
+	}
+	if err := r.checkApplicationAccess(ctx, deployment.ApplicationID, core.WriteAccess); err != nil {
+		return false, err
+	}
+	// verify the image is still available in the registry
+	imageRegistryUsername := r.Config.ImageRegistryUsername()
+	imageRegistryPassword := r.Config.ImageRegistryPassword()
+	if deployment.UpstreamType == core.UpstreamTypeImage {
+		imageRegistryUsername = ""
+		imageRegistryPassword = ""
+		if deployment.ImageRegistryCredentialID != nil && *deployment.ImageRegistryCredentialID != 0 {
+			var imageRegistryCredential core.ImageRegistryCredential
+			err := imageRegistryCredential.FindById(ctx, r.ServiceManager.DbClient, *deployment.ImageRegistryCredentialID)
+			if err != nil {
+				return false, errors.New("failed to fetch image registry credential")
+			}
+			imageRegistryUsername = imageRegistryCredential.Username
+			imageRegistryPassword = imageRegistryCredential.Password
+		}
+	}
+	dockerManager, err := FetchDockerManager(ctx, &r.ServiceManager.DbClient)
+	if err != nil {
+		return false, err
+	}
+	imageURI := deployment.DeployableDockerImageURI(r.Config.ImageRegistryURI())
+	if !dockerManager.ExistsImageInRegistry(ctx, imageURI, imageRegistryUsername, imageRegistryPassword) {
+		return false, errors.New("image " + imageURI + " is not available in the registry anymore, it may have been pruned. Rebuild the application instead")
+	}
+	// Start transaction
+	tx := r.ServiceManager.DbClient.Begin()
+	var record = &core.Application{
+		ID: deployment.ApplicationID,
+	}
+	newDeploymentId, err := record.RollbackApplication(ctx, *tx, deployment.ID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	// commit transaction
+	err = tx.Commit().Error
+	if err != nil {
+		tx.Rollback()
+		return false, errors.New("failed to create new deployment due to database error")
+	}
+	// enqueue deploy request, as the image is already built
+	err = r.WorkerManager.EnqueueDeployApplicationRequest(record.ID, newDeploymentId)
+	if err != nil {
+		return false, errors.New("failed to queue deploy request")
+	}
+	return true, nil
+}
+
+// SetCandidateTrafficSplit is the resolver for the setCandidateTrafficSplit field.
+func (r *mutationResolver) SetCandidateTrafficSplit(ctx context.Context, id string, candidatePercent uint) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
+	if candidatePercent > 100 {
+		return false, errors.New("candidate percent should be between 0 and 100")
+	}
+	var record = &core.Application{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, err
+	}
+	if !record.HasCandidate() {
+		return false, errors.New("application has no candidate deployment")
+	}
+	tx := r.ServiceManager.DbClient.Begin()
+	defer tx.Rollback()
+	err = record.UpdateCandidateTrafficPercent(ctx, *tx, candidatePercent)
+	if err != nil {
+		return false, err
+	}
+	// update weights of the servers in haproxy + commit
+	err = r.RunActionsInAllHAProxyNodes(ctx, tx, func(ctx context.Context, db *gorm.DB, transactionId string, manager *haproxymanager.Manager) error {
+		return runOnApplicationHAProxyBackends(ctx, db, transactionId, manager, record, func(backendProtocol haproxymanager.BackendProtocol, port int) error {
+			return manager.SetTrafficSplit(transactionId, backendProtocol, record.Name, port, record.CandidateServiceName(), int(candidatePercent))
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	// commit to db
+	err = tx.Commit().Error
+	return err == nil, err
+}
+
+// PromoteCandidate is the resolver for the promoteCandidate field.
+func (r *mutationResolver) PromoteCandidate(ctx context.Context, id string) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
+	var record = &core.Application{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, err
+	}
+	if !record.HasCandidate() {
+		return false, errors.New("application has no candidate deployment")
+	}
+	tx := r.ServiceManager.DbClient.Begin()
+	defer tx.Rollback()
+	// route all traffic to candidate, while the application is being updated to the candidate version
+	err = record.UpdateCandidateTrafficPercent(ctx, *tx, 100)
+	if err != nil {
+		return false, err
+	}
+	err = r.RunActionsInAllHAProxyNodes(ctx, tx, func(ctx context.Context, db *gorm.DB, transactionId string, manager *haproxymanager.Manager) error {
+		return runOnApplicationHAProxyBackends(ctx, db, transactionId, manager, record, func(backendProtocol haproxymanager.BackendProtocol, port int) error {
+			return manager.SetTrafficSplit(transactionId, backendProtocol, record.Name, port, record.CandidateServiceName(), 100)
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return false, errors.New("failed to promote candidate due to database error")
+	}
+	// candidate will be removed after the application is updated
+	err = r.WorkerManager.EnqueuePromoteCandidateRequest(record.ID, *record.CandidateDeploymentID)
+	if err != nil {
+		return false, errors.New("failed to queue promote request")
+	}
+	return true, nil
+}
+
+// AbortCandidate is the resolver for the abortCandidate field.
+func (r *mutationResolver) AbortCandidate(ctx context.Context, id string) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
+	var record = &core.Application{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, err
+	}
+	if !record.HasCandidate() {
+		return false, errors.New("application has no candidate deployment")
+	}
+	dockerManager, err := FetchDockerManager(ctx, &r.ServiceManager.DbClient)
+	if err != nil {
+		return false, err
+	}
+	tx := r.ServiceManager.DbClient.Begin()
+	defer tx.Rollback()
+	candidateDeployment := &core.Deployment{}
+	candidateDeployment.ID = *record.CandidateDeploymentID
+	err = candidateDeployment.UpdateStatus(ctx, *tx, core.DeploymentStatusStopped)
+	if err != nil {
+		return false, err
+	}
+	err = record.ClearCandidate(ctx, *tx)
+	if err != nil {
+		return false, err
+	}
+	// route all traffic back to the current version + commit
+	err = r.RunActionsInAllHAProxyNodes(ctx, tx, func(ctx context.Context, db *gorm.DB, transactionId string, manager *haproxymanager.Manager) error {
+		return runOnApplicationHAProxyBackends(ctx, db, transactionId, manager, record, func(backendProtocol haproxymanager.BackendProtocol, port int) error {
+			err := manager.DeleteServerTemplate(transactionId, backendProtocol, record.Name, port, record.CandidateServiceName())
+			if err != nil {
+				return err
+			}
+			return manager.ResetServerWeight(transactionId, backendProtocol, record.Name, port)
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return false, errors.New("failed to abort candidate due to database error")
+	}
+	// remove the candidate service
+	err = dockerManager.RemoveService(record.CandidateServiceName())
+	if err != nil {
+		return false, errors.New("candidate has been detached, but failed to remove the candidate service")
+	}
+	return true, nil
+}
+
 // RestartApplication is the resolver for the restartApplication field.
 func (r *mutationResolver) RestartApplication(ctx context.Context, id string) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
 	// fetch record
 	var record = &core.Application{}
 	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
@@ -331,6 +625,9 @@ func (r *mutationResolver) RestartApplication(ctx context.Context, id string) (b
 
 // RegenerateWebhookToken is the resolver for the regenerateWebhookToken field.
 func (r *mutationResolver) RegenerateWebhookToken(ctx context.Context, id string) (string, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return "", err
+	}
 	// fetch record
 	var record = &core.Application{
 		ID: id,
@@ -343,8 +640,29 @@ func (r *mutationResolver) RegenerateWebhookToken(ctx context.Context, id string
 	return record.WebhookToken, nil
 }
 
+// SetAllowUnsignedWebhooks is the resolver for the setAllowUnsignedWebhooks field.
+func (r *mutationResolver) SetAllowUnsignedWebhooks(ctx context.Context, id string, allow bool) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
+	// fetch record
+	var record = &core.Application{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, err
+	}
+	err = record.SetAllowUnsignedWebhooks(ctx, r.ServiceManager.DbClient, allow)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // SleepApplication is the resolver for the sleepApplication field.
 func (r *mutationResolver) SleepApplication(ctx context.Context, id string) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
 	tx := r.ServiceManager.DbClient.Begin()
 	// fetch record
 	var record = &core.Application{}
@@ -364,10 +682,13 @@ func (r *mutationResolver) SleepApplication(ctx context.Context, id string) (boo
 		tx.Rollback()
 		return false, errors.New("failed to mark application as sleeping due to database error")
 	}
-	// fetch latest deployment
-	latestDeployment, err := core.FindLatestDeploymentByApplicationId(ctx, r.ServiceManager.DbClient, record.ID)
+	// fetch current deployment, candidate is scaled along with it
+	latestDeployment, err := core.FindCurrentDeployedDeploymentByApplicationId(ctx, r.ServiceManager.DbClient, record.ID)
 	if err != nil {
-		return false, errors.New("failed to fetch latest deployment")
+		latestDeployment, err = core.FindLatestDeploymentByApplicationId(ctx, r.ServiceManager.DbClient, record.ID)
+		if err != nil {
+			return false, errors.New("failed to fetch latest deployment")
+		}
 	}
 	// fire deploy request
 	err = r.WorkerManager.EnqueueDeployApplicationRequest(record.ID, latestDeployment.ID)
@@ -379,6 +700,9 @@ func (r *mutationResolver) SleepApplication(ctx context.Context, id string) (boo
 
 // WakeApplication is the resolver for the wakeApplication field.
 func (r *mutationResolver) WakeApplication(ctx context.Context, id string) (bool, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return false, err
+	}
 	tx := r.ServiceManager.DbClient.Begin()
 	// fetch record
 	var record = &core.Application{}
@@ -398,10 +722,13 @@ func (r *mutationResolver) WakeApplication(ctx context.Context, id string) (bool
 		tx.Rollback()
 		return false, errors.New("failed to mark application as sleeping due to database error")
 	}
-	// fetch latest deployment
-	latestDeployment, err := core.FindLatestDeploymentByApplicationId(ctx, r.ServiceManager.DbClient, record.ID)
+	// fetch current deployment, candidate is scaled along with it
+	latestDeployment, err := core.FindCurrentDeployedDeploymentByApplicationId(ctx, r.ServiceManager.DbClient, record.ID)
 	if err != nil {
-		return false, errors.New("failed to fetch latest deployment")
+		latestDeployment, err = core.FindLatestDeploymentByApplicationId(ctx, r.ServiceManager.DbClient, record.ID)
+		if err != nil {
+			return false, errors.New("failed to fetch latest deployment")
+		}
 	}
 	// fire deploy request
 	err = r.WorkerManager.EnqueueDeployApplicationRequest(record.ID, latestDeployment.ID)
@@ -413,6 +740,9 @@ func (r *mutationResolver) WakeApplication(ctx context.Context, id string) (bool
 
 // Application is the resolver for the application field.
 func (r *queryResolver) Application(ctx context.Context, id string) (*model.Application, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.ReadAccess); err != nil {
+		return nil, err
+	}
 	var record = &core.Application{}
 	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
 	if err != nil {
@@ -428,8 +758,15 @@ func (r *queryResolver) Applications(ctx context.Context, includeGroupedApplicat
 	if err != nil {
 		return nil, err
 	}
+	isReadable, err := r.readableApplicationGroupFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var result = make([]*model.Application, 0)
 	for _, record := range records {
+		if !isReadable(record.ApplicationGroupID) {
+			continue
+		}
 		result = append(result, applicationToGraphqlObject(record))
 	}
 	return result, nil
@@ -447,6 +784,9 @@ func (r *queryResolver) IsExistApplicationName(ctx context.Context, name string)
 
 // ApplicationResourceAnalytics is the resolver for the applicationResourceAnalytics field.
 func (r *queryResolver) ApplicationResourceAnalytics(ctx context.Context, id string, timeframe model.ApplicationResourceAnalyticsTimeframe) ([]*model.ApplicationResourceAnalytics, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.ReadAccess); err != nil {
+		return nil, err
+	}
 	var previousTime time.Time = time.Now()
 	switch timeframe {
 	case model.ApplicationResourceAnalyticsTimeframeLast1Hour:
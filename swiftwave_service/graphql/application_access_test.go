@@ -0,0 +1,194 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+// applicationAccessFixture : two applications in different application groups
+// user `restricted` only has read access on the group of the first application
+type applicationAccessFixture struct {
+	resolver          *Resolver
+	adminCtx          context.Context
+	viewerCtx         context.Context
+	restrictedCtx     context.Context
+	allowedApp        *core.Application
+	deniedApp         *core.Application
+	allowedDeployment *core.Deployment
+	deniedDeployment  *core.Deployment
+	allowedRule       *core.IngressRule
+	deniedRule        *core.IngressRule
+	externalRule      *core.IngressRule
+	allowedDomain     *core.Domain
+	deniedDomain      *core.Domain
+	allowedVolume     *core.PersistentVolume
+	deniedVolume      *core.PersistentVolume
+	credential        *core.ImageRegistryCredential
+}
+
+func setupApplicationAccessFixture(t *testing.T) *applicationAccessFixture {
+	r := newTestResolver(t)
+	f := &applicationAccessFixture{resolver: r}
+	_, f.adminCtx = createTestUser(t, r, "admin", core.AdministratorRole)
+	_, f.viewerCtx = createTestUser(t, r, "viewer", core.ViewerRole)
+	restrictedUser, restrictedCtx := createTestUser(t, r, "restricted", core.ViewerRole)
+	f.restrictedCtx = restrictedCtx
+
+	allowedGroup := &core.ApplicationGroup{ID: "group-allowed", Name: "allowed"}
+	deniedGroup := &core.ApplicationGroup{ID: "group-denied", Name: "denied"}
+	mustCreate(t, r, allowedGroup, deniedGroup, &core.ApplicationGroupPermission{
+		UserID:             restrictedUser.ID,
+		ApplicationGroupID: allowedGroup.ID,
+		Access:             core.ReadAccess,
+	})
+
+	f.allowedApp = &core.Application{ID: "app-allowed", Name: "allowed", ApplicationGroupID: &allowedGroup.ID}
+	f.deniedApp = &core.Application{ID: "app-denied", Name: "denied", ApplicationGroupID: &deniedGroup.ID}
+	mustCreate(t, r, f.allowedApp, f.deniedApp)
+
+	f.credential = &core.ImageRegistryCredential{Url: "registry.example.com", Username: "ci", Password: "registry-password"}
+	mustCreate(t, r, f.credential)
+	f.allowedDeployment = &core.Deployment{ID: "deployment-allowed", ApplicationID: f.allowedApp.ID, UpstreamType: core.UpstreamTypeImage, ImageRegistryCredentialID: &f.credential.ID}
+	f.deniedDeployment = &core.Deployment{ID: "deployment-denied", ApplicationID: f.deniedApp.ID, UpstreamType: core.UpstreamTypeImage, ImageRegistryCredentialID: &f.credential.ID}
+	mustCreate(t, r, f.allowedDeployment, f.deniedDeployment)
+
+	f.allowedDomain = &core.Domain{Name: "allowed.example.com"}
+	f.deniedDomain = &core.Domain{Name: "denied.example.com"}
+	mustCreate(t, r, f.allowedDomain, f.deniedDomain)
+	f.allowedRule = &core.IngressRule{TargetType: core.ApplicationIngressRule, ApplicationID: &f.allowedApp.ID, DomainID: &f.allowedDomain.ID, Protocol: core.HTTPProtocol, Port: 80, TargetPort: 80}
+	f.deniedRule = &core.IngressRule{TargetType: core.ApplicationIngressRule, ApplicationID: &f.deniedApp.ID, DomainID: &f.deniedDomain.ID, Protocol: core.HTTPProtocol, Port: 80, TargetPort: 80}
+	f.externalRule = &core.IngressRule{TargetType: core.ExternalServiceIngressRule, ExternalService: "backend", DomainID: &f.deniedDomain.ID, Protocol: core.HTTPProtocol, Port: 80, TargetPort: 8080}
+	mustCreate(t, r, f.allowedRule, f.deniedRule, f.externalRule)
+
+	f.allowedVolume = &core.PersistentVolume{Name: "allowed-data"}
+	f.deniedVolume = &core.PersistentVolume{Name: "denied-data"}
+	mustCreate(t, r, f.allowedVolume, f.deniedVolume)
+	mustCreate(t, r,
+		&core.PersistentVolumeBinding{ApplicationID: f.allowedApp.ID, PersistentVolumeID: f.allowedVolume.ID, MountingPath: "/data"},
+		&core.PersistentVolumeBinding{ApplicationID: f.deniedApp.ID, PersistentVolumeID: f.deniedVolume.ID, MountingPath: "/data"},
+	)
+	return f
+}
+
+func TestNestedApplicationResolversCheckAccess(t *testing.T) {
+	f := setupApplicationAccessFixture(t)
+	r := f.resolver
+
+	t.Run("deployment application", func(t *testing.T) {
+		_, err := r.Deployment().Application(f.restrictedCtx, &model.Deployment{ApplicationID: f.deniedApp.ID})
+		assert.Error(t, err)
+		application, err := r.Deployment().Application(f.restrictedCtx, &model.Deployment{ApplicationID: f.allowedApp.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, f.allowedApp.ID, application.ID)
+		_, err = r.Deployment().Application(f.viewerCtx, &model.Deployment{ApplicationID: f.deniedApp.ID})
+		assert.NoError(t, err)
+	})
+
+	t.Run("ingress rule application", func(t *testing.T) {
+		_, err := r.IngressRule().Application(f.restrictedCtx, ingressRuleToGraphqlObject(f.deniedRule))
+		assert.Error(t, err)
+		application, err := r.IngressRule().Application(f.restrictedCtx, ingressRuleToGraphqlObject(f.allowedRule))
+		assert.NoError(t, err)
+		assert.Equal(t, f.allowedApp.ID, application.ID)
+	})
+
+	t.Run("persistent volume binding application", func(t *testing.T) {
+		_, err := r.PersistentVolumeBinding().Application(f.restrictedCtx, &model.PersistentVolumeBinding{ApplicationID: f.deniedApp.ID})
+		assert.Error(t, err)
+		_, err = r.PersistentVolumeBinding().Application(f.restrictedCtx, &model.PersistentVolumeBinding{ApplicationID: f.allowedApp.ID})
+		assert.NoError(t, err)
+	})
+}
+
+func TestListQueriesAreFilteredByApplicationGroup(t *testing.T) {
+	f := setupApplicationAccessFixture(t)
+	r := f.resolver
+
+	t.Run("ingress rules", func(t *testing.T) {
+		rules, err := r.Query().IngressRules(f.restrictedCtx)
+		assert.NoError(t, err)
+		if assert.Len(t, rules, 1) {
+			assert.Equal(t, f.allowedRule.ID, rules[0].ID)
+		}
+		rules, err = r.Query().IngressRules(f.viewerCtx)
+		assert.NoError(t, err)
+		assert.Len(t, rules, 3)
+
+		_, err = r.Query().IngressRule(f.restrictedCtx, f.deniedRule.ID)
+		assert.Error(t, err)
+		_, err = r.Query().IngressRule(f.restrictedCtx, f.externalRule.ID)
+		assert.Error(t, err)
+		_, err = r.Query().IngressRule(f.restrictedCtx, f.allowedRule.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("domains", func(t *testing.T) {
+		domains, err := r.Query().Domains(f.restrictedCtx)
+		assert.NoError(t, err)
+		if assert.Len(t, domains, 1) {
+			assert.Equal(t, f.allowedDomain.ID, domains[0].ID)
+		}
+		domains, err = r.Query().Domains(f.viewerCtx)
+		assert.NoError(t, err)
+		assert.Len(t, domains, 2)
+
+		_, err = r.Query().Domain(f.restrictedCtx, f.deniedDomain.ID)
+		assert.Error(t, err)
+		rules, err := r.Domain().IngressRules(f.restrictedCtx, domainToGraphqlObject(f.allowedDomain))
+		assert.NoError(t, err)
+		assert.Len(t, rules, 1)
+		rules, err = r.Domain().IngressRules(f.viewerCtx, domainToGraphqlObject(f.deniedDomain))
+		assert.NoError(t, err)
+		assert.Len(t, rules, 2)
+	})
+
+	t.Run("persistent volumes", func(t *testing.T) {
+		volumes, err := r.Query().PersistentVolumes(f.restrictedCtx)
+		assert.NoError(t, err)
+		if assert.Len(t, volumes, 1) {
+			assert.Equal(t, f.allowedVolume.ID, volumes[0].ID)
+		}
+		volumes, err = r.Query().PersistentVolumes(f.viewerCtx)
+		assert.NoError(t, err)
+		assert.Len(t, volumes, 2)
+
+		_, err = r.Query().PersistentVolume(f.restrictedCtx, f.deniedVolume.ID)
+		assert.Error(t, err)
+		_, err = r.Query().PersistentVolume(f.restrictedCtx, f.allowedVolume.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deployments of credential", func(t *testing.T) {
+		deployments, err := r.ImageRegistryCredential().Deployments(f.restrictedCtx, imageRegistryCredentialToGraphqlObject(f.credential))
+		assert.NoError(t, err)
+		if assert.Len(t, deployments, 1) {
+			assert.Equal(t, f.allowedDeployment.ID, deployments[0].ID)
+		}
+		deployments, err = r.ImageRegistryCredential().Deployments(f.viewerCtx, imageRegistryCredentialToGraphqlObject(f.credential))
+		assert.NoError(t, err)
+		assert.Len(t, deployments, 2)
+	})
+}
+
+func TestImageRegistryCredentialPasswordIsOnlyVisibleToAdmin(t *testing.T) {
+	f := setupApplicationAccessFixture(t)
+	r := f.resolver
+	obj := imageRegistryCredentialToGraphqlObject(f.credential)
+
+	password, err := r.ImageRegistryCredential().Password(f.adminCtx, obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "registry-password", password)
+
+	for _, ctx := range []context.Context{f.viewerCtx, f.restrictedCtx} {
+		password, err = r.ImageRegistryCredential().Password(ctx, obj)
+		assert.NoError(t, err)
+		assert.Empty(t, password)
+	}
+
+	_, err = r.ImageRegistryCredential().Password(context.Background(), obj)
+	assert.Error(t, err)
+}
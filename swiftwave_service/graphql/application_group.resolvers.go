@@ -57,8 +57,15 @@ func (r *queryResolver) ApplicationGroups(ctx context.Context) ([]*model.Applica
 	if err != nil {
 		return nil, err
 	}
+	isReadable, err := r.readableApplicationGroupFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	groupRecords := make([]*model.ApplicationGroup, 0)
 	for _, group := range groups {
+		if !isReadable(&group.ID) {
+			continue
+		}
 		groupRecords = append(groupRecords, applicationGroupToGraphqlObject(group))
 	}
 	return groupRecords, nil
@@ -66,6 +73,9 @@ func (r *queryResolver) ApplicationGroups(ctx context.Context) ([]*model.Applica
 
 // ApplicationGroup is the resolver for the applicationGroup field.
 func (r *queryResolver) ApplicationGroup(ctx context.Context, id string) (*model.ApplicationGroup, error) {
+	if err := r.checkApplicationGroupAccess(ctx, &id, core.ReadAccess); err != nil {
+		return nil, err
+	}
 	var record = &core.ApplicationGroup{}
 	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
 	if err != nil {
@@ -0,0 +1,55 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.48
+
+import (
+	"context"
+	"errors"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+// ApplicationGroup is the resolver for the applicationGroup field.
+func (r *applicationGroupPermissionResolver) ApplicationGroup(ctx context.Context, obj *model.ApplicationGroupPermission) (*model.ApplicationGroup, error) {
+	var record = &core.ApplicationGroup{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, obj.ApplicationGroupID)
+	if err != nil {
+		return nil, err
+	}
+	return applicationGroupToGraphqlObject(record), nil
+}
+
+// GrantApplicationGroupPermission is the resolver for the grantApplicationGroupPermission field.
+func (r *mutationResolver) GrantApplicationGroupPermission(ctx context.Context, input model.ApplicationGroupPermissionInput) (*model.ApplicationGroupPermission, error) {
+	record := applicationGroupPermissionInputToDatabaseObject(&input)
+	err := record.Create(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	return applicationGroupPermissionToGraphqlObject(record), nil
+}
+
+// RevokeApplicationGroupPermission is the resolver for the revokeApplicationGroupPermission field.
+func (r *mutationResolver) RevokeApplicationGroupPermission(ctx context.Context, id uint) (bool, error) {
+	var record = &core.ApplicationGroupPermission{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		// Don't return error if record not found -- assume it's already revoked
+		return true, nil
+	}
+	err = record.Delete(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, errors.New("failed to revoke permission")
+	}
+	return true, nil
+}
+
+// ApplicationGroupPermission returns ApplicationGroupPermissionResolver implementation.
+func (r *Resolver) ApplicationGroupPermission() ApplicationGroupPermissionResolver {
+	return &applicationGroupPermissionResolver{r}
+}
+
+type applicationGroupPermissionResolver struct{ *Resolver }
@@ -0,0 +1,46 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.48
+
+import (
+	"context"
+	"errors"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+// RunApplicationJob is the resolver for the runApplicationJob field.
+func (r *mutationResolver) RunApplicationJob(ctx context.Context, id string) (*model.ApplicationJobRun, error) {
+	if err := r.checkApplicationAccess(ctx, id, core.WriteAccess); err != nil {
+		return nil, err
+	}
+	var record = &core.ApplicationJobRun{
+		ApplicationID: id,
+		Trigger:       core.JobRunTriggerManual,
+	}
+	err := record.Create(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	err = r.WorkerManager.EnqueueRunApplicationJobRequest(record.ID)
+	if err != nil {
+		return nil, errors.New("failed to queue job run")
+	}
+	return applicationJobRunToGraphqlObject(record), nil
+}
+
+// FetchApplicationJobRunLogs is the resolver for the fetchApplicationJobRunLogs field.
+func (r *queryResolver) FetchApplicationJobRunLogs(ctx context.Context, id uint) (string, error) {
+	var record = &core.ApplicationJobRun{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		return "", err
+	}
+	if err := r.checkApplicationAccess(ctx, record.ApplicationID, core.ReadAccess); err != nil {
+		return "", err
+	}
+	return core.FetchApplicationJobRunLogsByID(ctx, r.ServiceManager.DbClient, id)
+}
@@ -0,0 +1,39 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.48
+
+import (
+	"context"
+	"errors"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+// DeleteApplicationPreview is the resolver for the deleteApplicationPreview field.
+func (r *mutationResolver) DeleteApplicationPreview(ctx context.Context, id uint) (bool, error) {
+	var record = &core.ApplicationPreview{}
+	err := record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, err
+	}
+	if err := r.checkApplicationAccess(ctx, record.ApplicationID, core.WriteAccess); err != nil {
+		return false, err
+	}
+	if record.Status == core.ApplicationPreviewStatusDeleting {
+		return false, errors.New("preview environment is already being removed")
+	}
+	err = record.MarkAsDeleting(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, err
+	}
+	// rest of the resources are removed by the cleanup cronjob, after the ingress rule is deleted
+	if record.IngressRuleID != nil {
+		err = r.WorkerManager.EnqueueIngressRuleDeleteRequest(*record.IngressRuleID)
+		if err != nil {
+			return false, errors.New("failed to schedule task to delete ingress rule of preview")
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"gorm.io/gorm"
+	"log"
+)
+
+// auditLogMiddleware : record the audit log of each mutation
+func auditLogMiddleware(db gorm.DB) gqlgen.FieldMiddleware {
+	return func(ctx context.Context, next gqlgen.Resolver) (interface{}, error) {
+		fieldContext := gqlgen.GetFieldContext(ctx)
+		if fieldContext == nil || fieldContext.Object != "Mutation" {
+			return next(ctx)
+		}
+		res, err := next(ctx)
+		auditLog := &core.AuditLog{
+			Username:  contextString(ctx, "username"),
+			IP:        contextString(ctx, "ip"),
+			Source:    core.AuditLogSourceGraphql,
+			Action:    fieldContext.Field.Name,
+			TargetID:  auditLogTargetID(fieldContext.Args, res),
+			Arguments: core.SanitizeAuditLogArguments(fieldContext.Args),
+			Outcome:   core.AuditLogOutcomeSuccess,
+		}
+		if err != nil {
+			auditLog.Outcome = core.AuditLogOutcomeFailure
+			auditLog.Error = err.Error()
+		}
+		if auditLogErr := auditLog.Create(context.Background(), db); auditLogErr != nil {
+			log.Println("failed to create audit log for " + auditLog.Action + " > " + auditLogErr.Error())
+		}
+		return res, err
+	}
+}
+
+// auditLogTargetID : find the id of the target resource from the arguments or the result of the mutation
+func auditLogTargetID(args map[string]interface{}, res interface{}) string {
+	if id, ok := args["id"]; ok && id != nil {
+		return fmt.Sprintf("%v", id)
+	}
+	if res == nil {
+		return ""
+	}
+	encoded, err := json.Marshal(res)
+	if err != nil {
+		return ""
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return ""
+	}
+	if id, ok := decoded["id"]; ok && id != nil {
+		return fmt.Sprintf("%v", id)
+	}
+	return ""
+}
+
+func contextString(ctx context.Context, key string) string {
+	value, ok := ctx.Value(key).(string)
+	if !ok {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,25 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.48
+
+import (
+	"context"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+// AuditLogs is the resolver for the auditLogs field.
+func (r *queryResolver) AuditLogs(ctx context.Context, filter *model.AuditLogFilter) ([]*model.AuditLog, error) {
+	records, err := core.FindAuditLogs(ctx, r.ServiceManager.DbClient, auditLogFilterToDatabaseObject(filter))
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*model.AuditLog, 0)
+	for _, record := range records {
+		result = append(result, auditLogToGraphqlObject(record))
+	}
+	return result, nil
+}
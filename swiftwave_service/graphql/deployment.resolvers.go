@@ -14,6 +14,9 @@ import (
 
 // Application is the resolver for the application field.
 func (r *deploymentResolver) Application(ctx context.Context, obj *model.Deployment) (*model.Application, error) {
+	if err := r.checkApplicationAccess(ctx, obj.ApplicationID, core.ReadAccess); err != nil {
+		return nil, err
+	}
 	// fetch record
 	var application = &core.Application{}
 	err := application.FindById(ctx, r.ServiceManager.DbClient, obj.ApplicationID)
@@ -62,6 +65,21 @@ func (r *deploymentResolver) BuildArgs(ctx context.Context, obj *model.Deploymen
 	return result, nil
 }
 
+// BuildSecrets is the resolver for the buildSecrets field.
+func (r *deploymentResolver) BuildSecrets(ctx context.Context, obj *model.Deployment) ([]string, error) {
+	// fetch record
+	records, err := core.FindBuildSecretsByDeploymentId(ctx, r.ServiceManager.DbClient, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	// only the keys, values are never exposed
+	var result = make([]string, 0)
+	for _, record := range records {
+		result = append(result, record.Key)
+	}
+	return result, nil
+}
+
 // CancelDeployment is the resolver for the cancelDeployment field.
 func (r *mutationResolver) CancelDeployment(ctx context.Context, id string) (bool, error) {
 	deployment := &core.Deployment{}
@@ -70,6 +88,9 @@ func (r *mutationResolver) CancelDeployment(ctx context.Context, id string) (boo
 	if err != nil {
 		return false, err
 	}
+	if err := r.checkApplicationAccess(ctx, deployment.ApplicationID, core.WriteAccess); err != nil {
+		return false, err
+	}
 	if deployment.Status != core.DeploymentStatusPending {
 		return false, errors.New("pending deployment only can be cancelled")
 	}
@@ -87,6 +108,9 @@ func (r *queryResolver) Deployment(ctx context.Context, id string) (*model.Deplo
 	if err != nil {
 		return nil, err
 	}
+	if err := r.checkApplicationAccess(ctx, deployment.ApplicationID, core.ReadAccess); err != nil {
+		return nil, err
+	}
 	return deploymentToGraphqlObject(deployment), nil
 }
 
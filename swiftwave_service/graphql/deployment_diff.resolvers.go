@@ -0,0 +1,37 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.48
+
+import (
+	"context"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+// DeploymentDiff is the resolver for the deploymentDiff field.
+func (r *queryResolver) DeploymentDiff(ctx context.Context, fromDeploymentID string, toDeploymentID *string) (*model.DeploymentDiff, error) {
+	var deployment = &core.Deployment{}
+	err := deployment.FindById(ctx, r.ServiceManager.DbClient, fromDeploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkApplicationAccess(ctx, deployment.ApplicationID, core.ReadAccess); err != nil {
+		return nil, err
+	}
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	toDeploymentId := ""
+	if toDeploymentID != nil {
+		toDeploymentId = *toDeploymentID
+	}
+	diff, err := core.FindDeploymentDiff(ctx, r.ServiceManager.DbClient, fromDeploymentID, toDeploymentId)
+	if err != nil {
+		return nil, err
+	}
+	return deploymentDiffToGraphqlObject(diff, user.HasRole(core.AdministratorRole)), nil
+}
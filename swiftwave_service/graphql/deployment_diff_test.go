@@ -0,0 +1,60 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+func TestDeploymentDiffToGraphqlObjectMasksValues(t *testing.T) {
+	diff := &core.DeploymentDiff{
+		Changes: []core.DeploymentChange{
+			{Category: core.DeploymentChangeConfigMount, Type: core.DeploymentChangeModified, Field: "/app/config.yml", OldValue: "password: old", NewValue: "password: new"},
+			{Category: core.DeploymentChangeConfigMount, Type: core.DeploymentChangeAdded, Field: "/app/.npmrc", NewValue: "token=abc"},
+			{Category: core.DeploymentChangeEnvironmentVariable, Type: core.DeploymentChangeModified, Field: "API_URL", OldValue: "http://old", NewValue: "http://new"},
+			{Category: core.DeploymentChangeEnvironmentVariable, Type: core.DeploymentChangeModified, Field: "API_KEY", OldValue: "old", NewValue: "new", IsSecret: true},
+			{Category: core.DeploymentChangeResource, Type: core.DeploymentChangeModified, Field: "replicas", OldValue: "1", NewValue: "2"},
+		},
+	}
+	tests := []struct {
+		name           string
+		isValueVisible bool
+		expected       [][2]string
+	}{
+		{
+			name:           "non-admin",
+			isValueVisible: false,
+			expected: [][2]string{
+				{core.SecretEnvironmentVariableMask, core.SecretEnvironmentVariableMask},
+				{"", core.SecretEnvironmentVariableMask},
+				{core.SecretEnvironmentVariableMask, core.SecretEnvironmentVariableMask},
+				{core.SecretEnvironmentVariableMask, core.SecretEnvironmentVariableMask},
+				{"1", "2"},
+			},
+		},
+		{
+			name:           "admin",
+			isValueVisible: true,
+			expected: [][2]string{
+				{"password: old", "password: new"},
+				{"", "token=abc"},
+				{"http://old", "http://new"},
+				{core.SecretEnvironmentVariableMask, core.SecretEnvironmentVariableMask},
+				{"1", "2"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := deploymentDiffToGraphqlObject(diff, test.isValueVisible)
+			if !assert.Len(t, result.Changes, len(test.expected)) {
+				return
+			}
+			for i, change := range result.Changes {
+				assert.Equal(t, test.expected[i][0], change.OldValue, change.Field)
+				assert.Equal(t, test.expected[i][1], change.NewValue, change.Field)
+			}
+		})
+	}
+}
@@ -17,6 +17,14 @@ import (
 
 // FetchDeploymentLog is the resolver for the fetchDeploymentLog field.
 func (r *subscriptionResolver) FetchDeploymentLog(ctx context.Context, id string) (<-chan *model.DeploymentLog, error) {
+	// verify access on application
+	var deployment = &core.Deployment{}
+	if err := deployment.FindById(ctx, r.ServiceManager.DbClient, id); err != nil {
+		return nil, err
+	}
+	if err := r.checkApplicationAccess(ctx, deployment.ApplicationID, core.ReadAccess); err != nil {
+		return nil, err
+	}
 	// find deployment status
 	deploymentStatus, err := core.FindDeploymentStatusByID(ctx, r.ServiceManager.DbClient, id)
 	if err != nil {
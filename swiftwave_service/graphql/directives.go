@@ -0,0 +1,228 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+	"gorm.io/gorm"
+)
+
+// hasRoleDirective : allow the field only if the current user has the required role
+// Users limited to specific application groups are rejected, unless allowRestricted is set
+// In that case, the resolver is responsible to verify the access on the application group
+func hasRoleDirective(db gorm.DB) func(ctx context.Context, obj interface{}, next gqlgen.Resolver, role model.UserRole, allowRestricted *bool) (interface{}, error) {
+	return func(ctx context.Context, obj interface{}, next gqlgen.Resolver, role model.UserRole, allowRestricted *bool) (interface{}, error) {
+		user, err := currentUser(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+		if !user.HasRole(core.UserRole(role)) {
+			return nil, fmt.Errorf("unauthorized: %s role required to perform this action", role)
+		}
+		if allowRestricted == nil || !*allowRestricted {
+			isRestricted, err := user.IsRestrictedToApplicationGroups(ctx, db)
+			if err != nil {
+				return nil, errors.New("failed to verify permissions of user")
+			}
+			if isRestricted {
+				return nil, errors.New("unauthorized: your access is limited to specific application groups")
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// currentUser : fetch the logged-in user
+func currentUser(ctx context.Context, db gorm.DB) (*core.User, error) {
+	username, ok := ctx.Value("username").(string)
+	if !ok || username == "" {
+		return nil, errors.New("unauthorized: login required")
+	}
+	user, err := core.FindUserByUsername(ctx, db, username)
+	if err != nil {
+		return nil, errors.New("unauthorized: user not found")
+	}
+	return &user, nil
+}
+
+// checkApplicationAccess : return error if the logged-in user doesn't have the required access on the application
+func (r *Resolver) checkApplicationAccess(ctx context.Context, applicationID string, access core.AccessLevel) error {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return err
+	}
+	allowed, err := user.CanAccessApplication(ctx, r.ServiceManager.DbClient, applicationID, access)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("application not found")
+		}
+		return errors.New("failed to verify permissions of user")
+	}
+	if !allowed {
+		return fmt.Errorf("unauthorized: %s access on the application is required", access)
+	}
+	return nil
+}
+
+// checkApplicationGroupAccess : return error if the logged-in user doesn't have the required access on the application group
+func (r *Resolver) checkApplicationGroupAccess(ctx context.Context, applicationGroupID *string, access core.AccessLevel) error {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return err
+	}
+	allowed, err := user.CanAccessApplicationGroup(ctx, r.ServiceManager.DbClient, applicationGroupID, access)
+	if err != nil {
+		return errors.New("failed to verify permissions of user")
+	}
+	if !allowed {
+		return fmt.Errorf("unauthorized: %s access on the application group is required", access)
+	}
+	return nil
+}
+
+// readableApplicationGroupFilter : return a function to check if the logged-in user can see the applications of an application group
+func (r *Resolver) readableApplicationGroupFilter(ctx context.Context) (func(applicationGroupID *string) bool, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	isRestricted, err := user.IsRestrictedToApplicationGroups(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, errors.New("failed to verify permissions of user")
+	}
+	if !isRestricted {
+		return func(applicationGroupID *string) bool {
+			return true
+		}, nil
+	}
+	permissions, err := core.FindApplicationGroupPermissionsByUserID(ctx, r.ServiceManager.DbClient, user.ID)
+	if err != nil {
+		return nil, errors.New("failed to verify permissions of user")
+	}
+	return func(applicationGroupID *string) bool {
+		if applicationGroupID == nil {
+			return false
+		}
+		for _, permission := range permissions {
+			if permission.ApplicationGroupID == *applicationGroupID {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// applicationFilter : decide which applications are visible to the logged-in user
+type applicationFilter struct {
+	isRestricted   bool
+	applicationIDs map[string]bool
+}
+
+// isReadable : check if the application is visible to the user
+func (f *applicationFilter) isReadable(applicationID string) bool {
+	return !f.isRestricted || f.applicationIDs[applicationID]
+}
+
+// readableApplicationFilter : return the filter of the applications visible to the logged-in user
+func (r *Resolver) readableApplicationFilter(ctx context.Context) (*applicationFilter, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	isRestricted, err := user.IsRestrictedToApplicationGroups(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, errors.New("failed to verify permissions of user")
+	}
+	filter := &applicationFilter{
+		isRestricted:   isRestricted,
+		applicationIDs: make(map[string]bool),
+	}
+	if !isRestricted {
+		return filter, nil
+	}
+	applicationIDs, err := user.FindAccessibleApplicationIDs(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, errors.New("failed to verify permissions of user")
+	}
+	for _, applicationID := range applicationIDs {
+		filter.applicationIDs[applicationID] = true
+	}
+	return filter, nil
+}
+
+// isIngressRuleReadable : ingress rules of external services are only visible to the users not limited to application groups
+func (f *applicationFilter) isIngressRuleReadable(record *core.IngressRule) bool {
+	if !f.isRestricted {
+		return true
+	}
+	return record.TargetType == core.ApplicationIngressRule && record.ApplicationID != nil && f.isReadable(*record.ApplicationID)
+}
+
+// isDomainReadable : domain is visible to users limited to application groups, if any of the ingress rules of the domain is visible
+func (r *Resolver) isDomainReadable(ctx context.Context, filter *applicationFilter, domainID uint) (bool, error) {
+	if !filter.isRestricted {
+		return true, nil
+	}
+	ingressRules, err := core.FindIngressRulesByDomainID(ctx, r.ServiceManager.DbClient, domainID)
+	if err != nil {
+		return false, err
+	}
+	for _, ingressRule := range ingressRules {
+		if filter.isIngressRuleReadable(ingressRule) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isPersistentVolumeReadable : persistent volume is visible to users limited to application groups, if it's bound to any visible application
+func (r *Resolver) isPersistentVolumeReadable(ctx context.Context, filter *applicationFilter, persistentVolumeID uint) (bool, error) {
+	if !filter.isRestricted {
+		return true, nil
+	}
+	bindings, err := core.FindPersistentVolumeBindingsByPersistentVolumeId(ctx, r.ServiceManager.DbClient, persistentVolumeID)
+	if err != nil {
+		return false, err
+	}
+	for _, binding := range bindings {
+		if filter.isReadable(binding.ApplicationID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkDomainAccess : return error if the domain is not visible to the logged-in user
+func (r *Resolver) checkDomainAccess(ctx context.Context, domainID uint) error {
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return err
+	}
+	isReadable, err := r.isDomainReadable(ctx, filter, domainID)
+	if err != nil {
+		return errors.New("failed to verify permissions of user")
+	}
+	if !isReadable {
+		return errors.New("unauthorized: your access is limited to specific application groups")
+	}
+	return nil
+}
+
+// checkPersistentVolumeAccess : return error if the persistent volume is not visible to the logged-in user
+func (r *Resolver) checkPersistentVolumeAccess(ctx context.Context, persistentVolumeID uint) error {
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return err
+	}
+	isReadable, err := r.isPersistentVolumeReadable(ctx, filter, persistentVolumeID)
+	if err != nil {
+		return errors.New("failed to verify permissions of user")
+	}
+	if !isReadable {
+		return errors.New("unauthorized: your access is limited to specific application groups")
+	}
+	return nil
+}
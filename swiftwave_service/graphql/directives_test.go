@@ -0,0 +1,120 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestHasRoleDirective(t *testing.T) {
+	r := newTestResolver(t)
+	directive := hasRoleDirective(r.ServiceManager.DbClient)
+	_, adminCtx := createTestUser(t, r, "admin", core.AdministratorRole)
+	_, managerCtx := createTestUser(t, r, "manager", core.ManagerRole)
+	_, viewerCtx := createTestUser(t, r, "viewer", core.ViewerRole)
+	restricted, restrictedCtx := createTestUser(t, r, "restricted", core.ManagerRole)
+	mustCreate(t, r,
+		&core.ApplicationGroup{ID: "group", Name: "group"},
+		&core.ApplicationGroupPermission{UserID: restricted.ID, ApplicationGroupID: "group", Access: core.WriteAccess},
+	)
+	allowRestricted := true
+
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		role            model.UserRole
+		allowRestricted *bool
+		allowed         bool
+	}{
+		{"admin on admin field", adminCtx, model.UserRoleAdmin, nil, true},
+		{"admin on manager field", adminCtx, model.UserRoleManager, nil, true},
+		{"manager on admin field", managerCtx, model.UserRoleAdmin, nil, false},
+		{"manager on manager field", managerCtx, model.UserRoleManager, nil, true},
+		{"manager on viewer field", managerCtx, model.UserRoleViewer, nil, true},
+		{"viewer on manager field", viewerCtx, model.UserRoleManager, nil, false},
+		{"viewer on viewer field", viewerCtx, model.UserRoleViewer, nil, true},
+		{"restricted user", restrictedCtx, model.UserRoleManager, nil, false},
+		{"restricted user on field allowing restricted users", restrictedCtx, model.UserRoleManager, &allowRestricted, true},
+		{"restricted user still needs the role", restrictedCtx, model.UserRoleAdmin, &allowRestricted, false},
+		{"anonymous", context.Background(), model.UserRoleViewer, nil, false},
+		//nolint:staticcheck
+		{"unknown user", context.WithValue(context.Background(), "username", "ghost"), model.UserRoleViewer, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called := false
+			result, err := directive(test.ctx, nil, func(ctx context.Context) (interface{}, error) {
+				called = true
+				return "ok", nil
+			}, test.role, test.allowRestricted)
+			assert.Equal(t, test.allowed, called)
+			if test.allowed {
+				assert.NoError(t, err)
+				assert.Equal(t, "ok", result)
+			} else {
+				assert.ErrorContains(t, err, "unauthorized")
+				assert.Nil(t, result)
+			}
+		})
+	}
+}
+
+func TestServerAndCredentialQueriesRejectRestrictedUsers(t *testing.T) {
+	r := newTestResolver(t)
+	c := newTestClient(r)
+	restricted, restrictedCtx := createTestUser(t, r, "restricted", core.ManagerRole)
+	_, viewerCtx := createTestUser(t, r, "viewer", core.ViewerRole)
+	mustCreate(t, r,
+		&core.ApplicationGroup{ID: "group", Name: "group"},
+		&core.ApplicationGroupPermission{UserID: restricted.ID, ApplicationGroupID: "group", Access: core.WriteAccess},
+		&core.GitCredential{Name: "github", Type: core.GitHttp, Username: "user", Password: "token", AllowedHosts: []string{"github.com"}},
+	)
+	queries := []string{
+		`query { noOfServers }`,
+		`query { noOfPreparedServers }`,
+		`query { servers { id ip } }`,
+		`query { server(id: 1) { id ip } }`,
+		`query { serverResourceAnalytics(id: 1, timeframe: last_1_hour) { cpu_usage_percent } }`,
+		`query { serverDiskUsage(id: 1) { timestamp } }`,
+		`query { serverLatestResourceAnalytics(id: 1) { cpu_usage_percent } }`,
+		`query { serverLatestDiskUsage(id: 1) { timestamp } }`,
+		`query { gitCredentials { id username } }`,
+		`query { gitCredential(id: 1) { id username } }`,
+		`query { imageRegistryCredentials { id username } }`,
+		`query { imageRegistryCredential(id: 1) { id username } }`,
+	}
+	for _, query := range queries {
+		var response map[string]interface{}
+		err := c.Post(query, &response, withContext(restrictedCtx))
+		if assert.Error(t, err, query) {
+			assert.Contains(t, err.Error(), "limited to specific application groups", query)
+		}
+	}
+
+	// users not limited to application groups can still list the credentials
+	var response struct {
+		GitCredentials []struct {
+			Username string
+		}
+	}
+	err := c.Post(`query { gitCredentials { username } }`, &response, withContext(viewerCtx))
+	assert.NoError(t, err)
+	assert.Len(t, response.GitCredentials, 1)
+}
+
+func TestRootFieldsHaveRoleDirective(t *testing.T) {
+	schema := NewExecutableSchema(Config{}).Schema()
+	for _, rootType := range []*ast.Definition{schema.Query, schema.Mutation, schema.Subscription} {
+		for _, field := range rootType.Fields {
+			if strings.HasPrefix(field.Name, "__") {
+				continue
+			}
+			assert.NotNil(t, field.Directives.ForName("hasRole"), "%s.%s has no @hasRole directive", rootType.Name, field.Name)
+		}
+	}
+}
@@ -42,6 +42,12 @@ func (r *queryResolver) DockerConfigGenerator(ctx context.Context, input model.D
 			if err := gitCredential.FindById(ctx, r.ServiceManager.DbClient, *input.GitCredentialID); err != nil {
 				return nil, errors.New("invalid git credential provided")
 			}
+			if input.RepositoryURL == nil {
+				return nil, errors.New("invalid git url provided")
+			}
+			if err := gitCredential.CheckRepositoryAccess(*input.RepositoryURL); err != nil {
+				return nil, err
+			}
 			gitUsername = gitCredential.Username
 			gitPassword = gitCredential.Password
 			gitPrivateKey = gitCredential.SshPrivateKey
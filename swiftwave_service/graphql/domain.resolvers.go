@@ -19,8 +19,15 @@ func (r *domainResolver) IngressRules(ctx context.Context, obj *model.Domain) ([
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var result []*model.IngressRule
 	for _, record := range records {
+		if !filter.isIngressRuleReadable(record) {
+			continue
+		}
 		result = append(result, ingressRuleToGraphqlObject(record))
 	}
 	return result, nil
@@ -131,8 +138,19 @@ func (r *queryResolver) Domains(ctx context.Context) ([]*model.Domain, error) {
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var result []*model.Domain
 	for _, record := range records {
+		isReadable, err := r.isDomainReadable(ctx, filter, record.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !isReadable {
+			continue
+		}
 		result = append(result, domainToGraphqlObject(record))
 	}
 	return result, nil
@@ -145,6 +163,9 @@ func (r *queryResolver) Domain(ctx context.Context, id uint) (*model.Domain, err
 	if err != nil {
 		return nil, err
 	}
+	if err := r.checkDomainAccess(ctx, record.ID); err != nil {
+		return nil, err
+	}
 	return domainToGraphqlObject(&record), nil
 }
 
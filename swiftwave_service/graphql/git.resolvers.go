@@ -21,7 +21,27 @@ func (r *queryResolver) GitBranches(ctx context.Context, input model.GitBranches
 		if tx.Error != nil {
 			return nil, tx.Error
 		}
+		if err := gitCredential.CheckRepositoryAccess(input.RepositoryURL); err != nil {
+			return nil, err
+		}
 	}
 	branches, err := GIT.FetchBranches(input.RepositoryURL, gitCredential.Username, gitCredential.Password, gitCredential.SshPrivateKey)
 	return branches, err
 }
+
+// GitTags is the resolver for the gitTags field.
+func (r *queryResolver) GitTags(ctx context.Context, input model.GitBranchesQueryInput) ([]string, error) {
+	// Fetch git credential
+	var gitCredential = &core.GitCredential{}
+	if input.GitCredentialID > 0 {
+		tx := r.ServiceManager.DbClient.First(&gitCredential, input.GitCredentialID)
+		if tx.Error != nil {
+			return nil, tx.Error
+		}
+		if err := gitCredential.CheckRepositoryAccess(input.RepositoryURL); err != nil {
+			return nil, err
+		}
+	}
+	tags, err := GIT.FetchTags(input.RepositoryURL, gitCredential.Username, gitCredential.Password, gitCredential.SshPrivateKey)
+	return tags, err
+}
@@ -21,9 +21,16 @@ func (r *gitCredentialResolver) Deployments(ctx context.Context, obj *model.GitC
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	// convert to graphql object
 	var result = make([]*model.Deployment, 0)
 	for _, record := range records {
+		if !filter.isReadable(record.ApplicationID) {
+			continue
+		}
 		result = append(result, deploymentToGraphqlObject(record))
 	}
 	return result, nil
@@ -67,6 +74,7 @@ func (r *mutationResolver) UpdateGitCredential(ctx context.Context, id uint, inp
 	record.Password = input.Password
 	record.SshPrivateKey = newRecord.SshPrivateKey
 	record.SshPublicKey = newRecord.SshPublicKey
+	record.AllowedHosts = newRecord.AllowedHosts
 	err = record.Update(ctx, r.ServiceManager.DbClient)
 	if err != nil {
 		return nil, err
@@ -129,6 +137,9 @@ func (r *queryResolver) CheckGitCredentialRepositoryAccess(ctx context.Context,
 		if tx.Error != nil {
 			return false, errors.New("git credential not found")
 		}
+		if err := gitCredential.CheckRepositoryAccess(input.RepositoryURL); err != nil {
+			return false, err
+		}
 	}
 	_, err := GIT.FetchBranches(input.RepositoryURL, gitCredential.Username, gitCredential.Password, gitCredential.SshPrivateKey)
 	return err == nil, nil
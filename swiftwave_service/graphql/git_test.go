@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+// newTestClient : graphql client with the role directive, requests are sent as the logged-in user of the context
+func newTestClient(r *Resolver) *client.Client {
+	graphqlHandler := handler.New(NewExecutableSchema(Config{
+		Resolvers: r,
+		Directives: DirectiveRoot{
+			HasRole: hasRoleDirective(r.ServiceManager.DbClient),
+		},
+	}))
+	graphqlHandler.AddTransport(transport.POST{})
+	return client.New(graphqlHandler)
+}
+
+func withContext(ctx context.Context) client.Option {
+	return func(request *client.Request) {
+		request.HTTP = request.HTTP.WithContext(ctx)
+	}
+}
+
+func TestGitQueriesRequireManagerRole(t *testing.T) {
+	r := newTestResolver(t)
+	c := newTestClient(r)
+	gitCredential := &core.GitCredential{Name: "github", Type: core.GitHttp, Username: "user", Password: "token", AllowedHosts: []string{"github.com"}}
+	mustCreate(t, r, gitCredential)
+	_, viewerCtx := createTestUser(t, r, "viewer", core.ViewerRole)
+	queries := []string{
+		`query($id: Uint!) { gitBranches(input: {gitCredentialId: $id, repositoryUrl: "https://github.com/owner/repo"}) }`,
+		`query($id: Uint!) { gitTags(input: {gitCredentialId: $id, repositoryUrl: "https://github.com/owner/repo"}) }`,
+		`query($id: Uint!) { checkGitCredentialRepositoryAccess(input: {gitCredentialId: $id, repositoryUrl: "https://github.com/owner/repo"}) }`,
+	}
+	for _, query := range queries {
+		var response map[string]interface{}
+		err := c.Post(query, &response, client.Var("id", gitCredential.ID), withContext(viewerCtx))
+		if assert.Error(t, err, query) {
+			assert.Contains(t, err.Error(), "manager role required")
+		}
+	}
+}
+
+func TestGitQueriesRejectCredentialForOtherHosts(t *testing.T) {
+	r := newTestResolver(t)
+	c := newTestClient(r)
+	gitCredential := &core.GitCredential{Name: "github", Type: core.GitHttp, Username: "user", Password: "token", AllowedHosts: []string{"github.com"}}
+	mustCreate(t, r, gitCredential)
+	_, managerCtx := createTestUser(t, r, "manager", core.ManagerRole)
+	repositoryUrls := []string{
+		"https://attacker.example.com/owner/repo",
+		"https://github.com.attacker.example.com/owner/repo",
+		"git@attacker.example.com:owner/repo.git",
+	}
+	for _, repositoryUrl := range repositoryUrls {
+		for _, field := range []string{"gitBranches", "gitTags", "checkGitCredentialRepositoryAccess"} {
+			var response map[string]interface{}
+			query := `query($id: Uint!, $url: String!) { ` + field + `(input: {gitCredentialId: $id, repositoryUrl: $url}) }`
+			err := c.Post(query, &response, client.Var("id", gitCredential.ID), client.Var("url", repositoryUrl), withContext(managerCtx))
+			if assert.Error(t, err, field+" "+repositoryUrl) {
+				assert.Contains(t, err.Error(), "not allowed to be used")
+			}
+		}
+	}
+}
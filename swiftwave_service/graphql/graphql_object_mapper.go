@@ -35,6 +35,7 @@ func gitCredentialToGraphqlObject(record *core.GitCredential) *model.GitCredenti
 		Name:         record.Name,
 		Username:     record.Username,
 		SSHPublicKey: record.SshPublicKey,
+		AllowedHosts: append(make([]string, 0), record.AllowedHosts...),
 	}
 }
 
@@ -88,6 +89,7 @@ func gitCredentialInputToDatabaseObject(record *model.GitCredentialInput, create
 		Password:      record.Password,
 		SshPrivateKey: sshPrivateKey,
 		SshPublicKey:  sshPublicKey,
+		AllowedHosts:  record.AllowedHosts,
 	}
 }
 
@@ -222,16 +224,24 @@ func persistentVolumeRestoreToGraphqlObject(record *core.PersistentVolumeRestore
 // environmentVariableInputToDatabaseObject converts EnvironmentVariableInput to EnvironmentVariableDatabaseObject
 func environmentVariableInputToDatabaseObject(record *model.EnvironmentVariableInput) *core.EnvironmentVariable {
 	return &core.EnvironmentVariable{
-		Key:   strings.TrimSpace(record.Key),
-		Value: strings.TrimSpace(record.Value),
+		Key:           strings.TrimSpace(record.Key),
+		Value:         strings.TrimSpace(record.Value),
+		IsSecret:      record.IsSecret,
+		SecretFileEnv: record.IsSecret && record.SecretFileEnv,
 	}
 }
 
 // environmentVariableToGraphqlObject converts EnvironmentVariable to EnvironmentVariableGraphqlObject
 func environmentVariableToGraphqlObject(record *core.EnvironmentVariable) *model.EnvironmentVariable {
+	value := record.Value
+	if record.IsSecret {
+		value = core.SecretEnvironmentVariableMask
+	}
 	return &model.EnvironmentVariable{
-		Key:   record.Key,
-		Value: record.Value,
+		Key:           record.Key,
+		Value:         value,
+		IsSecret:      record.IsSecret,
+		SecretFileEnv: record.SecretFileEnv,
 	}
 }
 
@@ -305,6 +315,10 @@ func applicationInputToDeploymentDatabaseObject(record *model.ApplicationInput)
 	for _, buildArg := range record.BuildArgs {
 		buildArgs = append(buildArgs, *buildArgInputToDatabaseObject(buildArg))
 	}
+	var buildSecrets = make([]core.BuildSecret, 0)
+	for _, key := range record.BuildSecrets {
+		buildSecrets = append(buildSecrets, core.BuildSecret{Key: strings.TrimSpace(key)})
+	}
 	var repoInfo gitmanager.GitRepoInfo
 	if record.UpstreamType == model.UpstreamTypeGit {
 		parsedRepoInfo, _ := gitmanager.ParseGitRepoInfo(*record.RepositoryURL)
@@ -316,6 +330,15 @@ func applicationInputToDeploymentDatabaseObject(record *model.ApplicationInput)
 	if repoInfo.IsSshEndpoint {
 		gitType = core.GitSsh
 	}
+	gitReferenceType := core.GitReferenceBranch
+	if record.GitReferenceType != nil {
+		gitReferenceType = core.GitReferenceType(*record.GitReferenceType)
+	}
+	// commit hash is resolved while building, unless the deployment is pinned to a commit
+	commitHash := ""
+	if gitReferenceType == core.GitReferenceCommit {
+		commitHash = strings.ToLower(strings.TrimSpace(DefaultString(record.CommitHash, "")))
+	}
 	return &core.Deployment{
 		UpstreamType:                 core.UpstreamType(record.UpstreamType),
 		GitCredentialID:              record.GitCredentialID,
@@ -326,14 +349,20 @@ func applicationInputToDeploymentDatabaseObject(record *model.ApplicationInput)
 		RepositoryBranch:             DefaultString(record.RepositoryBranch, ""),
 		GitEndpoint:                  repoInfo.Endpoint,
 		GitSshUser:                   repoInfo.SshUser,
-		CommitHash:                   "",
+		GitReferenceType:             gitReferenceType,
+		RepositoryTag:                DefaultString(record.RepositoryTag, ""),
+		GitTagPattern:                DefaultString(record.GitTagPattern, ""),
+		GitCloneConfig:               *gitCloneConfigInputToDatabaseObject(record.GitCloneConfig),
+		CommitHash:                   commitHash,
 		CommitMessage:                "",
 		CodePath:                     DefaultString(record.CodePath, ""),
 		SourceCodeCompressedFileName: DefaultString(record.SourceCodeCompressedFileName, ""),
 		DockerImage:                  DefaultString(record.DockerImage, ""),
 		ImageRegistryCredentialID:    record.ImageRegistryCredentialID,
 		BuildArgs:                    buildArgs,
+		BuildSecrets:                 buildSecrets,
 		Dockerfile:                   DefaultString(record.Dockerfile, ""),
+		DockerfileTarget:             DefaultString(record.DockerfileTarget, ""),
 		Logs:                         make([]core.DeploymentLog, 0),
 		Status:                       core.DeploymentStatusPending,
 		CreatedAt:                    time.Now(),
@@ -358,6 +387,105 @@ func applicationGroupToGraphqlObject(record *core.ApplicationGroup) *model.Appli
 	}
 }
 
+// applicationGroupPermissionInputToDatabaseObject converts ApplicationGroupPermissionInput to ApplicationGroupPermissionDatabaseObject
+func applicationGroupPermissionInputToDatabaseObject(record *model.ApplicationGroupPermissionInput) *core.ApplicationGroupPermission {
+	return &core.ApplicationGroupPermission{
+		UserID:             record.UserID,
+		ApplicationGroupID: record.ApplicationGroupID,
+		Access:             core.AccessLevel(record.Access),
+	}
+}
+
+// applicationGroupPermissionToGraphqlObject converts ApplicationGroupPermission to ApplicationGroupPermissionGraphqlObject
+func applicationGroupPermissionToGraphqlObject(record *core.ApplicationGroupPermission) *model.ApplicationGroupPermission {
+	return &model.ApplicationGroupPermission{
+		ID:                 record.ID,
+		UserID:             record.UserID,
+		ApplicationGroupID: record.ApplicationGroupID,
+		Access:             model.AccessLevel(record.Access),
+	}
+}
+
+// personalAccessTokenInputToDatabaseObject converts PersonalAccessTokenInput to PersonalAccessTokenDatabaseObject
+func personalAccessTokenInputToDatabaseObject(record *model.PersonalAccessTokenInput) *core.PersonalAccessToken {
+	var scopes = make([]string, 0)
+	for _, scope := range record.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return &core.PersonalAccessToken{
+		Name:      record.Name,
+		Scopes:    scopes,
+		ExpiresAt: record.ExpiresAt,
+	}
+}
+
+// personalAccessTokenToGraphqlObject converts PersonalAccessToken to PersonalAccessTokenGraphqlObject
+func personalAccessTokenToGraphqlObject(record *core.PersonalAccessToken) *model.PersonalAccessToken {
+	var scopes = make([]model.PersonalAccessTokenScope, 0)
+	for _, scope := range record.Scopes {
+		scopes = append(scopes, model.PersonalAccessTokenScope(scope))
+	}
+	return &model.PersonalAccessToken{
+		ID:         record.ID,
+		Name:       record.Name,
+		Scopes:     scopes,
+		ExpiresAt:  record.ExpiresAt,
+		LastUsedAt: record.LastUsedAt,
+		CreatedAt:  record.CreatedAt,
+	}
+}
+
+// auditLogFilterToDatabaseObject converts AuditLogFilter to AuditLogFilterDatabaseObject
+func auditLogFilterToDatabaseObject(record *model.AuditLogFilter) *core.AuditLogFilter {
+	// by default, return latest 100 records
+	filter := &core.AuditLogFilter{
+		Limit: 100,
+	}
+	if record == nil {
+		return filter
+	}
+	if record.Username != nil {
+		filter.Username = *record.Username
+	}
+	if record.Source != nil {
+		filter.Source = core.AuditLogSource(*record.Source)
+	}
+	if record.Action != nil {
+		filter.Action = *record.Action
+	}
+	if record.TargetID != nil {
+		filter.TargetID = *record.TargetID
+	}
+	if record.Outcome != nil {
+		filter.Outcome = core.AuditLogOutcome(*record.Outcome)
+	}
+	filter.From = record.From
+	filter.To = record.To
+	if record.Limit != nil {
+		filter.Limit = *record.Limit
+	}
+	if record.Offset != nil {
+		filter.Offset = *record.Offset
+	}
+	return filter
+}
+
+// auditLogToGraphqlObject converts AuditLog to AuditLogGraphqlObject
+func auditLogToGraphqlObject(record *core.AuditLog) *model.AuditLog {
+	return &model.AuditLog{
+		ID:        record.ID,
+		Username:  record.Username,
+		IP:        record.IP,
+		Source:    model.AuditLogSource(record.Source),
+		Action:    record.Action,
+		TargetID:  record.TargetID,
+		Arguments: record.Arguments,
+		Outcome:   model.AuditLogOutcome(record.Outcome),
+		Error:     record.Error,
+		CreatedAt: record.CreatedAt,
+	}
+}
+
 // applicationInputToDatabaseObject converts ApplicationInput to ApplicationDatabaseObject
 func applicationInputToDatabaseObject(record *model.ApplicationInput) *core.Application {
 	var environmentVariables = make([]core.EnvironmentVariable, 0)
@@ -372,27 +500,48 @@ func applicationInputToDatabaseObject(record *model.ApplicationInput) *core.Appl
 	for _, configMount := range record.ConfigMounts {
 		configMounts = append(configMounts, *configMountInputToDatabaseObject(configMount))
 	}
+	// nil keeps the existing overrides on update
+	var previewEnvironmentVariables []core.PreviewEnvironmentVariable = nil
+	if record.PreviewEnvironmentVariables != nil {
+		previewEnvironmentVariables = make([]core.PreviewEnvironmentVariable, 0)
+		for _, previewEnvironmentVariable := range record.PreviewEnvironmentVariables {
+			previewEnvironmentVariables = append(previewEnvironmentVariables, *previewEnvironmentVariableInputToDatabaseObject(previewEnvironmentVariable))
+		}
+	}
+	deploymentStrategy := core.DeploymentStrategyRolling
+	if record.DeploymentStrategy != nil {
+		deploymentStrategy = core.DeploymentStrategy(*record.DeploymentStrategy)
+	}
 	return &core.Application{
-		Name:                     record.Name,
-		EnvironmentVariables:     environmentVariables,
-		PersistentVolumeBindings: persistentVolumeBindings,
-		ConfigMounts:             configMounts,
-		DeploymentMode:           core.DeploymentMode(record.DeploymentMode),
-		Replicas:                 DefaultUint(record.Replicas, 0),
-		LatestDeployment:         *applicationInputToDeploymentDatabaseObject(record),
-		Deployments:              make([]core.Deployment, 0),
-		IngressRules:             make([]core.IngressRule, 0),
-		Hostname:                 record.Hostname,
-		Command:                  record.Command,
-		Capabilities:             record.Capabilities,
-		Sysctls:                  record.Sysctls,
-		ReservedResource:         *reservedResourceInputToDatabaseObject(record.ReservedResource),
-		ResourceLimit:            *resourceLimitInputToDatabaseObject(record.ResourceLimit),
-		IsSleeping:               false,
-		ApplicationGroupID:       record.ApplicationGroupID,
-		PreferredServerHostnames: record.PreferredServerHostnames,
-		DockerProxy:              *dockerProxyConfigToDatabaseObject(record.DockerProxyConfig),
-		CustomHealthCheck:        *applicationCustomHealthCheckInputToDatabaseObject(record.CustomHealthCheck),
+		Name:                        record.Name,
+		EnvironmentVariables:        environmentVariables,
+		PersistentVolumeBindings:    persistentVolumeBindings,
+		ConfigMounts:                configMounts,
+		DeploymentMode:              core.DeploymentMode(record.DeploymentMode),
+		Replicas:                    DefaultUint(record.Replicas, 0),
+		LatestDeployment:            *applicationInputToDeploymentDatabaseObject(record),
+		Deployments:                 make([]core.Deployment, 0),
+		IngressRules:                make([]core.IngressRule, 0),
+		Hostname:                    record.Hostname,
+		Command:                     record.Command,
+		Capabilities:                record.Capabilities,
+		Sysctls:                     record.Sysctls,
+		ReservedResource:            *reservedResourceInputToDatabaseObject(record.ReservedResource),
+		ResourceLimit:               *resourceLimitInputToDatabaseObject(record.ResourceLimit),
+		IsSleeping:                  false,
+		ApplicationGroupID:          record.ApplicationGroupID,
+		PreferredServerHostnames:    record.PreferredServerHostnames,
+		DockerProxy:                 *dockerProxyConfigToDatabaseObject(record.DockerProxyConfig),
+		CustomHealthCheck:           *applicationCustomHealthCheckInputToDatabaseObject(record.CustomHealthCheck),
+		AutoRollback:                *applicationAutoRollbackInputToDatabaseObject(record.AutoRollback),
+		UpdateConfig:                *applicationUpdateConfigInputToDatabaseObject(record.UpdateConfig),
+		DeploymentStrategy:          deploymentStrategy,
+		ReleaseCommands:             *applicationReleaseCommandsInputToDatabaseObject(record.ReleaseCommands),
+		JobConfig:                   *applicationJobConfigInputToDatabaseObject(record.JobConfig),
+		RetentionPolicy:             *applicationRetentionPolicyInputToDatabaseObject(record.RetentionPolicy),
+		GitPolling:                  *applicationGitPollingInputToDatabaseObject(record.GitPolling),
+		PreviewConfig:               *applicationPreviewConfigInputToDatabaseObject(record.PreviewConfig),
+		PreviewEnvironmentVariables: previewEnvironmentVariables,
 	}
 }
 
@@ -405,6 +554,9 @@ func applicationToGraphqlObject(record *core.Application) *model.Application {
 		Replicas:                 record.Replicas,
 		IsDeleted:                record.IsDeleted,
 		WebhookToken:             record.WebhookToken,
+		WebhookSecret:            record.WebhookSecret,
+		WebhookSignatureRequired: !record.AllowUnsignedWebhooks,
+		AllowUnsignedWebhooks:    record.AllowUnsignedWebhooks,
 		Capabilities:             record.Capabilities,
 		Sysctls:                  record.Sysctls,
 		ResourceLimit:            resourceLimitToGraphqlObject(&record.ResourceLimit),
@@ -417,6 +569,16 @@ func applicationToGraphqlObject(record *core.Application) *model.Application {
 		DockerProxyHost:          record.DockerProxyServiceName(),
 		DockerProxyConfig:        dockerProxyConfigToGraphqlObject(&record.DockerProxy),
 		CustomHealthCheck:        applicationCustomHealthCheckToGraphqlObject(&record.CustomHealthCheck),
+		AutoRollback:             applicationAutoRollbackToGraphqlObject(&record.AutoRollback),
+		UpdateConfig:             applicationUpdateConfigToGraphqlObject(&record.UpdateConfig),
+		DeploymentStrategy:       model.DeploymentStrategy(record.DeploymentStrategy),
+		CandidateDeploymentID:    record.CandidateDeploymentID,
+		CandidateTrafficPercent:  record.CandidateTrafficPercent,
+		ReleaseCommands:          applicationReleaseCommandsToGraphqlObject(&record.ReleaseCommands),
+		JobConfig:                applicationJobConfigToGraphqlObject(&record.JobConfig),
+		RetentionPolicy:          applicationRetentionPolicyToGraphqlObject(&record.RetentionPolicy),
+		GitPolling:               applicationGitPollingToGraphqlObject(&record.GitPolling),
+		PreviewConfig:            applicationPreviewConfigToGraphqlObject(&record.PreviewConfig),
 	}
 }
 
@@ -434,6 +596,14 @@ func deploymentToGraphqlObject(record *core.Deployment) *model.Deployment {
 	if record.UpstreamType == core.UpstreamTypeGit {
 		repositoryUrl = record.GitRepositoryURL()
 	}
+	gitReferenceType := record.GitReferenceType
+	if gitReferenceType == "" {
+		gitReferenceType = core.GitReferenceBranch
+	}
+	originalDeploymentId := ""
+	if record.OriginalDeploymentID != nil {
+		originalDeploymentId = *record.OriginalDeploymentID
+	}
 	return &model.Deployment{
 		ID:                           record.ID,
 		ApplicationID:                record.ApplicationID,
@@ -445,6 +615,10 @@ func deploymentToGraphqlObject(record *core.Deployment) *model.Deployment {
 		RepositoryName:               record.RepositoryName,
 		RepositoryBranch:             record.RepositoryBranch,
 		RepositoryURL:                repositoryUrl,
+		GitReferenceType:             model.GitReferenceType(gitReferenceType),
+		RepositoryTag:                record.RepositoryTag,
+		GitTagPattern:                record.GitTagPattern,
+		GitCloneConfig:               gitCloneConfigToGraphqlObject(&record.GitCloneConfig),
 		CommitHash:                   record.CommitHash,
 		CommitMessage:                record.CommitMessage,
 		CodePath:                     record.CodePath,
@@ -452,6 +626,8 @@ func deploymentToGraphqlObject(record *core.Deployment) *model.Deployment {
 		DockerImage:                  record.DockerImage,
 		ImageRegistryCredentialID:    imageRegistryCredentialId,
 		Dockerfile:                   record.Dockerfile,
+		DockerfileTarget:             record.DockerfileTarget,
+		OriginalDeploymentID:         originalDeploymentId,
 		Status:                       model.DeploymentStatus(record.Status),
 		CreatedAt:                    record.CreatedAt,
 	}
@@ -580,6 +756,265 @@ func applicationCustomHealthCheckInputToDatabaseObject(record *model.Application
 	}
 }
 
+// applicationAutoRollbackToGraphqlObject converts ApplicationAutoRollback to ApplicationAutoRollbackGraphqlObject
+func applicationAutoRollbackToGraphqlObject(record *core.ApplicationAutoRollback) *model.ApplicationAutoRollback {
+	return &model.ApplicationAutoRollback{
+		Enabled:       record.Enabled,
+		WindowSeconds: uint(record.Window().Seconds()),
+	}
+}
+
+// applicationAutoRollbackInputToDatabaseObject converts ApplicationAutoRollbackInput to ApplicationAutoRollbackDatabaseObject
+func applicationAutoRollbackInputToDatabaseObject(record *model.ApplicationAutoRollbackInput) *core.ApplicationAutoRollback {
+	if record == nil {
+		return &core.ApplicationAutoRollback{
+			Enabled:       true,
+			WindowSeconds: core.DefaultAutoRollbackWindowSeconds,
+		}
+	}
+	return &core.ApplicationAutoRollback{
+		Enabled:       record.Enabled,
+		WindowSeconds: record.WindowSeconds,
+	}
+}
+
+// applicationUpdateConfigToGraphqlObject converts ApplicationUpdateConfig to ApplicationUpdateConfigGraphqlObject
+func applicationUpdateConfigToGraphqlObject(record *core.ApplicationUpdateConfig) *model.ApplicationUpdateConfig {
+	return &model.ApplicationUpdateConfig{
+		Parallelism:     record.Parallelism,
+		DelaySeconds:    record.DelaySeconds,
+		Order:           model.UpdateOrder(strings.ReplaceAll(string(record.Order), "-", "_")),
+		FailureAction:   model.UpdateFailureAction(record.FailureAction),
+		MonitorSeconds:  record.MonitorSeconds,
+		MaxFailureRatio: float64(record.MaxFailureRatio),
+	}
+}
+
+// applicationUpdateConfigInputToDatabaseObject converts ApplicationUpdateConfigInput to ApplicationUpdateConfigDatabaseObject
+func applicationUpdateConfigInputToDatabaseObject(record *model.ApplicationUpdateConfigInput) *core.ApplicationUpdateConfig {
+	if record == nil {
+		updateConfig := core.DefaultApplicationUpdateConfig()
+		return &updateConfig
+	}
+	return &core.ApplicationUpdateConfig{
+		Parallelism:     record.Parallelism,
+		DelaySeconds:    record.DelaySeconds,
+		Order:           core.UpdateOrder(strings.ReplaceAll(string(record.Order), "_", "-")),
+		FailureAction:   core.UpdateFailureAction(record.FailureAction),
+		MonitorSeconds:  record.MonitorSeconds,
+		MaxFailureRatio: float32(record.MaxFailureRatio),
+	}
+}
+
+// applicationReleaseCommandsToGraphqlObject converts ApplicationReleaseCommands to ApplicationReleaseCommandsGraphqlObject
+func applicationReleaseCommandsToGraphqlObject(record *core.ApplicationReleaseCommands) *model.ApplicationReleaseCommands {
+	return &model.ApplicationReleaseCommands{
+		PreDeploy:      record.PreDeploy,
+		PostDeploy:     record.PostDeploy,
+		TimeoutSeconds: uint(record.Timeout().Seconds()),
+	}
+}
+
+// applicationReleaseCommandsInputToDatabaseObject converts ApplicationReleaseCommandsInput to ApplicationReleaseCommandsDatabaseObject
+func applicationReleaseCommandsInputToDatabaseObject(record *model.ApplicationReleaseCommandsInput) *core.ApplicationReleaseCommands {
+	if record == nil {
+		return &core.ApplicationReleaseCommands{
+			TimeoutSeconds: core.DefaultReleaseCommandTimeoutSeconds,
+		}
+	}
+	timeoutSeconds := record.TimeoutSeconds
+	if timeoutSeconds == 0 {
+		timeoutSeconds = core.DefaultReleaseCommandTimeoutSeconds
+	}
+	return &core.ApplicationReleaseCommands{
+		PreDeploy:      strings.TrimSpace(record.PreDeploy),
+		PostDeploy:     strings.TrimSpace(record.PostDeploy),
+		TimeoutSeconds: timeoutSeconds,
+	}
+}
+
+// applicationJobConfigToGraphqlObject converts ApplicationJobConfig to ApplicationJobConfigGraphqlObject
+func applicationJobConfigToGraphqlObject(record *core.ApplicationJobConfig) *model.ApplicationJobConfig {
+	var nextRunAt *time.Time
+	if record.Schedule != "" {
+		schedule, err := core.ParseCronSchedule(record.Schedule)
+		if err == nil {
+			next := schedule.Next(time.Now())
+			if !next.IsZero() {
+				nextRunAt = &next
+			}
+		}
+	}
+	return &model.ApplicationJobConfig{
+		Schedule:       record.Schedule,
+		TimeoutSeconds: uint(record.Timeout().Seconds()),
+		NextRunAt:      nextRunAt,
+	}
+}
+
+// applicationJobConfigInputToDatabaseObject converts ApplicationJobConfigInput to ApplicationJobConfigDatabaseObject
+func applicationJobConfigInputToDatabaseObject(record *model.ApplicationJobConfigInput) *core.ApplicationJobConfig {
+	if record == nil {
+		return &core.ApplicationJobConfig{
+			TimeoutSeconds: core.DefaultJobTimeoutSeconds,
+		}
+	}
+	timeoutSeconds := record.TimeoutSeconds
+	if timeoutSeconds == 0 {
+		timeoutSeconds = core.DefaultJobTimeoutSeconds
+	}
+	return &core.ApplicationJobConfig{
+		Schedule:       strings.TrimSpace(record.Schedule),
+		TimeoutSeconds: timeoutSeconds,
+	}
+}
+
+// applicationRetentionPolicyToGraphqlObject converts ApplicationRetentionPolicy to ApplicationRetentionPolicyGraphqlObject
+func applicationRetentionPolicyToGraphqlObject(record *core.ApplicationRetentionPolicy) *model.ApplicationRetentionPolicy {
+	return &model.ApplicationRetentionPolicy{
+		KeepLastDeployments: record.KeepLastDeployments,
+		KeepDays:            record.KeepDays,
+	}
+}
+
+// applicationRetentionPolicyInputToDatabaseObject converts ApplicationRetentionPolicyInput to ApplicationRetentionPolicyDatabaseObject
+func applicationRetentionPolicyInputToDatabaseObject(record *model.ApplicationRetentionPolicyInput) *core.ApplicationRetentionPolicy {
+	if record == nil {
+		return &core.ApplicationRetentionPolicy{}
+	}
+	return &core.ApplicationRetentionPolicy{
+		KeepLastDeployments: record.KeepLastDeployments,
+		KeepDays:            record.KeepDays,
+	}
+}
+
+// applicationGitPollingToGraphqlObject converts ApplicationGitPolling to ApplicationGitPollingGraphqlObject
+func applicationGitPollingToGraphqlObject(record *core.ApplicationGitPolling) *model.ApplicationGitPolling {
+	return &model.ApplicationGitPolling{
+		Enabled:         record.Enabled,
+		IntervalSeconds: uint(record.Interval().Seconds()),
+	}
+}
+
+// applicationGitPollingInputToDatabaseObject converts ApplicationGitPollingInput to ApplicationGitPollingDatabaseObject
+func applicationGitPollingInputToDatabaseObject(record *model.ApplicationGitPollingInput) *core.ApplicationGitPolling {
+	if record == nil {
+		return &core.ApplicationGitPolling{
+			Enabled:         false,
+			IntervalSeconds: core.DefaultGitPollingIntervalSeconds,
+		}
+	}
+	intervalSeconds := record.IntervalSeconds
+	if intervalSeconds == 0 {
+		intervalSeconds = core.DefaultGitPollingIntervalSeconds
+	}
+	return &core.ApplicationGitPolling{
+		Enabled:         record.Enabled,
+		IntervalSeconds: intervalSeconds,
+	}
+}
+
+// gitCloneConfigToGraphqlObject converts GitCloneConfig to GitCloneConfigGraphqlObject
+func gitCloneConfigToGraphqlObject(record *core.GitCloneConfig) *model.GitCloneConfig {
+	return &model.GitCloneConfig{
+		Submodules: record.Submodules,
+		Lfs:        record.LFS,
+		Shallow:    record.Shallow,
+	}
+}
+
+// gitCloneConfigInputToDatabaseObject converts GitCloneConfigInput to GitCloneConfigDatabaseObject
+func gitCloneConfigInputToDatabaseObject(record *model.GitCloneConfigInput) *core.GitCloneConfig {
+	if record == nil {
+		config := core.DefaultGitCloneConfig()
+		return &config
+	}
+	return &core.GitCloneConfig{
+		Submodules: record.Submodules,
+		LFS:        record.Lfs,
+		Shallow:    record.Shallow,
+	}
+}
+
+// applicationPreviewConfigToGraphqlObject converts ApplicationPreviewConfig to ApplicationPreviewConfigGraphqlObject
+func applicationPreviewConfigToGraphqlObject(record *core.ApplicationPreviewConfig) *model.ApplicationPreviewConfig {
+	return &model.ApplicationPreviewConfig{
+		Enabled:            record.Enabled,
+		BaseDomain:         record.BaseDomain,
+		TargetPort:         record.TargetPort,
+		CopySecrets:        record.CopySecrets,
+		RunReleaseCommands: record.RunReleaseCommands,
+	}
+}
+
+// applicationPreviewConfigInputToDatabaseObject converts ApplicationPreviewConfigInput to ApplicationPreviewConfigDatabaseObject
+func applicationPreviewConfigInputToDatabaseObject(record *model.ApplicationPreviewConfigInput) *core.ApplicationPreviewConfig {
+	if record == nil {
+		return &core.ApplicationPreviewConfig{
+			Enabled: false,
+		}
+	}
+	return &core.ApplicationPreviewConfig{
+		Enabled:            record.Enabled,
+		BaseDomain:         strings.TrimSpace(record.BaseDomain),
+		TargetPort:         record.TargetPort,
+		CopySecrets:        record.CopySecrets != nil && *record.CopySecrets,
+		RunReleaseCommands: record.RunReleaseCommands != nil && *record.RunReleaseCommands,
+	}
+}
+
+// previewEnvironmentVariableToGraphqlObject converts PreviewEnvironmentVariable to PreviewEnvironmentVariableGraphqlObject
+func previewEnvironmentVariableToGraphqlObject(record *core.PreviewEnvironmentVariable) *model.PreviewEnvironmentVariable {
+	value := record.Value
+	if record.IsSecret {
+		value = core.SecretEnvironmentVariableMask
+	}
+	return &model.PreviewEnvironmentVariable{
+		Key:      record.Key,
+		Value:    value,
+		IsSecret: record.IsSecret,
+	}
+}
+
+// previewEnvironmentVariableInputToDatabaseObject converts PreviewEnvironmentVariableInput to PreviewEnvironmentVariableDatabaseObject
+func previewEnvironmentVariableInputToDatabaseObject(record *model.PreviewEnvironmentVariableInput) *core.PreviewEnvironmentVariable {
+	return &core.PreviewEnvironmentVariable{
+		Key:      record.Key,
+		Value:    record.Value,
+		IsSecret: record.IsSecret,
+	}
+}
+
+// applicationPreviewToGraphqlObject converts ApplicationPreview to ApplicationPreviewGraphqlObject
+func applicationPreviewToGraphqlObject(record *core.ApplicationPreview) *model.ApplicationPreview {
+	return &model.ApplicationPreview{
+		ID:                   record.ID,
+		ApplicationID:        record.ApplicationID,
+		PreviewApplicationID: record.PreviewApplicationID,
+		PullRequestNumber:    record.PullRequestNumber,
+		Branch:               record.Branch,
+		CommitHash:           record.CommitHash,
+		Domain:               record.Domain,
+		Status:               model.ApplicationPreviewStatus(record.Status),
+		CreatedAt:            record.CreatedAt,
+	}
+}
+
+// applicationJobRunToGraphqlObject converts ApplicationJobRun to ApplicationJobRunGraphqlObject
+func applicationJobRunToGraphqlObject(record *core.ApplicationJobRun) *model.ApplicationJobRun {
+	return &model.ApplicationJobRun{
+		ID:            record.ID,
+		ApplicationID: record.ApplicationID,
+		DeploymentID:  record.DeploymentID,
+		Trigger:       model.JobRunTrigger(record.Trigger),
+		Status:        model.JobRunStatus(record.Status),
+		ExitCode:      record.ExitCode,
+		CreatedAt:     record.CreatedAt,
+		StartedAt:     record.StartedAt,
+		CompletedAt:   record.CompletedAt,
+	}
+}
+
 // ingressRuleInputToDatabaseObject converts IngressRuleInput to IngressRuleDatabaseObject
 func ingressRuleInputToDatabaseObject(record *model.IngressRuleInput) *core.IngressRule {
 	// unset domain id if protocol is tcp or udp
@@ -685,7 +1120,9 @@ func userToGraphqlObject(record *core.User) *model.User {
 	return &model.User{
 		ID:          record.ID,
 		Username:    record.Username,
+		Role:        model.UserRole(record.Role),
 		TotpEnabled: record.TotpEnabled,
+		SsoLinked:   record.OIDCSubject != "",
 	}
 }
 
@@ -732,6 +1169,22 @@ func stackToApplicationsInput(applicationGroupID *string, record *stack_parser.S
 		if service.Command != nil {
 			command = service.Command.String()
 		}
+		// update config
+		var updateConfig *model.ApplicationUpdateConfigInput
+		if service.Deploy.UpdateConfig != nil {
+			config, err := service.Deploy.UpdateConfig.ApplicationUpdateConfig()
+			if err != nil {
+				return nil, err
+			}
+			updateConfig = &model.ApplicationUpdateConfigInput{
+				Parallelism:     config.Parallelism,
+				DelaySeconds:    config.DelaySeconds,
+				Order:           model.UpdateOrder(strings.ReplaceAll(string(config.Order), "-", "_")),
+				FailureAction:   model.UpdateFailureAction(config.FailureAction),
+				MonitorSeconds:  config.MonitorSeconds,
+				MaxFailureRatio: float64(config.MaxFailureRatio),
+			}
+		}
 		// docker proxy config
 
 		app := model.ApplicationInput{
@@ -772,6 +1225,12 @@ func stackToApplicationsInput(applicationGroupID *string, record *stack_parser.S
 				Retries:              service.CustomHealthCheck.Retries,
 			},
 			PreferredServerHostnames: service.PreferredServerHostnames,
+			UpdateConfig:             updateConfig,
+			ReleaseCommands: &model.ApplicationReleaseCommandsInput{
+				PreDeploy:      service.ReleaseCommands.PreDeploy,
+				PostDeploy:     service.ReleaseCommands.PostDeploy,
+				TimeoutSeconds: service.ReleaseCommands.TimeoutSeconds,
+			},
 			DockerProxyConfig: &model.DockerProxyConfigInput{
 				Enabled: service.DockerProxyConfig.Enabled,
 				Permission: &model.DockerProxyPermissionInput{
@@ -836,6 +1295,8 @@ func serverToGraphqlObject(record *core.Server) *model.Server {
 		User:                 record.User,
 		ScheduleDeployments:  record.ScheduleDeployments,
 		MaintenanceMode:      record.MaintenanceMode,
+		IsBuildNode:          record.IsBuildNode,
+		MaxConcurrentBuilds:  record.MaxConcurrentBuilds,
 		DockerUnixSocketPath: record.DockerUnixSocketPath,
 		SwarmMode:            model.SwarmMode(record.SwarmMode),
 		ProxyType:            model.ProxyType(record.ProxyConfig.Type),
@@ -940,3 +1401,57 @@ func appBasicAuthAccessControlUserInputToDatabaseObject(record *model.AppBasicAu
 		AppBasicAuthAccessControlListID: record.AppBasicAuthAccessControlListID,
 	}
 }
+
+// webAuthnCredentialToGraphqlObject converts WebAuthnCredential to WebAuthnCredentialGraphqlObject
+func webAuthnCredentialToGraphqlObject(record *core.WebAuthnCredential) *model.WebAuthnCredential {
+	return &model.WebAuthnCredential{
+		ID:         record.ID,
+		Name:       record.Name,
+		LastUsedAt: record.LastUsedAt,
+		CreatedAt:  record.CreatedAt,
+	}
+}
+
+// userSessionToGraphqlObject converts UserSession to UserSessionGraphqlObject
+func userSessionToGraphqlObject(record *core.UserSession, currentSessionID string) *model.UserSession {
+	return &model.UserSession{
+		ID:              record.ID,
+		IP:              record.IP,
+		UserAgent:       record.UserAgent,
+		Current:         record.ID == currentSessionID,
+		LastRefreshedAt: record.LastRefreshedAt,
+		ExpiresAt:       record.ExpiresAt,
+		CreatedAt:       record.CreatedAt,
+	}
+}
+
+// deploymentDiffToGraphqlObject converts DeploymentDiff to DeploymentDiffGraphqlObject
+// Values of the variables are masked, if the values are not allowed to be shown
+func deploymentDiffToGraphqlObject(record *core.DeploymentDiff, isValueVisible bool) *model.DeploymentDiff {
+	changes := make([]*model.DeploymentChange, 0)
+	for _, change := range record.Changes {
+		oldValue := change.OldValue
+		newValue := change.NewValue
+		if change.IsSecret || (change.IsSensitive() && !isValueVisible) {
+			if oldValue != "" {
+				oldValue = core.SecretEnvironmentVariableMask
+			}
+			if newValue != "" {
+				newValue = core.SecretEnvironmentVariableMask
+			}
+		}
+		changes = append(changes, &model.DeploymentChange{
+			Category: model.DeploymentChangeCategory(change.Category),
+			Type:     model.DeploymentChangeType(change.Type),
+			Field:    change.Field,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+	return &model.DeploymentDiff{
+		Changes:                   changes,
+		RebuildRequired:           record.RebuildRequired,
+		ReloadRequired:            record.ReloadRequired,
+		IsConfigSnapshotAvailable: record.IsConfigSnapshotAvailable,
+	}
+}
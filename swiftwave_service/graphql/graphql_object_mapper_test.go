@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+func TestEnvironmentVariableSecretValueIsMasked(t *testing.T) {
+	secret := environmentVariableToGraphqlObject(&core.EnvironmentVariable{Key: "DB_PASSWORD", Value: "hunter2", IsSecret: true, SecretFileEnv: true})
+	assert.Equal(t, core.SecretEnvironmentVariableMask, secret.Value)
+	assert.True(t, secret.IsSecret)
+	assert.True(t, secret.SecretFileEnv)
+
+	plain := environmentVariableToGraphqlObject(&core.EnvironmentVariable{Key: "PORT", Value: "80"})
+	assert.Equal(t, "80", plain.Value)
+	assert.False(t, plain.IsSecret)
+}
+
+func TestEnvironmentVariableInputFileEnvRequiresSecret(t *testing.T) {
+	record := environmentVariableInputToDatabaseObject(&model.EnvironmentVariableInput{Key: " PORT ", Value: " 80 ", SecretFileEnv: true})
+	assert.Equal(t, "PORT", record.Key)
+	assert.Equal(t, "80", record.Value)
+	assert.False(t, record.SecretFileEnv)
+
+	record = environmentVariableInputToDatabaseObject(&model.EnvironmentVariableInput{Key: "DB_PASSWORD", Value: "hunter2", IsSecret: true, SecretFileEnv: true})
+	assert.True(t, record.IsSecret)
+	assert.True(t, record.SecretFileEnv)
+}
+
+func TestApplicationAutoRollbackIsEnabledByDefault(t *testing.T) {
+	record := applicationAutoRollbackInputToDatabaseObject(nil)
+	assert.True(t, record.Enabled)
+	assert.Equal(t, uint(core.DefaultAutoRollbackWindowSeconds), record.WindowSeconds)
+
+	record = applicationAutoRollbackInputToDatabaseObject(&model.ApplicationAutoRollbackInput{Enabled: false, WindowSeconds: 30})
+	assert.False(t, record.Enabled)
+	assert.Equal(t, uint(30), record.WindowSeconds)
+
+	// unset window is reported with the default, which is used by the health watch
+	object := applicationAutoRollbackToGraphqlObject(&core.ApplicationAutoRollback{Enabled: true})
+	assert.Equal(t, uint(core.DefaultAutoRollbackWindowSeconds), object.WindowSeconds)
+}
+
+func TestPreviewEnvironmentVariableSecretValueIsMasked(t *testing.T) {
+	secret := previewEnvironmentVariableToGraphqlObject(&core.PreviewEnvironmentVariable{Key: "DATABASE_URL", Value: "postgres://preview", IsSecret: true})
+	assert.Equal(t, core.SecretEnvironmentVariableMask, secret.Value)
+	assert.True(t, secret.IsSecret)
+
+	plain := previewEnvironmentVariableToGraphqlObject(&core.PreviewEnvironmentVariable{Key: "BASE_URL", Value: "https://{{PREVIEW_DOMAIN}}"})
+	assert.Equal(t, "https://{{PREVIEW_DOMAIN}}", plain.Value)
+	assert.False(t, plain.IsSecret)
+}
@@ -177,3 +177,33 @@ func (r *mutationResolver) RunActionsInAllHAProxyNodes(ctx context.Context, db *
 		return nil
 	}
 }
+
+// runOnApplicationHAProxyBackends : run the function for each haproxy backend of the application
+// udp ingress rules are skipped, as those are not handled by haproxy
+func runOnApplicationHAProxyBackends(ctx context.Context, db *gorm.DB, transactionId string, haproxyManager *haproxymanager.Manager, application *core.Application, innerFunction func(backendProtocol haproxymanager.BackendProtocol, port int) error) error {
+	ingressRules, err := core.FetchIngressRulesWithTargetPortAndProtocolOnly(ctx, *db, application.ID)
+	if err != nil {
+		return err
+	}
+	for _, record := range ingressRules {
+		if record.Protocol == core.UDPProtocol {
+			continue
+		}
+		backendProtocol := haproxymanager.HTTPBackend
+		if record.Protocol == core.TCPProtocol {
+			backendProtocol = haproxymanager.TCPBackend
+		}
+		isBackendExist, err := haproxyManager.IsBackendExist(transactionId, haproxyManager.GenerateBackendName(backendProtocol, application.Name, int(record.TargetPort)))
+		if err != nil {
+			return err
+		}
+		if !isBackendExist {
+			continue
+		}
+		err = innerFunction(backendProtocol, int(record.TargetPort))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
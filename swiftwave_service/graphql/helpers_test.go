@@ -0,0 +1,51 @@
+package graphql
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/service_manager"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestResolver : resolver backed by a sqlite database with all the tables migrated
+func newTestResolver(t *testing.T) *Resolver {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "swiftwave.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&core.User{}, &core.ApplicationGroup{}, &core.ApplicationGroupPermission{}, &core.Application{},
+		&core.Deployment{}, &core.Domain{}, &core.IngressRule{}, &core.PersistentVolume{}, &core.PersistentVolumeBinding{},
+		&core.GitCredential{}, &core.ImageRegistryCredential{}, &core.PersonalAccessToken{}, &core.UserSession{},
+		&core.TotpRecoveryCode{}, &core.AuthThrottle{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Resolver{
+		ServiceManager: service_manager.ServiceManager{DbClient: *db},
+	}
+}
+
+// createTestUser : create user and return the context of the logged-in user
+func createTestUser(t *testing.T, r *Resolver, username string, role core.UserRole) (*core.User, context.Context) {
+	user := &core.User{Username: username, Role: role}
+	if err := r.ServiceManager.DbClient.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	//nolint:staticcheck
+	return user, context.WithValue(context.Background(), "username", username)
+}
+
+func mustCreate(t *testing.T, r *Resolver, records ...interface{}) {
+	for _, record := range records {
+		if err := r.ServiceManager.DbClient.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
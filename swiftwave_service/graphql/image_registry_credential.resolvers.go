@@ -11,6 +11,18 @@ import (
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
 )
 
+// Password is the resolver for the password field.
+func (r *imageRegistryCredentialResolver) Password(ctx context.Context, obj *model.ImageRegistryCredential) (string, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return "", err
+	}
+	if user.Role != core.AdministratorRole {
+		return "", nil
+	}
+	return obj.Password, nil
+}
+
 // Deployments is the resolver for the deployments field.
 func (r *imageRegistryCredentialResolver) Deployments(ctx context.Context, obj *model.ImageRegistryCredential) ([]*model.Deployment, error) {
 	// fetch record
@@ -18,9 +30,16 @@ func (r *imageRegistryCredentialResolver) Deployments(ctx context.Context, obj *
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	// convert to graphql object
 	var result = make([]*model.Deployment, 0)
 	for _, record := range records {
+		if !filter.isReadable(record.ApplicationID) {
+			continue
+		}
 		result = append(result, deploymentToGraphqlObject(record))
 	}
 	return result, nil
@@ -47,7 +66,10 @@ func (r *mutationResolver) UpdateImageRegistryCredential(ctx context.Context, id
 	// update record
 	record.Url = input.URL
 	record.Username = input.Username
-	record.Password = input.Password
+	// password is not returned to non admin users, so keep the existing password if not provided
+	if input.Password != "" {
+		record.Password = input.Password
+	}
 	err = record.Update(ctx, r.ServiceManager.DbClient)
 	if err != nil {
 		return nil, err
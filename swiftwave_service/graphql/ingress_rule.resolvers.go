@@ -35,6 +35,9 @@ func (r *ingressRuleResolver) Application(ctx context.Context, obj *model.Ingres
 	if obj.TargetType == model.IngressRuleTargetTypeExternalService {
 		return applicationToGraphqlObject(application), nil
 	}
+	if err := r.checkApplicationAccess(ctx, obj.ApplicationID, core.ReadAccess); err != nil {
+		return nil, err
+	}
 	err := application.FindById(ctx, r.ServiceManager.DbClient, obj.ApplicationID)
 	if err != nil {
 		return nil, err
@@ -274,6 +277,13 @@ func (r *queryResolver) IngressRule(ctx context.Context, id uint) (*model.Ingres
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !filter.isIngressRuleReadable(&record) {
+		return nil, errors.New("unauthorized: your access is limited to specific application groups")
+	}
 	return ingressRuleToGraphqlObject(&record), nil
 }
 
@@ -283,8 +293,15 @@ func (r *queryResolver) IngressRules(ctx context.Context) ([]*model.IngressRule,
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var result []*model.IngressRule
 	for _, record := range records {
+		if !filter.isIngressRuleReadable(record) {
+			continue
+		}
 		result = append(result, ingressRuleToGraphqlObject(record))
 	}
 	return result, nil
@@ -32,32 +32,59 @@ type AppBasicAuthAccessControlUserInput struct {
 }
 
 type Application struct {
-	ID                       string                        `json:"id"`
-	Name                     string                        `json:"name"`
-	EnvironmentVariables     []*EnvironmentVariable        `json:"environmentVariables"`
-	PersistentVolumeBindings []*PersistentVolumeBinding    `json:"persistentVolumeBindings"`
-	ConfigMounts             []*ConfigMount                `json:"configMounts"`
-	Capabilities             []string                      `json:"capabilities"`
-	Sysctls                  []string                      `json:"sysctls"`
-	ResourceLimit            *ResourceLimit                `json:"resourceLimit"`
-	ReservedResource         *ReservedResource             `json:"reservedResource"`
-	RealtimeInfo             *RealtimeInfo                 `json:"realtimeInfo"`
-	LatestDeployment         *Deployment                   `json:"latestDeployment"`
-	Deployments              []*Deployment                 `json:"deployments"`
-	DeploymentMode           DeploymentMode                `json:"deploymentMode"`
-	Replicas                 uint                          `json:"replicas"`
-	IngressRules             []*IngressRule                `json:"ingressRules"`
-	IsDeleted                bool                          `json:"isDeleted"`
-	WebhookToken             string                        `json:"webhookToken"`
-	IsSleeping               bool                          `json:"isSleeping"`
-	Command                  string                        `json:"command"`
-	Hostname                 string                        `json:"hostname"`
-	ApplicationGroupID       *string                       `json:"applicationGroupID,omitempty"`
-	ApplicationGroup         *ApplicationGroup             `json:"applicationGroup,omitempty"`
-	PreferredServerHostnames []string                      `json:"preferredServerHostnames"`
-	DockerProxyHost          string                        `json:"dockerProxyHost"`
-	DockerProxyConfig        *DockerProxyConfig            `json:"dockerProxyConfig"`
-	CustomHealthCheck        *ApplicationCustomHealthCheck `json:"customHealthCheck"`
+	ID                          string                        `json:"id"`
+	Name                        string                        `json:"name"`
+	EnvironmentVariables        []*EnvironmentVariable        `json:"environmentVariables"`
+	PersistentVolumeBindings    []*PersistentVolumeBinding    `json:"persistentVolumeBindings"`
+	ConfigMounts                []*ConfigMount                `json:"configMounts"`
+	Capabilities                []string                      `json:"capabilities"`
+	Sysctls                     []string                      `json:"sysctls"`
+	ResourceLimit               *ResourceLimit                `json:"resourceLimit"`
+	ReservedResource            *ReservedResource             `json:"reservedResource"`
+	RealtimeInfo                *RealtimeInfo                 `json:"realtimeInfo"`
+	LatestDeployment            *Deployment                   `json:"latestDeployment"`
+	Deployments                 []*Deployment                 `json:"deployments"`
+	DeploymentMode              DeploymentMode                `json:"deploymentMode"`
+	Replicas                    uint                          `json:"replicas"`
+	IngressRules                []*IngressRule                `json:"ingressRules"`
+	IsDeleted                   bool                          `json:"isDeleted"`
+	WebhookToken                string                        `json:"webhookToken"`
+	WebhookSecret               string                        `json:"webhookSecret"`
+	WebhookSignatureRequired    bool                          `json:"webhookSignatureRequired"`
+	AllowUnsignedWebhooks       bool                          `json:"allowUnsignedWebhooks"`
+	IsSleeping                  bool                          `json:"isSleeping"`
+	Command                     string                        `json:"command"`
+	Hostname                    string                        `json:"hostname"`
+	ApplicationGroupID          *string                       `json:"applicationGroupID,omitempty"`
+	ApplicationGroup            *ApplicationGroup             `json:"applicationGroup,omitempty"`
+	PreferredServerHostnames    []string                      `json:"preferredServerHostnames"`
+	DockerProxyHost             string                        `json:"dockerProxyHost"`
+	DockerProxyConfig           *DockerProxyConfig            `json:"dockerProxyConfig"`
+	CustomHealthCheck           *ApplicationCustomHealthCheck `json:"customHealthCheck"`
+	AutoRollback                *ApplicationAutoRollback      `json:"autoRollback"`
+	UpdateConfig                *ApplicationUpdateConfig      `json:"updateConfig"`
+	DeploymentStrategy          DeploymentStrategy            `json:"deploymentStrategy"`
+	CandidateDeploymentID       *string                       `json:"candidateDeploymentID,omitempty"`
+	CandidateDeployment         *Deployment                   `json:"candidateDeployment,omitempty"`
+	CandidateTrafficPercent     uint                          `json:"candidateTrafficPercent"`
+	ReleaseCommands             *ApplicationReleaseCommands   `json:"releaseCommands"`
+	JobConfig                   *ApplicationJobConfig         `json:"jobConfig"`
+	RetentionPolicy             *ApplicationRetentionPolicy   `json:"retentionPolicy"`
+	GitPolling                  *ApplicationGitPolling        `json:"gitPolling"`
+	JobRuns                     []*ApplicationJobRun          `json:"jobRuns"`
+	PreviewConfig               *ApplicationPreviewConfig     `json:"previewConfig"`
+	PreviewEnvironmentVariables []*PreviewEnvironmentVariable `json:"previewEnvironmentVariables"`
+	Previews                    []*ApplicationPreview         `json:"previews"`
+}
+
+type ApplicationAutoRollback struct {
+	Enabled       bool `json:"enabled"`
+	WindowSeconds uint `json:"window_seconds"`
+}
+
+type ApplicationAutoRollbackInput struct {
+	Enabled       bool `json:"enabled"`
+	WindowSeconds uint `json:"window_seconds"`
 }
 
 type ApplicationCustomHealthCheck struct {
@@ -86,6 +113,16 @@ type ApplicationDeployResult struct {
 	Application *Application `json:"application,omitempty"`
 }
 
+type ApplicationGitPolling struct {
+	Enabled         bool `json:"enabled"`
+	IntervalSeconds uint `json:"interval_seconds"`
+}
+
+type ApplicationGitPollingInput struct {
+	Enabled         bool `json:"enabled"`
+	IntervalSeconds uint `json:"interval_seconds"`
+}
+
 type ApplicationGroup struct {
 	ID           string         `json:"id"`
 	Name         string         `json:"name"`
@@ -97,6 +134,20 @@ type ApplicationGroupInput struct {
 	Name string `json:"name"`
 }
 
+type ApplicationGroupPermission struct {
+	ID                 uint              `json:"id"`
+	UserID             uint              `json:"userId"`
+	ApplicationGroupID string            `json:"applicationGroupId"`
+	ApplicationGroup   *ApplicationGroup `json:"applicationGroup"`
+	Access             AccessLevel       `json:"access"`
+}
+
+type ApplicationGroupPermissionInput struct {
+	UserID             uint        `json:"userId"`
+	ApplicationGroupID string      `json:"applicationGroupId"`
+	Access             AccessLevel `json:"access"`
+}
+
 type ApplicationInput struct {
 	Name                         string                             `json:"name"`
 	EnvironmentVariables         []*EnvironmentVariableInput        `json:"environmentVariables"`
@@ -106,6 +157,8 @@ type ApplicationInput struct {
 	Sysctls                      []string                           `json:"sysctls"`
 	Dockerfile                   *string                            `json:"dockerfile,omitempty"`
 	BuildArgs                    []*BuildArgInput                   `json:"buildArgs"`
+	DockerfileTarget             *string                            `json:"dockerfileTarget,omitempty"`
+	BuildSecrets                 []string                           `json:"buildSecrets,omitempty"`
 	DeploymentMode               DeploymentMode                     `json:"deploymentMode"`
 	Replicas                     *uint                              `json:"replicas,omitempty"`
 	ResourceLimit                *ResourceLimitInput                `json:"resourceLimit"`
@@ -115,6 +168,11 @@ type ApplicationInput struct {
 	GitCredentialID              *uint                              `json:"gitCredentialID,omitempty"`
 	RepositoryURL                *string                            `json:"repositoryUrl,omitempty"`
 	RepositoryBranch             *string                            `json:"repositoryBranch,omitempty"`
+	GitReferenceType             *GitReferenceType                  `json:"gitReferenceType,omitempty"`
+	RepositoryTag                *string                            `json:"repositoryTag,omitempty"`
+	GitTagPattern                *string                            `json:"gitTagPattern,omitempty"`
+	CommitHash                   *string                            `json:"commitHash,omitempty"`
+	GitCloneConfig               *GitCloneConfigInput               `json:"gitCloneConfig,omitempty"`
 	CodePath                     *string                            `json:"codePath,omitempty"`
 	SourceCodeCompressedFileName *string                            `json:"sourceCodeCompressedFileName,omitempty"`
 	DockerImage                  *string                            `json:"dockerImage,omitempty"`
@@ -124,6 +182,78 @@ type ApplicationInput struct {
 	PreferredServerHostnames     []string                           `json:"preferredServerHostnames"`
 	DockerProxyConfig            *DockerProxyConfigInput            `json:"dockerProxyConfig"`
 	CustomHealthCheck            *ApplicationCustomHealthCheckInput `json:"customHealthCheck"`
+	AutoRollback                 *ApplicationAutoRollbackInput      `json:"autoRollback,omitempty"`
+	UpdateConfig                 *ApplicationUpdateConfigInput      `json:"updateConfig,omitempty"`
+	DeploymentStrategy           *DeploymentStrategy                `json:"deploymentStrategy,omitempty"`
+	ReleaseCommands              *ApplicationReleaseCommandsInput   `json:"releaseCommands,omitempty"`
+	JobConfig                    *ApplicationJobConfigInput         `json:"jobConfig,omitempty"`
+	RetentionPolicy              *ApplicationRetentionPolicyInput   `json:"retentionPolicy,omitempty"`
+	GitPolling                   *ApplicationGitPollingInput        `json:"gitPolling,omitempty"`
+	PreviewConfig                *ApplicationPreviewConfigInput     `json:"previewConfig,omitempty"`
+	PreviewEnvironmentVariables  []*PreviewEnvironmentVariableInput `json:"previewEnvironmentVariables,omitempty"`
+}
+
+type ApplicationJobConfig struct {
+	Schedule       string     `json:"schedule"`
+	TimeoutSeconds uint       `json:"timeout_seconds"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
+
+type ApplicationJobConfigInput struct {
+	Schedule       string `json:"schedule"`
+	TimeoutSeconds uint   `json:"timeout_seconds"`
+}
+
+type ApplicationJobRun struct {
+	ID            uint          `json:"id"`
+	ApplicationID string        `json:"applicationID"`
+	DeploymentID  string        `json:"deploymentID"`
+	Trigger       JobRunTrigger `json:"trigger"`
+	Status        JobRunStatus  `json:"status"`
+	ExitCode      int           `json:"exitCode"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	StartedAt     *time.Time    `json:"startedAt,omitempty"`
+	CompletedAt   *time.Time    `json:"completedAt,omitempty"`
+}
+
+type ApplicationPreview struct {
+	ID                   uint                     `json:"id"`
+	ApplicationID        string                   `json:"applicationID"`
+	PreviewApplicationID string                   `json:"previewApplicationID"`
+	PullRequestNumber    uint                     `json:"pullRequestNumber"`
+	Branch               string                   `json:"branch"`
+	CommitHash           string                   `json:"commitHash"`
+	Domain               string                   `json:"domain"`
+	Status               ApplicationPreviewStatus `json:"status"`
+	CreatedAt            time.Time                `json:"createdAt"`
+}
+
+type ApplicationPreviewConfig struct {
+	Enabled            bool   `json:"enabled"`
+	BaseDomain         string `json:"base_domain"`
+	TargetPort         uint   `json:"target_port"`
+	CopySecrets        bool   `json:"copy_secrets"`
+	RunReleaseCommands bool   `json:"run_release_commands"`
+}
+
+type ApplicationPreviewConfigInput struct {
+	Enabled            bool   `json:"enabled"`
+	BaseDomain         string `json:"base_domain"`
+	TargetPort         uint   `json:"target_port"`
+	CopySecrets        *bool  `json:"copy_secrets,omitempty"`
+	RunReleaseCommands *bool  `json:"run_release_commands,omitempty"`
+}
+
+type ApplicationReleaseCommands struct {
+	PreDeploy      string `json:"pre_deploy"`
+	PostDeploy     string `json:"post_deploy"`
+	TimeoutSeconds uint   `json:"timeout_seconds"`
+}
+
+type ApplicationReleaseCommandsInput struct {
+	PreDeploy      string `json:"pre_deploy"`
+	PostDeploy     string `json:"post_deploy"`
+	TimeoutSeconds uint   `json:"timeout_seconds"`
 }
 
 type ApplicationResourceAnalytics struct {
@@ -139,6 +269,59 @@ type ApplicationResourceAnalytics struct {
 	Timestamp            time.Time `json:"timestamp"`
 }
 
+type ApplicationRetentionPolicy struct {
+	KeepLastDeployments uint `json:"keep_last_deployments"`
+	KeepDays            uint `json:"keep_days"`
+}
+
+type ApplicationRetentionPolicyInput struct {
+	KeepLastDeployments uint `json:"keep_last_deployments"`
+	KeepDays            uint `json:"keep_days"`
+}
+
+type ApplicationUpdateConfig struct {
+	Parallelism     uint64              `json:"parallelism"`
+	DelaySeconds    uint64              `json:"delay_seconds"`
+	Order           UpdateOrder         `json:"order"`
+	FailureAction   UpdateFailureAction `json:"failure_action"`
+	MonitorSeconds  uint64              `json:"monitor_seconds"`
+	MaxFailureRatio float64             `json:"max_failure_ratio"`
+}
+
+type ApplicationUpdateConfigInput struct {
+	Parallelism     uint64              `json:"parallelism"`
+	DelaySeconds    uint64              `json:"delay_seconds"`
+	Order           UpdateOrder         `json:"order"`
+	FailureAction   UpdateFailureAction `json:"failure_action"`
+	MonitorSeconds  uint64              `json:"monitor_seconds"`
+	MaxFailureRatio float64             `json:"max_failure_ratio"`
+}
+
+type AuditLog struct {
+	ID        uint            `json:"id"`
+	Username  string          `json:"username"`
+	IP        string          `json:"ip"`
+	Source    AuditLogSource  `json:"source"`
+	Action    string          `json:"action"`
+	TargetID  string          `json:"targetId"`
+	Arguments string          `json:"arguments"`
+	Outcome   AuditLogOutcome `json:"outcome"`
+	Error     string          `json:"error"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type AuditLogFilter struct {
+	Username *string          `json:"username,omitempty"`
+	Source   *AuditLogSource  `json:"source,omitempty"`
+	Action   *string          `json:"action,omitempty"`
+	TargetID *string          `json:"targetId,omitempty"`
+	Outcome  *AuditLogOutcome `json:"outcome,omitempty"`
+	From     *time.Time       `json:"from,omitempty"`
+	To       *time.Time       `json:"to,omitempty"`
+	Limit    *int             `json:"limit,omitempty"`
+	Offset   *int             `json:"offset,omitempty"`
+}
+
 type BuildArg struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -185,6 +368,11 @@ type ConfigMountInput struct {
 	Gid          uint   `json:"gid"`
 }
 
+type CreatedPersonalAccessToken struct {
+	Token               string               `json:"token"`
+	PersonalAccessToken *PersonalAccessToken `json:"personalAccessToken"`
+}
+
 type CustomSSLInput struct {
 	FullChain  string `json:"fullChain"`
 	PrivateKey string `json:"privateKey"`
@@ -211,6 +399,10 @@ type Deployment struct {
 	RepositoryName               string                   `json:"repositoryName"`
 	RepositoryBranch             string                   `json:"repositoryBranch"`
 	RepositoryURL                string                   `json:"repositoryUrl"`
+	GitReferenceType             GitReferenceType         `json:"gitReferenceType"`
+	RepositoryTag                string                   `json:"repositoryTag"`
+	GitTagPattern                string                   `json:"gitTagPattern"`
+	GitCloneConfig               *GitCloneConfig          `json:"gitCloneConfig"`
 	CommitHash                   string                   `json:"commitHash"`
 	CommitMessage                string                   `json:"commitMessage"`
 	CodePath                     string                   `json:"codePath"`
@@ -219,11 +411,29 @@ type Deployment struct {
 	ImageRegistryCredentialID    uint                     `json:"imageRegistryCredentialID"`
 	ImageRegistryCredential      *ImageRegistryCredential `json:"imageRegistryCredential"`
 	BuildArgs                    []*BuildArg              `json:"buildArgs"`
+	BuildSecrets                 []string                 `json:"buildSecrets"`
 	Dockerfile                   string                   `json:"dockerfile"`
+	DockerfileTarget             string                   `json:"dockerfileTarget"`
+	OriginalDeploymentID         string                   `json:"originalDeploymentID"`
 	Status                       DeploymentStatus         `json:"status"`
 	CreatedAt                    time.Time                `json:"createdAt"`
 }
 
+type DeploymentChange struct {
+	Category DeploymentChangeCategory `json:"category"`
+	Type     DeploymentChangeType     `json:"type"`
+	Field    string                   `json:"field"`
+	OldValue string                   `json:"oldValue"`
+	NewValue string                   `json:"newValue"`
+}
+
+type DeploymentDiff struct {
+	Changes                   []*DeploymentChange `json:"changes"`
+	RebuildRequired           bool                `json:"rebuildRequired"`
+	ReloadRequired            bool                `json:"reloadRequired"`
+	IsConfigSnapshotAvailable bool                `json:"isConfigSnapshotAvailable"`
+}
+
 type DeploymentLog struct {
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"createdAt"`
@@ -332,13 +542,17 @@ type DomainInput struct {
 }
 
 type EnvironmentVariable struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	Key           string `json:"key"`
+	Value         string `json:"value"`
+	IsSecret      bool   `json:"isSecret"`
+	SecretFileEnv bool   `json:"secretFileEnv"`
 }
 
 type EnvironmentVariableInput struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	Key           string `json:"key"`
+	Value         string `json:"value"`
+	IsSecret      bool   `json:"isSecret"`
+	SecretFileEnv bool   `json:"secretFileEnv"`
 }
 
 type FileInfo struct {
@@ -351,21 +565,35 @@ type GitBranchesQueryInput struct {
 	RepositoryURL   string `json:"repositoryUrl"`
 }
 
+type GitCloneConfig struct {
+	Submodules bool `json:"submodules"`
+	Lfs        bool `json:"lfs"`
+	Shallow    bool `json:"shallow"`
+}
+
+type GitCloneConfigInput struct {
+	Submodules bool `json:"submodules"`
+	Lfs        bool `json:"lfs"`
+	Shallow    bool `json:"shallow"`
+}
+
 type GitCredential struct {
 	ID           uint          `json:"id"`
 	Type         GitType       `json:"type"`
 	Name         string        `json:"name"`
 	Username     string        `json:"username"`
 	SSHPublicKey string        `json:"sshPublicKey"`
+	AllowedHosts []string      `json:"allowedHosts"`
 	Deployments  []*Deployment `json:"deployments"`
 }
 
 type GitCredentialInput struct {
-	Name          string  `json:"name"`
-	Type          GitType `json:"type"`
-	Username      string  `json:"username"`
-	Password      string  `json:"password"`
-	SSHPrivateKey string  `json:"sshPrivateKey"`
+	Name          string   `json:"name"`
+	Type          GitType  `json:"type"`
+	Username      string   `json:"username"`
+	Password      string   `json:"password"`
+	SSHPrivateKey string   `json:"sshPrivateKey"`
+	AllowedHosts  []string `json:"allowedHosts"`
 }
 
 type GitCredentialRepositoryAccessInput struct {
@@ -513,6 +741,33 @@ type PersistentVolumeRestoreInput struct {
 	Type               PersistentVolumeRestoreType `json:"type"`
 }
 
+type PersonalAccessToken struct {
+	ID         string                     `json:"id"`
+	Name       string                     `json:"name"`
+	Scopes     []PersonalAccessTokenScope `json:"scopes"`
+	ExpiresAt  *time.Time                 `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time                 `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time                  `json:"createdAt"`
+}
+
+type PersonalAccessTokenInput struct {
+	Name      string                     `json:"name"`
+	Scopes    []PersonalAccessTokenScope `json:"scopes,omitempty"`
+	ExpiresAt *time.Time                 `json:"expiresAt,omitempty"`
+}
+
+type PreviewEnvironmentVariable struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	IsSecret bool   `json:"isSecret"`
+}
+
+type PreviewEnvironmentVariableInput struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	IsSecret bool   `json:"isSecret"`
+}
+
 type Query struct {
 }
 
@@ -577,6 +832,8 @@ type Server struct {
 	SwarmNodeStatus      string       `json:"swarmNodeStatus"`
 	ScheduleDeployments  bool         `json:"scheduleDeployments"`
 	MaintenanceMode      bool         `json:"maintenanceMode"`
+	IsBuildNode          bool         `json:"isBuildNode"`
+	MaxConcurrentBuilds  uint         `json:"maxConcurrentBuilds"`
 	DockerUnixSocketPath string       `json:"dockerUnixSocketPath"`
 	ProxyEnabled         bool         `json:"proxyEnabled"`
 	ProxyType            ProxyType    `json:"proxyType"`
@@ -649,14 +906,117 @@ type Subscription struct {
 }
 
 type User struct {
-	ID          uint   `json:"id"`
-	Username    string `json:"username"`
-	TotpEnabled bool   `json:"totpEnabled"`
+	ID                          uint                          `json:"id"`
+	Username                    string                        `json:"username"`
+	Role                        UserRole                      `json:"role"`
+	TotpEnabled                 bool                          `json:"totpEnabled"`
+	SsoLinked                   bool                          `json:"ssoLinked"`
+	ApplicationGroupPermissions []*ApplicationGroupPermission `json:"applicationGroupPermissions"`
 }
 
 type UserInput struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string    `json:"username"`
+	Password string    `json:"password"`
+	Role     *UserRole `json:"role,omitempty"`
+}
+
+type UserSession struct {
+	ID              string    `json:"id"`
+	IP              string    `json:"ip"`
+	UserAgent       string    `json:"userAgent"`
+	Current         bool      `json:"current"`
+	LastRefreshedAt time.Time `json:"lastRefreshedAt"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type WebAuthnCredential struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type AccessLevel string
+
+const (
+	AccessLevelRead  AccessLevel = "read"
+	AccessLevelWrite AccessLevel = "write"
+)
+
+var AllAccessLevel = []AccessLevel{
+	AccessLevelRead,
+	AccessLevelWrite,
+}
+
+func (e AccessLevel) IsValid() bool {
+	switch e {
+	case AccessLevelRead, AccessLevelWrite:
+		return true
+	}
+	return false
+}
+
+func (e AccessLevel) String() string {
+	return string(e)
+}
+
+func (e *AccessLevel) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AccessLevel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AccessLevel", str)
+	}
+	return nil
+}
+
+func (e AccessLevel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ApplicationPreviewStatus string
+
+const (
+	ApplicationPreviewStatusActive   ApplicationPreviewStatus = "active"
+	ApplicationPreviewStatusDeleting ApplicationPreviewStatus = "deleting"
+)
+
+var AllApplicationPreviewStatus = []ApplicationPreviewStatus{
+	ApplicationPreviewStatusActive,
+	ApplicationPreviewStatusDeleting,
+}
+
+func (e ApplicationPreviewStatus) IsValid() bool {
+	switch e {
+	case ApplicationPreviewStatusActive, ApplicationPreviewStatusDeleting:
+		return true
+	}
+	return false
+}
+
+func (e ApplicationPreviewStatus) String() string {
+	return string(e)
+}
+
+func (e *ApplicationPreviewStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ApplicationPreviewStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ApplicationPreviewStatus", str)
+	}
+	return nil
+}
+
+func (e ApplicationPreviewStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
 type ApplicationResourceAnalyticsTimeframe string
@@ -710,21 +1070,205 @@ func (e ApplicationResourceAnalyticsTimeframe) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type AuditLogOutcome string
+
+const (
+	AuditLogOutcomeSuccess AuditLogOutcome = "success"
+	AuditLogOutcomeFailure AuditLogOutcome = "failure"
+)
+
+var AllAuditLogOutcome = []AuditLogOutcome{
+	AuditLogOutcomeSuccess,
+	AuditLogOutcomeFailure,
+}
+
+func (e AuditLogOutcome) IsValid() bool {
+	switch e {
+	case AuditLogOutcomeSuccess, AuditLogOutcomeFailure:
+		return true
+	}
+	return false
+}
+
+func (e AuditLogOutcome) String() string {
+	return string(e)
+}
+
+func (e *AuditLogOutcome) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditLogOutcome(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditLogOutcome", str)
+	}
+	return nil
+}
+
+func (e AuditLogOutcome) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AuditLogSource string
+
+const (
+	AuditLogSourceGraphql AuditLogSource = "graphql"
+	AuditLogSourceRest    AuditLogSource = "rest"
+	AuditLogSourceConsole AuditLogSource = "console"
+)
+
+var AllAuditLogSource = []AuditLogSource{
+	AuditLogSourceGraphql,
+	AuditLogSourceRest,
+	AuditLogSourceConsole,
+}
+
+func (e AuditLogSource) IsValid() bool {
+	switch e {
+	case AuditLogSourceGraphql, AuditLogSourceRest, AuditLogSourceConsole:
+		return true
+	}
+	return false
+}
+
+func (e AuditLogSource) String() string {
+	return string(e)
+}
+
+func (e *AuditLogSource) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditLogSource(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditLogSource", str)
+	}
+	return nil
+}
+
+func (e AuditLogSource) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DeploymentChangeCategory string
+
+const (
+	DeploymentChangeCategoryUpstream                DeploymentChangeCategory = "upstream"
+	DeploymentChangeCategoryDockerfile              DeploymentChangeCategory = "dockerfile"
+	DeploymentChangeCategoryBuildArg                DeploymentChangeCategory = "build_arg"
+	DeploymentChangeCategoryBuildSecret             DeploymentChangeCategory = "build_secret"
+	DeploymentChangeCategoryEnvironmentVariable     DeploymentChangeCategory = "environment_variable"
+	DeploymentChangeCategoryConfigMount             DeploymentChangeCategory = "config_mount"
+	DeploymentChangeCategoryPersistentVolumeBinding DeploymentChangeCategory = "persistent_volume_binding"
+	DeploymentChangeCategoryResource                DeploymentChangeCategory = "resource"
+)
+
+var AllDeploymentChangeCategory = []DeploymentChangeCategory{
+	DeploymentChangeCategoryUpstream,
+	DeploymentChangeCategoryDockerfile,
+	DeploymentChangeCategoryBuildArg,
+	DeploymentChangeCategoryBuildSecret,
+	DeploymentChangeCategoryEnvironmentVariable,
+	DeploymentChangeCategoryConfigMount,
+	DeploymentChangeCategoryPersistentVolumeBinding,
+	DeploymentChangeCategoryResource,
+}
+
+func (e DeploymentChangeCategory) IsValid() bool {
+	switch e {
+	case DeploymentChangeCategoryUpstream, DeploymentChangeCategoryDockerfile, DeploymentChangeCategoryBuildArg, DeploymentChangeCategoryBuildSecret, DeploymentChangeCategoryEnvironmentVariable, DeploymentChangeCategoryConfigMount, DeploymentChangeCategoryPersistentVolumeBinding, DeploymentChangeCategoryResource:
+		return true
+	}
+	return false
+}
+
+func (e DeploymentChangeCategory) String() string {
+	return string(e)
+}
+
+func (e *DeploymentChangeCategory) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeploymentChangeCategory(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeploymentChangeCategory", str)
+	}
+	return nil
+}
+
+func (e DeploymentChangeCategory) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DeploymentChangeType string
+
+const (
+	DeploymentChangeTypeAdded    DeploymentChangeType = "added"
+	DeploymentChangeTypeRemoved  DeploymentChangeType = "removed"
+	DeploymentChangeTypeModified DeploymentChangeType = "modified"
+)
+
+var AllDeploymentChangeType = []DeploymentChangeType{
+	DeploymentChangeTypeAdded,
+	DeploymentChangeTypeRemoved,
+	DeploymentChangeTypeModified,
+}
+
+func (e DeploymentChangeType) IsValid() bool {
+	switch e {
+	case DeploymentChangeTypeAdded, DeploymentChangeTypeRemoved, DeploymentChangeTypeModified:
+		return true
+	}
+	return false
+}
+
+func (e DeploymentChangeType) String() string {
+	return string(e)
+}
+
+func (e *DeploymentChangeType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeploymentChangeType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeploymentChangeType", str)
+	}
+	return nil
+}
+
+func (e DeploymentChangeType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type DeploymentMode string
 
 const (
 	DeploymentModeReplicated DeploymentMode = "replicated"
 	DeploymentModeGlobal     DeploymentMode = "global"
+	DeploymentModeCronJob    DeploymentMode = "cron_job"
+	DeploymentModeRunOnceJob DeploymentMode = "run_once_job"
 )
 
 var AllDeploymentMode = []DeploymentMode{
 	DeploymentModeReplicated,
 	DeploymentModeGlobal,
+	DeploymentModeCronJob,
+	DeploymentModeRunOnceJob,
 }
 
 func (e DeploymentMode) IsValid() bool {
 	switch e {
-	case DeploymentModeReplicated, DeploymentModeGlobal:
+	case DeploymentModeReplicated, DeploymentModeGlobal, DeploymentModeCronJob, DeploymentModeRunOnceJob:
 		return true
 	}
 	return false
@@ -761,6 +1305,7 @@ const (
 	DeploymentStatusStopped       DeploymentStatus = "stopped"
 	DeploymentStatusFailed        DeploymentStatus = "failed"
 	DeploymentStatusStalled       DeploymentStatus = "stalled"
+	DeploymentStatusCandidate     DeploymentStatus = "candidate"
 )
 
 var AllDeploymentStatus = []DeploymentStatus{
@@ -771,11 +1316,12 @@ var AllDeploymentStatus = []DeploymentStatus{
 	DeploymentStatusStopped,
 	DeploymentStatusFailed,
 	DeploymentStatusStalled,
+	DeploymentStatusCandidate,
 }
 
 func (e DeploymentStatus) IsValid() bool {
 	switch e {
-	case DeploymentStatusPending, DeploymentStatusDeployPending, DeploymentStatusDeploying, DeploymentStatusDeployed, DeploymentStatusStopped, DeploymentStatusFailed, DeploymentStatusStalled:
+	case DeploymentStatusPending, DeploymentStatusDeployPending, DeploymentStatusDeploying, DeploymentStatusDeployed, DeploymentStatusStopped, DeploymentStatusFailed, DeploymentStatusStalled, DeploymentStatusCandidate:
 		return true
 	}
 	return false
@@ -802,6 +1348,49 @@ func (e DeploymentStatus) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type DeploymentStrategy string
+
+const (
+	DeploymentStrategyRolling   DeploymentStrategy = "rolling"
+	DeploymentStrategyBlueGreen DeploymentStrategy = "blue_green"
+	DeploymentStrategyCanary    DeploymentStrategy = "canary"
+)
+
+var AllDeploymentStrategy = []DeploymentStrategy{
+	DeploymentStrategyRolling,
+	DeploymentStrategyBlueGreen,
+	DeploymentStrategyCanary,
+}
+
+func (e DeploymentStrategy) IsValid() bool {
+	switch e {
+	case DeploymentStrategyRolling, DeploymentStrategyBlueGreen, DeploymentStrategyCanary:
+		return true
+	}
+	return false
+}
+
+func (e DeploymentStrategy) String() string {
+	return string(e)
+}
+
+func (e *DeploymentStrategy) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeploymentStrategy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeploymentStrategy", str)
+	}
+	return nil
+}
+
+func (e DeploymentStrategy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type DockerConfigSourceType string
 
 const (
@@ -933,6 +1522,49 @@ func (e DomainSSLStatus) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type GitReferenceType string
+
+const (
+	GitReferenceTypeBranch GitReferenceType = "branch"
+	GitReferenceTypeTag    GitReferenceType = "tag"
+	GitReferenceTypeCommit GitReferenceType = "commit"
+)
+
+var AllGitReferenceType = []GitReferenceType{
+	GitReferenceTypeBranch,
+	GitReferenceTypeTag,
+	GitReferenceTypeCommit,
+}
+
+func (e GitReferenceType) IsValid() bool {
+	switch e {
+	case GitReferenceTypeBranch, GitReferenceTypeTag, GitReferenceTypeCommit:
+		return true
+	}
+	return false
+}
+
+func (e GitReferenceType) String() string {
+	return string(e)
+}
+
+func (e *GitReferenceType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = GitReferenceType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid GitReferenceType", str)
+	}
+	return nil
+}
+
+func (e GitReferenceType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type GitType string
 
 const (
@@ -1144,6 +1776,92 @@ func (e IngressRuleTargetType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type JobRunStatus string
+
+const (
+	JobRunStatusPending   JobRunStatus = "pending"
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+)
+
+var AllJobRunStatus = []JobRunStatus{
+	JobRunStatusPending,
+	JobRunStatusRunning,
+	JobRunStatusSucceeded,
+	JobRunStatusFailed,
+}
+
+func (e JobRunStatus) IsValid() bool {
+	switch e {
+	case JobRunStatusPending, JobRunStatusRunning, JobRunStatusSucceeded, JobRunStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e JobRunStatus) String() string {
+	return string(e)
+}
+
+func (e *JobRunStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = JobRunStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid JobRunStatus", str)
+	}
+	return nil
+}
+
+func (e JobRunStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type JobRunTrigger string
+
+const (
+	JobRunTriggerSchedule JobRunTrigger = "schedule"
+	JobRunTriggerManual   JobRunTrigger = "manual"
+)
+
+var AllJobRunTrigger = []JobRunTrigger{
+	JobRunTriggerSchedule,
+	JobRunTriggerManual,
+}
+
+func (e JobRunTrigger) IsValid() bool {
+	switch e {
+	case JobRunTriggerSchedule, JobRunTriggerManual:
+		return true
+	}
+	return false
+}
+
+func (e JobRunTrigger) String() string {
+	return string(e)
+}
+
+func (e *JobRunTrigger) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = JobRunTrigger(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid JobRunTrigger", str)
+	}
+	return nil
+}
+
+func (e JobRunTrigger) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type PersistentVolumeBackupStatus string
 
 const (
@@ -1353,6 +2071,47 @@ func (e PersistentVolumeType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type PersonalAccessTokenScope string
+
+const (
+	PersonalAccessTokenScopeRead  PersonalAccessTokenScope = "read"
+	PersonalAccessTokenScopeWrite PersonalAccessTokenScope = "write"
+)
+
+var AllPersonalAccessTokenScope = []PersonalAccessTokenScope{
+	PersonalAccessTokenScopeRead,
+	PersonalAccessTokenScopeWrite,
+}
+
+func (e PersonalAccessTokenScope) IsValid() bool {
+	switch e {
+	case PersonalAccessTokenScopeRead, PersonalAccessTokenScopeWrite:
+		return true
+	}
+	return false
+}
+
+func (e PersonalAccessTokenScope) String() string {
+	return string(e)
+}
+
+func (e *PersonalAccessTokenScope) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PersonalAccessTokenScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PersonalAccessTokenScope", str)
+	}
+	return nil
+}
+
+func (e PersonalAccessTokenScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type ProtocolType string
 
 const (
@@ -1672,6 +2431,90 @@ func (e SwarmMode) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type UpdateFailureAction string
+
+const (
+	UpdateFailureActionPause    UpdateFailureAction = "pause"
+	UpdateFailureActionContinue UpdateFailureAction = "continue"
+	UpdateFailureActionRollback UpdateFailureAction = "rollback"
+)
+
+var AllUpdateFailureAction = []UpdateFailureAction{
+	UpdateFailureActionPause,
+	UpdateFailureActionContinue,
+	UpdateFailureActionRollback,
+}
+
+func (e UpdateFailureAction) IsValid() bool {
+	switch e {
+	case UpdateFailureActionPause, UpdateFailureActionContinue, UpdateFailureActionRollback:
+		return true
+	}
+	return false
+}
+
+func (e UpdateFailureAction) String() string {
+	return string(e)
+}
+
+func (e *UpdateFailureAction) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UpdateFailureAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UpdateFailureAction", str)
+	}
+	return nil
+}
+
+func (e UpdateFailureAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type UpdateOrder string
+
+const (
+	UpdateOrderStopFirst  UpdateOrder = "stop_first"
+	UpdateOrderStartFirst UpdateOrder = "start_first"
+)
+
+var AllUpdateOrder = []UpdateOrder{
+	UpdateOrderStopFirst,
+	UpdateOrderStartFirst,
+}
+
+func (e UpdateOrder) IsValid() bool {
+	switch e {
+	case UpdateOrderStopFirst, UpdateOrderStartFirst:
+		return true
+	}
+	return false
+}
+
+func (e UpdateOrder) String() string {
+	return string(e)
+}
+
+func (e *UpdateOrder) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UpdateOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UpdateOrder", str)
+	}
+	return nil
+}
+
+func (e UpdateOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type UpstreamType string
 
 const (
@@ -1714,3 +2557,46 @@ func (e *UpstreamType) UnmarshalGQL(v interface{}) error {
 func (e UpstreamType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type UserRole string
+
+const (
+	UserRoleAdmin   UserRole = "admin"
+	UserRoleManager UserRole = "manager"
+	UserRoleViewer  UserRole = "viewer"
+)
+
+var AllUserRole = []UserRole{
+	UserRoleAdmin,
+	UserRoleManager,
+	UserRoleViewer,
+}
+
+func (e UserRole) IsValid() bool {
+	switch e {
+	case UserRoleAdmin, UserRoleManager, UserRoleViewer:
+		return true
+	}
+	return false
+}
+
+func (e UserRole) String() string {
+	return string(e)
+}
+
+func (e *UserRole) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserRole", str)
+	}
+	return nil
+}
+
+func (e UserRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -48,9 +48,16 @@ func (r *persistentVolumeResolver) PersistentVolumeBindings(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	// convert to graphql object
 	var result = make([]*model.PersistentVolumeBinding, 0)
 	for _, record := range records {
+		if !filter.isReadable(record.ApplicationID) {
+			continue
+		}
 		result = append(result, persistentVolumeBindingToGraphqlObject(record))
 	}
 	return result, nil
@@ -92,8 +99,19 @@ func (r *queryResolver) PersistentVolumes(ctx context.Context) ([]*model.Persist
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.readableApplicationFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var result []*model.PersistentVolume
 	for _, record := range records {
+		isReadable, err := r.isPersistentVolumeReadable(ctx, filter, record.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !isReadable {
+			continue
+		}
 		result = append(result, persistentVolumeToGraphqlObject(record))
 	}
 	return result, nil
@@ -106,6 +124,9 @@ func (r *queryResolver) PersistentVolume(ctx context.Context, id uint) (*model.P
 	if err != nil {
 		return nil, err
 	}
+	if err := r.checkPersistentVolumeAccess(ctx, record.ID); err != nil {
+		return nil, err
+	}
 	return persistentVolumeToGraphqlObject(&record), nil
 }
 
@@ -117,6 +138,9 @@ func (r *queryResolver) PersistentVolumeSizeMb(ctx context.Context, id uint) (fl
 	if err != nil {
 		return 0, err
 	}
+	if err := r.checkPersistentVolumeAccess(ctx, record.ID); err != nil {
+		return 0, err
+	}
 	// Fetch a random swarm manager
 	swarmManagerServer, err := core.FetchSwarmManager(&r.ServiceManager.DbClient)
 	if err != nil {
@@ -23,6 +23,9 @@ func (r *persistentVolumeBindingResolver) PersistentVolume(ctx context.Context,
 
 // Application is the resolver for the application field.
 func (r *persistentVolumeBindingResolver) Application(ctx context.Context, obj *model.PersistentVolumeBinding) (*model.Application, error) {
+	if err := r.checkApplicationAccess(ctx, obj.ApplicationID, dbmodel.ReadAccess); err != nil {
+		return nil, err
+	}
 	var application = &dbmodel.Application{}
 	err := application.FindById(ctx, r.ServiceManager.DbClient, obj.ApplicationID)
 	if err != nil {
@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// errLoginSessionRequired : account security settings can be changed only from a login session
+var errLoginSessionRequired = errors.New("account security settings can't be changed with personal access token, login required")
+
+// isAuthenticatedByPersonalAccessToken : check if the request has been authenticated by personal access token instead of login session
+func isAuthenticatedByPersonalAccessToken(ctx context.Context) bool {
+	_, ok := ctx.Value("personal_access_token").(*core.PersonalAccessToken)
+	return ok
+}
+
+// personalAccessTokenScopeMiddleware : restrict the operations as per the scopes of personal access token
+func personalAccessTokenScopeMiddleware(ctx context.Context, next gqlgen.OperationHandler) gqlgen.ResponseHandler {
+	accessToken, ok := ctx.Value("personal_access_token").(*core.PersonalAccessToken)
+	if !ok {
+		return next(ctx)
+	}
+	requiredScope := core.PersonalAccessTokenScopeRead
+	operationContext := gqlgen.GetOperationContext(ctx)
+	if operationContext.Operation != nil && operationContext.Operation.Operation == ast.Mutation {
+		requiredScope = core.PersonalAccessTokenScopeWrite
+	}
+	if !accessToken.HasScope(requiredScope) {
+		return gqlgen.OneShot(gqlgen.ErrorResponse(ctx, "unauthorized: personal access token doesn't have %s scope", requiredScope))
+	}
+	return next(ctx)
+}
@@ -0,0 +1,75 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.48
+
+import (
+	"context"
+	"errors"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+// CreatePersonalAccessToken is the resolver for the createPersonalAccessToken field.
+func (r *mutationResolver) CreatePersonalAccessToken(ctx context.Context, input model.PersonalAccessTokenInput) (*model.CreatedPersonalAccessToken, error) {
+	// a leaked token should not be able to mint new tokens and outlive its own expiry or revocation
+	if isAuthenticatedByPersonalAccessToken(ctx) {
+		return nil, errors.New("personal access token can't be created with personal access token, login required")
+	}
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	record := personalAccessTokenInputToDatabaseObject(&input)
+	record.UserID = user.ID
+	token, err := record.Create(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreatedPersonalAccessToken{
+		Token:               token,
+		PersonalAccessToken: personalAccessTokenToGraphqlObject(record),
+	}, nil
+}
+
+// RevokePersonalAccessToken is the resolver for the revokePersonalAccessToken field.
+func (r *mutationResolver) RevokePersonalAccessToken(ctx context.Context, id string) (bool, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, err
+	}
+	var record = &core.PersonalAccessToken{}
+	err = record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		// Don't return error if record not found -- assume it's already revoked
+		return true, nil
+	}
+	// user can revoke only their own tokens
+	if record.UserID != user.ID {
+		return false, errors.New("personal access token not found")
+	}
+	err = record.Delete(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, errors.New("failed to revoke personal access token")
+	}
+	return true, nil
+}
+
+// PersonalAccessTokens is the resolver for the personalAccessTokens field.
+func (r *queryResolver) PersonalAccessTokens(ctx context.Context) ([]*model.PersonalAccessToken, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	records, err := core.FindPersonalAccessTokensByUserID(ctx, r.ServiceManager.DbClient, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*model.PersonalAccessToken, 0)
+	for _, record := range records {
+		result = append(result, personalAccessTokenToGraphqlObject(record))
+	}
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestCreatePersonalAccessTokenRequiresLoginSession(t *testing.T) {
+	r := newTestResolver(t)
+	_, ctx := createTestUser(t, r, "ci", core.ManagerRole)
+
+	created, err := r.Mutation().CreatePersonalAccessToken(ctx, model.PersonalAccessTokenInput{Name: "deploy"})
+	assert.NoError(t, err)
+
+	accessToken, _, err := core.ValidatePersonalAccessToken(ctx, r.ServiceManager.DbClient, created.Token)
+	assert.NoError(t, err)
+	//nolint:staticcheck
+	tokenCtx := context.WithValue(ctx, "personal_access_token", accessToken)
+	_, err = r.Mutation().CreatePersonalAccessToken(tokenCtx, model.PersonalAccessTokenInput{Name: "renewed"})
+	assert.Error(t, err)
+
+	tokens, err := core.FindPersonalAccessTokensByUserID(ctx, r.ServiceManager.DbClient, accessToken.UserID)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+}
+
+func TestPersonalAccessTokenScopeMiddleware(t *testing.T) {
+	readOnlyToken := &core.PersonalAccessToken{Scopes: []string{string(core.PersonalAccessTokenScopeRead)}}
+	readWriteToken := &core.PersonalAccessToken{Scopes: []string{string(core.PersonalAccessTokenScopeRead), string(core.PersonalAccessTokenScopeWrite)}}
+	testCases := []struct {
+		name      string
+		token     *core.PersonalAccessToken
+		operation ast.Operation
+		allowed   bool
+	}{
+		{name: "login session can run mutation", token: nil, operation: ast.Mutation, allowed: true},
+		{name: "read scope can run query", token: readOnlyToken, operation: ast.Query, allowed: true},
+		{name: "read scope can run subscription", token: readOnlyToken, operation: ast.Subscription, allowed: true},
+		{name: "read scope can't run mutation", token: readOnlyToken, operation: ast.Mutation, allowed: false},
+		{name: "write scope can run mutation", token: readWriteToken, operation: ast.Mutation, allowed: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := gqlgen.WithOperationContext(context.Background(), &gqlgen.OperationContext{
+				Operation: &ast.OperationDefinition{Operation: testCase.operation},
+			})
+			if testCase.token != nil {
+				//nolint:staticcheck
+				ctx = context.WithValue(ctx, "personal_access_token", testCase.token)
+			}
+			isNextCalled := false
+			response := personalAccessTokenScopeMiddleware(ctx, func(ctx context.Context) gqlgen.ResponseHandler {
+				isNextCalled = true
+				return gqlgen.OneShot(&gqlgen.Response{})
+			})(ctx)
+			assert.Equal(t, testCase.allowed, isNextCalled)
+			assert.Equal(t, testCase.allowed, len(response.Errors) == 0)
+		})
+	}
+}
+
+func TestAccountSecurityMutationsRequireLoginSession(t *testing.T) {
+	r := newTestResolver(t)
+	user, ctx := createTestUser(t, r, "ci", core.ManagerRole)
+	accessToken := &core.PersonalAccessToken{UserID: user.ID, Scopes: []string{string(core.PersonalAccessTokenScopeRead), string(core.PersonalAccessTokenScopeWrite)}}
+	//nolint:staticcheck
+	tokenCtx := context.WithValue(ctx, "personal_access_token", accessToken)
+	mutation := r.Mutation()
+
+	_, err := mutation.RequestTotpEnable(tokenCtx)
+	assert.ErrorIs(t, err, errLoginSessionRequired)
+	_, err = mutation.EnableTotp(tokenCtx, "123456")
+	assert.ErrorIs(t, err, errLoginSessionRequired)
+	_, err = mutation.EnableTotpWithRecoveryCodes(tokenCtx, "123456")
+	assert.ErrorIs(t, err, errLoginSessionRequired)
+	_, err = mutation.DisableTotp(tokenCtx)
+	assert.ErrorIs(t, err, errLoginSessionRequired)
+	_, err = mutation.GenerateTotpRecoveryCodes(tokenCtx)
+	assert.ErrorIs(t, err, errLoginSessionRequired)
+	_, err = mutation.ChangePassword(tokenCtx, &model.PasswordUpdateInput{OldPassword: "password", NewPassword: "changed"})
+	assert.ErrorIs(t, err, errLoginSessionRequired)
+	_, err = mutation.RemoveWebAuthnCredential(tokenCtx, 1)
+	assert.ErrorIs(t, err, errLoginSessionRequired)
+
+	// login session passes the guard
+	_, err = mutation.GenerateTotpRecoveryCodes(ctx)
+	assert.EqualError(t, err, "totp is not enabled")
+}
@@ -23,6 +23,9 @@ func (r *subscriptionResolver) FetchRuntimeLog(ctx context.Context, applicationI
 	if err != nil {
 		return nil, err
 	}
+	if err := r.checkApplicationAccess(ctx, application.ID, dbmodel.ReadAccess); err != nil {
+		return nil, err
+	}
 	// fetch docker manager
 	dockerManager, err := FetchDockerManager(ctx, &r.ServiceManager.DbClient)
 	if err != nil {
@@ -10,22 +10,39 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 )
 
+// websocketSessionCheckInterval : interval to re-validate the session of open websocket connections
+// so that the subscriptions are closed once the session or personal access token is revoked
+const websocketSessionCheckInterval = 30 * time.Second
+
 func (server *Server) Initialize() {
 	graphqlHandler := handler.New(
 		NewExecutableSchema(
-			Config{Resolvers: &Resolver{
-				Config:         *server.Config,
-				ServiceManager: *server.ServiceManager,
-				WorkerManager:  *server.WorkerManager,
-			}},
+			Config{
+				Resolvers: &Resolver{
+					Config:         *server.Config,
+					ServiceManager: *server.ServiceManager,
+					WorkerManager:  *server.WorkerManager,
+				},
+				Directives: DirectiveRoot{
+					HasRole: hasRoleDirective(server.ServiceManager.DbClient),
+				},
+			},
 		),
 	)
 
+	// Record the audit log of mutations
+	graphqlHandler.AroundFields(auditLogMiddleware(server.ServiceManager.DbClient))
+
+	// Restrict the operations as per the scopes of personal access token
+	graphqlHandler.AroundOperations(personalAccessTokenScopeMiddleware)
+
 	graphqlHandler.AddTransport(transport.POST{})
 	graphqlHandler.AddTransport(&transport.Websocket{
 		KeepAlivePingInterval: 10 * time.Second,
@@ -40,6 +57,24 @@ func (server *Server) Initialize() {
 			if jwtToken == "" {
 				return ctx, nil, errors.New("missing jwt token")
 			}
+			// personal access token can be used in place of jwt token
+			if core.IsPersonalAccessToken(jwtToken) {
+				accessToken, user, err := core.ValidatePersonalAccessToken(ctx, server.ServiceManager.DbClient, jwtToken)
+				if err != nil {
+					return ctx, nil, err
+				}
+				ctx = closeOnInvalidSession(ctx, websocketSessionCheckInterval, func(ctx context.Context) error {
+					_, _, err := core.ValidatePersonalAccessToken(ctx, server.ServiceManager.DbClient, jwtToken)
+					return err
+				})
+				//nolint:staticcheck
+				ctx = context.WithValue(ctx, "authorized", true)
+				//nolint:staticcheck
+				ctx = context.WithValue(ctx, "username", user.Username)
+				//nolint:staticcheck
+				ctx = context.WithValue(ctx, "personal_access_token", accessToken)
+				return ctx, nil, nil
+			}
 			//nolint:staticcheck
 			ctx = context.WithValue(ctx, "jwt_data", jwtToken)
 			// decode jwt token
@@ -59,10 +94,20 @@ func (server *Server) Initialize() {
 			}
 			// Data in context is available in all resolvers
 			username := claims["username"].(string)
+			sessionID, _ := claims["sid"].(string)
+			// check if the session has been revoked
+			if err := core.ValidateUserSession(ctx, server.ServiceManager.DbClient, sessionID, username); err != nil {
+				return ctx, nil, core.ErrUserSessionExpired
+			}
+			ctx = closeOnInvalidSession(ctx, websocketSessionCheckInterval, func(ctx context.Context) error {
+				return core.ValidateUserSession(ctx, server.ServiceManager.DbClient, sessionID, username)
+			})
 			//nolint:staticcheck
 			ctx = context.WithValue(ctx, "authorized", true)
 			//nolint:staticcheck
 			ctx = context.WithValue(ctx, "username", username)
+			//nolint:staticcheck
+			ctx = context.WithValue(ctx, "session_id", sessionID)
 			return ctx, nil, nil
 		},
 	})
@@ -90,3 +135,28 @@ func (server *Server) Initialize() {
 		})
 	}
 }
+
+// closeOnInvalidSession : re-validate the session periodically, the returned context is cancelled once it's invalid
+// gqlgen closes the websocket connection along with all of its subscriptions when the context is cancelled
+func closeOnInvalidSession(ctx context.Context, interval time.Duration, validate func(ctx context.Context) error) context.Context {
+	ctx, cancel := context.WithCancel(transport.AppendCloseReason(ctx, core.ErrUserSessionExpired.Error()))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := validate(ctx); err != nil {
+					if ctx.Err() == nil {
+						log.Println("closing websocket connection > " + err.Error())
+					}
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return ctx
+}
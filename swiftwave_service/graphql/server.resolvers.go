@@ -424,6 +424,41 @@ func (r *mutationResolver) AllowDeploymentOnServer(ctx context.Context, id uint)
 	return true, nil
 }
 
+// EnableBuildNodeOnServer is the resolver for the enableBuildNodeOnServer field.
+func (r *mutationResolver) EnableBuildNodeOnServer(ctx context.Context, id uint, maxConcurrentBuilds uint) (bool, error) {
+	if maxConcurrentBuilds == 0 {
+		return false, errors.New("max concurrent builds should be at least 1")
+	}
+	server, err := core.FetchServerByID(&r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, err
+	}
+	if server.Status == core.ServerNeedsSetup || server.Status == core.ServerPreparing {
+		return false, errors.New("server is not ready, setup the server before using it as build node")
+	}
+	err = core.ChangeBuildNodeConfig(&r.ServiceManager.DbClient, server, true, maxConcurrentBuilds)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DisableBuildNodeOnServer is the resolver for the disableBuildNodeOnServer field.
+func (r *mutationResolver) DisableBuildNodeOnServer(ctx context.Context, id uint) (bool, error) {
+	server, err := core.FetchServerByID(&r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, err
+	}
+	if !server.IsBuildNode {
+		return false, errors.New("server is not a build node")
+	}
+	err = core.ChangeBuildNodeConfig(&r.ServiceManager.DbClient, server, false, server.MaxConcurrentBuilds)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // PutServerInMaintenanceMode is the resolver for the putServerInMaintenanceMode field.
 func (r *mutationResolver) PutServerInMaintenanceMode(ctx context.Context, id uint) (bool, error) {
 	server, err := core.FetchServerByID(&r.ServiceManager.DbClient, id)
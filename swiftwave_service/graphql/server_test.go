@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+func TestCloseOnInvalidSession(t *testing.T) {
+	r := newTestResolver(t)
+	user, _ := createTestUser(t, r, "admin", core.AdministratorRole)
+	session, _, err := core.CreateUserSession(context.Background(), r.ServiceManager.DbClient, user.ID, "203.0.113.7", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validate := func(ctx context.Context) error {
+		return core.ValidateUserSession(ctx, r.ServiceManager.DbClient, session.ID, user.Username)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = closeOnInvalidSession(ctx, 10*time.Millisecond, validate)
+
+	// connection is kept open while the session is active
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, ctx.Err())
+
+	assert.NoError(t, session.Revoke(context.Background(), r.ServiceManager.DbClient))
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("websocket context is not cancelled after revoking the session")
+	}
+}
+
+func TestCloseOnInvalidSessionStopsWithConnection(t *testing.T) {
+	calls := make(chan struct{}, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = closeOnInvalidSession(ctx, 10*time.Millisecond, func(ctx context.Context) error {
+		calls <- struct{}{}
+		return nil
+	})
+	cancel()
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	count := len(calls)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, count, len(calls))
+}
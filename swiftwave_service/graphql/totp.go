@@ -0,0 +1,59 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/xlzd/gotp"
+)
+
+// enableTotp : verify the totp code and enable totp for the logged-in user
+// Recovery codes are generated in the same transaction, and returned to be shown to the user once
+func (r *mutationResolver) enableTotp(ctx context.Context, totp string) ([]string, error) {
+	// a leaked token should not be able to take over the account
+	if isAuthenticatedByPersonalAccessToken(ctx) {
+		return nil, errLoginSessionRequired
+	}
+	username := ctx.Value("username").(string)
+	user, err := core.FindUserByUsername(ctx, r.ServiceManager.DbClient, username)
+	if err != nil {
+		return nil, err
+	}
+	// check if secret generated
+	if strings.Compare(user.TotpSecret, "") == 0 {
+		return nil, errors.New("raise totp enable request first")
+	}
+	// check if totp is enabled
+	if user.TotpEnabled {
+		return nil, errors.New("totp is already enabled")
+	}
+	// check if too many failed attempts
+	ip := contextString(ctx, "ip")
+	err = core.CheckAuthThrottle(ctx, r.ServiceManager.DbClient, ip, username)
+	if err != nil {
+		return nil, err
+	}
+	// verify totp code
+	totpRecord := gotp.NewDefaultTOTP(user.TotpSecret)
+	if !totpRecord.Verify(totp, time.Now().Unix()) {
+		err = core.RecordFailedAuthAttempt(ctx, r.ServiceManager.DbClient, ip, username)
+		if err != nil {
+			log.Println("failed to record failed totp attempt of " + username + " > " + err.Error())
+		}
+		return nil, errors.New("invalid totp code")
+	}
+	err = core.ResetAuthThrottle(ctx, r.ServiceManager.DbClient, username)
+	if err != nil {
+		log.Println("failed to reset failed totp attempts of " + username + " > " + err.Error())
+	}
+	// enable totp along with the recovery codes
+	recoveryCodes, err := core.EnableTotp(ctx, r.ServiceManager.DbClient, user.ID)
+	if err != nil {
+		return nil, errors.New("failed to enable totp")
+	}
+	return recoveryCodes, nil
+}
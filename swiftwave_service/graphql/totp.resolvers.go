@@ -7,8 +7,6 @@ package graphql
 import (
 	"context"
 	"errors"
-	"strings"
-	"time"
 
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
@@ -17,6 +15,10 @@ import (
 
 // RequestTotpEnable is the resolver for the requestTotpEnable field.
 func (r *mutationResolver) RequestTotpEnable(ctx context.Context) (*model.RequestTotpEnable, error) {
+	// a leaked token should not be able to take over the account
+	if isAuthenticatedByPersonalAccessToken(ctx) {
+		return nil, errLoginSessionRequired
+	}
 	username := ctx.Value("username").(string)
 	user, err := core.FindUserByUsername(ctx, r.ServiceManager.DbClient, username)
 	if err != nil {
@@ -43,38 +45,47 @@ func (r *mutationResolver) RequestTotpEnable(ctx context.Context) (*model.Reques
 
 // EnableTotp is the resolver for the enableTotp field.
 func (r *mutationResolver) EnableTotp(ctx context.Context, totp string) (bool, error) {
-	username := ctx.Value("username").(string)
-	user, err := core.FindUserByUsername(ctx, r.ServiceManager.DbClient, username)
-	if err != nil {
-		return false, err
-	}
-	// check if secret generated
-	if strings.Compare(user.TotpSecret, "") == 0 {
-		return false, errors.New("raise totp enable request first")
-	}
-	// check if totp is enabled
-	if user.TotpEnabled {
-		return false, errors.New("totp is already enabled")
-	}
-	// verify totp code
-	totpRecord := gotp.NewDefaultTOTP(user.TotpSecret)
-	if !totpRecord.Verify(totp, time.Now().Unix()) {
-		return false, errors.New("invalid totp code")
-	}
-	// enable totp
-	user.TotpEnabled = true
-	err = r.ServiceManager.DbClient.Save(&user).Error
+	_, err := r.enableTotp(ctx, totp)
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
+// EnableTotpWithRecoveryCodes is the resolver for the enableTotpWithRecoveryCodes field.
+func (r *mutationResolver) EnableTotpWithRecoveryCodes(ctx context.Context, totp string) ([]string, error) {
+	return r.enableTotp(ctx, totp)
+}
+
 // DisableTotp is the resolver for the disableTotp field.
 func (r *mutationResolver) DisableTotp(ctx context.Context) (bool, error) {
+	// a leaked token should not be able to take over the account
+	if isAuthenticatedByPersonalAccessToken(ctx) {
+		return false, errLoginSessionRequired
+	}
 	err := core.DisableTotp(ctx, r.ServiceManager.DbClient, ctx.Value("username").(string))
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
+
+// GenerateTotpRecoveryCodes is the resolver for the generateTotpRecoveryCodes field.
+func (r *mutationResolver) GenerateTotpRecoveryCodes(ctx context.Context) ([]string, error) {
+	// a leaked token should not be able to take over the account
+	if isAuthenticatedByPersonalAccessToken(ctx) {
+		return nil, errLoginSessionRequired
+	}
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TotpEnabled {
+		return nil, errors.New("totp is not enabled")
+	}
+	recoveryCodes, err := core.GenerateTotpRecoveryCodes(ctx, r.ServiceManager.DbClient, user.ID)
+	if err != nil {
+		return nil, errors.New("failed to generate recovery codes")
+	}
+	return recoveryCodes, nil
+}
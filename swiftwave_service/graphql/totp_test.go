@@ -0,0 +1,78 @@
+package graphql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/xlzd/gotp"
+)
+
+func TestEnableTotpAndGenerateRecoveryCodes(t *testing.T) {
+	r := newTestResolver(t)
+	user, ctx := createTestUser(t, r, "admin", core.AdministratorRole)
+	secret := gotp.RandomSecret(16)
+	assert.NoError(t, r.ServiceManager.DbClient.Model(user).Update("totp_secret", secret).Error)
+
+	// recovery codes can't be generated before enabling totp
+	_, err := r.Mutation().GenerateTotpRecoveryCodes(ctx)
+	assert.Error(t, err)
+
+	enabled, err := r.Mutation().EnableTotp(ctx, "000000")
+	assert.Error(t, err)
+	assert.False(t, enabled)
+
+	enabled, err = r.Mutation().EnableTotp(ctx, gotp.NewDefaultTOTP(secret).At(time.Now().Unix()))
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	// totp is never enabled without recovery codes
+	count, err := core.CountUnusedTotpRecoveryCodes(ctx, r.ServiceManager.DbClient, user.ID)
+	assert.NoError(t, err)
+	assert.NotZero(t, count)
+
+	codes, err := r.Mutation().GenerateTotpRecoveryCodes(ctx)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, codes)
+	used, err := core.UseTotpRecoveryCode(ctx, r.ServiceManager.DbClient, user.ID, codes[0])
+	assert.NoError(t, err)
+	assert.True(t, used)
+
+	// new codes invalidate the previous ones
+	newCodes, err := r.Mutation().GenerateTotpRecoveryCodes(ctx)
+	assert.NoError(t, err)
+	used, err = core.UseTotpRecoveryCode(ctx, r.ServiceManager.DbClient, user.ID, codes[1])
+	assert.NoError(t, err)
+	assert.False(t, used)
+	used, err = core.UseTotpRecoveryCode(ctx, r.ServiceManager.DbClient, user.ID, newCodes[1])
+	assert.NoError(t, err)
+	assert.True(t, used)
+}
+
+func TestEnableTotpWithRecoveryCodes(t *testing.T) {
+	r := newTestResolver(t)
+	user, ctx := createTestUser(t, r, "admin", core.AdministratorRole)
+	secret := gotp.RandomSecret(16)
+	assert.NoError(t, r.ServiceManager.DbClient.Model(user).Update("totp_secret", secret).Error)
+
+	codes, err := r.Mutation().EnableTotpWithRecoveryCodes(ctx, "000000")
+	assert.Error(t, err)
+	assert.Empty(t, codes)
+	count, err := core.CountUnusedTotpRecoveryCodes(ctx, r.ServiceManager.DbClient, user.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	codes, err = r.Mutation().EnableTotpWithRecoveryCodes(ctx, gotp.NewDefaultTOTP(secret).At(time.Now().Unix()))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, codes)
+	updatedUser, err := core.FindUserByID(ctx, r.ServiceManager.DbClient, user.ID)
+	assert.NoError(t, err)
+	assert.True(t, updatedUser.TotpEnabled)
+	used, err := core.UseTotpRecoveryCode(ctx, r.ServiceManager.DbClient, user.ID, codes[0])
+	assert.NoError(t, err)
+	assert.True(t, used)
+
+	// can't be enabled twice
+	_, err = r.Mutation().EnableTotpWithRecoveryCodes(ctx, gotp.NewDefaultTOTP(secret).At(time.Now().Unix()))
+	assert.Error(t, err)
+}
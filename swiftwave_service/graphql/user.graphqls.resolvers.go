@@ -28,6 +28,10 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input *model.UserInpu
 	}
 	user := core.User{
 		Username: input.Username,
+		Role:     core.ManagerRole,
+	}
+	if input.Role != nil {
+		user.Role = core.UserRole(*input.Role)
 	}
 	err = user.SetPassword(input.Password)
 	if err != nil {
@@ -61,8 +65,57 @@ func (r *mutationResolver) DeleteUser(ctx context.Context, id uint) (bool, error
 	return true, nil
 }
 
+// UpdateUserRole is the resolver for the updateUserRole field.
+func (r *mutationResolver) UpdateUserRole(ctx context.Context, id uint, role model.UserRole) (bool, error) {
+	// Fetch user
+	user, err := core.FindUserByID(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, errors.New("user not found")
+	}
+	// Check if user is not current user, otherwise admin can lock themselves out
+	currentUsername := ctx.Value("username").(string)
+	if user.Username == currentUsername {
+		return false, errors.New("cannot change role of current user")
+	}
+	// Update role
+	err = core.UpdateUserRole(ctx, r.ServiceManager.DbClient, id, core.UserRole(role))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LinkUserToSSOIdentity is the resolver for the linkUserToSSOIdentity field.
+func (r *mutationResolver) LinkUserToSSOIdentity(ctx context.Context, id uint, subject string) (bool, error) {
+	oidcConfig := r.Config.SystemConfig.OIDCConfig
+	if !oidcConfig.Enabled {
+		return false, errors.New("oidc login is not enabled")
+	}
+	if subject == "" {
+		return false, errors.New("subject cannot be empty")
+	}
+	err := core.LinkUserToSSOIdentity(ctx, r.ServiceManager.DbClient, id, oidcConfig.IssuerURL, subject)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnlinkUserFromSSOIdentity is the resolver for the unlinkUserFromSSOIdentity field.
+func (r *mutationResolver) UnlinkUserFromSSOIdentity(ctx context.Context, id uint) (bool, error) {
+	err := core.UnlinkUserFromSSOIdentity(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // ChangePassword is the resolver for the changePassword field.
 func (r *mutationResolver) ChangePassword(ctx context.Context, input *model.PasswordUpdateInput) (bool, error) {
+	// a leaked token should not be able to take over the account
+	if isAuthenticatedByPersonalAccessToken(ctx) {
+		return false, errLoginSessionRequired
+	}
 	// Validate input
 	if input.OldPassword == "" {
 		return false, errors.New("old password cannot be empty")
@@ -110,3 +163,21 @@ func (r *queryResolver) CurrentUser(ctx context.Context) (*model.User, error) {
 	}
 	return userToGraphqlObject(&user), nil
 }
+
+// ApplicationGroupPermissions is the resolver for the applicationGroupPermissions field.
+func (r *userResolver) ApplicationGroupPermissions(ctx context.Context, obj *model.User) ([]*model.ApplicationGroupPermission, error) {
+	records, err := core.FindApplicationGroupPermissionsByUserID(ctx, r.ServiceManager.DbClient, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*model.ApplicationGroupPermission, 0)
+	for _, record := range records {
+		result = append(result, applicationGroupPermissionToGraphqlObject(record))
+	}
+	return result, nil
+}
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type userResolver struct{ *Resolver }
@@ -0,0 +1,92 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.48
+
+import (
+	"context"
+	"errors"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+// RevokeSession is the resolver for the revokeSession field.
+func (r *mutationResolver) RevokeSession(ctx context.Context, id string) (bool, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, err
+	}
+	var record = &core.UserSession{}
+	err = record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		// Don't return error if record not found -- assume it's already revoked
+		return true, nil
+	}
+	// user can revoke only their own sessions
+	if record.UserID != user.ID {
+		return false, core.ErrUserSessionNotFound
+	}
+	err = record.Revoke(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, errors.New("failed to revoke session")
+	}
+	return true, nil
+}
+
+// LogoutAllSessions is the resolver for the logoutAllSessions field.
+func (r *mutationResolver) LogoutAllSessions(ctx context.Context) (bool, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, err
+	}
+	err = core.RevokeUserSessions(ctx, r.ServiceManager.DbClient, user.ID)
+	if err != nil {
+		return false, errors.New("failed to revoke sessions")
+	}
+	return true, nil
+}
+
+// RevokeUserSessions is the resolver for the revokeUserSessions field.
+func (r *mutationResolver) RevokeUserSessions(ctx context.Context, userID uint) (bool, error) {
+	_, err := core.FindUserByID(ctx, r.ServiceManager.DbClient, userID)
+	if err != nil {
+		return false, errors.New("user not found")
+	}
+	err = core.RevokeUserSessions(ctx, r.ServiceManager.DbClient, userID)
+	if err != nil {
+		return false, errors.New("failed to revoke sessions")
+	}
+	return true, nil
+}
+
+// Sessions is the resolver for the sessions field.
+func (r *queryResolver) Sessions(ctx context.Context) ([]*model.UserSession, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	records, err := core.FindActiveUserSessionsByUserID(ctx, r.ServiceManager.DbClient, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*model.UserSession, 0)
+	for _, record := range records {
+		result = append(result, userSessionToGraphqlObject(record, contextString(ctx, "session_id")))
+	}
+	return result, nil
+}
+
+// UserSessions is the resolver for the userSessions field.
+func (r *queryResolver) UserSessions(ctx context.Context, userID uint) ([]*model.UserSession, error) {
+	records, err := core.FindActiveUserSessionsByUserID(ctx, r.ServiceManager.DbClient, userID)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*model.UserSession, 0)
+	for _, record := range records {
+		result = append(result, userSessionToGraphqlObject(record, contextString(ctx, "session_id")))
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.48
+
+import (
+	"context"
+	"errors"
+
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/graphql/model"
+)
+
+// RemoveWebAuthnCredential is the resolver for the removeWebAuthnCredential field.
+func (r *mutationResolver) RemoveWebAuthnCredential(ctx context.Context, id uint) (bool, error) {
+	// a leaked token should not be able to take over the account
+	if isAuthenticatedByPersonalAccessToken(ctx) {
+		return false, errLoginSessionRequired
+	}
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, err
+	}
+	var record = &core.WebAuthnCredential{}
+	err = record.FindById(ctx, r.ServiceManager.DbClient, id)
+	if err != nil {
+		// Don't return error if record not found -- assume it's already removed
+		return true, nil
+	}
+	// user can remove only their own authenticators
+	if record.UserID != user.ID {
+		return false, errors.New("authenticator not found")
+	}
+	err = record.Delete(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return false, errors.New("failed to remove authenticator")
+	}
+	return true, nil
+}
+
+// WebAuthnCredentials is the resolver for the webAuthnCredentials field.
+func (r *queryResolver) WebAuthnCredentials(ctx context.Context) ([]*model.WebAuthnCredential, error) {
+	user, err := currentUser(ctx, r.ServiceManager.DbClient)
+	if err != nil {
+		return nil, err
+	}
+	records, err := core.FindWebAuthnCredentialsByUserID(ctx, r.ServiceManager.DbClient, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*model.WebAuthnCredential, 0)
+	for _, record := range records {
+		result = append(result, webAuthnCredentialToGraphqlObject(record))
+	}
+	return result, nil
+}
@@ -85,6 +85,9 @@ func StartServer(config *config.Config, manager *service_manager.ServiceManager,
 	// Create Echo Server
 	echoServer := echo.New()
 	echoServer.HideBanner = true
+	// Use the remote address of the connection as client ip
+	// X-Forwarded-For and X-Real-IP headers can be set by the client, so those can't be trusted for audit logs and login throttling
+	echoServer.IPExtractor = echo.ExtractIPDirect()
 	echoServer.Pre(middleware.RemoveTrailingSlash())
 	echoServer.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
 		Skipper:             middleware.DefaultSkipper,
@@ -121,6 +124,16 @@ func StartServer(config *config.Config, manager *service_manager.ServiceManager,
 		}
 	})
 
+	// Add `ip` key to the context, used in audit logs
+	echoServer.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			//nolint:staticcheck
+			ctx := context.WithValue(c.Request().Context(), "ip", c.RealIP())
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+
 	// Internal Service Authentication Middleware
 	// Authorization : analytics_token <analytics_id>:<analytics_token>
 	// Only for /service/analytics endpoints
@@ -156,9 +169,56 @@ func StartServer(config *config.Config, manager *service_manager.ServiceManager,
 		}
 	})
 
+	// Personal Access Token Middleware
+	// Authorization : Bearer swp_<token>
+	echoServer.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if !core.IsPersonalAccessToken(token) {
+				return next(c)
+			}
+			accessToken, user, err := core.ValidatePersonalAccessToken(c.Request().Context(), manager.DbClient, token)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"message": err.Error(),
+				})
+			}
+			// GET requests need read scope, rest of the requests need write scope
+			// GraphQL operations are verified by the graphql server itself
+			if !strings.HasPrefix(c.Request().URL.Path, "/graphql") {
+				requiredScope := core.PersonalAccessTokenScopeWrite
+				if strings.Compare(c.Request().Method, http.MethodGet) == 0 {
+					requiredScope = core.PersonalAccessTokenScopeRead
+				}
+				if !accessToken.HasScope(requiredScope) {
+					return c.JSON(http.StatusForbidden, map[string]interface{}{
+						"message": "unauthorized: personal access token doesn't have " + string(requiredScope) + " scope",
+					})
+				}
+			}
+			c.Set("authorized", true)
+			c.Set("username", user.Username)
+			c.Set("hostname", "")
+			c.Set("personal_access_token", accessToken)
+			ctx := c.Request().Context()
+			//nolint:staticcheck
+			ctx = context.WithValue(ctx, "authorized", true)
+			//nolint:staticcheck
+			ctx = context.WithValue(ctx, "username", user.Username)
+			//nolint:staticcheck
+			ctx = context.WithValue(ctx, "personal_access_token", accessToken)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+
 	// JWT Middleware
 	echoServer.Use(echojwt.WithConfig(echojwt.Config{
 		Skipper: func(c echo.Context) bool {
+			// check if request is already authorized by personal access token
+			if c.Get("personal_access_token") != nil {
+				return true
+			}
 			// check if request is already authorized
 			if strings.HasPrefix(c.Request().URL.Path, "/service/analytics") &&
 				c.Get("authorized") != nil && c.Get("hostname") != nil {
@@ -213,6 +273,9 @@ func StartServer(config *config.Config, manager *service_manager.ServiceManager,
 	echoServer.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// ignore if already authorized
+			if c.Get("personal_access_token") != nil {
+				return next(c)
+			}
 			if c.Get("authorized") != nil && c.Get("hostname") != nil {
 				if c.Get("authorized").(bool) && strings.Compare(c.Get("hostname").(string), "") != 0 {
 					return next(c)
@@ -230,14 +293,25 @@ func StartServer(config *config.Config, manager *service_manager.ServiceManager,
 				ctx = context.WithValue(ctx, "username", "")
 			} else {
 				claims := token.Claims.(jwt.MapClaims)
-				username := claims["username"].(string)
+				username, _ := claims["username"].(string)
+				sessionID, _ := claims["sid"].(string)
+				// reject the token if the session has been revoked
+				err := core.ValidateUserSession(ctx, manager.DbClient, sessionID, username)
+				if err != nil {
+					return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+						"message": core.ErrUserSessionExpired.Error(),
+					})
+				}
 				c.Set("authorized", true)
 				c.Set("username", username)
 				c.Set("hostname", "")
+				c.Set("session_id", sessionID)
 				//nolint:staticcheck
 				ctx = context.WithValue(ctx, "authorized", true)
 				//nolint:staticcheck
 				ctx = context.WithValue(ctx, "username", username)
+				//nolint:staticcheck
+				ctx = context.WithValue(ctx, "session_id", sessionID)
 			}
 			c.SetRequest(c.Request().WithContext(ctx))
 			return next(c)
@@ -6,6 +6,7 @@ import (
 	"github.com/swiftwave-org/swiftwave/ssh_toolkit"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/config"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"net"
 )
 
 func DockerClient(ctx context.Context, server core.Server) (*containermanger.Manager, error) {
@@ -26,3 +27,22 @@ func DockerClient(ctx context.Context, server core.Server) (*containermanger.Man
 	}
 	return manager, nil
 }
+
+// DockerClientWithMultipleConnections creates a docker client which opens a new connection over SSH for every request
+// Required for building images on the server, as BuildKit session needs a separate connection
+func DockerClientWithMultipleConnections(ctx context.Context, server core.Server) (*containermanger.Manager, error) {
+	// Fetch config
+	c, err := config.Fetch()
+	if err != nil {
+		return nil, err
+	}
+	// Create Docker client
+	// Dialing is aborted if either the request or the client context is done, e.g. build is cancelled
+	return containermanger.NewWithDialer(ctx, func(dialCtx context.Context) (net.Conn, error) {
+		dialCtx, cancel := context.WithCancel(dialCtx)
+		defer cancel()
+		stop := context.AfterFunc(ctx, cancel)
+		defer stop()
+		return ssh_toolkit.NetConnOverSSHWithContext(dialCtx, "unix", server.DockerUnixSocketPath, 5, server.IP, server.SSHPort, server.User, c.SystemConfig.SshPrivateKey)
+	})
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"time"
+)
+
+// RecordAuditLog : middleware to record the audit log of the route
+// targetIDParam is the name of the route param holding the id of target resource, can be empty
+func RecordAuditLog(db gorm.DB, source core.AuditLogSource, action string, targetIDParam string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			arguments := make(map[string]string)
+			for _, name := range c.ParamNames() {
+				arguments[name] = c.Param(name)
+			}
+			username, _ := c.Get("username").(string)
+			auditLog := &core.AuditLog{
+				Username:  username,
+				IP:        c.RealIP(),
+				Source:    source,
+				Action:    action,
+				Arguments: core.SanitizeAuditLogArguments(arguments),
+				Outcome:   core.AuditLogOutcomeSuccess,
+			}
+			if targetIDParam != "" {
+				auditLog.TargetID = c.Param(targetIDParam)
+			}
+			if err != nil {
+				auditLog.Outcome = core.AuditLogOutcomeFailure
+				auditLog.Error = err.Error()
+			} else if c.Response().Status >= http.StatusBadRequest {
+				auditLog.Outcome = core.AuditLogOutcomeFailure
+				auditLog.Error = fmt.Sprintf("request failed with status %d", c.Response().Status)
+			}
+			if auditLogErr := auditLog.Create(context.Background(), db); auditLogErr != nil {
+				log.Println("failed to create audit log for " + action + " > " + auditLogErr.Error())
+			}
+			return err
+		}
+	}
+}
+
+// GET /audit-log/export
+// Query params: username, source, action, target_id, outcome, from, to [from, to in RFC3339 format]
+func (server *Server) exportAuditLogs(c echo.Context) error {
+	filter := &core.AuditLogFilter{
+		Username: c.QueryParam("username"),
+		Source:   core.AuditLogSource(c.QueryParam("source")),
+		Action:   c.QueryParam("action"),
+		TargetID: c.QueryParam("target_id"),
+		Outcome:  core.AuditLogOutcome(c.QueryParam("outcome")),
+	}
+	if from := c.QueryParam("from"); from != "" {
+		fromTime, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": "invalid from time, use RFC3339 format",
+			})
+		}
+		filter.From = &fromTime
+	}
+	if to := c.QueryParam("to"); to != "" {
+		toTime, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": "invalid to time, use RFC3339 format",
+			})
+		}
+		filter.To = &toTime
+	}
+	// stream the records as json lines
+	c.Response().Header().Set("Content-Type", "application/x-ndjson")
+	c.Response().Header().Set("Content-Disposition", "attachment; filename=audit_logs.jsonl")
+	c.Response().WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(c.Response())
+	err := core.ExportAuditLogs(c.Request().Context(), server.ServiceManager.DbClient, filter, func(auditLog *core.AuditLog) error {
+		return encoder.Encode(auditLog)
+	})
+	if err != nil {
+		log.Println("failed to export audit logs > " + err.Error())
+	}
+	c.Response().Flush()
+	return nil
+}
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+func TestRecordAuditLogIgnoresForwardedHeaders(t *testing.T) {
+	db := newTestDB(t, &core.AuditLog{})
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.POST("/volumes/:id/backup", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, RecordAuditLog(db, core.AuditLogSourceRest, "backupPersistentVolume", "id"))
+
+	req := httptest.NewRequest(http.MethodPost, "/volumes/12/backup", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set(echo.HeaderXForwardedFor, "10.0.0.1")
+	req.Header.Set(echo.HeaderXRealIP, "10.0.0.2")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var auditLog core.AuditLog
+	assert.NoError(t, db.First(&auditLog).Error)
+	assert.Equal(t, "203.0.113.7", auditLog.IP)
+	assert.Equal(t, "backupPersistentVolume", auditLog.Action)
+	assert.Equal(t, "12", auditLog.TargetID)
+	assert.Equal(t, core.AuditLogOutcomeSuccess, auditLog.Outcome)
+}
+
+func TestRecordAuditLogMarksFailedRequests(t *testing.T) {
+	db := newTestDB(t, &core.AuditLog{})
+	e := echo.New()
+	e.POST("/upload/code", func(c echo.Context) error {
+		return c.JSON(http.StatusForbidden, map[string]string{"message": "unauthorized"})
+	}, RecordAuditLog(db, core.AuditLogSourceRest, "uploadCode", ""))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload/code", nil))
+
+	var auditLog core.AuditLog
+	assert.NoError(t, db.First(&auditLog).Error)
+	assert.Equal(t, core.AuditLogOutcomeFailure, auditLog.Outcome)
+	assert.Contains(t, auditLog.Error, "403")
+}
@@ -1,6 +1,11 @@
 package rest
 
 import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,9 +21,15 @@ func (server *Server) login(c echo.Context) error {
 	password := c.FormValue("password")
 	totp := c.FormValue("totp")
 
+	// Check if too many failed attempts from the ip or for the user
+	if allowed, err := server.checkLoginThrottle(c, username); !allowed {
+		return err
+	}
+
 	// Check if user exists
 	user, err := core.FindUserByUsername(c.Request().Context(), server.ServiceManager.DbClient, username)
 	if err != nil {
+		server.recordFailedLogin(c, username, "user does not exist")
 		return c.JSON(400, &LoginResponse{
 			Message:      "user does not exist",
 			Token:        "",
@@ -26,38 +37,101 @@ func (server *Server) login(c echo.Context) error {
 		})
 	}
 
-	// check if totp is enabled
-	if user.TotpEnabled && strings.Compare(totp, "") == 0 {
+	// Check password
+	if !user.CheckPassword(password) {
+		server.recordFailedLogin(c, username, "invalid password")
 		return c.JSON(400, &LoginResponse{
-			Message:      "two factor authentication is enabled, but totp is not provided",
+			Message:      "invalid password",
 			Token:        "",
-			TotpRequired: true,
+			TotpRequired: false,
 		})
 	}
 
-	// Check password
-	if !user.CheckPassword(password) {
-		return c.JSON(400, &LoginResponse{
-			Message:      "invalid password",
+	// Second factors are disclosed only after the password is verified
+	webAuthnEnabled, err := core.HasWebAuthnCredentials(c.Request().Context(), server.ServiceManager.DbClient, user.ID)
+	if err != nil {
+		return c.JSON(500, &LoginResponse{
+			Message:      "failed to fetch registered authenticators",
 			Token:        "",
 			TotpRequired: false,
 		})
 	}
 
-	// Check totp
+	// check if second factor is required
+	if (user.TotpEnabled || webAuthnEnabled) && strings.Compare(totp, "") == 0 {
+		return c.JSON(400, &LoginResponse{
+			Message:          "two factor authentication is enabled, but totp or security key is not provided",
+			Token:            "",
+			TotpRequired:     user.TotpEnabled,
+			WebAuthnRequired: webAuthnEnabled,
+		})
+	}
+
+	// Check totp, or recovery code if totp doesn't match
 	if user.TotpEnabled {
 		totpRecord := gotp.NewDefaultTOTP(user.TotpSecret)
 		if !totpRecord.Verify(totp, time.Now().Unix()) {
-			return c.JSON(400, &LoginResponse{
-				Message:      "invalid totp",
-				Token:        "",
-				TotpRequired: false,
-			})
+			used, err := core.UseTotpRecoveryCode(c.Request().Context(), server.ServiceManager.DbClient, user.ID, totp)
+			if err != nil || !used {
+				server.recordFailedLogin(c, username, "invalid totp")
+				return c.JSON(400, &LoginResponse{
+					Message:      "invalid totp",
+					Token:        "",
+					TotpRequired: false,
+				})
+			}
 		}
+	} else if webAuthnEnabled {
+		// only security key is registered, that should be used through /auth/webauthn/login
+		return c.JSON(400, &LoginResponse{
+			Message:          "security key is required to login",
+			Token:            "",
+			TotpRequired:     false,
+			WebAuthnRequired: true,
+		})
+	}
+
+	return server.loginSucceeded(c, &user)
+}
+
+// checkLoginThrottle : respond with 429 if there are too many failed attempts from the ip or for the user
+// Returns false along with the response to be sent if the login attempt is not allowed
+func (server *Server) checkLoginThrottle(c echo.Context, username string) (bool, error) {
+	err := core.CheckAuthThrottle(c.Request().Context(), server.ServiceManager.DbClient, c.RealIP(), username)
+	if err == nil {
+		return true, nil
+	}
+	var throttleErr *core.AuthThrottleError
+	if errors.As(err, &throttleErr) {
+		server.recordLoginAuditLog(c, username, throttleErr)
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(throttleErr.RetryAfter.Seconds())+1))
+		return false, c.JSON(http.StatusTooManyRequests, &LoginResponse{
+			Message:      throttleErr.Error(),
+			Token:        "",
+			TotpRequired: false,
+		})
+	}
+	return false, c.JSON(500, &LoginResponse{
+		Message:      "failed to verify login attempt",
+		Token:        "",
+		TotpRequired: false,
+	})
+}
+
+// loginSucceeded : create a session, issue jwt and refresh token and reset the failed attempts of the user
+func (server *Server) loginSucceeded(c echo.Context, user *core.User) error {
+	// Create session
+	session, refreshToken, err := core.CreateUserSession(c.Request().Context(), server.ServiceManager.DbClient, user.ID, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		return c.JSON(500, &LoginResponse{
+			Message:      "failed to create session",
+			Token:        "",
+			TotpRequired: false,
+		})
 	}
 
 	// Generate jwt token
-	token, err := user.GenerateJWT(server.Config.SystemConfig.JWTSecretKey)
+	token, err := user.GenerateJWT(server.Config.SystemConfig.JWTSecretKey, session.ID)
 	if err != nil {
 		return c.JSON(500, &LoginResponse{
 			Message:      "failed to generate jwt token",
@@ -66,10 +140,95 @@ func (server *Server) login(c echo.Context) error {
 		})
 	}
 
+	// Reset failed attempts of the user
+	err = core.ResetAuthThrottle(c.Request().Context(), server.ServiceManager.DbClient, user.Username)
+	if err != nil {
+		log.Println("failed to reset failed login attempts of " + user.Username + " > " + err.Error())
+	}
+
 	// Return token
 	return c.JSON(200, &LoginResponse{
 		Message:      "success",
 		Token:        token,
+		RefreshToken: refreshToken,
 		TotpRequired: false,
 	})
 }
+
+// POST /auth/refresh
+// Exchange the refresh token for a new jwt token, the refresh token is rotated on every use
+func (server *Server) refreshToken(c echo.Context) error {
+	session, refreshToken, err := core.RefreshUserSession(c.Request().Context(), server.ServiceManager.DbClient, c.FormValue("refresh_token"), c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		if errors.Is(err, core.ErrUserSessionExpired) {
+			return c.JSON(http.StatusUnauthorized, &LoginResponse{
+				Message: err.Error(),
+			})
+		}
+		return c.JSON(500, &LoginResponse{
+			Message: "failed to refresh session",
+		})
+	}
+	user, err := core.FindUserByID(c.Request().Context(), server.ServiceManager.DbClient, session.UserID)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, &LoginResponse{
+			Message: core.ErrUserSessionExpired.Error(),
+		})
+	}
+	token, err := user.GenerateJWT(server.Config.SystemConfig.JWTSecretKey, session.ID)
+	if err != nil {
+		return c.JSON(500, &LoginResponse{
+			Message: "failed to generate jwt token",
+		})
+	}
+	return c.JSON(200, &LoginResponse{
+		Message:      "success",
+		Token:        token,
+		RefreshToken: refreshToken,
+	})
+}
+
+// POST /auth/logout
+// Revoke the session of the refresh token
+func (server *Server) logout(c echo.Context) error {
+	session, err := core.FindUserSessionByRefreshToken(c.Request().Context(), server.ServiceManager.DbClient, c.FormValue("refresh_token"))
+	if err != nil {
+		// Don't return error if session not found -- assume it's already revoked
+		return c.JSON(200, map[string]interface{}{
+			"message": "success",
+		})
+	}
+	err = session.Revoke(c.Request().Context(), server.ServiceManager.DbClient)
+	if err != nil {
+		return c.JSON(500, map[string]interface{}{
+			"message": "failed to revoke session",
+		})
+	}
+	return c.JSON(200, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// recordFailedLogin : count the failed attempt for throttling and keep it in audit log for review
+func (server *Server) recordFailedLogin(c echo.Context, username string, reason string) {
+	err := core.RecordFailedAuthAttempt(context.Background(), server.ServiceManager.DbClient, c.RealIP(), username)
+	if err != nil {
+		log.Println("failed to record failed login attempt of " + username + " > " + err.Error())
+	}
+	server.recordLoginAuditLog(c, username, errors.New(reason))
+}
+
+func (server *Server) recordLoginAuditLog(c echo.Context, username string, reason error) {
+	auditLog := &core.AuditLog{
+		Username:  username,
+		IP:        c.RealIP(),
+		Source:    core.AuditLogSourceRest,
+		Action:    "login",
+		Arguments: "{}",
+		Outcome:   core.AuditLogOutcomeFailure,
+		Error:     reason.Error(),
+	}
+	if err := auditLog.Create(context.Background(), server.ServiceManager.DbClient); err != nil {
+		log.Println("failed to create audit log for login > " + err.Error())
+	}
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/service_manager"
+)
+
+func TestLoginThrottleIgnoresForwardedHeaders(t *testing.T) {
+	db := newTestDB(t, &core.User{}, &core.AuthThrottle{}, &core.AuditLog{})
+	server := &Server{ServiceManager: &service_manager.ServiceManager{DbClient: db}}
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.POST("/auth/login", server.login)
+
+	login := func(username string, forwardedFor string) int {
+		form := url.Values{"username": {username}, "password": {"wrong"}}
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		req.RemoteAddr = "203.0.113.7:51234"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// attacker rotates the forwarded ip and the username, the connection ip is throttled anyway
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, http.StatusBadRequest, login("user"+strconv.Itoa(i), "10.0.0."+strconv.Itoa(i)))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login("admin", "10.0.0.100"))
+
+	var count int64
+	assert.NoError(t, db.Model(&core.AuthThrottle{}).Where("key LIKE ?", "ip:10.%").Count(&count).Error)
+	assert.Zero(t, count)
+}
@@ -1,5 +1,105 @@
 package rest
 
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"gorm.io/gorm"
+	"net/http"
+)
+
 // Migrate database table
 func (server *Server) MigrateDatabaseTables() {
 }
+
+// RequireRole : middleware to allow the route only if the logged-in user has the required role
+func RequireRole(db gorm.DB, role core.UserRole) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, err := loggedInUser(c, db)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"message": err.Error(),
+				})
+			}
+			if !user.HasRole(role) {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"message": fmt.Sprintf("unauthorized: %s role required to perform this action", role),
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireUnrestrictedAccess : middleware to reject the users limited to specific application groups
+func RequireUnrestrictedAccess(db gorm.DB) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, err := loggedInUser(c, db)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"message": err.Error(),
+				})
+			}
+			isRestricted, err := user.IsRestrictedToApplicationGroups(c.Request().Context(), db)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"message": "failed to verify permissions of user",
+				})
+			}
+			if isRestricted {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"message": "unauthorized: your access is limited to specific application groups",
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireApplicationAccess : middleware to allow the route only if the logged-in user has the required access on the application
+// applicationIDParam is the name of the route param holding the application id
+func RequireApplicationAccess(db gorm.DB, applicationIDParam string, access core.AccessLevel) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, err := loggedInUser(c, db)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"message": err.Error(),
+				})
+			}
+			allowed, err := user.CanAccessApplication(c.Request().Context(), db, c.Param(applicationIDParam), access)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return c.JSON(http.StatusNotFound, map[string]interface{}{
+						"message": "application not found",
+					})
+				}
+				return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"message": "failed to verify permissions of user",
+				})
+			}
+			if !allowed {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"message": fmt.Sprintf("unauthorized: %s access on the application is required", access),
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// loggedInUser : fetch the user set by the auth middleware
+func loggedInUser(c echo.Context, db gorm.DB) (*core.User, error) {
+	username, ok := c.Get("username").(string)
+	if !ok || username == "" {
+		return nil, errors.New("unauthorized: login required")
+	}
+	user, err := core.FindUserByUsername(c.Request().Context(), db, username)
+	if err != nil {
+		return nil, errors.New("unauthorized: user not found")
+	}
+	return &user, nil
+}
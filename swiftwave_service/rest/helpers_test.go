@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB : sqlite database with the tables of the provided models
+func newTestDB(t *testing.T, models ...interface{}) gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "swiftwave.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return *db
+}
+
+func TestRequireRole(t *testing.T) {
+	db := newTestDB(t, &core.User{})
+	for _, user := range []core.User{
+		{Username: "admin", Role: core.AdministratorRole},
+		{Username: "manager", Role: core.ManagerRole},
+		{Username: "viewer", Role: core.ViewerRole},
+	} {
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := echo.New()
+	e.GET("/protected", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		// stand-in for the auth middleware
+		return func(c echo.Context) error {
+			if username := c.Request().Header.Get("X-Username"); username != "" {
+				c.Set("username", username)
+			}
+			return next(c)
+		}
+	}, RequireRole(db, core.ManagerRole))
+
+	tests := []struct {
+		username string
+		status   int
+	}{
+		{"admin", http.StatusOK},
+		{"manager", http.StatusOK},
+		{"viewer", http.StatusForbidden},
+		{"ghost", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("X-Username", test.username)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, test.status, rec.Code, "username %q", test.username)
+	}
+}
@@ -0,0 +1,231 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	oidcmanager "github.com/swiftwave-org/swiftwave/oidc_manager"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+// oidcRequestCookieName : cookie to preserve state, nonce and pkce code verifier between login and callback
+const oidcRequestCookieName = "swiftwave_oidc_request"
+
+// oidcRequestLifetime : time available to the user to complete the login at the identity provider
+const oidcRequestLifetime = 10 * time.Minute
+
+// GET /auth/oidc
+func (server *Server) oidcStatus(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"enabled": server.Config.SystemConfig.OIDCConfig.Enabled,
+	})
+}
+
+// GET /auth/oidc/login?redirect=<path>
+// Redirect the user to the identity provider for login
+func (server *Server) oidcLogin(c echo.Context) error {
+	provider, err := server.oidcProvider(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	authRequest, err := oidcmanager.NewAuthRequest()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to generate oidc auth request",
+		})
+	}
+	// sign the auth request and keep it in cookie till callback
+	signedRequest, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"state":         authRequest.State,
+		"nonce":         authRequest.Nonce,
+		"code_verifier": authRequest.CodeVerifier,
+		"redirect":      sanitizeOIDCRedirect(c.QueryParam("redirect")),
+		"exp":           time.Now().Add(oidcRequestLifetime).Unix(),
+	}).SignedString([]byte(server.Config.SystemConfig.JWTSecretKey))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to generate oidc auth request",
+		})
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     oidcRequestCookieName,
+		Value:    signedRequest,
+		Path:     "/auth/oidc",
+		MaxAge:   int(oidcRequestLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	return c.Redirect(http.StatusFound, provider.AuthCodeURL(authRequest))
+}
+
+// GET /auth/oidc/callback?code=<code>&state=<state>
+// Verify the login, create the user if required and redirect to the dashboard with jwt token in url fragment
+func (server *Server) oidcCallback(c echo.Context) error {
+	// read and clear the auth request cookie
+	cookie, err := c.Cookie(oidcRequestCookieName)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "oidc login request not found or expired, please try again",
+		})
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     oidcRequestCookieName,
+		Value:    "",
+		Path:     "/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	requestClaims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(cookie.Value, requestClaims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(server.Config.SystemConfig.JWTSecretKey), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "oidc login request not found or expired, please try again",
+		})
+	}
+	state, _ := requestClaims["state"].(string)
+	nonce, _ := requestClaims["nonce"].(string)
+	codeVerifier, _ := requestClaims["code_verifier"].(string)
+	redirect, _ := requestClaims["redirect"].(string)
+	if state == "" || c.QueryParam("state") != state {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "oidc state mismatch",
+		})
+	}
+	// error returned by the identity provider
+	if c.QueryParam("error") != "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "oidc login failed > " + c.QueryParam("error") + " " + c.QueryParam("error_description"),
+		})
+	}
+	provider, err := server.oidcProvider(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	rawIDToken, err := provider.Exchange(c.Request().Context(), c.QueryParam("code"), codeVerifier)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	claims, err := provider.VerifyIDToken(c.Request().Context(), rawIDToken, nonce)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	username := oidcmanager.ClaimString(claims, server.Config.SystemConfig.OIDCConfig.UsernameClaim)
+	if username == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "id token doesn't have " + server.Config.SystemConfig.OIDCConfig.UsernameClaim + " claim",
+		})
+	}
+	// username can be changed at the identity provider, so the user is identified by issuer and subject
+	issuer := oidcmanager.ClaimString(claims, "iss")
+	subject := oidcmanager.ClaimString(claims, "sub")
+	if issuer == "" || subject == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "id token doesn't have iss or sub claim",
+		})
+	}
+	role, err := server.oidcUserRole(claims)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	// create the user on first login
+	user, err := core.FindOrCreateSSOUser(c.Request().Context(), server.ServiceManager.DbClient, issuer, subject, username, role)
+	if err != nil {
+		if errors.Is(err, core.ErrSSOIdentityNotLinked) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to create user",
+		})
+	}
+	session, refreshToken, err := core.CreateUserSession(c.Request().Context(), server.ServiceManager.DbClient, user.ID, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to create session",
+		})
+	}
+	token, err := user.GenerateJWT(server.Config.SystemConfig.JWTSecretKey, session.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to generate jwt token",
+		})
+	}
+	// url fragment is not sent to the server, so the tokens will not be logged anywhere
+	return c.Redirect(http.StatusFound, sanitizeOIDCRedirect(redirect)+"#token="+url.QueryEscape(token)+"&refresh_token="+url.QueryEscape(refreshToken))
+}
+
+// oidcProvider : create the oidc provider from the system config
+func (server *Server) oidcProvider(c echo.Context) (*oidcmanager.Provider, error) {
+	oidcConfig := server.Config.SystemConfig.OIDCConfig
+	if !oidcConfig.Enabled {
+		return nil, errors.New("oidc login is not enabled")
+	}
+	provider, err := oidcmanager.NewProvider(c.Request().Context(), oidcmanager.Options{
+		IssuerURL:    oidcConfig.IssuerURL,
+		ClientID:     oidcConfig.ClientID,
+		ClientSecret: oidcConfig.ClientSecret,
+		RedirectURL:  oidcConfig.RedirectURL,
+		Scopes:       strings.Fields(oidcConfig.Scopes),
+	})
+	if err != nil {
+		return nil, errors.New("failed to connect to oidc provider > " + err.Error())
+	}
+	return provider, nil
+}
+
+// oidcUserRole : map the role claim of the id token to user role
+// If multiple values match, the most privileged role is assigned
+func (server *Server) oidcUserRole(claims jwt.MapClaims) (core.UserRole, error) {
+	oidcConfig := server.Config.SystemConfig.OIDCConfig
+	roleMapping, err := oidcConfig.ParseRoleMapping()
+	if err != nil {
+		return "", err
+	}
+	var role core.UserRole
+	for _, value := range oidcmanager.ClaimValues(claims, oidcConfig.RoleClaim) {
+		mappedRole, ok := roleMapping[value]
+		if !ok {
+			continue
+		}
+		if core.UserRole(mappedRole).IsMorePrivilegedThan(role) {
+			role = core.UserRole(mappedRole)
+		}
+	}
+	if role == "" {
+		role = core.UserRole(oidcConfig.DefaultRole)
+	}
+	if role == "" {
+		return "", errors.New("unauthorized: no role is mapped for the user")
+	}
+	if !role.IsValid() {
+		return "", errors.New("invalid role in oidc role mapping > " + string(role))
+	}
+	return role, nil
+}
+
+// sanitizeOIDCRedirect : allow only relative path as redirect to avoid open redirect
+func sanitizeOIDCRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, "\\") {
+		return "/"
+	}
+	return strings.SplitN(redirect, "#", 2)[0]
+}
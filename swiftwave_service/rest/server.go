@@ -3,6 +3,7 @@ package rest
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/config/system_config/bootstrap"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
 )
 
 // Initialize : Initialize the server and its routes
@@ -14,26 +15,41 @@ func (server *Server) Initialize() {
 }
 
 func (server *Server) initiateProjectRoutes(e *echo.Echo) {
+	adminOnly := RequireRole(server.ServiceManager.DbClient, core.AdministratorRole)
+	managerOnly := RequireRole(server.ServiceManager.DbClient, core.ManagerRole)
+	unrestrictedOnly := RequireUnrestrictedAccess(server.ServiceManager.DbClient)
 	// Initiating Routes for Healthcheck
 	e.GET("/healthcheck", server.healthcheck)
 	// Initiating Routes for Version
 	e.GET("/version", server.version)
 	// Initiating Routes for Auth
 	e.POST("/auth/login", server.login)
+	e.POST("/auth/refresh", server.refreshToken)
+	e.POST("/auth/logout", server.logout)
+	e.GET("/auth/oidc", server.oidcStatus)
+	e.GET("/auth/oidc/login", server.oidcLogin)
+	e.GET("/auth/oidc/callback", server.oidcCallback)
+	e.POST("/auth/webauthn/login/begin", server.webAuthnLoginBegin)
+	e.POST("/auth/webauthn/login/finish", server.webAuthnLoginFinish)
 	e.GET("/verify-auth", server.verifyAuth)
+	// Initiating Routes for WebAuthn authenticator registration
+	e.POST("/webauthn/register/begin", server.webAuthnRegisterBegin)
+	e.POST("/webauthn/register/finish", server.webAuthnRegisterFinish, RecordAuditLog(server.ServiceManager.DbClient, core.AuditLogSourceRest, "registerWebAuthnCredential", ""))
 	// Initiating Routes for Project
-	e.POST("/upload/code", server.uploadTarFile)
+	e.POST("/upload/code", server.uploadTarFile, RecordAuditLog(server.ServiceManager.DbClient, core.AuditLogSourceRest, "uploadCode", ""), managerOnly)
 	// Initiating Routes for PersistentVolume
-	e.GET("/persistent-volume/backup/:id/download", server.downloadPersistentVolumeBackup)
-	e.GET("/persistent-volume/backup/:id/filename", server.getPersistentVolumeBackupFileName)
-	e.POST("/persistent-volume/:id/restore", server.uploadPersistentVolumeRestoreFile)
+	e.GET("/persistent-volume/backup/:id/download", server.downloadPersistentVolumeBackup, managerOnly, unrestrictedOnly)
+	e.GET("/persistent-volume/backup/:id/filename", server.getPersistentVolumeBackupFileName, managerOnly, unrestrictedOnly)
+	e.POST("/persistent-volume/:id/restore", server.uploadPersistentVolumeRestoreFile, RecordAuditLog(server.ServiceManager.DbClient, core.AuditLogSourceRest, "restorePersistentVolume", "id"), managerOnly, unrestrictedOnly)
 	// Initiating Routes for Webhook
-	e.Any("/webhook/redeploy-app/:app-id/:webhook-token", server.redeployApp)
+	e.Any("/webhook/redeploy-app/:app-id/:webhook-token", server.redeployApp, RecordAuditLog(server.ServiceManager.DbClient, core.AuditLogSourceRest, "redeployApplicationByWebhook", "app-id"))
 	// Initiating Routes for fetch and update system config
-	e.GET("/config/system", bootstrap.FetchSystemConfigHandler)
-	e.PUT("/config/system", bootstrap.UpdateSystemConfigHandler)
+	e.GET("/config/system", bootstrap.FetchSystemConfigHandler, adminOnly)
+	e.PUT("/config/system", bootstrap.UpdateSystemConfigHandler, RecordAuditLog(server.ServiceManager.DbClient, core.AuditLogSourceRest, "updateSystemConfig", ""), adminOnly)
 	// analytics
 	e.POST("/service/analytics", server.analytics)
 	// serve log file
-	e.GET("/log/:log_file_name", server.fetchLog)
+	e.GET("/log/:log_file_name", server.fetchLog, adminOnly)
+	// export audit logs
+	e.GET("/audit-log/export", server.exportAuditLogs, adminOnly)
 }
@@ -9,9 +9,11 @@ import (
 
 // LoginResponse : struct to hold login response
 type LoginResponse struct {
-	Message      string `json:"message"`
-	Token        string `json:"token"`
-	TotpRequired bool   `json:"totp_required"`
+	Message          string `json:"message"`
+	Token            string `json:"token"`
+	RefreshToken     string `json:"refresh_token"`
+	TotpRequired     bool   `json:"totp_required"`
+	WebAuthnRequired bool   `json:"webauthn_required"`
 }
 
 // Server : hold references to other components of service
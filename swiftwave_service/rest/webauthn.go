@@ -0,0 +1,320 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+const (
+	// webAuthnRegistrationCookieName : cookie to preserve the session data between registration begin and finish
+	webAuthnRegistrationCookieName = "swiftwave_webauthn_registration"
+	// webAuthnLoginCookieName : cookie to preserve the session data between login begin and finish
+	webAuthnLoginCookieName = "swiftwave_webauthn_login"
+	// webAuthnSessionLifetime : time available to the user to complete the ceremony with authenticator
+	webAuthnSessionLifetime = 5 * time.Minute
+)
+
+// POST /webauthn/register/begin
+// Start registration of a new authenticator for the logged-in user
+func (server *Server) webAuthnRegisterBegin(c echo.Context) error {
+	if c.Get("personal_access_token") != nil {
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"message": "authenticator can't be registered with personal access token",
+		})
+	}
+	user, err := loggedInUser(c, server.ServiceManager.DbClient)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	webAuthn, err := server.webAuthn()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	webAuthnUser, err := core.NewWebAuthnUser(c.Request().Context(), server.ServiceManager.DbClient, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to fetch registered authenticators",
+		})
+	}
+	// don't allow to register the same authenticator twice
+	exclusions := make([]protocol.CredentialDescriptor, 0)
+	for _, credential := range webAuthnUser.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+	options, session, err := webAuthn.BeginRegistration(webAuthnUser, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to start authenticator registration",
+		})
+	}
+	err = server.setWebAuthnSessionCookie(c, webAuthnRegistrationCookieName, "/webauthn", user.ID, session)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to start authenticator registration",
+		})
+	}
+	return c.JSON(http.StatusOK, options)
+}
+
+// POST /webauthn/register/finish?name=<name of authenticator>
+// Body should be the response of navigator.credentials.create()
+func (server *Server) webAuthnRegisterFinish(c echo.Context) error {
+	user, err := loggedInUser(c, server.ServiceManager.DbClient)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	userID, session, err := server.webAuthnSessionFromCookie(c, webAuthnRegistrationCookieName, "/webauthn")
+	if err != nil || userID != user.ID {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "authenticator registration request not found or expired, please try again",
+		})
+	}
+	webAuthn, err := server.webAuthn()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	webAuthnUser, err := core.NewWebAuthnUser(c.Request().Context(), server.ServiceManager.DbClient, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to fetch registered authenticators",
+		})
+	}
+	credential, err := webAuthn.FinishRegistration(webAuthnUser, *session, c.Request())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "failed to verify authenticator > " + webAuthnErrorMessage(err),
+		})
+	}
+	name := c.QueryParam("name")
+	if name == "" {
+		name = "Authenticator " + time.Now().Format("2006-01-02")
+	}
+	record := core.NewWebAuthnCredential(user.ID, name, credential)
+	err = record.Create(c.Request().Context(), server.ServiceManager.DbClient)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "failed to save authenticator > " + err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":   record.ID,
+		"name": record.Name,
+	})
+}
+
+// POST /auth/webauthn/login/begin
+// Verify the username and password, and start the assertion with registered authenticators
+func (server *Server) webAuthnLoginBegin(c echo.Context) error {
+	username := c.FormValue("username")
+	password := c.FormValue("password")
+	if allowed, err := server.checkLoginThrottle(c, username); !allowed {
+		return err
+	}
+	user, err := core.FindUserByUsername(c.Request().Context(), server.ServiceManager.DbClient, username)
+	if err != nil {
+		server.recordFailedLogin(c, username, "user does not exist")
+		return c.JSON(http.StatusBadRequest, &LoginResponse{
+			Message: "user does not exist",
+		})
+	}
+	if !user.CheckPassword(password) {
+		server.recordFailedLogin(c, username, "invalid password")
+		return c.JSON(http.StatusBadRequest, &LoginResponse{
+			Message: "invalid password",
+		})
+	}
+	webAuthnUser, err := core.NewWebAuthnUser(c.Request().Context(), server.ServiceManager.DbClient, &user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &LoginResponse{
+			Message: "failed to fetch registered authenticators",
+		})
+	}
+	if len(webAuthnUser.Credentials) == 0 {
+		return c.JSON(http.StatusBadRequest, &LoginResponse{
+			Message: "no authenticator registered for the user",
+		})
+	}
+	webAuthn, err := server.webAuthn()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &LoginResponse{
+			Message: err.Error(),
+		})
+	}
+	options, session, err := webAuthn.BeginLogin(webAuthnUser)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &LoginResponse{
+			Message: "failed to start authenticator login",
+		})
+	}
+	err = server.setWebAuthnSessionCookie(c, webAuthnLoginCookieName, "/auth/webauthn", user.ID, session)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &LoginResponse{
+			Message: "failed to start authenticator login",
+		})
+	}
+	return c.JSON(http.StatusOK, options)
+}
+
+// POST /auth/webauthn/login/finish
+// Body should be the response of navigator.credentials.get()
+func (server *Server) webAuthnLoginFinish(c echo.Context) error {
+	userID, session, err := server.webAuthnSessionFromCookie(c, webAuthnLoginCookieName, "/auth/webauthn")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &LoginResponse{
+			Message: "authenticator login request not found or expired, please try again",
+		})
+	}
+	user, err := core.FindUserByID(c.Request().Context(), server.ServiceManager.DbClient, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &LoginResponse{
+			Message: "user does not exist",
+		})
+	}
+	if allowed, err := server.checkLoginThrottle(c, user.Username); !allowed {
+		return err
+	}
+	webAuthn, err := server.webAuthn()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &LoginResponse{
+			Message: err.Error(),
+		})
+	}
+	webAuthnUser, err := core.NewWebAuthnUser(c.Request().Context(), server.ServiceManager.DbClient, &user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &LoginResponse{
+			Message: "failed to fetch registered authenticators",
+		})
+	}
+	credential, err := webAuthn.FinishLogin(webAuthnUser, *session, c.Request())
+	if err != nil {
+		server.recordFailedLogin(c, user.Username, "invalid webauthn assertion > "+webAuthnErrorMessage(err))
+		return c.JSON(http.StatusBadRequest, &LoginResponse{
+			Message: "failed to verify authenticator",
+		})
+	}
+	// signature counter went backwards, the authenticator may be cloned
+	if credential.Authenticator.CloneWarning {
+		server.recordFailedLogin(c, user.Username, "webauthn authenticator may be cloned")
+		return c.JSON(http.StatusBadRequest, &LoginResponse{
+			Message: "authenticator may be cloned, login denied",
+		})
+	}
+	for _, record := range webAuthnUser.Credentials {
+		if string(record.CredentialID) == string(credential.ID) {
+			_ = record.MarkAsUsed(c.Request().Context(), server.ServiceManager.DbClient, credential.Authenticator.SignCount)
+			break
+		}
+	}
+	return server.loginSucceeded(c, &user)
+}
+
+// webAuthn : relying party is the management node, dashboard is served from the same origin
+func (server *Server) webAuthn() (*webauthn.WebAuthn, error) {
+	localConfig := server.Config.LocalConfig
+	rpID := localConfig.ManagementNodeAddressConsideringTunnelling()
+	port := localConfig.ManagementNodePortConsideringTunnelling()
+	scheme := "http"
+	if localConfig.ServiceConfig.UseTLS {
+		scheme = "https"
+	}
+	origin := fmt.Sprintf("%s://%s", scheme, rpID)
+	if !(scheme == "http" && port == 80) && !(scheme == "https" && port == 443) {
+		origin = fmt.Sprintf("%s:%d", origin, port)
+	}
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: "SwiftWave",
+		RPOrigins:     []string{origin},
+	})
+	if err != nil {
+		return nil, errors.New("failed to configure webauthn > " + err.Error())
+	}
+	return webAuthn, nil
+}
+
+// setWebAuthnSessionCookie : sign the session data and keep it in cookie till the ceremony finishes
+func (server *Server) setWebAuthnSessionCookie(c echo.Context, name string, path string, userID uint, session *webauthn.SessionData) error {
+	sessionData, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	signedSession, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": strconv.FormatUint(uint64(userID), 10),
+		"session": string(sessionData),
+		"exp":     time.Now().Add(webAuthnSessionLifetime).Unix(),
+	}).SignedString([]byte(server.Config.SystemConfig.JWTSecretKey))
+	if err != nil {
+		return err
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    signedSession,
+		Path:     path,
+		MaxAge:   int(webAuthnSessionLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+// webAuthnSessionFromCookie : read and clear the session data, so it can't be used twice
+func (server *Server) webAuthnSessionFromCookie(c echo.Context, name string, path string) (uint, *webauthn.SessionData, error) {
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return 0, nil, err
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     path,
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(cookie.Value, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(server.Config.SystemConfig.JWTSecretKey), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, nil, err
+	}
+	userIDClaim, _ := claims["user_id"].(string)
+	userID, err := strconv.ParseUint(userIDClaim, 10, 64)
+	if err != nil {
+		return 0, nil, errors.New("invalid user id in webauthn session")
+	}
+	sessionClaim, _ := claims["session"].(string)
+	session := &webauthn.SessionData{}
+	err = json.Unmarshal([]byte(sessionClaim), session)
+	if err != nil {
+		return 0, nil, errors.New("invalid webauthn session")
+	}
+	return uint(userID), session, nil
+}
+
+// webAuthnErrorMessage : protocol errors carry the useful information in details
+func webAuthnErrorMessage(err error) string {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) && protocolErr.Details != "" {
+		return protocolErr.Details
+	}
+	return err.Error()
+}
@@ -2,16 +2,19 @@ package rest
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"github.com/labstack/echo/v4"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/logger"
 	"gorm.io/gorm"
 	"io"
-	"net/url"
 	"strings"
 )
 
+// unsignedWebhookWarning : warning header sent back when an unsigned git webhook is accepted
+const unsignedWebhookWarning = `299 swiftwave "unsigned webhook accepted, configure the webhook secret in the git provider"`
+
 // ANY /webhook/redeploy-app/:app-id/:webhook-token
 func (server *Server) redeployApp(c echo.Context) error {
 	appId := c.Param("app-id")
@@ -32,7 +35,7 @@ func (server *Server) redeployApp(c echo.Context) error {
 		return c.String(500, "Error fetching app")
 	}
 	// Check if webhook token matches
-	if application.WebhookToken != webhookToken {
+	if subtle.ConstantTimeCompare([]byte(application.WebhookToken), []byte(webhookToken)) != 1 {
 		return c.String(401, "Unauthorized")
 	}
 	// Fetch latest deployment
@@ -50,48 +53,82 @@ func (server *Server) redeployApp(c echo.Context) error {
 	if err != nil {
 		return c.String(500, "Error reading request body")
 	}
-	bodyString := string(body)
-	// url decode body
-	bodyString, err = url.QueryUnescape(bodyString)
-	if err != nil {
-		logger.HTTPLoggerError.Println(err)
-	}
 
 	triggeredRebuild := false
+	commitHash := ""
+	tag := ""
 	// Check if latest deployment is git
 	if deployment.UpstreamType == core.UpstreamTypeGit {
-		searchText := "refs/heads/" + deployment.RepositoryBranch
-		repoName := deployment.RepositoryOwner + "/" + deployment.RepositoryName
-		if strings.Contains(bodyString, searchText) && strings.Contains(bodyString, repoName) {
-			triggeredRebuild = true
-		} else {
+		header := c.Request().Header
+		provider := detectGitWebhookProvider(header)
+		if provider == unknownGitWebhookProvider {
+			return c.String(400, "Unsupported git provider, supported providers are GitHub, GitLab, Gitea, Forgejo and Bitbucket")
+		}
+		if !verifyGitWebhook(provider, header, body, application.WebhookSecret) {
+			// unsigned webhooks are accepted only if the application has opted in, an invalid signature never
+			if !application.AllowUnsignedWebhooks || hasGitWebhookSignature(provider, header) {
+				return c.String(401, "Unauthorized - Invalid webhook signature")
+			}
+			logger.HTTPLogger.Println("Accepted unsigned webhook of application ", application.Name, ", configure the webhook secret in the git provider to enforce the signature")
+			c.Response().Header().Set("Warning", unsignedWebhookWarning)
+		}
+		pullRequestEvent, err := parseGitPullRequestEvent(provider, header, body)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+		if pullRequestEvent != nil {
+			return server.processPullRequestEvent(ctx, c, &application, deployment, pullRequestEvent)
+		}
+		event, err := parseGitPushEvent(provider, header, body)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+		if !event.IsPush {
+			return c.String(200, "OK - Not a push event")
+		}
+		if !event.matchesRepository(deployment.RepositoryOwner, deployment.RepositoryName) {
 			return c.String(200, "OK - No rebuild")
 		}
+		if deployment.GitReferenceType == core.GitReferenceCommit {
+			return c.String(200, "OK - Deployment is pinned to a commit, no rebuild")
+		}
+		if deployment.GitReferenceType == core.GitReferenceTag {
+			// deploy the newest pushed tag matching the tag pattern
+			tag = deployment.LatestAutoDeployTag(event.Tags)
+			if tag == "" {
+				return c.String(200, "OK - No rebuild")
+			}
+		} else {
+			change := event.branchChange(deployment.RepositoryBranch)
+			if change == nil {
+				return c.String(200, "OK - No rebuild")
+			}
+			if change.IsDeleted {
+				return c.String(200, "OK - Branch deleted, no rebuild")
+			}
+			commitHash = change.CommitHash
+		}
+		triggeredRebuild = true
 	}
 
 	// Check if latest deployment is image
 	if deployment.UpstreamType == core.UpstreamTypeImage {
-		// remove the tag from the image name
-		splits := strings.Split(deployment.DockerImage, ":")
-		if len(splits) == 0 {
-			return c.String(500, "Error parsing docker image name")
+		if strings.Contains(deployment.DockerImage, "@") {
+			return c.String(200, "OK - Deployment is pinned to a digest, no rebuild")
 		}
-		imageName := splits[0]
-		// remove the registry from the image name
-		splits = strings.Split(imageName, "/")
-		if len(splits) == 0 {
-			return c.String(500, "Error parsing docker image name")
+		event, err := parseImagePushEvent(body)
+		if err != nil {
+			return c.String(400, err.Error())
 		}
-		if len(splits) == 1 {
-			imageName = splits[0]
-		} else {
-			imageName = splits[len(splits)-2] + "/" + splits[len(splits)-1]
+		isMatched, err := event.matchesImage(deployment.DockerImage)
+		if err != nil {
+			logger.HTTPLoggerError.Println(err)
+			return c.String(500, "Error parsing docker image name")
 		}
-		if strings.Contains(bodyString, imageName) {
-			triggeredRebuild = true
-		} else {
+		if !isMatched {
 			return c.String(200, "OK - No rebuild")
 		}
+		triggeredRebuild = true
 	}
 
 	if triggeredRebuild {
@@ -100,7 +137,12 @@ func (server *Server) redeployApp(c echo.Context) error {
 			ID: application.ID,
 		}
 		tx := server.ServiceManager.DbClient.Begin()
-		deploymentId, err := record.RebuildApplication(ctx, *tx)
+		var deploymentId string
+		if tag != "" {
+			deploymentId, err = record.RebuildApplicationFromTag(ctx, *tx, tag)
+		} else {
+			deploymentId, err = record.RebuildApplication(ctx, *tx, commitHash)
+		}
 		if err != nil {
 			tx.Rollback()
 			return errors.New("failed to create new deployment")
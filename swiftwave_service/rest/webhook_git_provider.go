@@ -0,0 +1,593 @@
+package rest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// gitWebhookProvider : git provider which has sent the webhook, detected from the headers
+type gitWebhookProvider string
+
+const (
+	unknownGitWebhookProvider gitWebhookProvider = ""
+	gitHubWebhookProvider     gitWebhookProvider = "github"
+	gitLabWebhookProvider     gitWebhookProvider = "gitlab"
+	giteaWebhookProvider      gitWebhookProvider = "gitea" // forgejo uses the same payload
+	bitbucketWebhookProvider  gitWebhookProvider = "bitbucket"
+)
+
+// zeroCommitHash : commit hash sent as the new commit, when a branch is deleted
+const zeroCommitHash = "0000000000000000000000000000000000000000"
+
+// gitPushEvent : push event parsed from the webhook payload
+type gitPushEvent struct {
+	IsPush     bool   // false for other events, e.g. ping or merge request
+	Repository string // full name of the repository, e.g. owner/name
+	Changes    []gitBranchChange
+	Tags       []string // tags created or moved in the push
+}
+
+// gitBranchChange : update of a branch in the push
+type gitBranchChange struct {
+	Branch     string
+	CommitHash string
+	IsDeleted  bool
+}
+
+// detectGitWebhookProvider : gitea and forgejo send github headers as well, so they are checked first
+func detectGitWebhookProvider(header http.Header) gitWebhookProvider {
+	if header.Get("X-Gitea-Event") != "" || header.Get("X-Forgejo-Event") != "" {
+		return giteaWebhookProvider
+	}
+	if header.Get("X-GitHub-Event") != "" {
+		return gitHubWebhookProvider
+	}
+	if header.Get("X-Gitlab-Event") != "" {
+		return gitLabWebhookProvider
+	}
+	if header.Get("X-Event-Key") != "" {
+		return bitbucketWebhookProvider
+	}
+	return unknownGitWebhookProvider
+}
+
+// verifyGitWebhook : verify the HMAC signature of the payload or the secret token, as per the provider
+func verifyGitWebhook(provider gitWebhookProvider, header http.Header, body []byte, secret string) bool {
+	if secret == "" {
+		return false
+	}
+	switch provider {
+	case gitHubWebhookProvider:
+		return verifyHMACSignature(header.Get("X-Hub-Signature-256"), "sha256=", body, secret)
+	case giteaWebhookProvider:
+		signature := header.Get("X-Gitea-Signature")
+		if signature == "" {
+			signature = header.Get("X-Forgejo-Signature")
+		}
+		return verifyHMACSignature(signature, "", body, secret)
+	case gitLabWebhookProvider:
+		return subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) == 1
+	case bitbucketWebhookProvider:
+		return verifyHMACSignature(header.Get("X-Hub-Signature"), "sha256=", body, secret)
+	default:
+		return false
+	}
+}
+
+// hasGitWebhookSignature : check if the provider has sent the signature or the secret token
+// Webhooks configured before the secret was introduced are sent without it
+func hasGitWebhookSignature(provider gitWebhookProvider, header http.Header) bool {
+	switch provider {
+	case gitHubWebhookProvider:
+		return header.Get("X-Hub-Signature-256") != "" || header.Get("X-Hub-Signature") != ""
+	case giteaWebhookProvider:
+		return header.Get("X-Gitea-Signature") != "" || header.Get("X-Forgejo-Signature") != ""
+	case gitLabWebhookProvider:
+		return header.Get("X-Gitlab-Token") != ""
+	case bitbucketWebhookProvider:
+		return header.Get("X-Hub-Signature") != ""
+	default:
+		return false
+	}
+}
+
+func verifyHMACSignature(signature string, prefix string, body []byte, secret string) bool {
+	if signature == "" || !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	receivedMAC, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(receivedMAC, mac.Sum(nil))
+}
+
+// parseGitPushEvent : parse the push event from the payload of the provider
+func parseGitPushEvent(provider gitWebhookProvider, header http.Header, body []byte) (*gitPushEvent, error) {
+	switch provider {
+	case gitHubWebhookProvider:
+		if header.Get("X-GitHub-Event") != "push" {
+			return &gitPushEvent{IsPush: false}, nil
+		}
+		// github can send the payload as form data
+		if strings.HasPrefix(header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			values, err := url.ParseQuery(string(body))
+			if err != nil {
+				return nil, errors.New("invalid form payload")
+			}
+			body = []byte(values.Get("payload"))
+		}
+		return parseGitHubPushPayload(body)
+	case giteaWebhookProvider:
+		event := header.Get("X-Gitea-Event")
+		if event == "" {
+			event = header.Get("X-Forgejo-Event")
+		}
+		if event != "push" {
+			return &gitPushEvent{IsPush: false}, nil
+		}
+		return parseGitHubPushPayload(body)
+	case gitLabWebhookProvider:
+		if header.Get("X-Gitlab-Event") != "Push Hook" && header.Get("X-Gitlab-Event") != "Tag Push Hook" {
+			return &gitPushEvent{IsPush: false}, nil
+		}
+		return parseGitLabPushPayload(body)
+	case bitbucketWebhookProvider:
+		// bitbucket cloud and data center use different events for push
+		switch header.Get("X-Event-Key") {
+		case "repo:push":
+			return parseBitbucketPushPayload(body)
+		case "repo:refs_changed":
+			return parseBitbucketDCPushPayload(body)
+		default:
+			return &gitPushEvent{IsPush: false}, nil
+		}
+	default:
+		return nil, errors.New("unsupported git provider")
+	}
+}
+
+// parseGitHubPushPayload : github, gitea and forgejo push payload
+func parseGitHubPushPayload(body []byte) (*gitPushEvent, error) {
+	var payload struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Deleted    bool   `json:"deleted"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid push payload")
+	}
+	event := &gitPushEvent{
+		IsPush:     true,
+		Repository: payload.Repository.FullName,
+		Changes:    make([]gitBranchChange, 0),
+		Tags:       make([]string, 0),
+	}
+	if strings.HasPrefix(payload.Ref, "refs/heads/") {
+		event.Changes = append(event.Changes, gitBranchChange{
+			Branch:     strings.TrimPrefix(payload.Ref, "refs/heads/"),
+			CommitHash: payload.After,
+			IsDeleted:  payload.Deleted || payload.After == zeroCommitHash,
+		})
+	} else if strings.HasPrefix(payload.Ref, "refs/tags/") && !payload.Deleted && payload.After != zeroCommitHash {
+		event.Tags = append(event.Tags, strings.TrimPrefix(payload.Ref, "refs/tags/"))
+	}
+	return event, nil
+}
+
+func parseGitLabPushPayload(body []byte) (*gitPushEvent, error) {
+	var payload struct {
+		Ref     string `json:"ref"`
+		After   string `json:"after"`
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid push payload")
+	}
+	event := &gitPushEvent{
+		IsPush:     true,
+		Repository: payload.Project.PathWithNamespace,
+		Changes:    make([]gitBranchChange, 0),
+		Tags:       make([]string, 0),
+	}
+	if strings.HasPrefix(payload.Ref, "refs/heads/") {
+		event.Changes = append(event.Changes, gitBranchChange{
+			Branch:     strings.TrimPrefix(payload.Ref, "refs/heads/"),
+			CommitHash: payload.After,
+			IsDeleted:  payload.After == zeroCommitHash,
+		})
+	} else if strings.HasPrefix(payload.Ref, "refs/tags/") && payload.After != zeroCommitHash {
+		event.Tags = append(event.Tags, strings.TrimPrefix(payload.Ref, "refs/tags/"))
+	}
+	return event, nil
+}
+
+// parseBitbucketPushPayload : bitbucket cloud push payload, a push can update multiple branches
+func parseBitbucketPushPayload(body []byte) (*gitPushEvent, error) {
+	type bitbucketRef struct {
+		Type   string `json:"type"`
+		Name   string `json:"name"`
+		Target struct {
+			Hash string `json:"hash"`
+		} `json:"target"`
+	}
+	var payload struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Push struct {
+			Changes []struct {
+				New *bitbucketRef `json:"new"`
+				Old *bitbucketRef `json:"old"`
+			} `json:"changes"`
+		} `json:"push"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid push payload")
+	}
+	event := &gitPushEvent{
+		IsPush:     true,
+		Repository: payload.Repository.FullName,
+		Changes:    make([]gitBranchChange, 0),
+		Tags:       make([]string, 0),
+	}
+	for _, change := range payload.Push.Changes {
+		if change.New != nil && change.New.Type == "tag" {
+			event.Tags = append(event.Tags, change.New.Name)
+			continue
+		}
+		if change.New != nil && change.New.Type == "branch" {
+			event.Changes = append(event.Changes, gitBranchChange{
+				Branch:     change.New.Name,
+				CommitHash: change.New.Target.Hash,
+			})
+		} else if change.New == nil && change.Old != nil && change.Old.Type == "branch" {
+			event.Changes = append(event.Changes, gitBranchChange{
+				Branch:    change.Old.Name,
+				IsDeleted: true,
+			})
+		}
+	}
+	return event, nil
+}
+
+// parseBitbucketDCPushPayload : bitbucket data center (server) push payload
+func parseBitbucketDCPushPayload(body []byte) (*gitPushEvent, error) {
+	var payload struct {
+		Repository struct {
+			Slug    string `json:"slug"`
+			Project struct {
+				Key string `json:"key"`
+			} `json:"project"`
+		} `json:"repository"`
+		Changes []struct {
+			Ref struct {
+				ID        string `json:"id"`
+				DisplayID string `json:"displayId"`
+				Type      string `json:"type"`
+			} `json:"ref"`
+			ToHash string `json:"toHash"`
+			Type   string `json:"type"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid push payload")
+	}
+	event := &gitPushEvent{
+		IsPush:     true,
+		Repository: payload.Repository.Project.Key + "/" + payload.Repository.Slug,
+		Changes:    make([]gitBranchChange, 0),
+		Tags:       make([]string, 0),
+	}
+	for _, change := range payload.Changes {
+		if change.Ref.Type == "TAG" && change.Type != "DELETE" {
+			event.Tags = append(event.Tags, change.Ref.DisplayID)
+			continue
+		}
+		if change.Ref.Type != "BRANCH" {
+			continue
+		}
+		event.Changes = append(event.Changes, gitBranchChange{
+			Branch:     change.Ref.DisplayID,
+			CommitHash: change.ToHash,
+			IsDeleted:  change.Type == "DELETE",
+		})
+	}
+	return event, nil
+}
+
+// matchesRepository : check if the repository of the event is the repository of the deployment
+func (event *gitPushEvent) matchesRepository(owner string, name string) bool {
+	return matchesRepository(event.Repository, owner, name)
+}
+
+// matchesRepository : Bitbucket data center repository urls have extra path segment e.g. scm/<project>/<repo>, so suffix is matched
+func matchesRepository(eventRepository string, owner string, name string) bool {
+	if eventRepository == "" {
+		return false
+	}
+	repository := strings.ToLower(owner + "/" + name)
+	eventRepository = strings.ToLower(eventRepository)
+	return repository == eventRepository || strings.HasSuffix(repository, "/"+eventRepository)
+}
+
+// branchChange : change of the branch in the push, nil if the branch is not pushed
+func (event *gitPushEvent) branchChange(branch string) *gitBranchChange {
+	for i := range event.Changes {
+		if event.Changes[i].Branch == branch {
+			return &event.Changes[i]
+		}
+	}
+	return nil
+}
+
+// gitPullRequestAction : change in the pull request, relevant for the preview environment
+type gitPullRequestAction string
+
+const (
+	gitPullRequestOpened  gitPullRequestAction = "opened"
+	gitPullRequestUpdated gitPullRequestAction = "updated" // new commits pushed to the branch
+	gitPullRequestClosed  gitPullRequestAction = "closed"  // closed or merged
+	gitPullRequestIgnored gitPullRequestAction = ""
+)
+
+// gitPullRequestEvent : pull request event parsed from the webhook payload
+type gitPullRequestEvent struct {
+	Action         gitPullRequestAction
+	Number         uint
+	Repository     string // full name of the target repository
+	HeadRepository string // full name of the source repository, differs for pull request from fork
+	HeadBranch     string
+	HeadCommitHash string // empty, if the provider doesn't send the full hash
+	BaseBranch     string
+}
+
+// isFromFork : code of forks is not trusted to be built automatically
+func (event *gitPullRequestEvent) isFromFork() bool {
+	return !strings.EqualFold(event.Repository, event.HeadRepository)
+}
+
+func (event *gitPullRequestEvent) matchesRepository(owner string, name string) bool {
+	return matchesRepository(event.Repository, owner, name)
+}
+
+// parseGitPullRequestEvent : parse the pull request event from the payload of the provider
+// nil is returned, if the webhook is not a pull request event
+func parseGitPullRequestEvent(provider gitWebhookProvider, header http.Header, body []byte) (*gitPullRequestEvent, error) {
+	switch provider {
+	case gitHubWebhookProvider:
+		if header.Get("X-GitHub-Event") != "pull_request" {
+			return nil, nil
+		}
+		if strings.HasPrefix(header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			values, err := url.ParseQuery(string(body))
+			if err != nil {
+				return nil, errors.New("invalid form payload")
+			}
+			body = []byte(values.Get("payload"))
+		}
+		return parseGitHubPullRequestPayload(body)
+	case giteaWebhookProvider:
+		event := header.Get("X-Gitea-Event")
+		if event == "" {
+			event = header.Get("X-Forgejo-Event")
+		}
+		if event != "pull_request" {
+			return nil, nil
+		}
+		return parseGitHubPullRequestPayload(body)
+	case gitLabWebhookProvider:
+		if header.Get("X-Gitlab-Event") != "Merge Request Hook" {
+			return nil, nil
+		}
+		return parseGitLabMergeRequestPayload(body)
+	case bitbucketWebhookProvider:
+		eventKey := header.Get("X-Event-Key")
+		if strings.HasPrefix(eventKey, "pullrequest:") {
+			return parseBitbucketPullRequestPayload(eventKey, body)
+		}
+		if strings.HasPrefix(eventKey, "pr:") {
+			return parseBitbucketDCPullRequestPayload(eventKey, body)
+		}
+		return nil, nil
+	default:
+		return nil, errors.New("unsupported git provider")
+	}
+}
+
+// parseGitHubPullRequestPayload : github, gitea and forgejo pull request payload
+func parseGitHubPullRequestPayload(body []byte) (*gitPullRequestEvent, error) {
+	type pullRequestRef struct {
+		Ref  string `json:"ref"`
+		Sha  string `json:"sha"`
+		Repo struct {
+			FullName string `json:"full_name"`
+		} `json:"repo"`
+	}
+	var payload struct {
+		Action      string `json:"action"`
+		Number      uint   `json:"number"`
+		PullRequest struct {
+			Head pullRequestRef `json:"head"`
+			Base pullRequestRef `json:"base"`
+		} `json:"pull_request"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid pull request payload")
+	}
+	action := gitPullRequestIgnored
+	switch payload.Action {
+	case "opened", "reopened":
+		action = gitPullRequestOpened
+	case "synchronize", "synchronized":
+		action = gitPullRequestUpdated
+	case "closed":
+		action = gitPullRequestClosed
+	}
+	return &gitPullRequestEvent{
+		Action:         action,
+		Number:         payload.Number,
+		Repository:     payload.Repository.FullName,
+		HeadRepository: payload.PullRequest.Head.Repo.FullName,
+		HeadBranch:     payload.PullRequest.Head.Ref,
+		HeadCommitHash: payload.PullRequest.Head.Sha,
+		BaseBranch:     payload.PullRequest.Base.Ref,
+	}, nil
+}
+
+func parseGitLabMergeRequestPayload(body []byte) (*gitPullRequestEvent, error) {
+	var payload struct {
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+		ObjectAttributes struct {
+			IID          uint   `json:"iid"`
+			Action       string `json:"action"`
+			OldRev       string `json:"oldrev"`
+			SourceBranch string `json:"source_branch"`
+			TargetBranch string `json:"target_branch"`
+			Source       struct {
+				PathWithNamespace string `json:"path_with_namespace"`
+			} `json:"source"`
+			LastCommit struct {
+				ID string `json:"id"`
+			} `json:"last_commit"`
+		} `json:"object_attributes"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid merge request payload")
+	}
+	action := gitPullRequestIgnored
+	switch payload.ObjectAttributes.Action {
+	case "open", "reopen":
+		action = gitPullRequestOpened
+	case "update":
+		// update is also sent for change in title, description etc.
+		if payload.ObjectAttributes.OldRev != "" {
+			action = gitPullRequestUpdated
+		}
+	case "close", "merge":
+		action = gitPullRequestClosed
+	}
+	return &gitPullRequestEvent{
+		Action:         action,
+		Number:         payload.ObjectAttributes.IID,
+		Repository:     payload.Project.PathWithNamespace,
+		HeadRepository: payload.ObjectAttributes.Source.PathWithNamespace,
+		HeadBranch:     payload.ObjectAttributes.SourceBranch,
+		HeadCommitHash: payload.ObjectAttributes.LastCommit.ID,
+		BaseBranch:     payload.ObjectAttributes.TargetBranch,
+	}, nil
+}
+
+// parseBitbucketPullRequestPayload : bitbucket cloud pull request payload
+func parseBitbucketPullRequestPayload(eventKey string, body []byte) (*gitPullRequestEvent, error) {
+	type pullRequestRef struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+		Commit struct {
+			Hash string `json:"hash"`
+		} `json:"commit"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	var payload struct {
+		PullRequest struct {
+			ID          uint           `json:"id"`
+			Source      pullRequestRef `json:"source"`
+			Destination pullRequestRef `json:"destination"`
+		} `json:"pullrequest"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid pull request payload")
+	}
+	action := gitPullRequestIgnored
+	switch eventKey {
+	case "pullrequest:created":
+		action = gitPullRequestOpened
+	case "pullrequest:updated":
+		action = gitPullRequestUpdated
+	case "pullrequest:fulfilled", "pullrequest:rejected":
+		action = gitPullRequestClosed
+	}
+	// bitbucket cloud sends the abbreviated hash, latest commit of the branch is used instead
+	commitHash := payload.PullRequest.Source.Commit.Hash
+	if len(commitHash) != len(zeroCommitHash) {
+		commitHash = ""
+	}
+	return &gitPullRequestEvent{
+		Action:         action,
+		Number:         payload.PullRequest.ID,
+		Repository:     payload.Repository.FullName,
+		HeadRepository: payload.PullRequest.Source.Repository.FullName,
+		HeadBranch:     payload.PullRequest.Source.Branch.Name,
+		HeadCommitHash: commitHash,
+		BaseBranch:     payload.PullRequest.Destination.Branch.Name,
+	}, nil
+}
+
+// parseBitbucketDCPullRequestPayload : bitbucket data center (server) pull request payload
+func parseBitbucketDCPullRequestPayload(eventKey string, body []byte) (*gitPullRequestEvent, error) {
+	type pullRequestRef struct {
+		DisplayID    string `json:"displayId"`
+		LatestCommit string `json:"latestCommit"`
+		Repository   struct {
+			Slug    string `json:"slug"`
+			Project struct {
+				Key string `json:"key"`
+			} `json:"project"`
+		} `json:"repository"`
+	}
+	var payload struct {
+		PullRequest struct {
+			ID      uint           `json:"id"`
+			FromRef pullRequestRef `json:"fromRef"`
+			ToRef   pullRequestRef `json:"toRef"`
+		} `json:"pullRequest"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid pull request payload")
+	}
+	action := gitPullRequestIgnored
+	switch eventKey {
+	case "pr:opened":
+		action = gitPullRequestOpened
+	case "pr:from_ref_updated":
+		action = gitPullRequestUpdated
+	case "pr:merged", "pr:declined", "pr:deleted":
+		action = gitPullRequestClosed
+	}
+	fromRepository := payload.PullRequest.FromRef.Repository
+	toRepository := payload.PullRequest.ToRef.Repository
+	return &gitPullRequestEvent{
+		Action:         action,
+		Number:         payload.PullRequest.ID,
+		Repository:     toRepository.Project.Key + "/" + toRepository.Slug,
+		HeadRepository: fromRepository.Project.Key + "/" + fromRepository.Slug,
+		HeadBranch:     payload.PullRequest.FromRef.DisplayID,
+		HeadCommitHash: payload.PullRequest.FromRef.LatestCommit,
+		BaseBranch:     payload.PullRequest.ToRef.DisplayID,
+	}, nil
+}
@@ -0,0 +1,332 @@
+package rest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testWebhookSecret = "webhook-secret"
+
+func signPayload(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookHeader(values ...string) http.Header {
+	header := http.Header{}
+	for i := 0; i+1 < len(values); i += 2 {
+		header.Set(values[i], values[i+1])
+	}
+	return header
+}
+
+func TestDetectGitWebhookProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   gitWebhookProvider
+	}{
+		{"github", webhookHeader("X-GitHub-Event", "push"), gitHubWebhookProvider},
+		{"gitea sends github headers too", webhookHeader("X-GitHub-Event", "push", "X-Gitea-Event", "push"), giteaWebhookProvider},
+		{"forgejo", webhookHeader("X-Forgejo-Event", "push"), giteaWebhookProvider},
+		{"gitlab", webhookHeader("X-Gitlab-Event", "Push Hook"), gitLabWebhookProvider},
+		{"bitbucket", webhookHeader("X-Event-Key", "repo:push"), bitbucketWebhookProvider},
+		{"unknown", webhookHeader("User-Agent", "curl"), unknownGitWebhookProvider},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectGitWebhookProvider(tt.header))
+		})
+	}
+}
+
+func TestVerifyGitWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	signature := signPayload(body, testWebhookSecret)
+	wrongSignature := signPayload(body, "another-secret")
+	tests := []struct {
+		name      string
+		provider  gitWebhookProvider
+		header    http.Header
+		secret    string
+		want      bool
+		hasSigned bool
+	}{
+		{"github valid", gitHubWebhookProvider, webhookHeader("X-Hub-Signature-256", "sha256="+signature), testWebhookSecret, true, true},
+		{"github wrong secret", gitHubWebhookProvider, webhookHeader("X-Hub-Signature-256", "sha256="+wrongSignature), testWebhookSecret, false, true},
+		{"github without prefix", gitHubWebhookProvider, webhookHeader("X-Hub-Signature-256", signature), testWebhookSecret, false, true},
+		{"github not hex", gitHubWebhookProvider, webhookHeader("X-Hub-Signature-256", "sha256=zz"), testWebhookSecret, false, true},
+		{"github legacy sha1 only", gitHubWebhookProvider, webhookHeader("X-Hub-Signature", "sha1=abc"), testWebhookSecret, false, true},
+		{"github missing", gitHubWebhookProvider, webhookHeader(), testWebhookSecret, false, false},
+		{"gitea valid", giteaWebhookProvider, webhookHeader("X-Gitea-Signature", signature), testWebhookSecret, true, true},
+		{"forgejo valid", giteaWebhookProvider, webhookHeader("X-Forgejo-Signature", signature), testWebhookSecret, true, true},
+		{"gitea wrong secret", giteaWebhookProvider, webhookHeader("X-Gitea-Signature", wrongSignature), testWebhookSecret, false, true},
+		{"gitea missing", giteaWebhookProvider, webhookHeader(), testWebhookSecret, false, false},
+		{"gitlab valid", gitLabWebhookProvider, webhookHeader("X-Gitlab-Token", testWebhookSecret), testWebhookSecret, true, true},
+		{"gitlab wrong token", gitLabWebhookProvider, webhookHeader("X-Gitlab-Token", "guess"), testWebhookSecret, false, true},
+		{"gitlab missing", gitLabWebhookProvider, webhookHeader(), testWebhookSecret, false, false},
+		{"bitbucket valid", bitbucketWebhookProvider, webhookHeader("X-Hub-Signature", "sha256="+signature), testWebhookSecret, true, true},
+		{"bitbucket wrong secret", bitbucketWebhookProvider, webhookHeader("X-Hub-Signature", "sha256="+wrongSignature), testWebhookSecret, false, true},
+		{"bitbucket missing", bitbucketWebhookProvider, webhookHeader(), testWebhookSecret, false, false},
+		{"empty secret is never valid", gitLabWebhookProvider, webhookHeader("X-Gitlab-Token", ""), "", false, false},
+		{"unknown provider", unknownGitWebhookProvider, webhookHeader("X-Hub-Signature-256", "sha256="+signature), testWebhookSecret, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, verifyGitWebhook(tt.provider, tt.header, body, tt.secret))
+			assert.Equal(t, tt.hasSigned, hasGitWebhookSignature(tt.provider, tt.header))
+		})
+	}
+}
+
+func TestParseGitPushEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider gitWebhookProvider
+		header   http.Header
+		body     string
+		want     *gitPushEvent
+		wantErr  bool
+	}{
+		{
+			name:     "github branch push",
+			provider: gitHubWebhookProvider,
+			header:   webhookHeader("X-GitHub-Event", "push"),
+			body:     `{"ref":"refs/heads/main","after":"abc123","repository":{"full_name":"owner/app"}}`,
+			want:     &gitPushEvent{IsPush: true, Repository: "owner/app", Changes: []gitBranchChange{{Branch: "main", CommitHash: "abc123"}}, Tags: []string{}},
+		},
+		{
+			name:     "github form payload",
+			provider: gitHubWebhookProvider,
+			header:   webhookHeader("X-GitHub-Event", "push", "Content-Type", "application/x-www-form-urlencoded"),
+			body:     `payload=%7B%22ref%22%3A%22refs%2Fheads%2Fmain%22%2C%22after%22%3A%22abc123%22%2C%22repository%22%3A%7B%22full_name%22%3A%22owner%2Fapp%22%7D%7D`,
+			want:     &gitPushEvent{IsPush: true, Repository: "owner/app", Changes: []gitBranchChange{{Branch: "main", CommitHash: "abc123"}}, Tags: []string{}},
+		},
+		{
+			name:     "github branch deletion",
+			provider: gitHubWebhookProvider,
+			header:   webhookHeader("X-GitHub-Event", "push"),
+			body:     `{"ref":"refs/heads/main","after":"` + zeroCommitHash + `","deleted":true,"repository":{"full_name":"owner/app"}}`,
+			want:     &gitPushEvent{IsPush: true, Repository: "owner/app", Changes: []gitBranchChange{{Branch: "main", CommitHash: zeroCommitHash, IsDeleted: true}}, Tags: []string{}},
+		},
+		{
+			name:     "github tag push",
+			provider: gitHubWebhookProvider,
+			header:   webhookHeader("X-GitHub-Event", "push"),
+			body:     `{"ref":"refs/tags/v1.2.0","after":"abc123","repository":{"full_name":"owner/app"}}`,
+			want:     &gitPushEvent{IsPush: true, Repository: "owner/app", Changes: []gitBranchChange{}, Tags: []string{"v1.2.0"}},
+		},
+		{
+			name:     "github ping",
+			provider: gitHubWebhookProvider,
+			header:   webhookHeader("X-GitHub-Event", "ping"),
+			body:     `{"zen":"hello"}`,
+			want:     &gitPushEvent{IsPush: false},
+		},
+		{
+			name:     "github invalid json",
+			provider: gitHubWebhookProvider,
+			header:   webhookHeader("X-GitHub-Event", "push"),
+			body:     `{`,
+			wantErr:  true,
+		},
+		{
+			name:     "gitea push",
+			provider: giteaWebhookProvider,
+			header:   webhookHeader("X-Gitea-Event", "push"),
+			body:     `{"ref":"refs/heads/dev","after":"def456","repository":{"full_name":"org/app"}}`,
+			want:     &gitPushEvent{IsPush: true, Repository: "org/app", Changes: []gitBranchChange{{Branch: "dev", CommitHash: "def456"}}, Tags: []string{}},
+		},
+		{
+			name:     "forgejo push",
+			provider: giteaWebhookProvider,
+			header:   webhookHeader("X-Forgejo-Event", "push"),
+			body:     `{"ref":"refs/heads/dev","after":"def456","repository":{"full_name":"org/app"}}`,
+			want:     &gitPushEvent{IsPush: true, Repository: "org/app", Changes: []gitBranchChange{{Branch: "dev", CommitHash: "def456"}}, Tags: []string{}},
+		},
+		{
+			name:     "gitlab push",
+			provider: gitLabWebhookProvider,
+			header:   webhookHeader("X-Gitlab-Event", "Push Hook"),
+			body:     `{"ref":"refs/heads/main","after":"abc123","project":{"path_with_namespace":"group/sub/app"}}`,
+			want:     &gitPushEvent{IsPush: true, Repository: "group/sub/app", Changes: []gitBranchChange{{Branch: "main", CommitHash: "abc123"}}, Tags: []string{}},
+		},
+		{
+			name:     "gitlab tag push",
+			provider: gitLabWebhookProvider,
+			header:   webhookHeader("X-Gitlab-Event", "Tag Push Hook"),
+			body:     `{"ref":"refs/tags/v2","after":"abc123","project":{"path_with_namespace":"group/app"}}`,
+			want:     &gitPushEvent{IsPush: true, Repository: "group/app", Changes: []gitBranchChange{}, Tags: []string{"v2"}},
+		},
+		{
+			name:     "gitlab branch deletion",
+			provider: gitLabWebhookProvider,
+			header:   webhookHeader("X-Gitlab-Event", "Push Hook"),
+			body:     `{"ref":"refs/heads/main","after":"` + zeroCommitHash + `","project":{"path_with_namespace":"group/app"}}`,
+			want:     &gitPushEvent{IsPush: true, Repository: "group/app", Changes: []gitBranchChange{{Branch: "main", CommitHash: zeroCommitHash, IsDeleted: true}}, Tags: []string{}},
+		},
+		{
+			name:     "gitlab merge request is not a push",
+			provider: gitLabWebhookProvider,
+			header:   webhookHeader("X-Gitlab-Event", "Merge Request Hook"),
+			body:     `{}`,
+			want:     &gitPushEvent{IsPush: false},
+		},
+		{
+			name:     "bitbucket cloud push with multiple changes",
+			provider: bitbucketWebhookProvider,
+			header:   webhookHeader("X-Event-Key", "repo:push"),
+			body: `{"repository":{"full_name":"team/app"},"push":{"changes":[
+				{"new":{"type":"branch","name":"main","target":{"hash":"abc123"}}},
+				{"new":null,"old":{"type":"branch","name":"old-feature"}},
+				{"new":{"type":"tag","name":"v3"}}]}}`,
+			want: &gitPushEvent{IsPush: true, Repository: "team/app", Changes: []gitBranchChange{
+				{Branch: "main", CommitHash: "abc123"},
+				{Branch: "old-feature", IsDeleted: true},
+			}, Tags: []string{"v3"}},
+		},
+		{
+			name:     "bitbucket data center push",
+			provider: bitbucketWebhookProvider,
+			header:   webhookHeader("X-Event-Key", "repo:refs_changed"),
+			body: `{"repository":{"slug":"app","project":{"key":"PRJ"}},"changes":[
+				{"ref":{"displayId":"main","type":"BRANCH"},"toHash":"abc123","type":"UPDATE"},
+				{"ref":{"displayId":"gone","type":"BRANCH"},"toHash":"` + zeroCommitHash + `","type":"DELETE"},
+				{"ref":{"displayId":"v4","type":"TAG"},"toHash":"abc123","type":"ADD"}]}`,
+			want: &gitPushEvent{IsPush: true, Repository: "PRJ/app", Changes: []gitBranchChange{
+				{Branch: "main", CommitHash: "abc123"},
+				{Branch: "gone", CommitHash: zeroCommitHash, IsDeleted: true},
+			}, Tags: []string{"v4"}},
+		},
+		{
+			name:     "bitbucket other event",
+			provider: bitbucketWebhookProvider,
+			header:   webhookHeader("X-Event-Key", "repo:fork"),
+			body:     `{}`,
+			want:     &gitPushEvent{IsPush: false},
+		},
+		{
+			name:     "unknown provider",
+			provider: unknownGitWebhookProvider,
+			header:   webhookHeader(),
+			body:     `{}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := parseGitPushEvent(tt.provider, tt.header, []byte(tt.body))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, event)
+		})
+	}
+}
+
+func TestGitPushEventMatchesRepository(t *testing.T) {
+	tests := []struct {
+		name            string
+		eventRepository string
+		owner           string
+		repository      string
+		want            bool
+	}{
+		{"same", "owner/app", "owner", "app", true},
+		{"case insensitive", "Owner/App", "owner", "app", true},
+		{"other repository", "owner/other", "owner", "app", false},
+		{"other owner", "attacker/app", "owner", "app", false},
+		{"bitbucket data center path", "PRJ/app", "scm/prj", "app", true},
+		{"partial name is not matched", "er/app", "owner", "app", false},
+		{"empty", "", "owner", "app", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &gitPushEvent{Repository: tt.eventRepository}
+			assert.Equal(t, tt.want, event.matchesRepository(tt.owner, tt.repository))
+		})
+	}
+}
+
+func TestParseGitPullRequestEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider gitWebhookProvider
+		header   http.Header
+		body     string
+		want     *gitPullRequestEvent
+	}{
+		{
+			name:     "github opened from fork",
+			provider: gitHubWebhookProvider,
+			header:   webhookHeader("X-GitHub-Event", "pull_request"),
+			body: `{"action":"opened","number":7,"repository":{"full_name":"owner/app"},"pull_request":{
+				"head":{"ref":"feature","sha":"abc123","repo":{"full_name":"fork/app"}},"base":{"ref":"main"}}}`,
+			want: &gitPullRequestEvent{Action: gitPullRequestOpened, Number: 7, Repository: "owner/app", HeadRepository: "fork/app", HeadBranch: "feature", HeadCommitHash: "abc123", BaseBranch: "main"},
+		},
+		{
+			name:     "gitea synchronized",
+			provider: giteaWebhookProvider,
+			header:   webhookHeader("X-Gitea-Event", "pull_request"),
+			body: `{"action":"synchronized","number":3,"repository":{"full_name":"org/app"},"pull_request":{
+				"head":{"ref":"fix","sha":"def456","repo":{"full_name":"org/app"}},"base":{"ref":"main"}}}`,
+			want: &gitPullRequestEvent{Action: gitPullRequestUpdated, Number: 3, Repository: "org/app", HeadRepository: "org/app", HeadBranch: "fix", HeadCommitHash: "def456", BaseBranch: "main"},
+		},
+		{
+			name:     "gitlab title update is ignored",
+			provider: gitLabWebhookProvider,
+			header:   webhookHeader("X-Gitlab-Event", "Merge Request Hook"),
+			body: `{"project":{"path_with_namespace":"group/app"},"object_attributes":{"iid":5,"action":"update",
+				"source_branch":"feature","target_branch":"main","source":{"path_with_namespace":"group/app"},"last_commit":{"id":"abc123"}}}`,
+			want: &gitPullRequestEvent{Action: gitPullRequestIgnored, Number: 5, Repository: "group/app", HeadRepository: "group/app", HeadBranch: "feature", HeadCommitHash: "abc123", BaseBranch: "main"},
+		},
+		{
+			name:     "gitlab merged",
+			provider: gitLabWebhookProvider,
+			header:   webhookHeader("X-Gitlab-Event", "Merge Request Hook"),
+			body: `{"project":{"path_with_namespace":"group/app"},"object_attributes":{"iid":5,"action":"merge",
+				"source_branch":"feature","target_branch":"main","source":{"path_with_namespace":"group/app"},"last_commit":{"id":"abc123"}}}`,
+			want: &gitPullRequestEvent{Action: gitPullRequestClosed, Number: 5, Repository: "group/app", HeadRepository: "group/app", HeadBranch: "feature", HeadCommitHash: "abc123", BaseBranch: "main"},
+		},
+		{
+			name:     "bitbucket cloud abbreviated hash is dropped",
+			provider: bitbucketWebhookProvider,
+			header:   webhookHeader("X-Event-Key", "pullrequest:created"),
+			body: `{"repository":{"full_name":"team/app"},"pullrequest":{"id":9,
+				"source":{"branch":{"name":"feature"},"commit":{"hash":"abc123"},"repository":{"full_name":"team/app"}},
+				"destination":{"branch":{"name":"main"}}}}`,
+			want: &gitPullRequestEvent{Action: gitPullRequestOpened, Number: 9, Repository: "team/app", HeadRepository: "team/app", HeadBranch: "feature", BaseBranch: "main"},
+		},
+		{
+			name:     "bitbucket data center declined",
+			provider: bitbucketWebhookProvider,
+			header:   webhookHeader("X-Event-Key", "pr:declined"),
+			body: `{"pullRequest":{"id":4,
+				"fromRef":{"displayId":"feature","latestCommit":"abc123","repository":{"slug":"app","project":{"key":"PRJ"}}},
+				"toRef":{"displayId":"main","repository":{"slug":"app","project":{"key":"PRJ"}}}}}`,
+			want: &gitPullRequestEvent{Action: gitPullRequestClosed, Number: 4, Repository: "PRJ/app", HeadRepository: "PRJ/app", HeadBranch: "feature", HeadCommitHash: "abc123", BaseBranch: "main"},
+		},
+		{
+			name:     "push is not a pull request",
+			provider: gitHubWebhookProvider,
+			header:   webhookHeader("X-GitHub-Event", "push"),
+			body:     `{}`,
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := parseGitPullRequestEvent(tt.provider, tt.header, []byte(tt.body))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, event)
+		})
+	}
+}
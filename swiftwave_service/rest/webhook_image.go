@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// imagePushEvent : push of the image parsed from the webhook payload of the registry
+type imagePushEvent struct {
+	Repository string   // path of the repository without the registry, e.g. owner/name
+	Tags       []string // pushed tags, empty if the registry doesn't send those
+}
+
+// parseImagePushEvent : parse the push event sent by Docker Hub, Harbor or Quay
+// Other registries or scripts can send the image reference (e.g. ghcr.io/owner/name:tag) as plain text
+func parseImagePushEvent(body []byte) (*imagePushEvent, error) {
+	var payload struct {
+		// docker hub sends an object, quay sends the name of the repository
+		Repository json.RawMessage `json:"repository"`
+		PushData   struct {
+			Tag string `json:"tag"`
+		} `json:"push_data"`
+		UpdatedTags []string `json:"updated_tags"`
+		EventData   struct {
+			Repository struct {
+				RepoFullName string `json:"repo_full_name"`
+			} `json:"repository"`
+			Resources []struct {
+				Tag string `json:"tag"`
+			} `json:"resources"`
+		} `json:"event_data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return parseImageReferencePushEvent(body)
+	}
+	event := &imagePushEvent{Tags: make([]string, 0)}
+	switch {
+	case payload.EventData.Repository.RepoFullName != "":
+		// harbor
+		event.Repository = payload.EventData.Repository.RepoFullName
+		for _, resource := range payload.EventData.Resources {
+			if resource.Tag != "" {
+				event.Tags = append(event.Tags, resource.Tag)
+			}
+		}
+	case len(payload.Repository) > 0 && payload.Repository[0] == '"':
+		// quay
+		if err := json.Unmarshal(payload.Repository, &event.Repository); err != nil {
+			return nil, errors.New("invalid image push payload")
+		}
+		event.Tags = append(event.Tags, payload.UpdatedTags...)
+	case len(payload.Repository) > 0:
+		// docker hub
+		var repository struct {
+			RepoName string `json:"repo_name"`
+		}
+		if err := json.Unmarshal(payload.Repository, &repository); err != nil {
+			return nil, errors.New("invalid image push payload")
+		}
+		event.Repository = repository.RepoName
+		if payload.PushData.Tag != "" {
+			event.Tags = append(event.Tags, payload.PushData.Tag)
+		}
+	}
+	if event.Repository == "" {
+		return nil, errors.New("image repository not found in the payload")
+	}
+	return event, nil
+}
+
+// parseImageReferencePushEvent : plain text or url encoded image reference
+func parseImageReferencePushEvent(body []byte) (*imagePushEvent, error) {
+	value, err := url.QueryUnescape(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, errors.New("invalid image push payload")
+	}
+	named, err := reference.ParseNormalizedNamed(strings.TrimSpace(value))
+	if err != nil {
+		return nil, errors.New("invalid image reference in the payload")
+	}
+	event := &imagePushEvent{
+		Repository: reference.Path(named),
+		Tags:       make([]string, 0),
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		event.Tags = append(event.Tags, tagged.Tag())
+	}
+	return event, nil
+}
+
+// matchesImage : check if the pushed image is the image of the deployment
+// Repository should be the same, registry is not compared as most of the registries don't send it
+// If the registry sends the pushed tags, the tag of the deployment should be one of those
+func (event *imagePushEvent) matchesImage(image string) (bool, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return false, errors.New("invalid docker image of the deployment")
+	}
+	pushedRepository, err := reference.ParseNormalizedNamed(event.Repository)
+	if err != nil {
+		return false, nil
+	}
+	if !strings.EqualFold(reference.Path(named), reference.Path(pushedRepository)) {
+		return false, nil
+	}
+	if len(event.Tags) == 0 {
+		return true, nil
+	}
+	tag := "latest"
+	if tagged, ok := named.(reference.Tagged); ok {
+		tag = tagged.Tag()
+	}
+	for _, pushedTag := range event.Tags {
+		if pushedTag == tag {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImagePushEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *imagePushEvent
+		wantErr bool
+	}{
+		{
+			name: "docker hub",
+			body: `{"push_data":{"tag":"v1"},"repository":{"repo_name":"owner/app","name":"app"}}`,
+			want: &imagePushEvent{Repository: "owner/app", Tags: []string{"v1"}},
+		},
+		{
+			name: "harbor",
+			body: `{"type":"PUSH_ARTIFACT","event_data":{"resources":[{"tag":"latest"},{"tag":"v2"}],"repository":{"repo_full_name":"project/app"}}}`,
+			want: &imagePushEvent{Repository: "project/app", Tags: []string{"latest", "v2"}},
+		},
+		{
+			name: "quay",
+			body: `{"repository":"ns/app","docker_url":"quay.io/ns/app","updated_tags":["latest"]}`,
+			want: &imagePushEvent{Repository: "ns/app", Tags: []string{"latest"}},
+		},
+		{
+			name: "plain image reference",
+			body: "ghcr.io/owner/app:v3\n",
+			want: &imagePushEvent{Repository: "owner/app", Tags: []string{"v3"}},
+		},
+		{
+			name: "url encoded image reference",
+			body: "registry.example.com%3A5000%2Fteam%2Fapp",
+			want: &imagePushEvent{Repository: "team/app", Tags: []string{}},
+		},
+		{
+			name:    "json without repository",
+			body:    `{"message":"owner/app"}`,
+			wantErr: true,
+		},
+		{
+			name:    "text which is not an image",
+			body:    "please deploy owner/app now",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			body:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := parseImagePushEvent([]byte(tt.body))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, event)
+		})
+	}
+}
+
+func TestImagePushEventMatchesImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		event imagePushEvent
+		image string
+		want  bool
+	}{
+		{"same repository and tag", imagePushEvent{Repository: "owner/app", Tags: []string{"v1"}}, "owner/app:v1", true},
+		{"registry is not compared", imagePushEvent{Repository: "owner/app"}, "ghcr.io/owner/app:v1", true},
+		{"registry with port", imagePushEvent{Repository: "team/app", Tags: []string{"v1"}}, "localhost:5000/team/app:v1", true},
+		{"official image", imagePushEvent{Repository: "nginx", Tags: []string{"latest"}}, "nginx", true},
+		{"other tag is pushed", imagePushEvent{Repository: "owner/app", Tags: []string{"v2"}}, "owner/app:v1", false},
+		{"default tag is latest", imagePushEvent{Repository: "owner/app", Tags: []string{"v2"}}, "owner/app", false},
+		{"prefix of the repository", imagePushEvent{Repository: "owner/app"}, "owner/app-private:v1", false},
+		{"repository containing the name", imagePushEvent{Repository: "attacker/owner/app"}, "owner/app:v1", false},
+		{"other owner", imagePushEvent{Repository: "attacker/app"}, "owner/app:v1", false},
+		{"nested repository", imagePushEvent{Repository: "group/sub/app", Tags: []string{"v1"}}, "registry.gitlab.com/group/sub/app:v1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := tt.event.matchesImage(tt.image)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, matched)
+		})
+	}
+	_, err := (&imagePushEvent{Repository: "owner/app"}).matchesImage("Invalid Image")
+	assert.Error(t, err)
+}
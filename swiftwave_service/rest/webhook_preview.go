@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/logger"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/manager"
+	"gorm.io/gorm"
+)
+
+// processPullRequestEvent : create, rebuild or tear down the preview environment of the pull request
+func (server *Server) processPullRequestEvent(ctx context.Context, c echo.Context, application *core.Application, deployment *core.Deployment, event *gitPullRequestEvent) error {
+	if !application.PreviewConfig.Enabled {
+		return c.String(200, "OK - Preview environments are not enabled")
+	}
+	if event.Action == gitPullRequestIgnored {
+		return c.String(200, "OK - No preview update")
+	}
+	// only pull requests to the deployed branch are previewed
+	if !event.matchesRepository(deployment.RepositoryOwner, deployment.RepositoryName) || event.BaseBranch != deployment.RepositoryBranch {
+		return c.String(200, "OK - No preview update")
+	}
+	db := server.ServiceManager.DbClient
+	preview := &core.ApplicationPreview{}
+	err := preview.FindByPullRequest(ctx, db, application.ID, event.Number)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.String(500, "Error fetching preview environment")
+	}
+	isPreviewExist := err == nil
+
+	if event.Action == gitPullRequestClosed {
+		if !isPreviewExist || preview.Status == core.ApplicationPreviewStatusDeleting {
+			return c.String(200, "OK - No preview to remove")
+		}
+		err = preview.MarkAsDeleting(ctx, db)
+		if err != nil {
+			return c.String(500, "Error removing preview environment")
+		}
+		if preview.IngressRuleID != nil {
+			err = server.WorkerManager.EnqueueIngressRuleDeleteRequest(*preview.IngressRuleID)
+			if err != nil {
+				// cleanup cronjob will retry
+				logger.HTTPLoggerError.Println("failed to queue deletion of ingress rule of preview ", preview.Domain, " ", err.Error())
+			}
+		}
+		return c.String(200, "OK - Preview removal triggered")
+	}
+
+	if event.isFromFork() {
+		return c.String(200, "OK - Preview is not supported for pull requests from forks")
+	}
+	if isPreviewExist && preview.Status == core.ApplicationPreviewStatusDeleting {
+		return c.String(409, "Preview environment is being removed, push again after a minute")
+	}
+	// rebuild the existing preview
+	if isPreviewExist {
+		previewApplication := &core.Application{
+			ID: preview.PreviewApplicationID,
+		}
+		tx := db.Begin()
+		deploymentId, err := previewApplication.RebuildApplication(ctx, *tx, event.HeadCommitHash)
+		if err != nil {
+			tx.Rollback()
+			return errors.New("failed to create new deployment of preview")
+		}
+		err = preview.UpdateCommit(ctx, *tx, event.HeadBranch, event.HeadCommitHash)
+		if err != nil {
+			tx.Rollback()
+			return errors.New("failed to update preview environment")
+		}
+		err = tx.Commit().Error
+		if err != nil {
+			tx.Rollback()
+			return errors.New("failed to create new deployment of preview due to database error")
+		}
+		err = server.WorkerManager.EnqueueBuildApplicationRequest(previewApplication.ID, deploymentId)
+		if err != nil {
+			return errors.New("failed to queue build request")
+		}
+		return c.String(200, "OK - Preview rebuild triggered")
+	}
+	// create new preview
+	swarmManager, err := core.FetchSwarmManager(&db)
+	if err != nil {
+		return c.String(500, "Failed to fetch swarm manager")
+	}
+	dockerManager, err := manager.DockerClient(ctx, swarmManager)
+	if err != nil {
+		return c.String(500, "Failed to fetch docker manager")
+	}
+	tx := db.Begin()
+	preview, err = application.CreatePreview(ctx, *tx, *dockerManager, event.Number, event.HeadBranch, event.HeadCommitHash)
+	if err != nil {
+		tx.Rollback()
+		return c.String(400, "Failed to create preview environment > "+err.Error())
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		tx.Rollback()
+		return errors.New("failed to create preview environment due to database error")
+	}
+	latestDeploymentId, err := core.FindLatestDeploymentIDByApplicationId(ctx, db, preview.PreviewApplicationID)
+	if err != nil {
+		return errors.New("failed to fetch latest deployment of preview")
+	}
+	err = server.WorkerManager.EnqueueBuildApplicationRequest(preview.PreviewApplicationID, latestDeploymentId)
+	if err != nil {
+		return errors.New("failed to queue build request")
+	}
+	err = server.WorkerManager.EnqueueIngressRuleApplyRequest(*preview.IngressRuleID)
+	if err != nil {
+		return errors.New("failed to queue ingress rule apply request")
+	}
+	return c.String(200, "OK - Preview created at http://"+preview.Domain)
+}
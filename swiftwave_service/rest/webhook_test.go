@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/service_manager"
+)
+
+func TestRedeployAppWebhookSignature(t *testing.T) {
+	db := newTestDB(t, &core.Application{}, &core.Deployment{})
+	server := &Server{ServiceManager: &service_manager.ServiceManager{DbClient: db}}
+	e := echo.New()
+	e.POST("/webhook/redeploy-app/:app-id/:webhook-token", server.redeployApp)
+
+	application := core.Application{ID: "app", Name: "app", WebhookToken: "token", WebhookSecret: testWebhookSecret}
+	assert.NoError(t, db.Create(&application).Error)
+	deployment := core.Deployment{ID: "deployment", ApplicationID: application.ID, UpstreamType: core.UpstreamTypeGit,
+		RepositoryOwner: "owner", RepositoryName: "app", RepositoryBranch: "main", Status: core.DeploymentStatusDeployed}
+	assert.NoError(t, db.Create(&deployment).Error)
+
+	// push of another branch, so that no rebuild is triggered
+	body := `{"ref":"refs/heads/other","after":"abc123","repository":{"full_name":"owner/app"}}`
+	send := func(token string, signature string) (int, string, string) {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/redeploy-app/app/"+token, strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "push")
+		if signature != "" {
+			req.Header.Set("X-Hub-Signature-256", signature)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String(), rec.Header().Get("Warning")
+	}
+	allowUnsignedWebhooks := func(allow bool) {
+		assert.NoError(t, application.SetAllowUnsignedWebhooks(context.Background(), db, allow))
+	}
+	validSignature := "sha256=" + signPayload([]byte(body), testWebhookSecret)
+	invalidSignature := "sha256=" + signPayload([]byte(body), "another-secret")
+
+	code, _, _ := send("wrong-token", validSignature)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// unsigned webhooks are rejected by default
+	code, _, _ = send("token", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _, _ = send("token", invalidSignature)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, response, warning := send("token", validSignature)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "OK - No rebuild", response)
+	assert.Empty(t, warning)
+
+	// opt-in accepts unsigned webhooks with a warning, but never an invalid signature
+	allowUnsignedWebhooks(true)
+	code, response, warning = send("token", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "OK - No rebuild", response)
+	assert.Equal(t, unsignedWebhookWarning, warning)
+	code, _, _ = send("token", invalidSignature)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _, warning = send("token", validSignature)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, warning)
+
+	// regenerating the webhook token revokes the opt-in
+	assert.NoError(t, application.RegenerateWebhookToken(context.Background(), db))
+	assert.False(t, application.AllowUnsignedWebhooks)
+	var record core.Application
+	assert.NoError(t, record.FindById(context.Background(), db, application.ID))
+	assert.False(t, record.AllowUnsignedWebhooks)
+}
+
+func TestRedeployAppImageWebhook(t *testing.T) {
+	db := newTestDB(t, &core.Application{}, &core.Deployment{})
+	server := &Server{ServiceManager: &service_manager.ServiceManager{DbClient: db}}
+	e := echo.New()
+	e.POST("/webhook/redeploy-app/:app-id/:webhook-token", server.redeployApp)
+
+	assert.NoError(t, db.Create(&core.Application{ID: "app", Name: "app", WebhookToken: "token"}).Error)
+	assert.NoError(t, db.Create(&core.Application{ID: "pinned", Name: "pinned", WebhookToken: "token"}).Error)
+	assert.NoError(t, db.Create(&[]core.Deployment{
+		{ID: "deployment", ApplicationID: "app", UpstreamType: core.UpstreamTypeImage, DockerImage: "ghcr.io/owner/app:v1", Status: core.DeploymentStatusDeployed},
+		{ID: "pinned-deployment", ApplicationID: "pinned", UpstreamType: core.UpstreamTypeImage, DockerImage: "owner/app@sha256:" + strings.Repeat("a", 64), Status: core.DeploymentStatusDeployed},
+	}).Error)
+
+	send := func(appID string, body string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/redeploy-app/"+appID+"/token", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+	tests := []struct {
+		name     string
+		appID    string
+		body     string
+		code     int
+		response string
+	}{
+		{"body mentioning the image is not a push", "app", "owner/app is great", http.StatusBadRequest, ""},
+		{"other repository containing the name", "app", `{"repository":{"repo_name":"attacker/owner/app"},"push_data":{"tag":"v1"}}`, http.StatusOK, "OK - No rebuild"},
+		{"other tag", "app", `{"repository":{"repo_name":"owner/app"},"push_data":{"tag":"v2"}}`, http.StatusOK, "OK - No rebuild"},
+		{"pinned to digest", "pinned", `{"repository":{"repo_name":"owner/app"},"push_data":{"tag":"latest"}}`, http.StatusOK, "OK - Deployment is pinned to a digest, no rebuild"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, response := send(tt.appID, tt.body)
+			assert.Equal(t, tt.code, code)
+			if tt.response != "" {
+				assert.Equal(t, tt.response, response)
+			}
+		})
+	}
+}
@@ -158,6 +158,7 @@ type Service struct {
 	CustomHealthCheck        CustomHealthCheck `yaml:"custom_health_check"`
 	PreferredServerHostnames []string          `yaml:"preferred_server_hostnames"`
 	DockerProxyConfig        DockerProxyConfig `yaml:"docker_proxy_config"`
+	ReleaseCommands          ReleaseCommands   `yaml:"release_commands"`
 }
 
 // DeploymentMode mode of deployment of application (replicated or global)
@@ -170,9 +171,21 @@ const (
 )
 
 type Deploy struct {
-	Mode      DeploymentMode `yaml:"mode"`
-	Replicas  uint           `yaml:"replicas"`
-	Resources Resources      `yaml:"resources"`
+	Mode         DeploymentMode `yaml:"mode"`
+	Replicas     uint           `yaml:"replicas"`
+	Resources    Resources      `yaml:"resources"`
+	UpdateConfig *UpdateConfig  `yaml:"update_config,omitempty"`
+}
+
+// UpdateConfig of the service, same settings are used for rollback
+// Durations are in docker compose format (e.g. 10s, 1m30s)
+type UpdateConfig struct {
+	Parallelism     *uint64 `yaml:"parallelism,omitempty"`
+	Delay           string  `yaml:"delay,omitempty"`
+	Order           string  `yaml:"order,omitempty"`
+	FailureAction   string  `yaml:"failure_action,omitempty"`
+	Monitor         string  `yaml:"monitor,omitempty"`
+	MaxFailureRatio float32 `yaml:"max_failure_ratio,omitempty"`
 }
 
 // Resources for the service
@@ -287,6 +300,13 @@ type CustomHealthCheck struct {
 	Retries              uint64 `yaml:"retries"`                // Consecutive failures needed to report unhealthy
 }
 
+// ReleaseCommands commands run as one-off job with the new version, before and after the rollout
+type ReleaseCommands struct {
+	PreDeploy      string `yaml:"pre_deploy"`
+	PostDeploy     string `yaml:"post_deploy"`
+	TimeoutSeconds uint   `yaml:"timeout_seconds"` // Maximum time to allow each command to run
+}
+
 func (s *Stack) deepCopy() (*Stack, error) {
 	yamlBytes, err := yaml.Marshal(s)
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -41,6 +42,11 @@ func ParseStackYaml(yamlStr string, currentSwiftwaveVersion string) (Stack, erro
 		} else {
 			return Stack{}, errors.New("invalid deploy mode")
 		}
+		if service.Deploy.UpdateConfig != nil {
+			if _, err := service.Deploy.UpdateConfig.ApplicationUpdateConfig(); err != nil {
+				return Stack{}, errors.New("invalid update_config of service " + serviceName + " > " + err.Error())
+			}
+		}
 		service.DockerProxyConfig.Permission.Ping = fillDefaultDockerProxyPermissionIfNotPresent(service.DockerProxyConfig.Permission.Ping)
 		service.DockerProxyConfig.Permission.Version = fillDefaultDockerProxyPermissionIfNotPresent(service.DockerProxyConfig.Permission.Version)
 		service.DockerProxyConfig.Permission.Info = fillDefaultDockerProxyPermissionIfNotPresent(service.DockerProxyConfig.Permission.Info)
@@ -160,6 +166,9 @@ func (s *Stack) FillAndVerifyVariables(variableMapping *map[string]string, servi
 		// inject variable in healthcheck if required
 		newHealthCheckTestCommand := variableFillerHelper(service.CustomHealthCheck.TestCommand, variableMapping)
 		service.CustomHealthCheck.TestCommand = newHealthCheckTestCommand
+		// inject variable in release commands if required
+		service.ReleaseCommands.PreDeploy = variableFillerHelper(service.ReleaseCommands.PreDeploy, variableMapping)
+		service.ReleaseCommands.PostDeploy = variableFillerHelper(service.ReleaseCommands.PostDeploy, variableMapping)
 		stackCopy.Services[serviceName] = service
 	}
 	// check if docs present
@@ -384,6 +393,36 @@ func stringToInteger(str string) (int, error) {
 	return strconv.Atoi(str)
 }
 
+// ApplicationUpdateConfig : convert to application update config, docker's default is used for the missing fields
+func (u *UpdateConfig) ApplicationUpdateConfig() (core.ApplicationUpdateConfig, error) {
+	updateConfig := core.DefaultApplicationUpdateConfig()
+	if u.Parallelism != nil {
+		updateConfig.Parallelism = *u.Parallelism
+	}
+	if u.Delay != "" {
+		delay, err := time.ParseDuration(u.Delay)
+		if err != nil || delay < 0 {
+			return updateConfig, errors.New("invalid delay " + u.Delay)
+		}
+		updateConfig.DelaySeconds = uint64(delay.Seconds())
+	}
+	if u.Order != "" {
+		updateConfig.Order = core.UpdateOrder(u.Order)
+	}
+	if u.FailureAction != "" {
+		updateConfig.FailureAction = core.UpdateFailureAction(u.FailureAction)
+	}
+	if u.Monitor != "" {
+		monitor, err := time.ParseDuration(u.Monitor)
+		if err != nil || monitor < 0 {
+			return updateConfig, errors.New("invalid monitor " + u.Monitor)
+		}
+		updateConfig.MonitorSeconds = uint64(monitor.Seconds())
+	}
+	updateConfig.MaxFailureRatio = u.MaxFailureRatio
+	return updateConfig, updateConfig.Validate()
+}
+
 func fillDefaultDockerProxyPermissionIfNotPresent(val DockerProxyPermissionType) DockerProxyPermissionType {
 	if val == DockerProxyNoPermission || val == DockerProxyReadPermission || val == DockerProxyReadWritePermission {
 		return val
@@ -0,0 +1,129 @@
+package stack_parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/service_manager"
+)
+
+func TestUpdateConfigApplicationUpdateConfig(t *testing.T) {
+	parallelism := uint64(2)
+	tests := []struct {
+		name     string
+		config   UpdateConfig
+		expected core.ApplicationUpdateConfig
+		isValid  bool
+	}{
+		{
+			name:     "defaults for missing fields",
+			config:   UpdateConfig{},
+			expected: core.DefaultApplicationUpdateConfig(),
+			isValid:  true,
+		},
+		{
+			name: "all fields",
+			config: UpdateConfig{
+				Parallelism:     &parallelism,
+				Delay:           "1m30s",
+				Order:           "start-first",
+				FailureAction:   "rollback",
+				Monitor:         "20s",
+				MaxFailureRatio: 0.5,
+			},
+			expected: core.ApplicationUpdateConfig{
+				Parallelism:     2,
+				DelaySeconds:    90,
+				Order:           core.UpdateOrderStartFirst,
+				FailureAction:   core.UpdateFailureActionRollback,
+				MonitorSeconds:  20,
+				MaxFailureRatio: 0.5,
+			},
+			isValid: true,
+		},
+		{name: "invalid delay", config: UpdateConfig{Delay: "10"}},
+		{name: "negative delay", config: UpdateConfig{Delay: "-5s"}},
+		{name: "invalid monitor", config: UpdateConfig{Monitor: "soon"}},
+		{name: "invalid order", config: UpdateConfig{Order: "random"}},
+		{name: "invalid failure action", config: UpdateConfig{FailureAction: "retry"}},
+		{name: "max failure ratio above 1", config: UpdateConfig{MaxFailureRatio: 1.5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updateConfig, err := test.config.ApplicationUpdateConfig()
+			if !test.isValid {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.expected, updateConfig)
+			}
+		})
+	}
+}
+
+func TestParseStackYamlUpdateConfig(t *testing.T) {
+	stack, err := ParseStackYaml(`
+services:
+  web:
+    image: nginx
+    deploy:
+      update_config:
+        parallelism: 2
+        order: start-first
+`, "develop")
+	if !assert.NoError(t, err) {
+		return
+	}
+	service, ok := stack.Services["{{STACK_NAME}}_web"]
+	if !assert.True(t, ok) {
+		return
+	}
+	if assert.NotNil(t, service.Deploy.UpdateConfig) {
+		assert.Equal(t, uint64(2), *service.Deploy.UpdateConfig.Parallelism)
+		assert.Equal(t, "start-first", service.Deploy.UpdateConfig.Order)
+	}
+
+	_, err = ParseStackYaml(`
+services:
+  web:
+    image: nginx
+    deploy:
+      update_config:
+        failure_action: retry
+`, "develop")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid update_config of service web")
+	}
+}
+
+func TestStackReleaseCommandsAreFilledWithVariables(t *testing.T) {
+	stack, err := ParseStackYaml(`
+services:
+  web:
+    image: app
+    release_commands:
+      pre_deploy: ./migrate --db {{DB_NAME}}
+      post_deploy: ./notify {{STACK_NAME}}
+      timeout_seconds: 120
+`, "develop")
+	if !assert.NoError(t, err) {
+		return
+	}
+	filledStack, err := stack.FillAndVerifyVariables(&map[string]string{
+		"STACK_NAME": "shop",
+		"DB_NAME":    "orders",
+	}, service_manager.ServiceManager{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	service, ok := filledStack.Services["shop_web"]
+	if assert.True(t, ok) {
+		assert.Equal(t, ReleaseCommands{
+			PreDeploy:      "./migrate --db orders",
+			PostDeploy:     "./notify shop",
+			TimeoutSeconds: 120,
+		}, service.ReleaseCommands)
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"sync"
+	"time"
+)
+
+// interval to recheck the build servers, when all of them are running max no of builds
+var buildServerPollInterval = 3 * time.Second
+
+// buildServerScheduler : keeps track of the builds running on the build servers
+type buildServerScheduler struct {
+	mutex         sync.Mutex
+	runningBuilds map[uint]uint // server id -> no of running builds
+}
+
+var buildScheduler = &buildServerScheduler{
+	runningBuilds: make(map[uint]uint),
+}
+
+// errNoBuildServerAvailable : build servers are configured, but none of them can run the build now
+var errNoBuildServerAvailable = errors.New("no build server is available, all build servers are either offline or in maintenance mode")
+
+// acquire : reserves a slot on the least loaded build server
+// if all the build servers are running max no of builds, it will wait till any slot is free or context is cancelled
+// if all the build servers are offline or in maintenance mode, the build fails right away with errNoBuildServerAvailable, also while waiting
+// It never falls back to the local docker daemon, as the builds are kept off the swarm managers once build servers are configured
+func (s *buildServerScheduler) acquire(ctx context.Context, fetchServers func() ([]core.Server, error), onWait func()) (*core.Server, error) {
+	isWaiting := false
+	for {
+		servers, err := fetchServers()
+		if err != nil {
+			return nil, err
+		}
+		if len(servers) == 0 {
+			return nil, errNoBuildServerAvailable
+		}
+		server := s.reserve(servers)
+		if server != nil {
+			return server, nil
+		}
+		if !isWaiting {
+			isWaiting = true
+			onWait()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.New("build cancelled while waiting for a build server")
+		case <-time.After(buildServerPollInterval):
+		}
+	}
+}
+
+// reserve : picks the build server with the lowest ratio of running builds to max concurrent builds
+// ties are resolved by the no of running builds, then by the order of servers
+func (s *buildServerScheduler) reserve(servers []core.Server) *core.Server {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var selected *core.Server
+	var selectedRunning, selectedLimit uint
+	for i := range servers {
+		limit := servers[i].MaxConcurrentBuilds
+		if limit == 0 {
+			limit = 1
+		}
+		running := s.runningBuilds[servers[i].ID]
+		if running >= limit {
+			continue
+		}
+		if selected == nil ||
+			uint64(running)*uint64(selectedLimit) < uint64(selectedRunning)*uint64(limit) ||
+			(uint64(running)*uint64(selectedLimit) == uint64(selectedRunning)*uint64(limit) && running < selectedRunning) {
+			selected = &servers[i]
+			selectedRunning = running
+			selectedLimit = limit
+		}
+	}
+	if selected != nil {
+		s.runningBuilds[selected.ID]++
+	}
+	return selected
+}
+
+// release : frees the slot reserved on the build server
+func (s *buildServerScheduler) release(serverId uint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.runningBuilds[serverId] <= 1 {
+		delete(s.runningBuilds, serverId)
+		return
+	}
+	s.runningBuilds[serverId]--
+}
@@ -0,0 +1,211 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+func newTestBuildScheduler() *buildServerScheduler {
+	return &buildServerScheduler{runningBuilds: make(map[uint]uint)}
+}
+
+func buildServer(id uint, maxConcurrentBuilds uint) core.Server {
+	return core.Server{ID: id, MaxConcurrentBuilds: maxConcurrentBuilds}
+}
+
+func TestBuildServerSchedulerReserve(t *testing.T) {
+	tests := []struct {
+		name          string
+		servers       []core.Server
+		runningBuilds map[uint]uint
+		expected      uint // 0, if no server should be reserved
+	}{
+		{
+			name:     "no servers",
+			servers:  []core.Server{},
+			expected: 0,
+		},
+		{
+			name:     "first server on tie",
+			servers:  []core.Server{buildServer(1, 2), buildServer(2, 2)},
+			expected: 1,
+		},
+		{
+			name:          "least loaded server",
+			servers:       []core.Server{buildServer(1, 2), buildServer(2, 2)},
+			runningBuilds: map[uint]uint{1: 1},
+			expected:      2,
+		},
+		{
+			name:          "lowest ratio of running builds to limit",
+			servers:       []core.Server{buildServer(1, 2), buildServer(2, 4)},
+			runningBuilds: map[uint]uint{1: 1, 2: 1},
+			expected:      2,
+		},
+		{
+			name:          "fewer running builds on same ratio",
+			servers:       []core.Server{buildServer(1, 4), buildServer(2, 2)},
+			runningBuilds: map[uint]uint{1: 2, 2: 1},
+			expected:      2,
+		},
+		{
+			name:          "idle servers tie regardless of limit",
+			servers:       []core.Server{buildServer(1, 1), buildServer(2, 8)},
+			runningBuilds: map[uint]uint{},
+			expected:      1,
+		},
+		{
+			name:          "servers at limit are skipped",
+			servers:       []core.Server{buildServer(1, 1), buildServer(2, 3)},
+			runningBuilds: map[uint]uint{1: 1, 2: 2},
+			expected:      2,
+		},
+		{
+			name:          "all servers at limit",
+			servers:       []core.Server{buildServer(1, 1), buildServer(2, 2)},
+			runningBuilds: map[uint]uint{1: 1, 2: 2},
+			expected:      0,
+		},
+		{
+			name:     "limit 0 allows one build",
+			servers:  []core.Server{buildServer(1, 0)},
+			expected: 1,
+		},
+		{
+			name:          "limit 0 is full with one build",
+			servers:       []core.Server{buildServer(1, 0)},
+			runningBuilds: map[uint]uint{1: 1},
+			expected:      0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestBuildScheduler()
+			for id, running := range test.runningBuilds {
+				s.runningBuilds[id] = running
+			}
+			server := s.reserve(test.servers)
+			if test.expected == 0 {
+				assert.Nil(t, server)
+				assert.Equal(t, test.runningBuilds[1], s.runningBuilds[1])
+				return
+			}
+			if assert.NotNil(t, server) {
+				assert.Equal(t, test.expected, server.ID)
+				assert.Equal(t, test.runningBuilds[test.expected]+1, s.runningBuilds[test.expected])
+			}
+		})
+	}
+}
+
+func TestBuildServerSchedulerSpreadsAndReleases(t *testing.T) {
+	s := newTestBuildScheduler()
+	servers := []core.Server{buildServer(1, 1), buildServer(2, 2)}
+	reserved := make([]uint, 0)
+	for {
+		server := s.reserve(servers)
+		if server == nil {
+			break
+		}
+		reserved = append(reserved, server.ID)
+	}
+	assert.Equal(t, []uint{1, 2, 2}, reserved)
+
+	s.release(2)
+	assert.Equal(t, uint(1), s.runningBuilds[2])
+	server := s.reserve(servers)
+	if assert.NotNil(t, server) {
+		assert.Equal(t, uint(2), server.ID)
+	}
+
+	s.release(1)
+	_, ok := s.runningBuilds[1]
+	assert.False(t, ok, "server without running builds should be removed")
+	// releasing more than reserved doesn't underflow
+	s.release(1)
+	_, ok = s.runningBuilds[1]
+	assert.False(t, ok)
+	server = s.reserve(servers)
+	if assert.NotNil(t, server) {
+		assert.Equal(t, uint(1), server.ID)
+	}
+}
+
+func TestBuildServerSchedulerAcquire(t *testing.T) {
+	previousInterval := buildServerPollInterval
+	buildServerPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		buildServerPollInterval = previousInterval
+	})
+	servers := []core.Server{buildServer(1, 1)}
+	fetchServers := func() ([]core.Server, error) {
+		return servers, nil
+	}
+
+	t.Run("fails right away if all build servers are offline or in maintenance", func(t *testing.T) {
+		s := newTestBuildScheduler()
+		var waitCount int32
+		_, err := s.acquire(context.Background(), func() ([]core.Server, error) {
+			return []core.Server{}, nil
+		}, func() {
+			atomic.AddInt32(&waitCount, 1)
+		})
+		assert.ErrorIs(t, err, errNoBuildServerAvailable)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&waitCount))
+	})
+
+	t.Run("reserves a free slot", func(t *testing.T) {
+		s := newTestBuildScheduler()
+		server, err := s.acquire(context.Background(), fetchServers, func() {
+			t.Error("should not wait for a free slot")
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint(1), server.ID)
+			assert.Equal(t, uint(1), s.runningBuilds[1])
+		}
+	})
+
+	t.Run("waits till a slot is released", func(t *testing.T) {
+		s := newTestBuildScheduler()
+		s.runningBuilds[1] = 1
+		var waitCount int32
+		go func() {
+			time.Sleep(5 * buildServerPollInterval)
+			s.release(1)
+		}()
+		server, err := s.acquire(context.Background(), fetchServers, func() {
+			atomic.AddInt32(&waitCount, 1)
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint(1), server.ID)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&waitCount), "wait should be reported once")
+	})
+
+	t.Run("fails if build servers go offline while waiting", func(t *testing.T) {
+		s := newTestBuildScheduler()
+		s.runningBuilds[1] = 1
+		var fetchCount int32
+		_, err := s.acquire(context.Background(), func() ([]core.Server, error) {
+			if atomic.AddInt32(&fetchCount, 1) > 2 {
+				return []core.Server{}, nil
+			}
+			return servers, nil
+		}, func() {})
+		assert.ErrorIs(t, err, errNoBuildServerAvailable)
+	})
+
+	t.Run("stops waiting once cancelled", func(t *testing.T) {
+		s := newTestBuildScheduler()
+		s.runningBuilds[1] = 1
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := s.acquire(ctx, fetchServers, cancel)
+		assert.Error(t, err)
+		assert.Equal(t, uint(1), s.runningBuilds[1], "slot of the running build should be kept")
+	})
+}
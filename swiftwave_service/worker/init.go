@@ -47,6 +47,7 @@ func (m Manager) registerWorkerFunctions() {
 	panicOnError(taskQueueClient.RegisterFunction(setupServerQueueName, m.SetupServer))
 	panicOnError(taskQueueClient.RegisterFunction(setupAndEnableProxyQueueName, m.SetupAndEnableProxy))
 	panicOnError(taskQueueClient.RegisterFunction(updateApplicationOnServerScheduleDeploymentUpdateQueueName, m.UpdateApplicationOnServerScheduleDeploymentUpdate))
+	panicOnError(taskQueueClient.RegisterFunction(runApplicationJobQueueName, m.RunApplicationJob))
 	// When adding a new function, add it to the list of Queues() as well
 }
 
@@ -68,6 +69,7 @@ func Queues() []string {
 		setupServerQueueName,
 		setupAndEnableProxyQueueName,
 		updateApplicationOnServerScheduleDeploymentUpdateQueueName,
+		runApplicationJobQueueName,
 	}
 }
 
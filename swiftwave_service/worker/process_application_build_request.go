@@ -10,18 +10,15 @@ import (
 	gitmanager "github.com/swiftwave-org/swiftwave/git_manager"
 	"github.com/swiftwave-org/swiftwave/pubsub"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/manager"
 	"gorm.io/gorm"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 func (m Manager) BuildApplication(request BuildApplicationRequest, ctx context.Context, cancelContext context.CancelFunc) error {
-	// fetch docker manager
-	dockerManager, err := containermanger.NewLocalClient(ctx)
-	if err != nil {
-		return err
-	}
 	isFailed, err := core.IsDeploymentFailed(context.Background(), m.ServiceManager.DbClient, request.DeploymentId)
 	if err == nil {
 		if isFailed {
@@ -51,7 +48,7 @@ func (m Manager) BuildApplication(request BuildApplicationRequest, ctx context.C
 		}
 	}(request.DeploymentId, cancelContext)
 
-	err = m.buildApplicationHelper(request, ctx, cancelContext, dockerManager)
+	err = m.scheduleApplicationBuild(request, ctx, cancelContext)
 	isHelperExited <- true
 	if err != nil {
 		addPersistentDeploymentLog(m.ServiceManager.DbClient, m.ServiceManager.PubSubClient, request.DeploymentId, "Failed to build application\n"+err.Error()+"\n", true)
@@ -68,6 +65,49 @@ func (m Manager) BuildApplication(request BuildApplicationRequest, ctx context.C
 }
 
 // private functions
+
+// scheduleApplicationBuild : runs the build on the least loaded build server
+// if no build server is configured, the build runs on the local docker daemon
+// if build servers are configured but all of them are offline or in maintenance mode, the build fails
+func (m Manager) scheduleApplicationBuild(request BuildApplicationRequest, ctx context.Context, cancelContext context.CancelFunc) error {
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	isBuildServerConfigured, err := core.IsAnyBuildServerConfigured(&dbWithoutTx)
+	if err != nil {
+		return err
+	}
+	if !isBuildServerConfigured {
+		dockerManager, err := containermanger.NewLocalClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = dockerManager.Close()
+		}()
+		return m.buildApplicationHelper(request, ctx, cancelContext, dockerManager)
+	}
+	// reserve a slot on the build server
+	server, err := buildScheduler.acquire(ctx, func() ([]core.Server, error) {
+		return core.FetchBuildServers(&dbWithoutTx)
+	}, func() {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, request.DeploymentId, "All build servers are busy, waiting for a build server to be free\n", false)
+	})
+	if err != nil {
+		return err
+	}
+	defer buildScheduler.release(server.ID)
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, request.DeploymentId, "Building on server > "+server.HostName+"\n", false)
+	// BuildKit session requires a separate connection, so the client should be able to open multiple connections
+	dockerManager, err := manager.DockerClientWithMultipleConnections(ctx, *server)
+	if err != nil {
+		return errors.New("failed to connect to the build server " + server.HostName + "\n" + err.Error())
+	}
+	defer func() {
+		_ = dockerManager.Close()
+	}()
+	return m.buildApplicationHelper(request, ctx, cancelContext, dockerManager)
+}
+
 func (m Manager) buildApplicationHelper(request BuildApplicationRequest, ctx context.Context, cancelContext context.CancelFunc, dockerManager *containermanger.Manager) error {
 	// database client to work without transaction
 	dbWithoutTx := m.ServiceManager.DbClient
@@ -84,30 +124,36 @@ func (m Manager) buildApplicationHelper(request BuildApplicationRequest, ctx con
 	if err != nil {
 		return err
 	}
+	// #####  FOR ROLLBACK  ######
+	// image of the original deployment is reused, so no build or push is required
+	isRollback := deployment.IsRollback()
+	if isRollback {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Rollback to the image of deployment "+deployment.ImageDeploymentID()+", no build is required\n", false)
+	}
 	// #####  FOR IMAGE  ######
 	// build for docker image
-	if deployment.UpstreamType == core.UpstreamTypeImage {
+	if !isRollback && deployment.UpstreamType == core.UpstreamTypeImage {
 		err = m.buildApplicationForDockerImage(deployment, *db, dbWithoutTx, pubSubClient, ctx, cancelContext, dockerManager)
 		if err != nil {
 			return err
 		}
 	}
 	// #####  FOR GIT  ######
-	if deployment.UpstreamType == core.UpstreamTypeGit {
+	if !isRollback && deployment.UpstreamType == core.UpstreamTypeGit {
 		err = m.buildApplicationForGit(deployment, *db, dbWithoutTx, pubSubClient, ctx, cancelContext, dockerManager)
 		if err != nil {
 			return err
 		}
 	}
 	// #####  FOR SOURCE CODE TARBALL  ######
-	if deployment.UpstreamType == core.UpstreamTypeSourceCode {
+	if !isRollback && deployment.UpstreamType == core.UpstreamTypeSourceCode {
 		err = m.buildApplicationForTarball(deployment, *db, dbWithoutTx, pubSubClient, ctx, cancelContext, dockerManager)
 		if err != nil {
 			return err
 		}
 	}
 	// Push image to registry
-	if deployment.UpstreamType == core.UpstreamTypeGit || deployment.UpstreamType == core.UpstreamTypeSourceCode {
+	if !isRollback && (deployment.UpstreamType == core.UpstreamTypeGit || deployment.UpstreamType == core.UpstreamTypeSourceCode) {
 		err = m.pushImageToRegistry(deployment, *db, dbWithoutTx, pubSubClient, ctx, cancelContext, dockerManager)
 		if err != nil {
 			return err
@@ -133,8 +179,39 @@ func (m Manager) buildApplicationHelper(request BuildApplicationRequest, ctx con
 	return err
 }
 
-func (m Manager) buildApplicationForDockerImage(deployment *core.Deployment, _ gorm.DB, dbWithoutTx gorm.DB, pubSubClient pubsub.Client, _ context.Context, _ context.CancelFunc, _ *containermanger.Manager) error {
+func (m Manager) buildApplicationForDockerImage(deployment *core.Deployment, db gorm.DB, dbWithoutTx gorm.DB, pubSubClient pubsub.Client, ctx context.Context, _ context.CancelFunc, dockerManager *containermanger.Manager) error {
 	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "As the upstream type is image, no build is required\n", false)
+	// pin the deployment to the digest of the image, so that rollback deploys the same image even if the tag is moved
+	// digest copied from the previous deployment is stale, so it's always reset
+	imageRegistryUsername := ""
+	imageRegistryPassword := ""
+	if deployment.ImageRegistryCredentialID != nil && *deployment.ImageRegistryCredentialID != 0 {
+		var imageRegistryCredential core.ImageRegistryCredential
+		err := imageRegistryCredential.FindById(ctx, db, *deployment.ImageRegistryCredentialID)
+		if err != nil {
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch image registry credential\n", true)
+			return err
+		}
+		imageRegistryUsername = imageRegistryCredential.Username
+		imageRegistryPassword = imageRegistryCredential.Password
+	}
+	deployment.ImageDigest = ""
+	if !strings.Contains(deployment.DockerImage, "@") {
+		digest, err := dockerManager.ImageDigest(ctx, deployment.DockerImage, imageRegistryUsername, imageRegistryPassword)
+		if err != nil {
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to resolve digest of the image, deployment will use the tag and can't be rolled back to\n", false)
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Reason > "+err.Error()+"\n", false)
+		} else {
+			deployment.ImageDigest = digest
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Image digest > "+digest+"\n", false)
+		}
+	}
+	err := deployment.UpdateImageDigest(ctx, dbWithoutTx, deployment.ImageDigest)
+	if err != nil {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to update image digest in database\n", false)
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Reason > "+err.Error()+"\n", true)
+		return err
+	}
 	return nil
 }
 
@@ -142,6 +219,8 @@ func (m Manager) buildApplicationForGit(deployment *core.Deployment, db gorm.DB,
 	gitUsername := ""
 	gitPassword := ""
 	gitPrivateKey := ""
+	// credential can be used for submodules and git lfs on these hosts as well
+	var gitCredentialHosts []string
 
 	if deployment.GitCredentialID != nil {
 		// fetch git credentials
@@ -151,9 +230,15 @@ func (m Manager) buildApplicationForGit(deployment *core.Deployment, db gorm.DB,
 			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch git credentials\n", true)
 			return err
 		}
+		err = gitCredentials.CheckRepositoryAccess(deployment.GitRepositoryURL())
+		if err != nil {
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Git credential is not allowed for the repository host, update the allowed hosts of the credential\n", true)
+			return err
+		}
 		gitUsername = gitCredentials.Username
 		gitPassword = gitCredentials.Password
 		gitPrivateKey = gitCredentials.SshPrivateKey
+		gitCredentialHosts = gitCredentials.AllowedHosts
 	}
 	// create temporary directory for git clone
 	tempDirectory := "/tmp/" + uuid.New().String()
@@ -170,7 +255,16 @@ func (m Manager) buildApplicationForGit(deployment *core.Deployment, db gorm.DB,
 	}(tempDirectory)
 	// clone git repository
 	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Cloning git repository > "+deployment.GitRepositoryURL()+"\n", false)
-	commitHash, commitMessage, err := gitmanager.CloneRepository(deployment.GitRepositoryURL(), deployment.RepositoryBranch, gitUsername, gitPassword, gitPrivateKey, tempDirectory)
+	branch, tag := deployment.GitCloneReference()
+	if tag != "" {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Tag > "+tag+"\n", false)
+	}
+	commitHash, commitMessage, err := gitmanager.CloneRepository(deployment.GitRepositoryURL(), branch, tag, deployment.CommitHash, gitUsername, gitPassword, gitPrivateKey, gitmanager.CloneOptions{
+		Submodules:      deployment.GitCloneConfig.Submodules,
+		LFS:             deployment.GitCloneConfig.LFS,
+		Shallow:         deployment.GitCloneConfig.Shallow,
+		CredentialHosts: gitCredentialHosts,
+	}, tempDirectory)
 	if err != nil {
 		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to clone git repository\n", false)
 		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Reason > "+err.Error()+"\n", true)
@@ -190,19 +284,14 @@ func (m Manager) buildApplicationForGit(deployment *core.Deployment, db gorm.DB,
 	}
 	// build docker image
 	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Started building docker image\n", false)
-	// fetch build args
-	var buildArgs []*core.BuildArg
-	err = db.Where("deployment_id = ?", deployment.ID).Find(&buildArgs).Error
+	// fetch build args, secrets and cache sources
+	buildConfig, err := m.imageBuildConfig(ctx, db, dbWithoutTx, pubSubClient, deployment)
 	if err != nil {
 		return err
 	}
-	var buildArgsMap = make(map[string]string)
-	for _, buildArg := range buildArgs {
-		buildArgsMap[buildArg.Key] = buildArg.Value
-	}
 
 	// start building docker image
-	scanner, err := dockerManager.CreateImageWithContext(ctx, deployment.Dockerfile, buildArgsMap, tempDirectory, deployment.CodePath, deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI()))
+	scanner, err := dockerManager.CreateImageWithContext(ctx, deployment.Dockerfile, buildConfig, tempDirectory, deployment.CodePath, deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI()))
 	if err != nil {
 		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to build docker image\n", true)
 		return err
@@ -266,19 +355,14 @@ func (m Manager) buildApplicationForTarball(deployment *core.Deployment, db gorm
 	}
 	// build docker image
 	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Started building docker image\n", false)
-	// fetch build args
-	var buildArgs []*core.BuildArg
-	err = db.Where("deployment_id = ?", deployment.ID).Find(&buildArgs).Error
+	// fetch build args, secrets and cache sources
+	buildConfig, err := m.imageBuildConfig(ctx, db, dbWithoutTx, pubSubClient, deployment)
 	if err != nil {
 		return err
 	}
-	var buildArgsMap = make(map[string]string)
-	for _, buildArg := range buildArgs {
-		buildArgsMap[buildArg.Key] = buildArg.Value
-	}
 
 	// start building docker image
-	scanner, err := dockerManager.CreateImageWithContext(ctx, deployment.Dockerfile, buildArgsMap, tempDirectory, deployment.CodePath, deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI()))
+	scanner, err := dockerManager.CreateImageWithContext(ctx, deployment.Dockerfile, buildConfig, tempDirectory, deployment.CodePath, deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI()))
 	if err != nil {
 		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to build docker image\n", true)
 		return err
@@ -317,6 +401,46 @@ func (m Manager) buildApplicationForTarball(deployment *core.Deployment, db gorm
 	}
 }
 
+// imageBuildConfig : config to build the image of the deployment
+// The image of the current deployment of the application is used as the build cache
+func (m Manager) imageBuildConfig(ctx context.Context, db gorm.DB, dbWithoutTx gorm.DB, pubSubClient pubsub.Client, deployment *core.Deployment) (containermanger.ImageBuildConfig, error) {
+	buildConfig := containermanger.ImageBuildConfig{
+		BuildArgs: make(map[string]string),
+		Target:    deployment.DockerfileTarget,
+		CacheFrom: make([]string, 0),
+		RegistryCredentials: []containermanger.RegistryCredential{
+			{
+				Host:     m.Config.ImageRegistryURI(),
+				Username: m.Config.ImageRegistryUsername(),
+				Password: m.Config.ImageRegistryPassword(),
+			},
+		},
+	}
+	buildArgs, err := core.FindBuildArgsByDeploymentId(ctx, db, deployment.ID)
+	if err != nil {
+		return buildConfig, err
+	}
+	for _, buildArg := range buildArgs {
+		buildConfig.BuildArgs[buildArg.Key] = buildArg.Value
+	}
+	buildConfig.Secrets, err = core.FetchBuildSecretValues(ctx, db, deployment)
+	if err != nil {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch build secrets\n", false)
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Reason > "+err.Error()+"\n", true)
+		return buildConfig, err
+	}
+	if deployment.DockerfileTarget != "" {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Dockerfile target > "+deployment.DockerfileTarget+"\n", false)
+	}
+	currentDeployment, err := core.FindCurrentDeployedDeploymentByApplicationId(ctx, db, deployment.ApplicationID)
+	if err == nil && currentDeployment.ID != deployment.ID && currentDeployment.UpstreamType != core.UpstreamTypeImage {
+		cacheImage := currentDeployment.DeployableDockerImageURI(m.Config.ImageRegistryURI())
+		buildConfig.CacheFrom = append(buildConfig.CacheFrom, cacheImage)
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Build cache > "+cacheImage+"\n", false)
+	}
+	return buildConfig, nil
+}
+
 func (m Manager) pushImageToRegistry(deployment *core.Deployment, _ gorm.DB, dbWithoutTx gorm.DB, pubSubClient pubsub.Client, ctx context.Context, _ context.CancelFunc, dockerManager *containermanger.Manager) error {
 	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Image : "+deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI())+"\n", false)
 	scanner, err := dockerManager.PushImage(ctx, deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI()), m.Config.ImageRegistryUsername(), m.Config.ImageRegistryPassword())
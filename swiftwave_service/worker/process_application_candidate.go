@@ -0,0 +1,248 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	containermanger "github.com/swiftwave-org/swiftwave/container_manager"
+	haproxymanager "github.com/swiftwave-org/swiftwave/haproxy_manager"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"gorm.io/gorm"
+)
+
+// deployCandidateHelper : deploy the new version as a separate service next to the current version
+// Both the services are registered in the same haproxy backend, traffic is split by the weight of the servers
+func (m Manager) deployCandidateHelper(db *gorm.DB, application *core.Application, deployment *core.Deployment, service containermanger.Service, imageRegistryUsername string, imageRegistryPassword string, refetchImage bool, dockerManager *containermanger.Manager, haproxyManagers []*haproxymanager.Manager) error {
+	ctx := context.Background()
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Deploying as candidate next to the current version ["+string(application.DeploymentStrategy)+" strategy]\n", false)
+	// replace the existing candidate, traffic split is kept as it is
+	trafficPercent := application.DeploymentStrategy.InitialCandidateTrafficPercent()
+	if application.HasCandidate() && *application.CandidateDeploymentID != deployment.ID {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Replacing the existing candidate deployment "+*application.CandidateDeploymentID+"\n", false)
+		previousCandidate := &core.Deployment{}
+		previousCandidate.ID = *application.CandidateDeploymentID
+		err := previousCandidate.UpdateStatus(ctx, *db, core.DeploymentStalled)
+		if err != nil {
+			return err
+		}
+		trafficPercent = application.CandidateTrafficPercent
+	}
+	err := application.SetCandidate(ctx, *db, deployment.ID, trafficPercent)
+	if err != nil {
+		return err
+	}
+	err = deployment.UpdateStatus(ctx, *db, core.DeploymentStatusCandidate)
+	if err != nil {
+		return err
+	}
+	// create or update the candidate service
+	service.Name = application.CandidateServiceName()
+	_, err = dockerManager.GetService(service.Name)
+	if err != nil {
+		err = dockerManager.CreateService(service, imageRegistryUsername, imageRegistryPassword, refetchImage)
+	} else {
+		err = dockerManager.UpdateService(service, imageRegistryUsername, imageRegistryPassword, refetchImage)
+	}
+	if err != nil {
+		return err
+	}
+	err = db.Commit().Error
+	if err != nil {
+		m.removeCandidate(application, deployment.ID, dockerManager, haproxyManagers)
+		return err
+	}
+	// remove the candidate if it doesn't become healthy, current version keeps serving the traffic
+	// watched in background, so that the worker is free for other deployments
+	if application.AutoRollback.Enabled {
+		go m.watchCandidateHealthInBackground(*application, *deployment, dockerManager, haproxyManagers)
+		return nil
+	}
+	return m.registerCandidate(application, deployment.ID, haproxyManagers)
+}
+
+// updateCandidateService : apply the current configuration of the application to the candidate service
+// Candidate keeps its own image, replicas follow the application, so it's scaled down as well while the application is sleeping
+// Errors are only logged, as the current version is not affected
+func (m Manager) updateCandidateService(application *core.Application, deploymentID string, dockerManager *containermanger.Manager) {
+	ctx := context.Background()
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	candidateDeployment := &core.Deployment{}
+	err := candidateDeployment.FindById(ctx, dbWithoutTx, *application.CandidateDeploymentID)
+	if err != nil {
+		log.Println("failed to fetch candidate deployment", err)
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Failed to fetch candidate deployment\n", false)
+		return
+	}
+	service, imageRegistryUsername, imageRegistryPassword, refetchImage, err := m.applicationService(ctx, dbWithoutTx, application, candidateDeployment, dockerManager, func(content string) {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, content, false)
+	})
+	if err == nil {
+		service.Name = application.CandidateServiceName()
+		err = dockerManager.UpdateService(service, imageRegistryUsername, imageRegistryPassword, refetchImage)
+	}
+	if err != nil {
+		log.Println("failed to update candidate service > "+application.CandidateServiceName(), err)
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Failed to update candidate service\n", false)
+		return
+	}
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Candidate updated with the current configuration\n", false)
+}
+
+// registerCandidate : register the candidate servers in haproxy, traffic is split as per the application
+func (m Manager) registerCandidate(application *core.Application, deploymentID string, haproxyManagers []*haproxymanager.Manager) error {
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	err := runActionsInHAProxyNodes(haproxyManagers, func(haproxyManager *haproxymanager.Manager, transactionId string) error {
+		return m.addCandidateInHAProxy(haproxyManager, transactionId, application)
+	})
+	if err != nil {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Failed to register candidate in proxy\n", false)
+		return err
+	}
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Candidate deployed successfully, routing "+strconv.Itoa(int(application.CandidateTrafficPercent))+"% of the traffic to it\n", false)
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Adjust the traffic split, promote or abort the candidate from the application page\n", true)
+	return nil
+}
+
+// addCandidateInHAProxy : add the candidate servers in the backends of the application and split the traffic
+func (m Manager) addCandidateInHAProxy(haproxyManager *haproxymanager.Manager, transactionId string, application *core.Application) error {
+	ingressRules, err := core.FetchIngressRulesWithTargetPortAndProtocolOnly(context.Background(), m.ServiceManager.DbClient, application.ID)
+	if err != nil {
+		return err
+	}
+	for _, record := range ingressRules {
+		if record.Protocol == core.UDPProtocol {
+			continue
+		}
+		backendProtocol := ingressRuleProtocolToBackendProtocol(record.Protocol)
+		backendName := haproxyManager.GenerateBackendName(backendProtocol, application.Name, int(record.TargetPort))
+		isBackendExist, err := haproxyManager.IsBackendExist(transactionId, backendName)
+		if err != nil {
+			return err
+		}
+		if !isBackendExist {
+			continue
+		}
+		err = haproxyManager.AddOrUpdateServerTemplate(transactionId, backendProtocol, application.Name, int(record.TargetPort), application.CandidateServiceName(), int(application.ReplicaCount()))
+		if err != nil {
+			return err
+		}
+		err = haproxyManager.SetTrafficSplit(transactionId, backendProtocol, application.Name, int(record.TargetPort), application.CandidateServiceName(), int(application.CandidateTrafficPercent))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeCandidateFromHAProxy : remove the candidate servers from the backends of the application
+func (m Manager) removeCandidateFromHAProxy(haproxyManager *haproxymanager.Manager, transactionId string, application *core.Application) error {
+	ingressRules, err := core.FetchIngressRulesWithTargetPortAndProtocolOnly(context.Background(), m.ServiceManager.DbClient, application.ID)
+	if err != nil {
+		return err
+	}
+	for _, record := range ingressRules {
+		if record.Protocol == core.UDPProtocol {
+			continue
+		}
+		backendProtocol := ingressRuleProtocolToBackendProtocol(record.Protocol)
+		backendName := haproxyManager.GenerateBackendName(backendProtocol, application.Name, int(record.TargetPort))
+		isBackendExist, err := haproxyManager.IsBackendExist(transactionId, backendName)
+		if err != nil {
+			return err
+		}
+		if !isBackendExist {
+			continue
+		}
+		err = haproxyManager.DeleteServerTemplate(transactionId, backendProtocol, application.Name, int(record.TargetPort), application.CandidateServiceName())
+		if err != nil {
+			return err
+		}
+		err = haproxyManager.ResetServerWeight(transactionId, backendProtocol, application.Name, int(record.TargetPort))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeCandidate : remove the candidate from haproxy and swarm, and clear the reference from the application
+// Errors are only logged, as the current version is not affected
+func (m Manager) removeCandidate(application *core.Application, deploymentID string, dockerManager *containermanger.Manager, haproxyManagers []*haproxymanager.Manager) {
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	err := runActionsInHAProxyNodes(haproxyManagers, func(haproxyManager *haproxymanager.Manager, transactionId string) error {
+		return m.removeCandidateFromHAProxy(haproxyManager, transactionId, application)
+	})
+	if err != nil {
+		log.Println("failed to remove candidate from haproxy", err)
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Failed to remove candidate from proxy\n", false)
+	}
+	_, err = dockerManager.GetService(application.CandidateServiceName())
+	if err == nil {
+		err = dockerManager.RemoveService(application.CandidateServiceName())
+		if err != nil {
+			log.Println("failed to remove candidate service > "+application.CandidateServiceName(), err)
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Failed to remove candidate service\n", false)
+		}
+	}
+	err = application.ClearCandidate(context.Background(), dbWithoutTx)
+	if err != nil {
+		log.Println("failed to clear candidate of application", err)
+	}
+}
+
+// promoteCandidateCleanup : the candidate version is serving from the main service after promotion
+// remove the candidate from haproxy and swarm
+func (m Manager) promoteCandidateCleanup(request DeployApplicationRequest, dockerManager *containermanger.Manager, haproxyManagers []*haproxymanager.Manager) {
+	var application core.Application
+	err := application.FindById(context.Background(), m.ServiceManager.DbClient, request.AppId)
+	if err != nil {
+		log.Println("failed to fetch application for candidate cleanup", err)
+		return
+	}
+	if !application.HasCandidate() || *application.CandidateDeploymentID != request.DeploymentId {
+		return
+	}
+	m.removeCandidate(&application, request.DeploymentId, dockerManager, haproxyManagers)
+	addPersistentDeploymentLog(m.ServiceManager.DbClient, m.ServiceManager.PubSubClient, request.DeploymentId, "Candidate promoted successfully\n", true)
+}
+
+// runActionsInHAProxyNodes : run the function in a transaction on each haproxy node
+// Transactions are committed only if the function succeeds on all nodes
+func runActionsInHAProxyNodes(haproxyManagers []*haproxymanager.Manager, innerFunction func(haproxyManager *haproxymanager.Manager, transactionId string) error) error {
+	transactionIdMap := make(map[*haproxymanager.Manager]string)
+	var failedErr error
+	for _, haproxyManager := range haproxyManagers {
+		haproxyTransactionId, err := haproxyManager.FetchNewTransactionId()
+		if err != nil {
+			failedErr = errors.New("failed to create new haproxy transaction")
+			break
+		}
+		transactionIdMap[haproxyManager] = haproxyTransactionId
+		err = innerFunction(haproxyManager, haproxyTransactionId)
+		if err != nil {
+			failedErr = err
+			break
+		}
+	}
+	for haproxyManager, haproxyTransactionId := range transactionIdMap {
+		if failedErr == nil {
+			err := haproxyManager.CommitTransaction(haproxyTransactionId)
+			if err == nil {
+				continue
+			}
+			failedErr = err
+		}
+		err := haproxyManager.DeleteTransaction(haproxyTransactionId)
+		if err != nil {
+			log.Println("failed to rollback haproxy transaction", err)
+		}
+	}
+	return failedErr
+}
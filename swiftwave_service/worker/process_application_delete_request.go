@@ -52,9 +52,18 @@ func (m Manager) DeleteApplication(request DeleteApplicationRequest, ctx context
 	if err != nil {
 		log.Println("[WARN] error deleting application from swarm manager : " + application.Name)
 	}
+	// remove candidate of blue-green or canary deployment
+	if application.HasCandidate() {
+		err = dockerManager.RemoveService(application.CandidateServiceName())
+		if err != nil {
+			log.Println("[WARN] error deleting candidate of application from swarm manager : " + application.Name)
+		}
+	}
 	// remove docker proxy
 	dockerManager.RemoveDockerProxy(application.DockerProxyServiceName())
 	// prune config mounts
 	dockerManager.PruneConfig(application.ID)
+	// prune secrets
+	dockerManager.PruneSecret(application.ID)
 	return nil
 }
@@ -3,11 +3,13 @@ package worker
 import (
 	"context"
 	"errors"
+	"fmt"
 	haproxymanager "github.com/swiftwave-org/swiftwave/haproxy_manager"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/logger"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/manager"
 	"log"
 	"strings"
+	"time"
 
 	containermanger "github.com/swiftwave-org/swiftwave/container_manager"
 	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
@@ -38,7 +40,14 @@ func (m Manager) DeployApplication(request DeployApplicationRequest, _ context.C
 	if err != nil {
 		return err
 	}
-	err = m.deployApplicationHelper(request, dockerManager, haproxyManagers)
+	isWatchingHealth, err := m.deployApplicationHelper(request, dockerManager, haproxyManagers)
+	if isWatchingHealth {
+		// candidate cleanup and pruning are done by the watcher, configs and secrets are required to rollback
+		return nil
+	}
+	if err == nil && request.PromoteCandidate {
+		m.promoteCandidateCleanup(request, dockerManager, haproxyManagers)
+	}
 	if err != nil {
 		// mark as failed
 		ctx := context.Background()
@@ -52,227 +61,115 @@ func (m Manager) DeployApplication(request DeployApplicationRequest, _ context.C
 	}
 	// prune config mounts
 	dockerManager.PruneConfig(request.AppId)
+	// prune unused secrets
+	dockerManager.PruneSecret(request.AppId)
 	return nil
 }
 
-func (m Manager) deployApplicationHelper(request DeployApplicationRequest, dockerManager *containermanger.Manager, haproxyManagers []*haproxymanager.Manager) error {
+// deployApplicationHelper : deploy the application, isWatchingHealth is true if the health of the new version is being watched in background
+// In that case, the watcher takes care of rollback and pruning of the unused configs and secrets
+func (m Manager) deployApplicationHelper(request DeployApplicationRequest, dockerManager *containermanger.Manager, haproxyManagers []*haproxymanager.Manager) (isWatchingHealth bool, err error) {
 	// context
 	ctx := context.Background()
 	dbWithoutTx := m.ServiceManager.DbClient
-	db := m.ServiceManager.DbClient.Begin()
-	defer func() {
-		db.Rollback()
-	}()
 	// pubSub client
 	pubSubClient := m.ServiceManager.PubSubClient
 	// fetch application
 	var application core.Application
-	err := application.FindById(ctx, *db, request.AppId)
+	err = application.FindById(ctx, dbWithoutTx, request.AppId)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// return nil as don't want to requeue the job
-			return nil
+			return false, nil
 		} else {
-			return err
+			return false, err
 		}
 	}
 	// fetch deployment
 	deployment := &core.Deployment{}
 	deployment.ID = request.DeploymentId
-	err = deployment.FindById(ctx, *db, request.DeploymentId)
+	err = deployment.FindById(ctx, dbWithoutTx, request.DeploymentId)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// create new deployment
-			return nil
+			return false, nil
 		} else {
-			return err
+			return false, err
 		}
 	}
 	// log message
 	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Deployment starting...\n", false)
-	// fetch environment variables
-	environmentVariables, err := core.FindEnvironmentVariablesByApplicationId(ctx, *db, request.AppId)
-	if err != nil {
-		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch environment variables\n", false)
-		return err
+	if deployment.IsRollback() {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Rolling back to the image of deployment "+deployment.ImageDeploymentID()+"\n", false)
 	}
-	var environmentVariablesMap = make(map[string]string)
-	for _, environmentVariable := range environmentVariables {
-		value := environmentVariable.Value
-		if application.DockerProxy.Enabled {
-			value = strings.ReplaceAll(value, "{{DOCKER_PROXY_HOST}}", application.DockerProxyServiceName())
-		}
-		environmentVariablesMap[environmentVariable.Key] = value
-	}
-
-	// fetch persistent volumes
-	persistentVolumeBindings, err := core.FindPersistentVolumeBindingsByApplicationId(ctx, *db, request.AppId)
+	// record the config of the application, to compare the deployments later
+	// saved outside the transaction, so that it's available for failed deployments as well
+	configSnapshot, err := core.FetchApplicationConfigSnapshot(ctx, dbWithoutTx, application.ID)
 	if err != nil {
-		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch persistent volumes\n", false)
-		return err
+		return false, err
 	}
-	var volumeMounts = make([]containermanger.VolumeMount, 0)
-	for _, persistentVolumeBinding := range persistentVolumeBindings {
-		// fetch the volume
-		var persistentVolume core.PersistentVolume
-		err := persistentVolume.FindById(ctx, dbWithoutTx, persistentVolumeBinding.PersistentVolumeID)
-		if err != nil {
-			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch persistent volume\n", false)
-			return err
-		}
-		volumeMounts = append(volumeMounts, containermanger.VolumeMount{
-			Source:   persistentVolume.Name,
-			Target:   persistentVolumeBinding.MountingPath,
-			ReadOnly: false,
-		})
-	}
-	sysctls := make(map[string]string)
-	for _, sysctl := range application.Sysctls {
-		sysctlPart := strings.SplitN(sysctl, "=", 2)
-		if len(sysctlPart) == 2 {
-			sysctls[sysctlPart[0]] = sysctlPart[1]
-		}
-	}
-	command := make([]string, 0)
-	if application.Command != "" {
-		command = strings.Split(application.Command, " ")
-	}
-	// docker image info
-	dockerImageUri := deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI())
-	refetchImage := false
-	imageRegistryUsername := m.Config.ImageRegistryUsername()
-	imageRegistryPassword := m.Config.ImageRegistryPassword()
-
-	if deployment.UpstreamType == core.UpstreamTypeImage {
-		// fetch image registry credential
-		if deployment.ImageRegistryCredentialID != nil && *deployment.ImageRegistryCredentialID != 0 {
-			var imageRegistryCredential core.ImageRegistryCredential
-			err := imageRegistryCredential.FindById(ctx, dbWithoutTx, *deployment.ImageRegistryCredentialID)
-			if err != nil {
-				addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch image registry credential\n", false)
-				return err
-			}
-			imageRegistryUsername = imageRegistryCredential.Username
-			imageRegistryPassword = imageRegistryCredential.Password
-		} else {
-			imageRegistryUsername = ""
-			imageRegistryPassword = ""
-		}
-		refetchImage = true
-	}
-
-	if refetchImage {
-		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "[Notice] Image will be fetched from remote during deployment\n", false)
-	}
-	// create the configs if required
-	configMountRecord, err := core.FindConfigMountsByApplicationId(ctx, *db, request.AppId)
+	err = deployment.SaveConfigSnapshot(ctx, dbWithoutTx, configSnapshot)
 	if err != nil {
-		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch config mounts", false)
-		return err
-	}
-	for _, configMount := range configMountRecord {
-		if strings.Compare(configMount.ConfigID, "") == 0 {
-			// create config
-			configID, err := dockerManager.CreateConfig(configMount.Content, configMount.ApplicationID)
-			if err != nil {
-				addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to create config", false)
-				return err
-			}
-			// update config id
-			err = configMount.UpdateConfigID(ctx, dbWithoutTx, configID)
-			if err != nil {
-				addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to update config id in database", false)
-				return err
-			}
-		}
+		return false, err
 	}
-	// prepare config mounts
-	var configMounts = make([]containermanger.ConfigMount, 0)
-	for _, configMount := range configMountRecord {
-		configMounts = append(configMounts, containermanger.ConfigMount{
-			ConfigID:     configMount.ConfigID,
-			Uid:          configMount.Uid,
-			Gid:          configMount.Gid,
-			FileMode:     configMount.FileMode,
-			MountingPath: configMount.MountingPath,
-		})
-	}
-	// prepare placement constraints
-	var placementConstraints = make([]string, 0)
-	disabledServerHostnames, err := core.FetchDisabledDeploymentServerHostNames(&m.ServiceManager.DbClient)
+	// prepare the service with the image of the deployment
+	// done before starting the transaction, as the pre-deploy command can run for long
+	service, imageRegistryUsername, imageRegistryPassword, refetchImage, err := m.applicationService(ctx, dbWithoutTx, &application, deployment, dockerManager, func(content string) {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, content, false)
+	})
 	if err != nil {
-		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to fetch disabled deployment servers\nPlease check database connection\n", false)
-		return err
-	}
-	for _, hostname := range disabledServerHostnames {
-		placementConstraints = append(placementConstraints, "node.hostname!="+hostname)
-	}
-	for _, preferredServerHostName := range application.PreferredServerHostnames {
-		// if it's not a disabled server, add it to the placement constraints
-		isDisabled := false
-		for _, hostname := range disabledServerHostnames {
-			if strings.Compare(hostname, preferredServerHostName) == 0 {
-				isDisabled = true
-				break
-			}
-		}
-		if !isDisabled {
-			placementConstraints = append(placementConstraints, "node.hostname=="+preferredServerHostName)
+		return false, err
+	}
+	// release commands run only for a new version, not for redeployment of the current version on config changes
+	isNewVersion := deployment.Status == core.DeploymentStatusDeployPending
+	// run pre-deploy command with the new version, deployment is stopped if it fails
+	// for promotion of the candidate, it has already run while deploying the candidate
+	if isNewVersion && !request.PromoteCandidate && application.ReleaseCommands.PreDeploy != "" {
+		err = m.runReleaseCommand(dockerManager, service, "pre-deploy", application.ReleaseCommands.PreDeploy, application.ReleaseCommands.Timeout(), imageRegistryUsername, imageRegistryPassword, refetchImage, deployment.ID)
+		if err != nil {
+			return false, err
 		}
 	}
-	// create service
-	service := containermanger.Service{
-		Name:                 application.Name,
-		Image:                dockerImageUri,
-		Hostname:             application.Hostname,
-		Command:              command,
-		Env:                  environmentVariablesMap,
-		Networks:             []string{m.Config.SystemConfig.NetworkName},
-		DeploymentMode:       containermanger.DeploymentMode(application.DeploymentMode),
-		Replicas:             uint64(application.ReplicaCount()),
-		VolumeMounts:         volumeMounts,
-		ConfigMounts:         configMounts,
-		Capabilities:         application.Capabilities,
-		Sysctls:              sysctls,
-		PlacementConstraints: placementConstraints,
-		ResourceLimit: containermanger.Resource{
-			MemoryMB: application.ResourceLimit.MemoryMB,
-		},
-		ReservedResource: containermanger.Resource{
-			MemoryMB: application.ReservedResource.MemoryMB,
-		},
-		CustomHealthCheck: containermanger.CustomHealthCheck{
-			Enabled:              application.CustomHealthCheck.Enabled,
-			TestCommand:          application.CustomHealthCheck.TestCommand,
-			IntervalSeconds:      application.CustomHealthCheck.IntervalSeconds,
-			TimeoutSeconds:       application.CustomHealthCheck.TimeoutSeconds,
-			StartPeriodSeconds:   application.CustomHealthCheck.StartPeriodSeconds,
-			StartIntervalSeconds: application.CustomHealthCheck.StartIntervalSeconds,
-			Retries:              application.CustomHealthCheck.Retries,
-		},
+	// database changes of the deployment are committed only after the service is updated
+	db := m.ServiceManager.DbClient.Begin()
+	defer func() {
+		db.Rollback()
+	}()
+	// job has no long-running service, the deployed version is used by the next runs
+	if application.DeploymentMode.IsJob() {
+		return false, m.deployJobHelper(db, &application, deployment, dockerManager)
+	}
+	// run the new version as candidate next to the current version, if the application uses blue-green or canary strategy
+	// first deployment and redeployment of the current version are done in place
+	if application.DeploymentStrategy.UsesCandidate() && !request.PromoteCandidate &&
+		deployment.Status == core.DeploymentStatusDeployPending && application.ReplicaCount() > 0 {
+		_, err = dockerManager.GetService(service.Name)
+		if err == nil {
+			return false, m.deployCandidateHelper(db, &application, deployment, service, imageRegistryUsername, imageRegistryPassword, refetchImage, dockerManager, haproxyManagers)
+		}
 	}
 	// find current deployment and mark it as stalled
 	currentDeployment, err := core.FindCurrentDeployedDeploymentByApplicationId(ctx, *db, request.AppId)
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			return err
+			return false, err
 		}
 	} else {
 		// Update status to stalled
 		err = currentDeployment.UpdateStatus(ctx, *db, core.DeploymentStalled)
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
 	// update deployment status
 	err = deployment.UpdateStatus(ctx, *db, core.DeploymentStatusDeployed)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// docker proxy setup
 	if application.DockerProxy.Enabled {
-		err := dockerManager.CreateDockerProxy(application.DockerProxyServiceName(), placementConstraints, containermanger.DockerProxyConfig{
+		err := dockerManager.CreateDockerProxy(application.DockerProxyServiceName(), service.PlacementConstraints, containermanger.DockerProxyConfig{
 			Permission: containermanger.DockerProxyPermission{
 				Ping:         convertDockerPermissionType(application.DockerProxy.Permission.Ping),
 				Version:      convertDockerPermissionType(application.DockerProxy.Permission.Version),
@@ -310,11 +207,12 @@ func (m Manager) deployApplicationHelper(request DeployApplicationRequest, docke
 
 	// check if the service already exists
 	_, err = dockerManager.GetService(service.Name)
-	if err != nil {
+	serviceExists := err == nil
+	if !serviceExists {
 		// create service
 		err = dockerManager.CreateService(service, imageRegistryUsername, imageRegistryPassword, refetchImage)
 		if err != nil {
-			return err
+			return false, err
 		}
 		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Application deployed successfully\n", false)
 	} else {
@@ -322,12 +220,13 @@ func (m Manager) deployApplicationHelper(request DeployApplicationRequest, docke
 		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Application already exists, updating the application\n", false)
 		err = dockerManager.UpdateService(service, imageRegistryUsername, imageRegistryPassword, refetchImage)
 		if err != nil {
-			return err
+			return false, err
 		}
 		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Application re-deployed successfully\n", true)
 	}
 	// commit the changes
 	err = db.Commit().Error
+	isCommitted := err == nil
 	// if error occurs rollback the service
 	if err != nil {
 		// rollback the service
@@ -337,6 +236,34 @@ func (m Manager) deployApplicationHelper(request DeployApplicationRequest, docke
 			log.Println("failed to rollback service > "+service.Name, err)
 			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to rollback service\n", false)
 		}
+	} else if application.ReplicaCount() > 0 && (application.AutoRollback.Enabled || serviceExists) {
+		// watch the new version in background, so that the worker is free for other deployments
+		// previous version is restored if the new version doesn't become healthy
+		// without auto rollback, only the outcome of the swarm update is tracked, as the failure action of update config can rollback or pause it
+		isWatchingHealth = true
+		go m.watchDeploymentHealthInBackground(deploymentHealthWatch{
+			swarmUpdateOnly:       !application.AutoRollback.Enabled,
+			request:               request,
+			application:           application,
+			deployment:            *deployment,
+			previousDeployment:    currentDeployment,
+			service:               service,
+			serviceExists:         serviceExists,
+			runPostDeployCommand:  isNewVersion || request.PromoteCandidate,
+			imageRegistryUsername: imageRegistryUsername,
+			imageRegistryPassword: imageRegistryPassword,
+			refetchImage:          refetchImage,
+			dockerManager:         dockerManager,
+			haproxyManagers:       haproxyManagers,
+		})
+	}
+	// candidate runs with the configuration of the application as well, it's replaced on promotion
+	if isCommitted && !request.PromoteCandidate && application.HasCandidate() && *application.CandidateDeploymentID != deployment.ID {
+		m.updateCandidateService(&application, deployment.ID, dockerManager)
+	}
+	// run post-deploy command, the new version is already serving so failure is only reported
+	if isCommitted && !isWatchingHealth && (isNewVersion || request.PromoteCandidate) {
+		m.runPostDeployCommand(&application, dockerManager, service, imageRegistryUsername, imageRegistryPassword, refetchImage, deployment.ID)
 	}
 
 	if !request.IgnoreProxyUpdate {
@@ -388,6 +315,18 @@ func (m Manager) deployApplicationHelper(request DeployApplicationRequest, docke
 									addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to update replica count\n", false)
 								}
 							}
+							// keep the traffic split with the candidate as per the new replica count
+							if application.HasCandidate() && !request.PromoteCandidate {
+								err = haproxyManager.AddOrUpdateServerTemplate(haproxyTransactionId, backendProtocol, application.Name, int(record.TargetPort), application.CandidateServiceName(), int(application.ReplicaCount()))
+								if err == nil {
+									err = haproxyManager.SetTrafficSplit(haproxyTransactionId, backendProtocol, application.Name, int(record.TargetPort), application.CandidateServiceName(), int(application.CandidateTrafficPercent))
+								}
+								if err != nil {
+									isFailed = true
+									log.Println("failed to update traffic split with candidate", err)
+									addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to update traffic split with candidate\n", false)
+								}
+							}
 						}
 					}
 				}
@@ -415,9 +354,342 @@ func (m Manager) deployApplicationHelper(request DeployApplicationRequest, docke
 	} else {
 		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "[Notice] Ignoring proxy update as it's not requested\n", false)
 	}
+	return isWatchingHealth, nil
+}
+
+// applicationService : prepare the swarm service of the application with the image of the deployment
+// Secrets and configs are created if required, unused ones are pruned after deployment
+func (m Manager) applicationService(ctx context.Context, db gorm.DB, application *core.Application, deployment *core.Deployment, dockerManager *containermanger.Manager, addLog func(content string)) (containermanger.Service, string, string, bool, error) {
+	dbWithoutTx := m.ServiceManager.DbClient
+	// fetch environment variables
+	environmentVariables, err := core.FindEnvironmentVariablesByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		addLog("Failed to fetch environment variables\n")
+		return containermanger.Service{}, "", "", false, err
+	}
+	var environmentVariablesMap = make(map[string]string)
+	var secretMounts = make([]containermanger.SecretMount, 0)
+	for _, environmentVariable := range environmentVariables {
+		value := environmentVariable.Value
+		if application.DockerProxy.Enabled {
+			value = strings.ReplaceAll(value, "{{DOCKER_PROXY_HOST}}", application.DockerProxyServiceName())
+		}
+		if !environmentVariable.IsSecret {
+			environmentVariablesMap[environmentVariable.Key] = value
+			continue
+		}
+		// create a new secret on each deployment, so that updated value gets rotated
+		// unused secrets will be pruned after deployment
+		secretID, err := dockerManager.CreateSecret(value, application.ID)
+		if err != nil {
+			addLog("Failed to create secret for " + environmentVariable.Key + "\n")
+			return containermanger.Service{}, "", "", false, err
+		}
+		secretMounts = append(secretMounts, containermanger.SecretMount{
+			SecretID: secretID,
+			FileName: environmentVariable.Key,
+			Uid:      0,
+			Gid:      0,
+			FileMode: 0444,
+		})
+		if environmentVariable.SecretFileEnv {
+			environmentVariablesMap[environmentVariable.SecretFileEnvKey()] = environmentVariable.SecretFilePath()
+		}
+	}
+
+	// fetch persistent volumes
+	persistentVolumeBindings, err := core.FindPersistentVolumeBindingsByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		addLog("Failed to fetch persistent volumes\n")
+		return containermanger.Service{}, "", "", false, err
+	}
+	var volumeMounts = make([]containermanger.VolumeMount, 0)
+	for _, persistentVolumeBinding := range persistentVolumeBindings {
+		// fetch the volume
+		var persistentVolume core.PersistentVolume
+		err := persistentVolume.FindById(ctx, dbWithoutTx, persistentVolumeBinding.PersistentVolumeID)
+		if err != nil {
+			addLog("Failed to fetch persistent volume\n")
+			return containermanger.Service{}, "", "", false, err
+		}
+		volumeMounts = append(volumeMounts, containermanger.VolumeMount{
+			Source:   persistentVolume.Name,
+			Target:   persistentVolumeBinding.MountingPath,
+			ReadOnly: false,
+		})
+	}
+	sysctls := make(map[string]string)
+	for _, sysctl := range application.Sysctls {
+		sysctlPart := strings.SplitN(sysctl, "=", 2)
+		if len(sysctlPart) == 2 {
+			sysctls[sysctlPart[0]] = sysctlPart[1]
+		}
+	}
+	command := make([]string, 0)
+	if application.Command != "" {
+		command = strings.Split(application.Command, " ")
+	}
+	// docker image info
+	dockerImageUri := deployment.DeployableDockerImageURI(m.Config.ImageRegistryURI())
+	refetchImage := false
+	imageRegistryUsername := m.Config.ImageRegistryUsername()
+	imageRegistryPassword := m.Config.ImageRegistryPassword()
+
+	if deployment.UpstreamType == core.UpstreamTypeImage {
+		// fetch image registry credential
+		if deployment.ImageRegistryCredentialID != nil && *deployment.ImageRegistryCredentialID != 0 {
+			var imageRegistryCredential core.ImageRegistryCredential
+			err := imageRegistryCredential.FindById(ctx, dbWithoutTx, *deployment.ImageRegistryCredentialID)
+			if err != nil {
+				addLog("Failed to fetch image registry credential\n")
+				return containermanger.Service{}, "", "", false, err
+			}
+			imageRegistryUsername = imageRegistryCredential.Username
+			imageRegistryPassword = imageRegistryCredential.Password
+		} else {
+			imageRegistryUsername = ""
+			imageRegistryPassword = ""
+		}
+		refetchImage = true
+	}
+
+	if refetchImage {
+		addLog("[Notice] Image will be fetched from remote during deployment\n")
+	}
+	// create the configs if required
+	configMountRecord, err := core.FindConfigMountsByApplicationId(ctx, db, application.ID)
+	if err != nil {
+		addLog("Failed to fetch config mounts")
+		return containermanger.Service{}, "", "", false, err
+	}
+	for _, configMount := range configMountRecord {
+		if strings.Compare(configMount.ConfigID, "") == 0 {
+			// create config
+			configID, err := dockerManager.CreateConfig(configMount.Content, configMount.ApplicationID)
+			if err != nil {
+				addLog("Failed to create config")
+				return containermanger.Service{}, "", "", false, err
+			}
+			// update config id
+			err = configMount.UpdateConfigID(ctx, dbWithoutTx, configID)
+			if err != nil {
+				addLog("Failed to update config id in database")
+				return containermanger.Service{}, "", "", false, err
+			}
+		}
+	}
+	// prepare config mounts
+	var configMounts = make([]containermanger.ConfigMount, 0)
+	for _, configMount := range configMountRecord {
+		configMounts = append(configMounts, containermanger.ConfigMount{
+			ConfigID:     configMount.ConfigID,
+			Uid:          configMount.Uid,
+			Gid:          configMount.Gid,
+			FileMode:     configMount.FileMode,
+			MountingPath: configMount.MountingPath,
+		})
+	}
+	// prepare placement constraints
+	var placementConstraints = make([]string, 0)
+	disabledServerHostnames, err := core.FetchDisabledDeploymentServerHostNames(&m.ServiceManager.DbClient)
+	if err != nil {
+		addLog("Failed to fetch disabled deployment servers\nPlease check database connection\n")
+		return containermanger.Service{}, "", "", false, err
+	}
+	for _, hostname := range disabledServerHostnames {
+		placementConstraints = append(placementConstraints, "node.hostname!="+hostname)
+	}
+	for _, preferredServerHostName := range application.PreferredServerHostnames {
+		// if it's not a disabled server, add it to the placement constraints
+		isDisabled := false
+		for _, hostname := range disabledServerHostnames {
+			if strings.Compare(hostname, preferredServerHostName) == 0 {
+				isDisabled = true
+				break
+			}
+		}
+		if !isDisabled {
+			placementConstraints = append(placementConstraints, "node.hostname=="+preferredServerHostName)
+		}
+	}
+	service := containermanger.Service{
+		Name:                 application.Name,
+		Image:                dockerImageUri,
+		Hostname:             application.Hostname,
+		Command:              command,
+		Env:                  environmentVariablesMap,
+		Networks:             []string{m.Config.SystemConfig.NetworkName},
+		DeploymentMode:       containermanger.DeploymentMode(application.DeploymentMode),
+		Replicas:             uint64(application.ReplicaCount()),
+		VolumeMounts:         volumeMounts,
+		ConfigMounts:         configMounts,
+		SecretMounts:         secretMounts,
+		Capabilities:         application.Capabilities,
+		Sysctls:              sysctls,
+		PlacementConstraints: placementConstraints,
+		ResourceLimit: containermanger.Resource{
+			MemoryMB: application.ResourceLimit.MemoryMB,
+		},
+		ReservedResource: containermanger.Resource{
+			MemoryMB: application.ReservedResource.MemoryMB,
+		},
+		CustomHealthCheck: containermanger.CustomHealthCheck{
+			Enabled:              application.CustomHealthCheck.Enabled,
+			TestCommand:          application.CustomHealthCheck.TestCommand,
+			IntervalSeconds:      application.CustomHealthCheck.IntervalSeconds,
+			TimeoutSeconds:       application.CustomHealthCheck.TimeoutSeconds,
+			StartPeriodSeconds:   application.CustomHealthCheck.StartPeriodSeconds,
+			StartIntervalSeconds: application.CustomHealthCheck.StartIntervalSeconds,
+			Retries:              application.CustomHealthCheck.Retries,
+		},
+		UpdateConfig: containermanger.UpdateConfig{
+			Parallelism:     application.UpdateConfig.Parallelism,
+			DelaySeconds:    application.UpdateConfig.DelaySeconds,
+			Order:           string(application.UpdateConfig.Order),
+			FailureAction:   string(application.UpdateConfig.FailureAction),
+			MonitorSeconds:  application.UpdateConfig.MonitorSeconds,
+			MaxFailureRatio: application.UpdateConfig.MaxFailureRatio,
+		},
+	}
+	return service, imageRegistryUsername, imageRegistryPassword, refetchImage, nil
+}
+
+// runReleaseCommand : run the command as one-off job with the image, environment and volumes of the service
+// Output of the command is streamed to the deployment log, returns error if the command doesn't exit with zero code
+func (m Manager) runReleaseCommand(dockerManager *containermanger.Manager, service containermanger.Service, stage string, releaseCommand string, timeout time.Duration, imageRegistryUsername string, imageRegistryPassword string, refetchImage bool, deploymentID string) error {
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Running "+stage+" command > "+releaseCommand+"\n", false)
+	job := service
+	job.Name = service.Name + "-" + stage
+	job.Hostname = ""
+	job.Command = []string{"sh", "-c", releaseCommand}
+	job.CustomHealthCheck = containermanger.CustomHealthCheck{}
+	job.UpdateConfig = containermanger.UpdateConfig{}
+	exitCode, err := dockerManager.RunJob(job, imageRegistryUsername, imageRegistryPassword, refetchImage, timeout, func(line string) {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, line+"\n", false)
+	})
+	if err != nil {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Failed to run "+stage+" command\n", false)
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("%s command exited with code %d", stage, exitCode)
+	}
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Completed "+stage+" command successfully\n", false)
 	return nil
 }
 
+// runPostDeployCommand : run the post-deploy command of the application, if configured
+// The new version is already serving, so failure is only reported
+func (m Manager) runPostDeployCommand(application *core.Application, dockerManager *containermanger.Manager, service containermanger.Service, imageRegistryUsername string, imageRegistryPassword string, refetchImage bool, deploymentID string) {
+	if application.ReleaseCommands.PostDeploy == "" {
+		return
+	}
+	err := m.runReleaseCommand(dockerManager, service, "post-deploy", application.ReleaseCommands.PostDeploy, application.ReleaseCommands.Timeout(), imageRegistryUsername, imageRegistryPassword, refetchImage, deploymentID)
+	if err != nil {
+		addPersistentDeploymentLog(m.ServiceManager.DbClient, m.ServiceManager.PubSubClient, deploymentID, "[Warning] Post-deploy command failed, the new version is kept running > "+err.Error()+"\n", false)
+	}
+}
+
+// watchDeploymentHealth : wait till all the tasks of the new version are running and stay healthy
+// Returns error if it doesn't happen within the window, or swarm pauses or rolls back the update due to task failures
+// If swarm has already rolled back the update as per the failure action, rolledBackBySwarm will be true
+// Returns errDeploymentSuperseded as soon as isActive reports that the deployment has been replaced
+func (m Manager) watchDeploymentHealth(dockerManager *containermanger.Manager, serviceName string, window time.Duration, deploymentID string, isActive func() bool) (rolledBackBySwarm bool, err error) {
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Waiting up to "+window.String()+" for the application to become healthy\n", false)
+	// tasks should be healthy for this duration to consider the deployment successful
+	stablePeriod := window / 2
+	if stablePeriod > 30*time.Second {
+		stablePeriod = 30 * time.Second
+	}
+	deadline := time.Now().Add(window)
+	var healthySince *time.Time
+	lastProgress := ""
+	lastMessage := ""
+	for {
+		if !isActive() {
+			return false, errDeploymentSuperseded
+		}
+		health, healthErr := dockerManager.ServiceHealth(serviceName)
+		if healthErr != nil {
+			log.Println("failed to fetch health of service > "+serviceName, healthErr)
+		} else {
+			if health.Message != "" {
+				lastMessage = health.Message
+			}
+			progress := fmt.Sprintf("%d/%d", health.RunningTasks, health.DesiredTasks)
+			if progress != lastProgress {
+				lastProgress = progress
+				addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Healthy tasks "+progress+"\n", true)
+			}
+			if health.RolledBack {
+				return true, errors.New("update rolled back by swarm due to task failures > " + lastMessage)
+			}
+			if health.UpdatePaused {
+				return false, errors.New("update paused by swarm due to task failures > " + lastMessage)
+			}
+			if health.IsHealthy() {
+				if healthySince == nil {
+					now := time.Now()
+					healthySince = &now
+				}
+				if time.Since(*healthySince) >= stablePeriod {
+					addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deploymentID, "Application is healthy\n", false)
+					return false, nil
+				}
+			} else {
+				healthySince = nil
+			}
+		}
+		if time.Now().After(deadline) {
+			message := "application didn't become healthy within " + window.String()
+			if lastMessage != "" {
+				message += " > " + lastMessage
+			}
+			return false, errors.New(message)
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// watchSwarmUpdate : wait till swarm finishes the update of the service
+// Returns error if swarm pauses or rolls back the update due to task failures, as per the failure action of the update config
+// If swarm doesn't report the outcome within swarmUpdateWatchTimeout, the update is considered successful
+func (m Manager) watchSwarmUpdate(dockerManager *containermanger.Manager, serviceName string, deploymentID string, isActive func() bool) (rolledBackBySwarm bool, err error) {
+	deadline := time.Now().Add(swarmUpdateWatchTimeout)
+	lastMessage := ""
+	for {
+		if !isActive() {
+			return false, errDeploymentSuperseded
+		}
+		health, healthErr := dockerManager.ServiceHealth(serviceName)
+		if healthErr != nil {
+			log.Println("failed to fetch health of service > "+serviceName, healthErr)
+		} else {
+			if health.Message != "" {
+				lastMessage = health.Message
+			}
+			if health.RolledBack {
+				return true, errors.New("update rolled back by swarm due to task failures > " + lastMessage)
+			}
+			if health.UpdatePaused {
+				return false, errors.New("update paused by swarm due to task failures > " + lastMessage)
+			}
+			if health.UpdateCompleted {
+				return false, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			addPersistentDeploymentLog(m.ServiceManager.DbClient, m.ServiceManager.PubSubClient, deploymentID, "[Notice] Swarm didn't report the outcome of the update within "+swarmUpdateWatchTimeout.String()+", stopped watching it\n", false)
+			return false, nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
 // private functions
 func ingressRuleProtocolToBackendProtocol(protocol core.ProtocolType) haproxymanager.BackendProtocol {
 	if protocol == core.HTTPProtocol || protocol == core.HTTPSProtocol {
@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	containermanger "github.com/swiftwave-org/swiftwave/container_manager"
+	haproxymanager "github.com/swiftwave-org/swiftwave/haproxy_manager"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+)
+
+// max duration to track the update of the service, when auto rollback is disabled
+const swarmUpdateWatchTimeout = 30 * time.Minute
+
+// errDeploymentSuperseded : returned by the health watch, if a newer deployment has replaced the watched one
+var errDeploymentSuperseded = errors.New("deployment has been superseded by a newer deployment")
+
+// deploymentHealthWatch : state of the deployment required to act on the result of the health watch
+type deploymentHealthWatch struct {
+	swarmUpdateOnly       bool // only track the outcome of the swarm update, previous version is restored only if swarm rolls back
+	request               DeployApplicationRequest
+	application           core.Application
+	deployment            core.Deployment
+	previousDeployment    *core.Deployment
+	service               containermanger.Service
+	serviceExists         bool
+	runPostDeployCommand  bool
+	imageRegistryUsername string
+	imageRegistryPassword string
+	refetchImage          bool
+	dockerManager         *containermanger.Manager
+	haproxyManagers       []*haproxymanager.Manager
+}
+
+// watchDeploymentHealthInBackground : watch the new version of the application and rollback if it doesn't become healthy
+// If it's the first deployment, there is no version to rollback to. The deployment is marked as failed
+// and the service is kept as it is to inspect the logs
+// With swarmUpdateOnly, the deployment is marked as failed only if swarm pauses or rolls back the update
+func (m Manager) watchDeploymentHealthInBackground(w deploymentHealthWatch) {
+	ctx := context.Background()
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	defer func() {
+		// configs and secrets of the previous version are kept till the watch is over, those are required to rollback
+		w.dockerManager.PruneConfig(w.application.ID)
+		w.dockerManager.PruneSecret(w.application.ID)
+	}()
+	isActive := func() bool {
+		currentDeploymentID, err := core.FindCurrentDeployedDeploymentIDByApplicationId(ctx, dbWithoutTx, w.application.ID)
+		return err == nil && currentDeploymentID == w.deployment.ID
+	}
+	var rolledBackBySwarm bool
+	var err error
+	if w.swarmUpdateOnly {
+		rolledBackBySwarm, err = m.watchSwarmUpdate(w.dockerManager, w.service.Name, w.deployment.ID, isActive)
+	} else {
+		rolledBackBySwarm, err = m.watchDeploymentHealth(w.dockerManager, w.service.Name, w.application.AutoRollback.Window(), w.deployment.ID, isActive)
+	}
+	if errors.Is(err, errDeploymentSuperseded) {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, w.deployment.ID, "Stopped watching the health, as a newer deployment has been started\n", true)
+		return
+	}
+	if err != nil {
+		if w.swarmUpdateOnly && !rolledBackBySwarm {
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, w.deployment.ID, "Auto rollback is disabled, fix the issue and redeploy or rollback the application\n", false)
+		} else if w.serviceExists {
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, w.deployment.ID, "Rolling back the application to the previous version\n", false)
+			var rollbackErr error
+			if !rolledBackBySwarm {
+				rollbackErr = w.dockerManager.RollbackService(w.service.Name)
+			}
+			if rollbackErr != nil {
+				log.Println("failed to rollback service > "+w.service.Name, rollbackErr)
+				addPersistentDeploymentLog(dbWithoutTx, pubSubClient, w.deployment.ID, "Failed to rollback service\n", false)
+			} else if w.previousDeployment != nil && w.previousDeployment.ID != "" {
+				// previous deployment is serving the traffic again
+				rollbackErr = w.previousDeployment.UpdateStatus(ctx, dbWithoutTx, core.DeploymentStatusDeployed)
+				if rollbackErr != nil {
+					log.Println("failed to update previous deployment status to deployed", rollbackErr)
+				}
+			}
+		} else {
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, w.deployment.ID, "No previous version to rollback to, the application is kept running to inspect the logs. Fix the issue and redeploy\n", false)
+		}
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, w.deployment.ID, "Deployment failed > \n"+err.Error()+"\n", true)
+		err = w.deployment.UpdateStatus(ctx, dbWithoutTx, core.DeploymentStatusFailed)
+		if err != nil {
+			log.Println("failed to update deployment status to failed", err)
+		}
+		return
+	}
+	if w.runPostDeployCommand {
+		m.runPostDeployCommand(&w.application, w.dockerManager, w.service, w.imageRegistryUsername, w.imageRegistryPassword, w.refetchImage, w.deployment.ID)
+	}
+	if w.request.PromoteCandidate {
+		m.promoteCandidateCleanup(w.request, w.dockerManager, w.haproxyManagers)
+	}
+}
+
+// watchCandidateHealthInBackground : watch the candidate and register it in haproxy once it's healthy
+// Candidate is removed if it doesn't become healthy, current version keeps serving the traffic
+func (m Manager) watchCandidateHealthInBackground(application core.Application, deployment core.Deployment, dockerManager *containermanger.Manager, haproxyManagers []*haproxymanager.Manager) {
+	ctx := context.Background()
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	_, err := m.watchDeploymentHealth(dockerManager, application.CandidateServiceName(), application.AutoRollback.Window(), deployment.ID, func() bool {
+		var record core.Application
+		err := record.FindById(ctx, dbWithoutTx, application.ID)
+		return err == nil && record.HasCandidate() && *record.CandidateDeploymentID == deployment.ID
+	})
+	if errors.Is(err, errDeploymentSuperseded) {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Stopped watching the health, as the candidate has been replaced\n", true)
+		return
+	}
+	if err != nil {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Removing the candidate, current version will keep serving the traffic\n", false)
+		m.removeCandidate(&application, deployment.ID, dockerManager, haproxyManagers)
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Deployment failed > \n"+err.Error()+"\n", true)
+		err = deployment.UpdateStatus(ctx, dbWithoutTx, core.DeploymentStatusFailed)
+		if err != nil {
+			log.Println("failed to update deployment status to failed", err)
+		}
+		return
+	}
+	err = m.registerCandidate(&application, deployment.ID, haproxyManagers)
+	if err != nil {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Deployment failed > \n"+err.Error()+"\n", true)
+		err = deployment.UpdateStatus(ctx, dbWithoutTx, core.DeploymentStatusFailed)
+		if err != nil {
+			log.Println("failed to update deployment status to failed", err)
+		}
+	}
+}
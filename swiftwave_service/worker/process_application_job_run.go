@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	containermanger "github.com/swiftwave-org/swiftwave/container_manager"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/core"
+	"github.com/swiftwave-org/swiftwave/swiftwave_service/manager"
+	"gorm.io/gorm"
+)
+
+func (m Manager) RunApplicationJob(request RunApplicationJobRequest, ctx context.Context, _ context.CancelFunc) error {
+	dbWithoutTx := m.ServiceManager.DbClient
+	jobRun := &core.ApplicationJobRun{}
+	err := jobRun.FindById(ctx, dbWithoutTx, request.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// return nil as don't want to requeue the job
+			return nil
+		}
+		return err
+	}
+	// run is already processed
+	if jobRun.Status != core.JobRunStatusPending {
+		return nil
+	}
+	var logs strings.Builder
+	exitCode, err := m.runApplicationJobHelper(jobRun, func(content string) {
+		logs.WriteString(content)
+	})
+	if err != nil {
+		logs.WriteString("Job run failed > " + err.Error() + "\n")
+		if exitCode == 0 {
+			exitCode = -1
+		}
+	}
+	err = jobRun.MarkAsCompleted(ctx, dbWithoutTx, exitCode, logs.String())
+	if err != nil {
+		log.Println("failed to update result of job run", err)
+	}
+	return nil
+}
+
+// runApplicationJobHelper : run the job as one-off swarm job with the image, environment and volumes of the deployment
+func (m Manager) runApplicationJobHelper(jobRun *core.ApplicationJobRun, addLog func(content string)) (int, error) {
+	ctx := context.Background()
+	dbWithoutTx := m.ServiceManager.DbClient
+	var application core.Application
+	err := application.FindById(ctx, dbWithoutTx, jobRun.ApplicationID)
+	if err != nil {
+		return -1, err
+	}
+	deployment := &core.Deployment{}
+	err = deployment.FindById(ctx, dbWithoutTx, jobRun.DeploymentID)
+	if err != nil {
+		addLog("Failed to fetch deployment " + jobRun.DeploymentID + "\n")
+		return -1, err
+	}
+	// fetch the swarm server
+	swarmManager, err := core.FetchSwarmManager(&dbWithoutTx)
+	if err != nil {
+		return -1, err
+	}
+	// create docker manager
+	dockerManager, err := manager.DockerClient(ctx, swarmManager)
+	if err != nil {
+		return -1, err
+	}
+	service, imageRegistryUsername, imageRegistryPassword, refetchImage, err := m.applicationService(ctx, dbWithoutTx, &application, deployment, dockerManager, addLog)
+	defer func() {
+		// prune config mounts
+		dockerManager.PruneConfig(application.ID)
+		// prune unused secrets
+		dockerManager.PruneSecret(application.ID)
+	}()
+	if err != nil {
+		return -1, err
+	}
+	err = jobRun.MarkAsRunning(ctx, dbWithoutTx)
+	if err != nil {
+		return -1, err
+	}
+	addLog("Running job with the image of deployment " + deployment.ID + "\n")
+	service.Name = fmt.Sprintf("%s-run-%d", application.Name, jobRun.ID)
+	service.CustomHealthCheck = containermanger.CustomHealthCheck{}
+	service.UpdateConfig = containermanger.UpdateConfig{}
+	exitCode, err := dockerManager.RunJob(service, imageRegistryUsername, imageRegistryPassword, refetchImage, application.JobConfig.Timeout(), func(line string) {
+		addLog(line + "\n")
+	})
+	if err != nil {
+		return -1, err
+	}
+	addLog(fmt.Sprintf("Job exited with code %d\n", exitCode))
+	return exitCode, nil
+}
+
+// deployJobHelper : mark the deployment as live, the next runs of the job will use it
+// Service of the application is removed, if it was deployed as a long-running service before
+func (m Manager) deployJobHelper(db *gorm.DB, application *core.Application, deployment *core.Deployment, dockerManager *containermanger.Manager) error {
+	ctx := context.Background()
+	dbWithoutTx := m.ServiceManager.DbClient
+	pubSubClient := m.ServiceManager.PubSubClient
+	// find current deployment and mark it as stalled
+	currentDeployment, err := core.FindCurrentDeployedDeploymentByApplicationId(ctx, *db, application.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	} else {
+		err = currentDeployment.UpdateStatus(ctx, *db, core.DeploymentStalled)
+		if err != nil {
+			return err
+		}
+	}
+	err = deployment.UpdateStatus(ctx, *db, core.DeploymentStatusDeployed)
+	if err != nil {
+		return err
+	}
+	err = db.Commit().Error
+	if err != nil {
+		return err
+	}
+	_, err = dockerManager.GetService(application.Name)
+	if err == nil {
+		addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Removing the long-running service of the application\n", false)
+		err = dockerManager.RemoveService(application.Name)
+		if err != nil {
+			log.Println("failed to remove service > "+application.Name, err)
+			addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Failed to remove the long-running service\n", false)
+		}
+	}
+	addPersistentDeploymentLog(dbWithoutTx, pubSubClient, deployment.ID, "Job deployed successfully, next runs will use this version\n", true)
+	return nil
+}
@@ -23,6 +23,21 @@ func (m Manager) EnqueueDeployApplicationRequestWithNoProxyUpdate(applicationId
 	})
 }
 
+func (m Manager) EnqueuePromoteCandidateRequest(applicationId string, deploymentId string) error {
+	return m.ServiceManager.TaskQueueClient.EnqueueTask(deployApplicationQueueName, DeployApplicationRequest{
+		AppId:             applicationId,
+		DeploymentId:      deploymentId,
+		IgnoreProxyUpdate: false,
+		PromoteCandidate:  true,
+	})
+}
+
+func (m Manager) EnqueueRunApplicationJobRequest(jobRunId uint) error {
+	return m.ServiceManager.TaskQueueClient.EnqueueTask(runApplicationJobQueueName, RunApplicationJobRequest{
+		Id: jobRunId,
+	})
+}
+
 func (m Manager) EnqueueDeleteApplicationRequest(applicationId string) error {
 	return m.ServiceManager.TaskQueueClient.EnqueueTask(deleteApplicationQueueName, DeleteApplicationRequest{
 		Id: applicationId,
@@ -29,6 +29,7 @@ const (
 	setupAndEnableProxyQueueName                               = "setup_and_enable_proxy"
 	deletePersistentVolumeQueueName                            = "delete_persistent_volume"
 	updateApplicationOnServerScheduleDeploymentUpdateQueueName = "update_application_on_server_schedule_deployment_status_update"
+	runApplicationJobQueueName                                 = "run_application_job"
 )
 
 // Request Payload
@@ -38,6 +39,12 @@ type DeployApplicationRequest struct {
 	AppId             string `json:"app_id"`
 	DeploymentId      string `json:"deployment_id"`
 	IgnoreProxyUpdate bool   `json:"ignore_proxy_update"`
+	PromoteCandidate  bool   `json:"promote_candidate"` // deploy the candidate in place of the current version
+}
+
+// RunApplicationJobRequest : request payload for run of job application
+type RunApplicationJobRequest struct {
+	Id uint `json:"id"`
 }
 
 // BuildApplicationRequest : request payload for deploy application